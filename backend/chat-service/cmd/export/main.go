@@ -0,0 +1,110 @@
+// Command export downloads a channel export from chat-service:
+//
+//	go run ./cmd/export -channel <channel_id> -format html -zip -token <jwt>
+//
+// The token may also be passed in the CHAT_TOKEN environment variable. Without -out
+// the file is saved into the current directory under the name chosen by the server
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	chatpb "chat-service/gen"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:810", "chat-service gRPC address")
+	token := flag.String("token", os.Getenv("CHAT_TOKEN"), "access token of the user")
+	channelID := flag.String("channel", "", "channel_id to export")
+	format := flag.String("format", "json", "export format: json, html or text")
+	bundle := flag.Bool("zip", false, "bundle the export with attachments into a zip archive")
+	out := flag.String("out", "", "output file path")
+	flag.Parse()
+
+	if *channelID == "" || *token == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	path, err := run(ctx, *addr, *token, *channelID, *format, *bundle, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "export failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("channel exported to", path)
+}
+
+func run(ctx context.Context, addr string, token string, channelID string, format string, bundle bool, out string) (string, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	stream, err := chatpb.NewConversationClient(conn).ExportChannel(ctx, &chatpb.ExportChannelRequest{
+		ChannelId: channelID,
+		Format:    format,
+		Zip:       bundle,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	file := first.GetFile()
+	if file == nil {
+		return "", errors.New("server did not describe the export file")
+	}
+
+	if out == "" {
+		out = filepath.Base(file.GetName())
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return "", err
+	}
+
+	if err := receive(stream, f); err != nil {
+		f.Close()
+		os.Remove(out)
+		return "", err
+	}
+
+	return out, f.Close()
+}
+
+func receive(stream chatpb.Conversation_ExportChannelClient, w io.Writer) error {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(chunk.GetData()); err != nil {
+			return err
+		}
+	}
+}
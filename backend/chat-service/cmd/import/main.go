@@ -0,0 +1,202 @@
+// Command import loads chat history exported from Telegram Desktop (result.json) or a Slack
+// workspace export (zip) into chat-service storage on behalf of an existing user:
+//
+//	go run ./cmd/import -config ./config/local.yaml -file result.json -owner alice -self user123
+//
+// It works with the storage and user-service directly, so run it next to chat-service.
+// Authors of the export are mapped with -users, a JSON object of external user ids to user_ids
+// or usernames, then by username, and get placeholder accounts otherwise
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"chat-service/internal/app"
+	"chat-service/internal/config"
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/usergrpc"
+	"chat-service/internal/lib/importer"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/services"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "path to config file")
+	source := flag.String("source", "", "export source: telegram or slack, detected by the file extension when empty")
+	file := flag.String("file", "", "path to result.json of Telegram or the Slack export zip")
+	owner := flag.String("owner", "", "user_id or username of the user who imports the history")
+	self := flag.String("self", "", "Telegram id of the owner in the export, e.g. user123")
+	usersPath := flag.String("users", "", "JSON file mapping external user ids to user_ids or usernames")
+	name := flag.String("name", "", "name of the chat created for a Slack workspace")
+	flag.Parse()
+
+	if *configPath == "" || *file == "" || *owner == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.MustLoadByPath(*configPath)
+	log := logger.SetupLogger(cfg.Yaml.Env)
+
+	archive, err := readArchive(*source, *file, *self, *name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		os.Exit(1)
+	}
+
+	mapping, err := readMapping(*usersPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		os.Exit(1)
+	}
+
+	storage := app.NewStorage(cfg)
+	defer storage.Close()
+
+	userClient := usergrpc.NewCachedClient(
+		usergrpc.New(cfg.Yaml.UserService.Address, cfg.Yaml.UserService.Timeout, cfg.DotEnv.Secrets.ServiceSecret),
+		cfg.Yaml.UserService.CacheTTL,
+		cfg.Yaml.UserService.BlocksCacheTTL,
+		cfg.Yaml.UserService.BatchWindow,
+		cfg.Yaml.UserService.BatchSize,
+	)
+
+	ownerID, err := resolveOwner(ctx, userClient, *owner)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		os.Exit(1)
+	}
+
+	eventBus := services.NewEventBus(log, storage.Updates)
+	managerService := services.NewManagerService(
+		log,
+		storage.Chats,
+		storage.Channels,
+		storage.Messages,
+		storage.Invites,
+		storage.Posts,
+		storage.Communities,
+		storage.Webhooks,
+		userClient,
+		storage.Transactor,
+		eventBus,
+		cfg.Yaml.App.InviteLinkBase,
+		cfg.Yaml.Webhooks.URLBase,
+	)
+	importService := services.NewImportService(log, managerService, storage.Chats, storage.Channels, storage.Messages, userClient)
+
+	report, err := importService.Import(context.WithValue(ctx, "user_id", ownerID), archive, mapping)
+	printReport(os.Stdout, report)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		os.Exit(1)
+	}
+}
+
+func readArchive(source string, path string, selfID string, name string) (importer.Archive, error) {
+	if source == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".zip":
+			source = importer.SourceSlack
+		case ".json":
+			source = importer.SourceTelegram
+		default:
+			return importer.Archive{}, errors.New("cannot detect the source, pass -source")
+		}
+	}
+
+	switch source {
+	case importer.SourceTelegram:
+		f, err := os.Open(path)
+		if err != nil {
+			return importer.Archive{}, err
+		}
+		defer f.Close()
+
+		return importer.ReadTelegram(f, selfID)
+	case importer.SourceSlack:
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		return importer.ReadSlack(path, name)
+	default:
+		return importer.Archive{}, fmt.Errorf("unknown source %q", source)
+	}
+}
+
+func readMapping(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping map[string]string
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// resolveOwner accepts a user_id or a username
+func resolveOwner(ctx context.Context, userClient *usergrpc.CachedClient, owner string) (string, error) {
+	usernames, err := userClient.GetUsernames(ctx, []string{owner})
+	if err != nil {
+		return "", err
+	}
+	if _, ok := usernames[owner]; ok {
+		return owner, nil
+	}
+
+	userIDs, err := userClient.GetUserIDs(ctx, []string{owner})
+	if err != nil {
+		return "", err
+	}
+	if userID, ok := userIDs[owner]; ok {
+		return userID, nil
+	}
+
+	return "", fmt.Errorf("owner %s: %w", owner, domain.ErrUserNotFound)
+}
+
+func printReport(w io.Writer, report domain.ImportReport) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "CHAT\tCHAT_ID\tCHANNELS\tMESSAGES")
+	for _, chat := range report.Chats {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", chat.Name, chat.ChatID, chat.Channels, chat.Messages)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "AUTHOR\tNAME\tUSERNAME\tPLACEHOLDER")
+	for _, user := range report.Users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\n", user.ExternalID, user.Name, user.Username, user.Placeholder)
+	}
+
+	if len(report.Skipped) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "SKIPPED\tREASON\tCOUNT")
+		for _, skip := range report.Skipped {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", skip.Chat, skip.Reason, skip.Count)
+		}
+	}
+
+	tw.Flush()
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"chat-service/internal/app"
 
 	"chat-service/internal/config"
+	"chat-service/internal/infrastructure/cache"
 	"chat-service/internal/lib/logger"
 )
 
@@ -23,6 +26,25 @@ func main() {
 	application := app.New(log, cfg)
 
 	go application.GRPCSrv.MustRun()
+	if application.HTTPSrv != nil {
+		go application.HTTPSrv.MustRun()
+	}
+	if application.MetricsSrv != nil {
+		go application.MetricsSrv.MustRun()
+	}
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	go application.Retention.Run(backgroundCtx)
+	go application.Updates.Run(backgroundCtx)
+	if application.Archive != nil && cfg.Yaml.Archive.RunJob && cfg.Yaml.Archive.Interval > 0 {
+		go application.Archive.Run(backgroundCtx)
+	}
+	if application.Cache != nil && application.Storage.Bus != nil {
+		go application.Cache.Share(backgroundCtx, application.Storage.Bus)
+	}
+	if application.Cache != nil && cfg.Yaml.Cache.StatsInterval > 0 {
+		go reportCacheStats(backgroundCtx, log, application.Cache, cfg.Yaml.Cache.StatsInterval)
+	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
@@ -30,7 +52,40 @@ func main() {
 	sign := <-stop
 	log.Info("stopping application", slog.String("signal", sign.String()))
 
+	stopBackground()
 	application.GRPCSrv.Stop()
+	if application.HTTPSrv != nil {
+		application.HTTPSrv.Stop()
+	}
+	if application.MetricsSrv != nil {
+		application.MetricsSrv.Stop()
+	}
+	if err := application.Storage.Close(); err != nil {
+		log.Error("failed to close storage", logger.Err(err))
+	}
 
 	log.Info("application stopped")
 }
+
+// reportCacheStats logs hits and misses of the cache every interval until ctx is done
+func reportCacheStats(ctx context.Context, log *slog.Logger, storageCache *cache.Cache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats := storageCache.Stats()
+		log.Info("cache stats",
+			slog.Uint64("chat_hits", stats.ChatHits),
+			slog.Uint64("chat_misses", stats.ChatMisses),
+			slog.Uint64("channel_hits", stats.ChannelHits),
+			slog.Uint64("channel_misses", stats.ChannelMisses),
+			slog.Uint64("invalidations", stats.Invalidations),
+			slog.Int("entries", stats.Entries))
+	}
+}
@@ -0,0 +1,166 @@
+// Command migrate applies and reverts versioned migrations of the chat-service databases:
+//
+//	go run ./cmd/migrate -config ./config/local.yaml up
+//	go run ./cmd/migrate -config ./config/local.yaml -steps 1 down
+//	go run ./cmd/migrate -config ./config/local.yaml -db postgres status
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"chat-service/internal/config"
+	"chat-service/internal/infrastructure/mongodb"
+	"chat-service/internal/infrastructure/postgres"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "path to config file")
+	steps := flag.Int("steps", 1, "number of migrations to revert with down")
+	db := flag.String("db", config.StorageDriverMongoDB, "database to migrate: mongodb or postgres")
+	flag.Parse()
+
+	if *configPath == "" || flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: migrate -config <path> [-db mongodb|postgres] [-steps n] up|down|status")
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoadByPath(*configPath)
+
+	var m migrator
+	switch *db {
+	case config.StorageDriverMongoDB:
+		storage := mongodb.New(cfg.DotEnv.Storage.StoragePath, cfg.Yaml.Storage)
+		defer storage.Close()
+		m = mongoMigrator{storage}
+	case config.StorageDriverPostgres:
+		if cfg.DotEnv.Storage.PostgresPath == "" {
+			fmt.Fprintln(os.Stderr, "migrate failed: POSTGRES_PATH is not set")
+			os.Exit(2)
+		}
+		storage := postgres.New(cfg.DotEnv.Storage.PostgresPath)
+		defer storage.Close()
+		m = postgresMigrator{storage}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown database %q, use mongodb or postgres\n", *db)
+		os.Exit(2)
+	}
+
+	if err := run(context.Background(), m, flag.Arg(0), *steps); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate failed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, m migrator, command string, steps int) error {
+	switch command {
+	case "up":
+		applied, err := m.up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d %s\n", migration.version, migration.name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		reverted, err := m.down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d %s\n", migration.version, migration.name)
+		}
+		return err
+
+	case "status":
+		statuses, err := m.statuses(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED_AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.applied {
+				appliedAt = status.appliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", status.version, status.name, appliedAt)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown command %q, use up, down or status", command)
+	}
+}
+
+type migration struct {
+	version int
+	name    string
+}
+
+type migrationStatus struct {
+	migration
+	applied   bool
+	appliedAt time.Time
+}
+
+// migrator hides the migration types of the storage packages
+type migrator interface {
+	up(ctx context.Context) ([]migration, error)
+	down(ctx context.Context, steps int) ([]migration, error)
+	statuses(ctx context.Context) ([]migrationStatus, error)
+}
+
+type mongoMigrator struct {
+	storage *mongodb.MongoDB
+}
+
+func (m mongoMigrator) up(ctx context.Context) ([]migration, error) {
+	applied, err := m.storage.MigrateUp(ctx)
+	return convertMigrations(applied, func(m mongodb.Migration) migration { return migration{m.Version, m.Name} }), err
+}
+
+func (m mongoMigrator) down(ctx context.Context, steps int) ([]migration, error) {
+	reverted, err := m.storage.MigrateDown(ctx, steps)
+	return convertMigrations(reverted, func(m mongodb.Migration) migration { return migration{m.Version, m.Name} }), err
+}
+
+func (m mongoMigrator) statuses(ctx context.Context) ([]migrationStatus, error) {
+	statuses, err := m.storage.MigrationStatuses(ctx)
+	return convertMigrations(statuses, func(s mongodb.MigrationStatus) migrationStatus {
+		return migrationStatus{migration{s.Version, s.Name}, s.Applied, s.AppliedAt}
+	}), err
+}
+
+type postgresMigrator struct {
+	storage *postgres.Postgres
+}
+
+func (m postgresMigrator) up(ctx context.Context) ([]migration, error) {
+	applied, err := m.storage.MigrateUp(ctx)
+	return convertMigrations(applied, func(m postgres.Migration) migration { return migration{m.Version, m.Name} }), err
+}
+
+func (m postgresMigrator) down(ctx context.Context, steps int) ([]migration, error) {
+	reverted, err := m.storage.MigrateDown(ctx, steps)
+	return convertMigrations(reverted, func(m postgres.Migration) migration { return migration{m.Version, m.Name} }), err
+}
+
+func (m postgresMigrator) statuses(ctx context.Context) ([]migrationStatus, error) {
+	statuses, err := m.storage.MigrationStatuses(ctx)
+	return convertMigrations(statuses, func(s postgres.MigrationStatus) migrationStatus {
+		return migrationStatus{migration{s.Version, s.Name}, s.Applied, s.AppliedAt}
+	}), err
+}
+
+func convertMigrations[From any, To any](from []From, convert func(From) To) []To {
+	to := make([]To, 0, len(from))
+	for _, item := range from {
+		to = append(to, convert(item))
+	}
+	return to
+}
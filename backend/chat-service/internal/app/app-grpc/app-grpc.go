@@ -25,6 +25,7 @@ func New(log *slog.Logger,
 	conversationService interfaces.ConversationService,
 	viewService interfaces.ViewService,
 	managerService interfaces.ManagerService,
+	voiceService interfaces.VoiceService,
 	port int,
 	appSecret string,
 ) *App {
@@ -33,7 +34,7 @@ func New(log *slog.Logger,
 		grpc.StreamInterceptor(middleware.StreamAuthInterceptor(appSecret)),
 	)
 
-	chatgrpc.Register(gRPCServer, conversationService, viewService, managerService)
+	chatgrpc.Register(gRPCServer, conversationService, viewService, managerService, voiceService)
 
 	return &App{
 		log:        log,
@@ -0,0 +1,101 @@
+package apphttp
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"chat-service/internal/domain/interfaces"
+	httpcontroller "chat-service/internal/http"
+	"chat-service/internal/lib/logger"
+)
+
+// App serves incoming webhooks, requests are authorized by the token in the webhook url
+type App struct {
+	log        *slog.Logger
+	httpServer *http.Server
+	port       int
+}
+
+func New(log *slog.Logger,
+	conversationService interfaces.ConversationService,
+	port int,
+	timeout time.Duration,
+	rateLimit float64,
+	burst int,
+) *App {
+	mux := http.NewServeMux()
+	httpcontroller.Register(mux, log, conversationService, rateLimit, burst)
+
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: timeout,
+		ReadTimeout:       timeout,
+		WriteTimeout:      timeout,
+	}
+
+	return &App{
+		log:        log,
+		httpServer: httpServer,
+		port:       port,
+	}
+}
+
+// NewMetrics serves expvar metrics at /debug/vars
+func NewMetrics(log *slog.Logger, port int, timeout time.Duration) *App {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: timeout,
+		ReadTimeout:       timeout,
+		WriteTimeout:      timeout,
+	}
+
+	return &App{
+		log:        log,
+		httpServer: httpServer,
+		port:       port,
+	}
+}
+
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *App) Run() error {
+	const op = "apphttp.Run"
+
+	log := a.log.With(slog.String("operation", op), slog.Int("port", a.port))
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("http server is running", slog.String("address", l.Addr().String()))
+
+	if err := a.httpServer.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (a *App) Stop() {
+	const op = "apphttp.Stop"
+
+	log := a.log.With(slog.String("operation", op))
+	log.Info("http server is stopping")
+	if err := a.httpServer.Shutdown(context.Background()); err != nil {
+		log.Error("failed to stop http server", logger.Err(err))
+	}
+}
@@ -1,16 +1,32 @@
 package app
 
 import (
+	"context"
+	"expvar"
 	"log/slog"
 
 	appgrpc "chat-service/internal/app/app-grpc"
+	apphttp "chat-service/internal/app/app-http"
 	"chat-service/internal/config"
-	"chat-service/internal/infrastructure/mongodb"
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/cache"
+	"chat-service/internal/infrastructure/usergrpc"
 	"chat-service/internal/services"
 )
 
 type App struct {
 	GRPCSrv *appgrpc.App
+	// HTTPSrv is nil when webhooks.port is zero
+	HTTPSrv *apphttp.App
+	// MetricsSrv is nil when metrics.port is zero
+	MetricsSrv *apphttp.App
+	Retention  *services.RetentionService
+	Updates    *services.UpdatesService
+	// Archive is nil when archive.store is empty
+	Archive *services.ArchiveService
+	Storage *Storage
+	// Cache is nil when cache.ttl is zero
+	Cache *cache.Cache
 }
 
 func New(
@@ -18,13 +34,25 @@ func New(
 	cfg *config.Config,
 ) *App {
 
-	storage := mongodb.New(
-		cfg.DotEnv.Storage.StoragePath,
-		cfg.Yaml.Storage.StorageName,
-		cfg.Yaml.Storage.ChatsColName,
-		cfg.Yaml.Storage.ChannelsColName,
-		cfg.Yaml.Storage.MessagesColName,
-	)
+	storage := NewStorage(cfg)
+	if cfg.Yaml.Storage.MigrateOnStart {
+		if err := storage.MigrateUp(context.Background(), log); err != nil {
+			panic(err)
+		}
+	}
+	log.Info("storage initialized", slog.String("driver", cfg.Yaml.Storage.Driver))
+
+	if cfg.Yaml.Archive.Store != "" {
+		storage.WithArchive(cfg, log)
+		log.Info("archive initialized", slog.String("store", cfg.Yaml.Archive.Store))
+	}
+
+	var storageCache *cache.Cache
+	if cfg.Yaml.Cache.TTL > 0 {
+		storageCache = cache.New(log, cfg.Yaml.Cache.TTL, cfg.Yaml.Cache.MaxEntries)
+		storage.WithCache(storageCache)
+		expvar.Publish("cache", storageCache.Var())
+	}
 
 	// chatService := services.NewChatService(log, storage, storage)
 	// channelService := services.NewChannelService(log, storage, storage, storage)
@@ -36,20 +64,134 @@ func New(
 	// 	cfg.Yaml.App.MaxMessageLength,
 	// )
 
-	conversationService := services.NewConversationService(log, storage, storage, storage, cfg.Yaml.App.MaxMessageLength)
-	viewService := services.NewViewService(log, storage, storage, storage)
-	managerService := services.NewManagerService(log, storage, storage, storage)
+	userClient := usergrpc.NewCachedClient(
+		usergrpc.New(cfg.Yaml.UserService.Address, cfg.Yaml.UserService.Timeout, cfg.DotEnv.Secrets.ServiceSecret),
+		cfg.Yaml.UserService.CacheTTL,
+		cfg.Yaml.UserService.BlocksCacheTTL,
+		cfg.Yaml.UserService.BatchWindow,
+		cfg.Yaml.UserService.BatchSize,
+	)
+
+	eventBus := services.NewEventBus(log, storage.Updates)
+
+	conversationService := services.NewConversationService(
+		log,
+		storage.Chats,
+		storage.Channels,
+		storage.Messages,
+		storage.Posts,
+		storage.Webhooks,
+		userClient,
+		storage.Transactor,
+		eventBus,
+		cfg.Yaml.App.MaxMessageLength,
+	)
+	viewService := services.NewViewService(
+		log,
+		storage.Chats,
+		storage.Channels,
+		storage.Messages,
+		storage.Updates,
+		storage.Posts,
+		storage.Communities,
+		userClient,
+	)
+	managerService := services.NewManagerService(
+		log,
+		storage.Chats,
+		storage.Channels,
+		storage.Messages,
+		storage.Invites,
+		storage.Posts,
+		storage.Communities,
+		storage.Webhooks,
+		userClient,
+		storage.Transactor,
+		eventBus,
+		cfg.Yaml.App.InviteLinkBase,
+		cfg.Yaml.Webhooks.URLBase,
+	)
+	voiceService := services.NewVoiceService(
+		log,
+		storage.Chats,
+		storage.Channels,
+		storage.Messages,
+		userClient,
+		eventBus,
+		cfg.Yaml.App.CallRingTimeout,
+		cfg.Yaml.App.CallReconnectTimeout,
+	)
+
+	retentionService := services.NewRetentionService(
+		log,
+		storage.Chats,
+		storage.Channels,
+		storage.Messages,
+		eventBus,
+		domain.RetentionPolicy{MaxAge: cfg.Yaml.Retention.MaxAge, LegalHold: cfg.Yaml.Retention.LegalHold},
+		cfg.Yaml.Retention.Interval,
+		cfg.Yaml.Retention.BatchSize,
+		cfg.Yaml.Retention.BatchPause,
+	)
+
+	updatesService := services.NewUpdatesService(
+		log,
+		storage.Updates,
+		cfg.Yaml.App.UpdatesRetention,
+		cfg.Yaml.App.UpdatesTrimInterval,
+	)
+
+	var archiveService *services.ArchiveService
+	if storage.Archive != nil {
+		archiveService = services.NewArchiveService(
+			log,
+			storage.Chats,
+			storage.Channels,
+			storage.Archive,
+			eventBus,
+			domain.RetentionPolicy{MaxAge: cfg.Yaml.Retention.MaxAge, LegalHold: cfg.Yaml.Retention.LegalHold},
+			cfg.Yaml.Archive.MaxAge,
+			cfg.Yaml.Archive.Interval,
+			cfg.Yaml.Archive.BatchSize,
+			cfg.Yaml.Archive.BatchPause,
+		)
+	}
 
 	appgrpc := appgrpc.New(
 		log,
 		conversationService,
 		viewService,
 		managerService,
+		voiceService,
 		cfg.Yaml.GRPC.Port,
 		cfg.DotEnv.Secrets.AppSecret,
 	)
 
+	var httpSrv *apphttp.App
+	if cfg.Yaml.Webhooks.Port > 0 {
+		httpSrv = apphttp.New(
+			log,
+			conversationService,
+			cfg.Yaml.Webhooks.Port,
+			cfg.Yaml.Webhooks.Timeout,
+			cfg.Yaml.Webhooks.RateLimit,
+			cfg.Yaml.Webhooks.Burst,
+		)
+	}
+
+	var metricsSrv *apphttp.App
+	if cfg.Yaml.Metrics.Port > 0 {
+		metricsSrv = apphttp.NewMetrics(log, cfg.Yaml.Metrics.Port, cfg.Yaml.Metrics.Timeout)
+	}
+
 	return &App{
-		GRPCSrv: appgrpc,
+		GRPCSrv:    appgrpc,
+		HTTPSrv:    httpSrv,
+		MetricsSrv: metricsSrv,
+		Retention:  retentionService,
+		Updates:    updatesService,
+		Archive:    archiveService,
+		Storage:    storage,
+		Cache:      storageCache,
 	}
 }
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"chat-service/internal/config"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/infrastructure/archive"
+	"chat-service/internal/infrastructure/boltdb"
+	"chat-service/internal/infrastructure/cache"
+	"chat-service/internal/infrastructure/mongodb"
+	"chat-service/internal/infrastructure/postgres"
+)
+
+// Storage holds the providers chosen by storage.driver, all data lives in MongoDB,
+// Postgres or the embedded file
+type Storage struct {
+	// Mongo is nil unless the driver is mongodb
+	Mongo *mongodb.MongoDB
+	// Postgres is nil unless the driver is postgres
+	Postgres *postgres.Postgres
+	// Embedded is nil unless the driver is embedded
+	Embedded *boltdb.BoltDB
+
+	Chats       interfaces.ChatProvider
+	Channels    interfaces.ChannelProvider
+	Messages    interfaces.MessageProvider
+	Communities interfaces.CommunityProvider
+	Posts       interfaces.PostProvider
+	Updates     interfaces.UpdateProvider
+	Invites     interfaces.InviteProvider
+	Webhooks    interfaces.WebhookProvider
+	// Transactor covers writes of every provider
+	Transactor interfaces.Transactor
+	// Archive is nil unless archive.store is set
+	Archive *archive.Archive
+	// Bus carries cache invalidations between replicas, it is nil for the embedded driver
+	// whose file is opened by a single replica
+	Bus cache.Bus
+}
+
+// cacheChannel is the channel of the Bus
+const cacheChannel = "cache_invalidations"
+
+// mustSupportTransactions refuses a standalone MongoDB, writes that touch several documents
+// and the update log rely on transactions
+func mustSupportTransactions(mongo *mongodb.MongoDB) {
+	if !mongo.Transactional() {
+		panic("mongodb does not support transactions, run it as a replica set (a single-node one is enough) or a sharded cluster")
+	}
+}
+
+func NewStorage(cfg *config.Config) *Storage {
+	switch cfg.Yaml.Storage.Driver {
+	case config.StorageDriverMongoDB:
+		mongo := mongodb.New(cfg.DotEnv.Storage.StoragePath, cfg.Yaml.Storage)
+		mustSupportTransactions(mongo)
+		return &Storage{
+			Mongo:       mongo,
+			Chats:       mongo,
+			Channels:    mongo,
+			Messages:    mongo,
+			Communities: mongo,
+			Posts:       mongo,
+			Updates:     mongo,
+			Invites:     mongo,
+			Webhooks:    mongo,
+			Transactor:  mongo,
+			Bus:         mongo.Notifier(cacheChannel),
+		}
+
+	case config.StorageDriverPostgres:
+		if cfg.DotEnv.Storage.PostgresPath == "" {
+			panic("POSTGRES_PATH is required with the postgres storage driver")
+		}
+
+		pg := postgres.New(cfg.DotEnv.Storage.PostgresPath)
+		return &Storage{
+			Postgres:    pg,
+			Chats:       pg,
+			Channels:    pg,
+			Messages:    pg,
+			Communities: pg,
+			Posts:       pg,
+			Updates:     pg,
+			Invites:     pg,
+			Webhooks:    pg,
+			Transactor:  pg,
+			Bus:         pg.Notifier(cacheChannel),
+		}
+
+	case config.StorageDriverEmbedded:
+		embedded := boltdb.New(cfg.Yaml.Storage.EmbeddedPath)
+		return &Storage{
+			Embedded:    embedded,
+			Chats:       embedded,
+			Channels:    embedded,
+			Messages:    embedded,
+			Communities: embedded,
+			Posts:       embedded,
+			Updates:     embedded,
+			Invites:     embedded,
+			Webhooks:    embedded,
+			Transactor:  embedded,
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown storage driver %q", cfg.Yaml.Storage.Driver))
+	}
+}
+
+// WithArchive keeps old messages in the archive set by archive.store, Messages read through to it
+func (storage *Storage) WithArchive(cfg *config.Config, log *slog.Logger) {
+	archiveCfg := cfg.Yaml.Archive
+
+	var store archive.Store
+	switch archiveCfg.Store {
+	case config.ArchiveStoreFilesystem:
+		store = archive.NewFileStore(archiveCfg.Path)
+
+	case config.ArchiveStoreS3:
+		s3, err := archive.NewS3Store(
+			archiveCfg.Endpoint,
+			cfg.DotEnv.Storage.ArchiveAccessKey,
+			cfg.DotEnv.Storage.ArchiveSecretKey,
+			archiveCfg.Bucket,
+			archiveCfg.UseSSL,
+		)
+		if err != nil {
+			panic(err)
+		}
+		store = s3
+
+	default:
+		panic(fmt.Sprintf("unknown archive store %q", archiveCfg.Store))
+	}
+
+	storage.Archive = archive.New(store, storage.Messages, storage.Channels, archiveCfg.SegmentSpan, archiveCfg.ManifestTTL)
+	storage.Messages = storage.Archive.MessageProvider()
+	storage.Transactor = storage.Archive.Transactor(storage.Transactor, log)
+}
+
+// WithCache reads chats and channels through the cache and makes writes drop the entries they change
+func (storage *Storage) WithCache(c *cache.Cache) {
+	storage.Chats = c.ChatProvider(storage.Chats)
+	storage.Channels = c.ChannelProvider(storage.Channels)
+	storage.Communities = c.CommunityProvider(storage.Communities)
+	storage.Transactor = c.Transactor(storage.Transactor)
+}
+
+// MigrateUp applies pending migrations of every database in use, the embedded file needs none
+func (storage *Storage) MigrateUp(ctx context.Context, log *slog.Logger) error {
+	if storage.Mongo != nil {
+		applied, err := storage.Mongo.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			log.Info("migration applied", slog.String("database", "mongodb"), slog.Int("version", migration.Version), slog.String("name", migration.Name))
+		}
+	}
+
+	if storage.Postgres != nil {
+		applied, err := storage.Postgres.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			log.Info("migration applied", slog.String("database", "postgres"), slog.Int("version", migration.Version), slog.String("name", migration.Name))
+		}
+	}
+
+	return nil
+}
+
+func (storage *Storage) Close() error {
+	switch {
+	case storage.Postgres != nil:
+		return storage.Postgres.Close()
+	case storage.Embedded != nil:
+		return storage.Embedded.Close()
+	default:
+		return storage.Mongo.Close()
+	}
+}
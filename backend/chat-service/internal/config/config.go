@@ -21,25 +21,48 @@ type DotEnvConfig struct {
 }
 
 type DotEnvStorage struct {
+	// StoragePath is the MongoDB connection string, it is used only with the mongodb storage driver
 	StoragePath string
+	// PostgresPath is the connection string used with the postgres storage driver
+	PostgresPath string
+	// ArchiveAccessKey and ArchiveSecretKey are credentials of the s3 archive store
+	ArchiveAccessKey string
+	ArchiveSecretKey string
 }
 
 type SecretsConfig struct {
 	AppSecret string
+	// ServiceSecret authenticates chat-service to the Internal gRPC service of user-service
+	ServiceSecret string
 }
 
 // Config opts from yaml file
 type YamlConfig struct {
-	App     AppConfig   `yaml:"app"`
-	GRPC    GRPCConfig  `yaml:"grpc"`
-	Storage YamlStorage `yaml:"storage"`
+	App         AppConfig         `yaml:"app"`
+	GRPC        GRPCConfig        `yaml:"grpc"`
+	Storage     YamlStorage       `yaml:"storage"`
+	UserService UserServiceConfig `yaml:"user_service"`
+	Retention   RetentionConfig   `yaml:"retention"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Archive     ArchiveConfig     `yaml:"archive"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
 
 	Env      string        `yaml:"env" env-default:"local"`
 	TokenTTL time.Duration `yaml:"token_ttl" env-required:"true"`
 }
 
 type AppConfig struct {
-	MaxMessageLength int `yaml:"max_message_length"`
+	MaxMessageLength int           `yaml:"max_message_length"`
+	UpdatesRetention time.Duration `yaml:"updates_retention" env-default:"720h"`
+	// UpdatesTrimInterval is how often updates older than UpdatesRetention are deleted
+	UpdatesTrimInterval time.Duration `yaml:"updates_trim_interval" env-default:"1h"`
+	InviteLinkBase      string        `yaml:"invite_link_base"`
+
+	// A call is missed after CallRingTimeout. Call parties and voice channel participants
+	// are disconnected after CallReconnectTimeout without a signaling stream
+	CallRingTimeout      time.Duration `yaml:"call_ring_timeout" env-default:"45s"`
+	CallReconnectTimeout time.Duration `yaml:"call_reconnect_timeout" env-default:"15s"`
 }
 
 type GRPCConfig struct {
@@ -47,11 +70,109 @@ type GRPCConfig struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+type UserServiceConfig struct {
+	Address string        `yaml:"address"`
+	Timeout time.Duration `yaml:"timeout"`
+
+	CacheTTL       time.Duration `yaml:"cache_ttl" env-default:"5m"`
+	BlocksCacheTTL time.Duration `yaml:"blocks_cache_ttl" env-default:"30s"`
+	BatchWindow    time.Duration `yaml:"batch_window" env-default:"5ms"`
+	BatchSize      int           `yaml:"batch_size" env-default:"100"`
+}
+
+// RetentionConfig is the global message retention policy and the schedule of the purge job.
+// Zero max_age keeps messages forever unless a chat or a channel sets its own policy
+type RetentionConfig struct {
+	MaxAge     time.Duration `yaml:"max_age" env-default:"0s"`
+	LegalHold  bool          `yaml:"legal_hold"`
+	Interval   time.Duration `yaml:"interval" env-default:"1h"`
+	BatchSize  int32         `yaml:"batch_size" env-default:"500"`
+	BatchPause time.Duration `yaml:"batch_pause" env-default:"100ms"`
+}
+
+// CacheConfig is the cache of chats and channels read to check access to a channel, zero ttl disables it.
+// Replicas sharing mongodb or postgres share invalidations through the database. Hits and misses
+// are served as metrics and logged every stats_interval, zero stats_interval turns the log off
+type CacheConfig struct {
+	TTL           time.Duration `yaml:"ttl" env-default:"1m"`
+	MaxEntries    int           `yaml:"max_entries" env-default:"100000"`
+	StatsInterval time.Duration `yaml:"stats_interval" env-default:"10m"`
+}
+
+// MetricsConfig is the HTTP endpoint serving expvar metrics at /debug/vars, zero port turns it off
+type MetricsConfig struct {
+	Port    int           `yaml:"port"`
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+}
+
+// ArchiveConfig is the cold storage of old messages, an empty store turns it off. Messages older than
+// max_age are moved to compressed segments, each holding messages of one segment_span. The move runs
+// every interval on the replica that sets run_job, only one replica of the service may set it:
+// replicas moving the same channel write conflicting segments and manifests
+type ArchiveConfig struct {
+	Store string `yaml:"store"`
+	// Path is the directory of the filesystem store
+	Path string `yaml:"path" env-default:"./archive"`
+	// Endpoint, Bucket and UseSSL locate the s3 store
+	Endpoint string `yaml:"endpoint"`
+	Bucket   string `yaml:"bucket"`
+	UseSSL   bool   `yaml:"use_ssl"`
+
+	MaxAge      time.Duration `yaml:"max_age" env-default:"8760h"`
+	SegmentSpan time.Duration `yaml:"segment_span" env-default:"720h"`
+	ManifestTTL time.Duration `yaml:"manifest_ttl" env-default:"1m"`
+	Interval    time.Duration `yaml:"interval" env-default:"24h"`
+	BatchSize   int32         `yaml:"batch_size" env-default:"500"`
+	BatchPause  time.Duration `yaml:"batch_pause" env-default:"100ms"`
+	RunJob      bool          `yaml:"run_job" env-default:"false"`
+}
+
+// WebhooksConfig is the HTTP endpoint of incoming webhooks, zero port turns it off. url_base is the
+// public address of the endpoint that webhook urls are built from. Every webhook may post
+// rate_limit messages per second with bursts of burst messages
+type WebhooksConfig struct {
+	Port      int           `yaml:"port"`
+	Timeout   time.Duration `yaml:"timeout" env-default:"10s"`
+	URLBase   string        `yaml:"url_base"`
+	RateLimit float64       `yaml:"rate_limit" env-default:"1"`
+	Burst     int           `yaml:"burst" env-default:"10"`
+}
+
+const (
+	ArchiveStoreFilesystem = "filesystem"
+	ArchiveStoreS3         = "s3"
+)
+
+const (
+	StorageDriverMongoDB  = "mongodb"
+	StorageDriverPostgres = "postgres"
+	// StorageDriverEmbedded keeps all data in a single file at EmbeddedPath and needs no database server
+	StorageDriverEmbedded = "embedded"
+)
+
 type YamlStorage struct {
-	StorageName     string `yaml:"storage_name"`
-	ChatsColName    string `yaml:"chats_collection"`
-	ChannelsColName string `yaml:"channels_collection"`
-	MessagesColName string `yaml:"messages_collection"`
+	// Driver selects the database keeping all data: mongodb, postgres or the embedded file
+	Driver string `yaml:"driver" env-default:"mongodb"`
+	// EmbeddedPath is the file of the embedded driver, ":memory:" keeps data only while the service runs
+	EmbeddedPath string `yaml:"embedded_path" env-default:":memory:"`
+
+	StorageName        string `yaml:"storage_name"`
+	ChatsColName       string `yaml:"chats_collection"`
+	ChannelsColName    string `yaml:"channels_collection"`
+	MessagesColName    string `yaml:"messages_collection"`
+	UpdatesColName     string `yaml:"updates_collection"`
+	CountersColName    string `yaml:"counters_collection"`
+	InvitesColName     string `yaml:"invites_collection"`
+	JoinsColName       string `yaml:"joins_collection"`
+	PostsColName       string `yaml:"posts_collection"`
+	CommunitiesColName string `yaml:"communities_collection"`
+	WebhooksColName    string `yaml:"webhooks_collection" env-default:"webhooks"`
+	MigrationsColName  string `yaml:"migrations_collection" env-default:"migrations"`
+	// NotificationsColName keeps messages replicas send each other, such as cache invalidations
+	NotificationsColName string `yaml:"notifications_collection" env-default:"notifications"`
+
+	// MigrateOnStart applies pending migrations when the service starts
+	MigrateOnStart bool `yaml:"migrate_on_start" env-default:"true"`
 }
 
 func MustLoad() *Config {
@@ -75,12 +196,22 @@ func MustLoadByPath(configPath string) *Config {
 		panic("cannot read config: " + err.Error())
 	}
 
+	storagePath := os.Getenv("STORAGE_PATH")
+	if cfg.Yaml.Storage.Driver == StorageDriverMongoDB {
+		storagePath = getEnvParam("STORAGE_PATH", "")
+	}
+
 	cfg.DotEnv = DotEnvConfig{
 		Storage: DotEnvStorage{
-			StoragePath: getEnvParam("STORAGE_PATH", ""),
+			StoragePath:  storagePath,
+			PostgresPath: os.Getenv("POSTGRES_PATH"),
+
+			ArchiveAccessKey: os.Getenv("ARCHIVE_ACCESS_KEY"),
+			ArchiveSecretKey: os.Getenv("ARCHIVE_SECRET_KEY"),
 		},
 		Secrets: SecretsConfig{
-			AppSecret: getEnvParam("APP_SECRET", "app-secret"),
+			AppSecret:     getEnvParam("APP_SECRET", "app-secret"),
+			ServiceSecret: getEnvParam("SERVICE_SECRET", "service-secret"),
 		},
 	}
 
@@ -1,20 +1,169 @@
 package domain
 
-import chatpb "chat-service/gen"
+import (
+	chatpb "chat-service/gen"
+	"time"
+)
 
 type ChatInfo struct {
 	ID            string
 	Type          string
 	Name          string
+	Topic         string
+	Description   string
+	Archived      bool
+	CommunityID   string
 	MemberIDs     []string
+	MemberNames   map[string]string
+	Roles         map[string]string
 	ProtoChannels []*chatpb.Channel
 }
 
 type ChatPreview struct {
-	ID   string
-	Name string
+	ID          string
+	Name        string
+	Archived    bool
+	CommunityID string
+	// PeerID is the other member of a private chat
+	PeerID string
 }
 
 type NewMessageEvent struct {
 	Message *Message
 }
+
+type UpdatesDifference struct {
+	Updates        []*Update
+	Seq            int64
+	ResyncRequired bool
+	HasMore        bool
+}
+
+// VoiceParticipant is a user connected to a voice channel, voice state is kept in memory only
+type VoiceParticipant struct {
+	UserID   string
+	Muted    bool
+	Deafened bool
+	JoinedAt time.Time
+}
+
+// Signal is a WebRTC signaling message relayed between voice participants
+type Signal struct {
+	ChannelID  string
+	CallID     string
+	FromUserID string
+	ToUserID   string
+	Type       string
+	Payload    string
+}
+
+const (
+	SignalOffer      = "offer"
+	SignalAnswer     = "answer"
+	SignalCandidate  = "candidate"
+	SignalPeerJoined = "peer_joined"
+	SignalPeerLeft   = "peer_left"
+)
+
+// Call is a one-to-one call in a private chat, calls are kept in memory until they end
+type Call struct {
+	ID        string
+	ChatID    string
+	CallerID  string
+	CalleeID  string
+	Video     bool
+	State     string
+	EndReason string
+
+	CreatedAt  time.Time
+	AcceptedAt time.Time
+	EndedAt    time.Time
+}
+
+// Duration is the talk time of an ended call, zero if the call was never accepted
+func (call Call) Duration() time.Duration {
+	if call.AcceptedAt.IsZero() || call.EndedAt.IsZero() {
+		return 0
+	}
+	return call.EndedAt.Sub(call.AcceptedAt)
+}
+
+func (call Call) HasParty(userID string) bool {
+	return call.CallerID == userID || call.CalleeID == userID
+}
+
+// OtherParty returns the callee for the caller and the caller for the callee
+func (call Call) OtherParty(userID string) string {
+	if userID == call.CallerID {
+		return call.CalleeID
+	}
+	return call.CallerID
+}
+
+const (
+	CallStateRinging = "ringing"
+	CallStateActive  = "active"
+	CallStateEnded   = "ended"
+)
+
+const (
+	CallEndDeclined = "declined"
+	CallEndCanceled = "canceled"
+	// CallEndMissed means nobody answered before the ring timeout
+	CallEndMissed = "missed"
+	// CallEndTimeout means both parties lost their signaling streams for longer than the reconnect timeout
+	CallEndTimeout = "timeout"
+	CallEndHangup  = "hangup"
+)
+
+// ExportFile describes the file produced by a channel export
+type ExportFile struct {
+	Name        string
+	ContentType string
+}
+
+// ImportReport describes what an import created and what it skipped
+type ImportReport struct {
+	Chats   []ImportedChat
+	Users   []ImportedUser
+	Skipped []ImportSkip
+}
+
+type ImportedChat struct {
+	ExternalID string
+	Name       string
+	ChatID     string
+	Channels   int
+	Messages   int
+}
+
+// ImportedUser maps an author of the export to the account their messages were imported under
+type ImportedUser struct {
+	ExternalID  string
+	Name        string
+	UserID      string
+	Username    string
+	Placeholder bool
+}
+
+type ImportSkip struct {
+	Chat   string
+	Reason string
+	Count  int
+}
+
+// PurgeReport describes messages deleted by one run of the retention job
+type PurgeReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Channels   []PurgedChannel
+	Messages   int
+}
+
+type PurgedChannel struct {
+	ChatID    string
+	ChannelID string
+	// Before is the cutoff of the channel policy, older messages were deleted
+	Before   time.Time
+	Messages int
+}
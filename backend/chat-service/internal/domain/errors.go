@@ -6,17 +6,57 @@ var (
 	ErrMsgNotFound     = errors.New("message not found")
 	ErrChatNotFound    = errors.New("chat not gound")
 	ErrChannelNotFound = errors.New("channel not found")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrNotChatMember   = errors.New("user is not a member of this chat")
+	ErrInviteNotFound  = errors.New("invite not found")
+	ErrJoinReqNotFound = errors.New("join request not found")
+	ErrWebhookNotFound = errors.New("webhook not found")
 
-	ErrChatExists = errors.New("chat already exists")
-	ErrSameUser   = errors.New("cannot create chat with same user")
+	ErrChatExists  = errors.New("chat already exists")
+	ErrSameUser    = errors.New("cannot create chat with same user")
+	ErrUserBlocked = errors.New("user is blocked")
 
-	ErrAccessDenied = errors.New("access denied")
+	ErrAccessDenied     = errors.New("access denied")
+	ErrPermissionDenied = errors.New("not enough permissions")
+	ErrOwnerCannotLeave = errors.New("owner must transfer ownership before leaving")
 
 	ErrEmptyGroupName = errors.New("group name is empty")
 
 	ErrInvalidChannelType          = errors.New("invalid channel type")
 	ErrInvalidChatType             = errors.New("invalid chat type")
 	ErrInvalidUserCountPrivateChat = errors.New("chat type and user_ids count mismatch")
+	ErrPrivateChatMembers          = errors.New("private chat must keep exactly two members")
 	ErrInvalidMessage              = errors.New("invalid message format")
 	ErrInvalidPage                 = errors.New("invalid pagination params")
+	ErrInvalidRole                 = errors.New("invalid role")
+	ErrInvalidPermission           = errors.New("invalid permission")
+	ErrInvalidInvite               = errors.New("invite is revoked, expired or used up")
+	ErrInvalidInviteParams         = errors.New("invalid invite params")
+	ErrEmptyName                   = errors.New("name is empty")
+	ErrPrivateChatName             = errors.New("private chat cannot be renamed")
+	ErrInvalidPosition             = errors.New("channel position must be not negative")
+	ErrArchived                    = errors.New("chat or channel is archived")
+	ErrLastChannel                 = errors.New("cannot delete the last channel of a chat")
+	ErrNotChannelMember            = errors.New("user is not a member of this channel")
+	ErrAnnouncementOnly            = errors.New("only admins and allowed posters can post in announcement channel")
+	ErrInvalidChannelMode          = errors.New("invalid channel mode")
+	ErrPostNotFound                = errors.New("post not found")
+	ErrNotForumChannel             = errors.New("channel is not a forum")
+	ErrForumPostRequired           = errors.New("messages in forum channel must be replies to a post")
+	ErrInvalidTag                  = errors.New("tag is not defined in this channel")
+	ErrEmptyTitle                  = errors.New("post title is empty")
+	ErrNotVoiceChannel             = errors.New("channel is not a voice channel")
+	ErrNotVoiceParticipant         = errors.New("user is not connected to this voice channel")
+	ErrInvalidSignal               = errors.New("invalid signal")
+	ErrCallNotFound                = errors.New("call not found")
+	ErrCallInProgress              = errors.New("user is already in a call")
+	ErrInvalidCallState            = errors.New("action is not allowed in the current call state")
+	ErrCommunityNotFound           = errors.New("community not found")
+	ErrNotCommunityMember          = errors.New("user is not a member of this community")
+	ErrCommunityChat               = errors.New("members of community chats are managed by the community")
+	ErrDefaultCommunityChat        = errors.New("default chat of a community cannot be deleted")
+	ErrEmptyCommunityName          = errors.New("community name is empty")
+	ErrInvalidExportFormat         = errors.New("invalid export format")
+	ErrInvalidRetention            = errors.New("retention max age must be not negative")
+	ErrInvalidAvatarURL            = errors.New("avatar url must be an absolute http or https url")
 )
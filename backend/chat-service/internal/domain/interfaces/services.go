@@ -4,20 +4,91 @@ import (
 	chatpb "chat-service/gen"
 	"chat-service/internal/domain"
 	"context"
+	"io"
+	"time"
 )
 
 type ConversationService interface {
 	SubscribeToChannelEvents(ctx context.Context, channelID, userID string, sendEvent func(*chatpb.ChatStreamResponse)) error
 	SendMessage(ctx context.Context, channelID, text string) (string, error)
+	CreateForumPost(ctx context.Context, channelID string, title string, text string, tags []string) (post domain.Post, err error)
+	ReplyToPost(ctx context.Context, postID string, text string) (messageID string, err error)
+	ExecuteWebhook(ctx context.Context, webhookID string, token string, text string) (messageID string, err error)
 }
 
 type ViewService interface {
-	GetUserChats(ctx context.Context, chatType string) ([]*chatpb.ChatPreview, error)
-	GetChatInfo(ctx context.Context, chatID string) (chatInfo domain.ChatInfo, err error)
+	GetUserChats(ctx context.Context, chatType string, communityID string, includeArchived bool) ([]*chatpb.ChatPreview, error)
+	GetChatInfo(ctx context.Context, chatID string, includeArchived bool) (chatInfo domain.ChatInfo, err error)
 	GetMessages(ctx context.Context, channelID string, limit int32, offset int32) ([]*chatpb.Message, error)
+	GetUpdates(ctx context.Context, sinceSeq int64, limit int32) (diff domain.UpdatesDifference, err error)
+	ListForumPosts(ctx context.Context, channelID string, tag string, limit int32, offset int32) ([]*chatpb.ForumPost, error)
+	GetPostReplies(ctx context.Context, postID string, limit int32, offset int32) ([]*chatpb.Message, error)
+	ExportChannel(ctx context.Context, channelID string, format string, bundle bool, open func(file domain.ExportFile) (io.Writer, error)) error
+	GetCommunity(ctx context.Context, communityID string) (community domain.Community, err error)
+	GetUserCommunities(ctx context.Context) (communities []*domain.Community, err error)
 }
 
 type ManagerService interface {
 	CreateChat(ctx context.Context, chatType string, name string, user_ids []string) (string, error)
-	CreateChannel(ctx context.Context, chatID string, name string, chanType string) (string, error)
+	CreateChannel(ctx context.Context, chatID string, name string, chanType string, mode string, posterIDs []string) (string, error)
+
+	UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) (chat domain.Chat, err error)
+	UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) (channel domain.Channel, err error)
+
+	ArchiveChat(ctx context.Context, chatID string, archived bool) error
+	DeleteChat(ctx context.Context, chatID string) error
+	HideChat(ctx context.Context, chatID string, hidden bool) error
+	ArchiveChannel(ctx context.Context, channelID string, archived bool) error
+	DeleteChannel(ctx context.Context, channelID string) error
+
+	SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) (channel domain.Channel, err error)
+	AddChannelMembers(ctx context.Context, channelID string, userIDs []string) (memberIDs []string, err error)
+	RemoveChannelMember(ctx context.Context, channelID string, userID string) error
+	SetForumTags(ctx context.Context, channelID string, tags []string) (channel domain.Channel, err error)
+
+	SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error
+	SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error
+
+	CreateWebhook(ctx context.Context, channelID string, name string, avatarURL string) (webhook domain.Webhook, err error)
+	ListWebhooks(ctx context.Context, channelID string) (webhooks []*domain.Webhook, err error)
+	RevokeWebhook(ctx context.Context, webhookID string) error
+
+	AddMembers(ctx context.Context, chatID string, userIDs []string) (memberIDs []string, err error)
+	RemoveMember(ctx context.Context, chatID string, userID string) error
+	LeaveChat(ctx context.Context, chatID string) error
+
+	SetMemberRole(ctx context.Context, chatID string, userID string, role string) error
+	TransferOwnership(ctx context.Context, chatID string, userID string) error
+	SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error
+
+	CreateInvite(ctx context.Context, chatID string, expiresAt *time.Time, maxUses int32, requiresApproval bool) (invite domain.Invite, err error)
+	ListInvites(ctx context.Context, chatID string) (invites []*domain.Invite, err error)
+	RevokeInvite(ctx context.Context, inviteID string) error
+	JoinByInvite(ctx context.Context, code string) (chatID string, pending bool, err error)
+	ListJoinRequests(ctx context.Context, chatID string) (requests []*domain.JoinRecord, err error)
+	ReviewJoinRequest(ctx context.Context, requestID string, approve bool) error
+
+	CreateCommunity(ctx context.Context, name string, description string, memberIDs []string) (communityID string, defaultChatID string, err error)
+	UpdateCommunity(ctx context.Context, communityID string, patch domain.CommunityPatch) (community domain.Community, err error)
+	CreateCommunityChat(ctx context.Context, communityID string, name string) (chatID string, err error)
+	AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) (memberIDs []string, err error)
+	RemoveCommunityMember(ctx context.Context, communityID string, userID string) error
+	LeaveCommunity(ctx context.Context, communityID string) error
+	SetCommunityRole(ctx context.Context, communityID string, userID string, role string) error
+	TransferCommunityOwnership(ctx context.Context, communityID string, userID string) error
+}
+
+type VoiceService interface {
+	JoinVoiceChannel(ctx context.Context, channelID string, muted bool, deafened bool) (participants []domain.VoiceParticipant, err error)
+	LeaveVoiceChannel(ctx context.Context, channelID string) error
+	UpdateVoiceState(ctx context.Context, channelID string, muted *bool, deafened *bool) (participant domain.VoiceParticipant, err error)
+	GetVoiceParticipants(ctx context.Context, channelID string) (participants []domain.VoiceParticipant, err error)
+	RelaySignals(ctx context.Context, channelID string, recv func() (domain.Signal, error), send func(domain.Signal) error) error
+
+	StartCall(ctx context.Context, chatID string, video bool) (call domain.Call, err error)
+	AcceptCall(ctx context.Context, callID string) (call domain.Call, err error)
+	DeclineCall(ctx context.Context, callID string) (call domain.Call, err error)
+	CancelCall(ctx context.Context, callID string) (call domain.Call, err error)
+	HangUpCall(ctx context.Context, callID string) (call domain.Call, err error)
+	RelayCallSignals(ctx context.Context, callID string, recv func() (domain.Signal, error), send func(domain.Signal) error) error
 }
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"chat-service/internal/domain"
 )
@@ -10,7 +11,33 @@ type ChatProvider interface {
 	SaveChat(ctx context.Context, chat domain.Chat) (chatID string, err error)
 	FindChat(ctx context.Context, userIDs []string) (chat *domain.Chat, err error)
 	FindChatByID(ctx context.Context, chatID string, userID string) (chat domain.Chat, err error)
-	FindUserChats(ctx context.Context, userID string, chatType string) (chatPreviews []*domain.ChatPreview, err error)
+	FindUserChats(ctx context.Context, userID string, chatType string, communityID string, includeArchived bool) (chatPreviews []*domain.ChatPreview, err error)
+
+	AddChatMembers(ctx context.Context, chatID string, userIDs []string) error
+	RemoveChatMember(ctx context.Context, chatID string, userID string) error
+	SetChatRoles(ctx context.Context, chatID string, roles map[string]string) error
+	UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) error
+	SetChatHidden(ctx context.Context, chatID string, userID string, hidden bool) error
+	UnhideChat(ctx context.Context, chatID string) error
+	DeleteChat(ctx context.Context, chatID string) error
+
+	SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error
+	FindChatsByIDs(ctx context.Context, chatIDs []string) (chats []domain.Chat, err error)
+}
+
+// CommunityProvider keeps members and roles of community chats in sync with the community
+type CommunityProvider interface {
+	SaveCommunity(ctx context.Context, community domain.Community) (communityID string, err error)
+	FindCommunityByID(ctx context.Context, communityID string) (community domain.Community, err error)
+	FindUserCommunities(ctx context.Context, userID string) (communities []*domain.Community, err error)
+	UpdateCommunity(ctx context.Context, communityID string, patch domain.CommunityPatch) error
+
+	AddCommunityChat(ctx context.Context, communityID string, chatID string) error
+	RemoveCommunityChat(ctx context.Context, communityID string, chatID string) error
+
+	AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) error
+	RemoveCommunityMember(ctx context.Context, communityID string, userID string) error
+	SetCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error
 }
 
 type ChannelProvider interface {
@@ -18,9 +45,101 @@ type ChannelProvider interface {
 	FindChannelByID(ctx context.Context, channelID string) (channel domain.Channel, err error)
 
 	FindChannelsByIDs(ctx context.Context, channelIDs []string) (channels []domain.Channel, err error)
+	// FindChatChannels lists channels of the chat in creation order, so the first one is the default channel
+	FindChatChannels(ctx context.Context, chatID string) (channels []domain.Channel, err error)
+
+	SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error
+	UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) error
+	DeleteChannel(ctx context.Context, channelID string) error
+	DeleteChatChannels(ctx context.Context, chatID string) error
+
+	SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) error
+	AddChannelMembers(ctx context.Context, channelID string, userIDs []string) error
+	RemoveChannelMember(ctx context.Context, channelID string, userID string) error
+	SetChannelTags(ctx context.Context, channelID string, tags []string) error
+
+	SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error
+	// FindChannelsPage lists channels ordered by channel_id starting after afterID
+	FindChannelsPage(ctx context.Context, afterID string, limit int32) (channels []domain.Channel, err error)
 }
 
 type MessageProvider interface {
 	SaveMessage(ctx context.Context, message domain.Message) (messageID string, err error)
+	// SaveMessages inserts messages of one channel keeping their created_at
+	SaveMessages(ctx context.Context, channelID string, messages []domain.Message) (messageIDs []string, err error)
 	GetMessages(ctx context.Context, channelID string, limit int32, offset int32) (messages []*domain.Message, err error)
+	DeleteChannelsMessages(ctx context.Context, channelIDs []string) error
+	GetPostMessages(ctx context.Context, postID string, limit int32, offset int32) (messages []*domain.Message, err error)
+	GetMessagesAfter(ctx context.Context, channelID string, afterTime time.Time, afterID string, limit int32) (messages []*domain.Message, err error)
+	// DeleteMessagesBefore deletes up to limit oldest messages of the channel created before the time
+	// and removes them from the channel
+	DeleteMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) (messageIDs []string, err error)
+	// DeleteArchivedMessages deletes up to limit oldest messages of the channel created before the time
+	// after they are copied to the archive, the message count and the last message of the channel are kept
+	DeleteArchivedMessages(ctx context.Context, channelID string, before time.Time, limit int32) (messageIDs []string, err error)
+	// DecreaseMessageCount subtracts messages deleted from the archive from the message count of the channel
+	DecreaseMessageCount(ctx context.Context, channelID string, count int64) error
+}
+
+type PostProvider interface {
+	SavePost(ctx context.Context, post domain.Post) (postID string, err error)
+	FindPostByID(ctx context.Context, postID string) (post domain.Post, err error)
+	FindChannelPosts(ctx context.Context, channelID string, tag string, limit int32, offset int32) (posts []*domain.Post, err error)
+	TouchPost(ctx context.Context, postID string, activityAt time.Time) error
+	DeleteChannelsPosts(ctx context.Context, channelIDs []string) error
+}
+
+type UpdateProvider interface {
+	// SaveUpdates appends the update to the logs of the users and returns the seq of every user
+	SaveUpdates(ctx context.Context, update domain.Update, userIDs []string) (seqs map[string]int64, err error)
+	GetUpdates(ctx context.Context, userID string, sinceSeq int64, limit int32) (updates []*domain.Update, err error)
+	GetUpdatesState(ctx context.Context, userID string) (seq int64, err error)
+	// DeleteUpdatesBefore deletes updates of all users created before the given moment
+	DeleteUpdatesBefore(ctx context.Context, before time.Time) (deleted int64, err error)
+}
+
+// UserProvider is backed by user-service
+type UserProvider interface {
+	GetUsernames(ctx context.Context, userIDs []string) (usernames map[string]string, err error)
+	GetUserIDs(ctx context.Context, usernames []string) (userIDs map[string]string, err error)
+	GetBlockedBy(ctx context.Context, userIDs []string) (blockers map[string][]string, err error)
+	CreatePlaceholderUsers(ctx context.Context, usernames []string) (userIDs map[string]string, err error)
+}
+
+type InviteProvider interface {
+	SaveInvite(ctx context.Context, invite domain.Invite) (inviteID string, err error)
+	FindInviteByID(ctx context.Context, inviteID string) (invite domain.Invite, err error)
+	FindInviteByCode(ctx context.Context, code string) (invite domain.Invite, err error)
+	FindChatInvites(ctx context.Context, chatID string) (invites []*domain.Invite, err error)
+	UseInvite(ctx context.Context, inviteID string, now time.Time) error
+	RevokeInvite(ctx context.Context, inviteID string) error
+	DeleteChatInvites(ctx context.Context, chatID string) error
+
+	SaveJoinRecord(ctx context.Context, record domain.JoinRecord) (recordID string, err error)
+	FindJoinRecordByID(ctx context.Context, recordID string) (record domain.JoinRecord, err error)
+	FindJoinRecords(ctx context.Context, chatID string, status string) (records []*domain.JoinRecord, err error)
+	FindPendingJoinRecord(ctx context.Context, chatID string, userID string) (record *domain.JoinRecord, err error)
+	SetJoinRecordStatus(ctx context.Context, recordID string, status string, reviewerID string) error
+}
+
+type WebhookProvider interface {
+	SaveWebhook(ctx context.Context, webhook domain.Webhook) (webhookID string, err error)
+	FindWebhookByID(ctx context.Context, webhookID string) (webhook domain.Webhook, err error)
+	FindChannelWebhooks(ctx context.Context, channelID string) (webhooks []*domain.Webhook, err error)
+	RevokeWebhook(ctx context.Context, webhookID string) error
+	DeleteChannelsWebhooks(ctx context.Context, channelIDs []string) error
+}
+
+// MessageArchive moves old messages of channels to the cold storage, MessageProvider reads through to it
+type MessageArchive interface {
+	// ArchiveMessagesBefore moves up to limit oldest messages of the channel created before the time
+	ArchiveMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) (archived int, err error)
+	// PurgeBefore deletes archived messages of the channel created before the time
+	PurgeBefore(ctx context.Context, channelID string, before time.Time) (purged int, err error)
+}
+
+// Transactor runs fn so that the storage calls it makes with the given ctx are committed or rolled back together.
+// fn may be retried and must not have side effects outside the storage
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
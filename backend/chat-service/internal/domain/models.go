@@ -3,19 +3,125 @@ package domain
 import "time"
 
 type Channel struct {
-	ID         string   `bson:"_id,omitempty"`
-	ChatID     string   `bson:"chat_id"`
-	Name       string   `bson:"name"`
-	Type       string   `bson:"type"`
-	MessageIDs []string `bson:"message_ids"`
+	ID     string `bson:"_id,omitempty"`
+	ChatID string `bson:"chat_id"`
+	Name   string `bson:"name"`
+	Type   string `bson:"type"`
+
+	// MessageCount and LastMessage are kept in sync by the message storage,
+	// messages themselves are found by channel_id
+	MessageCount int64        `bson:"message_count"`
+	LastMessage  *LastMessage `bson:"last_message,omitempty"`
+
+	Topic       string `bson:"topic,omitempty"`
+	Description string `bson:"description,omitempty"`
+	Position    int32  `bson:"position"`
+	Category    string `bson:"category,omitempty"`
+	Archived    bool   `bson:"archived,omitempty"`
+
+	// Private channels are visible only to MemberIDs, members with AllowedRoles and chat admins
+	Private      bool     `bson:"private,omitempty"`
+	MemberIDs    []string `bson:"member_ids,omitempty"`
+	AllowedRoles []string `bson:"allowed_roles,omitempty"`
+
+	// Mode is ChannelModeNormal or ChannelModeAnnouncement, empty for channels created before modes
+	Mode string `bson:"mode,omitempty"`
+	// PosterIDs may post in an announcement channel besides admins
+	PosterIDs []string `bson:"poster_ids,omitempty"`
+	// Tags are available for posts of a forum channel
+	Tags []string `bson:"tags,omitempty"`
+
+	PermissionOverrides []PermissionOverride `bson:"permission_overrides,omitempty"`
+
+	// Retention overrides the retention policy of the chat
+	Retention *RetentionPolicy `bson:"retention,omitempty"`
+}
+
+// LastMessage is a preview of the newest message of a channel
+type LastMessage struct {
+	MessageID string    `bson:"message_id"`
+	SenderID  string    `bson:"sender_id"`
+	Text      string    `bson:"text"`
+	Type      string    `bson:"type,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// lastMessagePreviewLength limits the text kept in LastMessage, in runes
+const lastMessagePreviewLength = 200
+
+func NewLastMessage(message Message) LastMessage {
+	text := []rune(message.Text)
+	if len(text) > lastMessagePreviewLength {
+		text = text[:lastMessagePreviewLength]
+	}
+
+	return LastMessage{
+		MessageID: message.ID,
+		SenderID:  message.SenderID,
+		Text:      string(text),
+		Type:      message.Type,
+		CreatedAt: message.CreatedAt,
+	}
 }
 
+const (
+	ChannelModeNormal       = "normal"
+	ChannelModeAnnouncement = "announcement"
+)
+
 type Chat struct {
-	ID         string   `bson:"_id,omitempty"`
-	Type       string   `bson:"type"`
-	Name       string   `bson:"name"`
-	MemberIDs  []string `bson:"member_ids"`
-	ChannelIDs []string `bson:"channel_ids"`
+	ID        string   `bson:"_id,omitempty"`
+	Type      string   `bson:"type"`
+	Name      string   `bson:"name"`
+	MemberIDs []string `bson:"member_ids"`
+
+	Topic       string `bson:"topic,omitempty"`
+	Description string `bson:"description,omitempty"`
+	Archived    bool   `bson:"archived,omitempty"`
+	// HiddenFor lists members of a private chat who hid it from their list
+	HiddenFor []string `bson:"hidden_for,omitempty"`
+
+	// Roles maps user_id to role, members without an entry have RoleMember
+	Roles map[string]string `bson:"roles,omitempty"`
+
+	// CommunityID is set for group chats owned by a community
+	CommunityID string `bson:"community_id,omitempty"`
+
+	// Retention overrides the global retention policy
+	Retention *RetentionPolicy `bson:"retention,omitempty"`
+}
+
+// RetentionPolicy deletes messages older than MaxAge, zero MaxAge keeps messages forever.
+// LegalHold suspends deletion whatever MaxAge is
+type RetentionPolicy struct {
+	MaxAge    time.Duration `bson:"max_age,omitempty"`
+	LegalHold bool          `bson:"legal_hold,omitempty"`
+}
+
+// EffectiveRetention resolves the policy applied to the channel. The channel policy overrides
+// the chat policy which overrides the global one, a legal hold at any level suspends deletion
+func EffectiveRetention(global RetentionPolicy, chat Chat, channel Channel) RetentionPolicy {
+	policy := global
+	for _, override := range []*RetentionPolicy{chat.Retention, channel.Retention} {
+		if override == nil {
+			continue
+		}
+		policy.MaxAge = override.MaxAge
+		policy.LegalHold = policy.LegalHold || override.LegalHold
+	}
+	return policy
+}
+
+// Community owns group chats. Its members are members of every community chat
+// and its roles are copied into every community chat
+type Community struct {
+	ID            string            `bson:"_id,omitempty"`
+	Name          string            `bson:"name"`
+	Description   string            `bson:"description,omitempty"`
+	MemberIDs     []string          `bson:"member_ids"`
+	ChatIDs       []string          `bson:"chat_ids"`
+	DefaultChatID string            `bson:"default_chat_id"`
+	Roles         map[string]string `bson:"roles,omitempty"`
 }
 
 type Message struct {
@@ -24,4 +130,183 @@ type Message struct {
 	Text      string    `bson:"text"`
 	SenderID  string    `bson:"sender_id"`
 	CreatedAt time.Time `bson:"created_at"`
+	Type      string    `bson:"type,omitempty"`
+	// PostID is set for replies in forum channels
+	PostID string `bson:"post_id,omitempty"`
+	// Call is set for call history messages
+	Call *CallRecord `bson:"call,omitempty"`
+	// Webhook is set for messages posted by an incoming webhook, SenderID is empty then
+	Webhook *WebhookSender `bson:"webhook,omitempty"`
+
+	// SenderName is resolved from user-service and is not stored
+	SenderName string `bson:"-"`
+}
+
+const (
+	MessageTypeSystem = "system"
+	MessageTypeCall   = "call"
+)
+
+// WebhookSender is the identity of the webhook at the moment it posted the message
+type WebhookSender struct {
+	WebhookID string `bson:"webhook_id"`
+	Name      string `bson:"name"`
+	AvatarURL string `bson:"avatar_url,omitempty"`
+}
+
+// CallRecord is the outcome of a call stored in the history message
+type CallRecord struct {
+	CallID          string `bson:"call_id"`
+	CallerID        string `bson:"caller_id"`
+	Video           bool   `bson:"video"`
+	EndReason       string `bson:"end_reason"`
+	DurationSeconds int64  `bson:"duration_seconds"`
+}
+
+const (
+	ChannelTypeVoice = "voice"
+	ChannelTypeForum = "forum"
+)
+
+// Post is a top-level topic of a forum channel, replies are messages with its PostID
+type Post struct {
+	ID             string    `bson:"_id,omitempty"`
+	ChannelID      string    `bson:"channel_id"`
+	AuthorID       string    `bson:"author_id"`
+	Title          string    `bson:"title"`
+	Text           string    `bson:"text"`
+	Tags           []string  `bson:"tags,omitempty"`
+	CreatedAt      time.Time `bson:"created_at"`
+	LastActivityAt time.Time `bson:"last_activity_at"`
+	ReplyCount     int32     `bson:"reply_count"`
+}
+
+const (
+	UpdateNewMessage     = "new_message"
+	UpdateMessagesPurged = "messages_purged"
+	UpdateMembersAdded   = "members_added"
+	UpdateMemberRemoved  = "member_removed"
+	UpdateChatUpdated    = "chat_updated"
+	UpdateChannelUpdated = "channel_updated"
+	UpdateChatDeleted    = "chat_deleted"
+	UpdateChannelDeleted = "channel_deleted"
+	UpdateNewPost        = "new_post"
+	UpdateCallUpdated    = "call_updated"
+)
+
+// Update is an entry of the per-user update log, Seq grows monotonically for every user
+type Update struct {
+	ID        string    `bson:"_id,omitempty"`
+	UserID    string    `bson:"user_id"`
+	Seq       int64     `bson:"seq"`
+	Type      string    `bson:"type"`
+	ChatID    string    `bson:"chat_id"`
+	ChannelID string    `bson:"channel_id,omitempty"`
+	MessageID string    `bson:"message_id,omitempty"`
+	Message   *Message  `bson:"message,omitempty"`
+	UserIDs   []string  `bson:"user_ids,omitempty"`
+	Chat      *Chat     `bson:"chat,omitempty"`
+	Channel   *Channel  `bson:"channel,omitempty"`
+	Post      *Post     `bson:"post,omitempty"`
+	Call      *Call     `bson:"call,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+
+	// PurgedBefore is set for UpdateMessagesPurged, messages of the channel created before it were deleted
+	PurgedBefore *time.Time `bson:"purged_before,omitempty"`
+}
+
+const (
+	JoinStatusJoined   = "joined"
+	JoinStatusPending  = "pending"
+	JoinStatusApproved = "approved"
+	JoinStatusRejected = "rejected"
+)
+
+type Invite struct {
+	ID               string     `bson:"_id,omitempty"`
+	ChatID           string     `bson:"chat_id"`
+	Code             string     `bson:"code"`
+	CreatorID        string     `bson:"creator_id"`
+	ExpiresAt        *time.Time `bson:"expires_at,omitempty"`
+	MaxUses          int32      `bson:"max_uses"`
+	Uses             int32      `bson:"uses"`
+	RequiresApproval bool       `bson:"requires_approval"`
+	Revoked          bool       `bson:"revoked"`
+	CreatedAt        time.Time  `bson:"created_at"`
+
+	Link string `bson:"-"`
+}
+
+// Usable reports whether the invite can still be used at the given moment
+func (i Invite) Usable(now time.Time) bool {
+	switch {
+	case i.Revoked:
+		return false
+	case i.ExpiresAt != nil && !now.Before(*i.ExpiresAt):
+		return false
+	case i.MaxUses > 0 && i.Uses >= i.MaxUses:
+		return false
+	default:
+		return true
+	}
+}
+
+// JoinRecord is kept for every join by invite for auditing
+type JoinRecord struct {
+	ID         string    `bson:"_id,omitempty"`
+	ChatID     string    `bson:"chat_id"`
+	UserID     string    `bson:"user_id"`
+	InviteID   string    `bson:"invite_id"`
+	InviteCode string    `bson:"invite_code"`
+	Status     string    `bson:"status"`
+	ReviewerID string    `bson:"reviewer_id,omitempty"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+// Webhook posts messages into a channel. Only the hash of its secret token is kept,
+// the token itself is shown once when the webhook is created
+type Webhook struct {
+	ID        string    `bson:"_id,omitempty"`
+	ChatID    string    `bson:"chat_id"`
+	ChannelID string    `bson:"channel_id"`
+	Name      string    `bson:"name"`
+	AvatarURL string    `bson:"avatar_url,omitempty"`
+	TokenHash string    `bson:"token_hash"`
+	CreatorID string    `bson:"creator_id"`
+	Revoked   bool      `bson:"revoked"`
+	CreatedAt time.Time `bson:"created_at"`
+
+	URL string `bson:"-"`
+}
+
+// Sender returns the identity stored in messages posted by the webhook
+func (w Webhook) Sender() *WebhookSender {
+	return &WebhookSender{WebhookID: w.ID, Name: w.Name, AvatarURL: w.AvatarURL}
+}
+
+// ChatPatch holds changed chat fields, nil fields are left as is
+type ChatPatch struct {
+	Name        *string
+	Topic       *string
+	Description *string
+	Archived    *bool
+}
+
+// CommunityPatch holds changed community fields, nil fields are left as is
+type CommunityPatch struct {
+	Name          *string
+	Description   *string
+	DefaultChatID *string
+}
+
+// ChannelPatch holds changed channel fields, nil fields are left as is
+type ChannelPatch struct {
+	Name        *string
+	Topic       *string
+	Description *string
+	Position    *int32
+	Category    *string
+	Archived    *bool
+	Mode        *string
+	PosterIDs   *[]string
 }
@@ -0,0 +1,158 @@
+package domain
+
+type Permission uint32
+
+const (
+	PermSendMessages Permission = 1 << iota
+	PermManageChannels
+	PermManageMembers
+	PermDeleteMessages
+	PermPinMessages
+	PermMentionAll
+	PermManageChat
+
+	PermAll = PermSendMessages | PermManageChannels | PermManageMembers | PermDeleteMessages | PermPinMessages | PermMentionAll | PermManageChat
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+var PermissionNames = map[Permission]string{
+	PermSendMessages:   "send_messages",
+	PermManageChannels: "manage_channels",
+	PermManageMembers:  "manage_members",
+	PermDeleteMessages: "delete_messages",
+	PermPinMessages:    "pin_messages",
+	PermMentionAll:     "mention_all",
+	PermManageChat:     "manage_chat",
+}
+
+var RolePermissions = map[string]Permission{
+	RoleOwner:  PermAll,
+	RoleAdmin:  PermAll,
+	RoleMember: PermSendMessages,
+}
+
+var roleRanks = map[string]int{
+	RoleMember: 1,
+	RoleAdmin:  2,
+	RoleOwner:  3,
+}
+
+// PermissionOverride allows or denies permissions in a channel for a role or for a single user
+type PermissionOverride struct {
+	Role   string     `bson:"role,omitempty"`
+	UserID string     `bson:"user_id,omitempty"`
+	Allow  Permission `bson:"allow"`
+	Deny   Permission `bson:"deny"`
+}
+
+// RoleOf returns the role of a chat member. Private chats have no owner, so their members act as admins.
+// Group chats saved before roles get an owner from a migration, until then their members act as admins too
+func (c Chat) RoleOf(userID string) string {
+	if c.Type == "private" || len(c.Roles) == 0 {
+		return RoleAdmin
+	}
+	if role, ok := c.Roles[userID]; ok {
+		return role
+	}
+	return RoleMember
+}
+
+// RoleOf returns the role of a community member
+func (c Community) RoleOf(userID string) string {
+	if role, ok := c.Roles[userID]; ok {
+		return role
+	}
+	return RoleMember
+}
+
+// CanView reports whether the chat member sees the channel. Admins and owners see every channel
+func (c Channel) CanView(chat Chat, userID string) bool {
+	if !c.Private {
+		return true
+	}
+
+	role := chat.RoleOf(userID)
+	if role == RoleOwner || role == RoleAdmin {
+		return true
+	}
+	for _, id := range c.MemberIDs {
+		if id == userID {
+			return true
+		}
+	}
+	for _, allowed := range c.AllowedRoles {
+		if allowed == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CanPost reports whether the chat member may post in the channel with respect to its mode.
+// Only admins, owners and PosterIDs post in announcement channels
+func (c Channel) CanPost(chat Chat, userID string) bool {
+	if c.Mode != ChannelModeAnnouncement {
+		return true
+	}
+
+	role := chat.RoleOf(userID)
+	if role == RoleOwner || role == RoleAdmin {
+		return true
+	}
+	for _, id := range c.PosterIDs {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ViewerIDs returns chat members who see the channel
+func (c Channel) ViewerIDs(chat Chat) []string {
+	if !c.Private {
+		return chat.MemberIDs
+	}
+
+	viewers := make([]string, 0, len(chat.MemberIDs))
+	for _, id := range chat.MemberIDs {
+		if c.CanView(chat, id) {
+			viewers = append(viewers, id)
+		}
+	}
+	return viewers
+}
+
+// Outranks reports whether the first role is higher than the second one
+func Outranks(role string, other string) bool {
+	return roleRanks[role] > roleRanks[other]
+}
+
+// PermissionsOf returns effective permissions of a member, channel overrides
+// are applied for the role first and for the user after that. Owners ignore overrides
+func PermissionsOf(chat Chat, channel *Channel, userID string) Permission {
+	role := chat.RoleOf(userID)
+	perms := RolePermissions[role]
+	if channel == nil || role == RoleOwner {
+		return perms
+	}
+
+	for _, override := range channel.PermissionOverrides {
+		if override.UserID == "" && override.Role == role {
+			perms = (perms | override.Allow) &^ override.Deny
+		}
+	}
+	for _, override := range channel.PermissionOverrides {
+		if override.UserID == userID {
+			perms = (perms | override.Allow) &^ override.Deny
+		}
+	}
+
+	return perms
+}
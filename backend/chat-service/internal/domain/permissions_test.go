@@ -0,0 +1,88 @@
+package domain
+
+import "testing"
+
+func TestChatRoleOf(t *testing.T) {
+	group := Chat{Type: "group", MemberIDs: []string{"owner", "admin", "member"}, Roles: map[string]string{"owner": RoleOwner, "admin": RoleAdmin}}
+
+	tests := []struct {
+		name   string
+		chat   Chat
+		userID string
+		want   string
+	}{
+		{"owner", group, "owner", RoleOwner},
+		{"admin", group, "admin", RoleAdmin},
+		{"member without entry", group, "member", RoleMember},
+		{"private chat", Chat{Type: "private", MemberIDs: []string{"a", "b"}}, "a", RoleAdmin},
+		{"group chat without roles", Chat{Type: "group", MemberIDs: []string{"a"}}, "a", RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chat.RoleOf(tt.userID); got != tt.want {
+				t.Errorf("RoleOf(%q) = %q, want %q", tt.userID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionsOf(t *testing.T) {
+	chat := Chat{Type: "group", MemberIDs: []string{"owner", "admin", "member", "muted"}, Roles: map[string]string{"owner": RoleOwner, "admin": RoleAdmin}}
+	channel := &Channel{PermissionOverrides: []PermissionOverride{
+		{Role: RoleMember, Allow: PermPinMessages},
+		{Role: RoleAdmin, Deny: PermManageChannels},
+		{Role: RoleOwner, Deny: PermAll},
+		{UserID: "muted", Deny: PermSendMessages},
+		{UserID: "admin", Allow: PermManageChannels},
+	}}
+
+	tests := []struct {
+		name    string
+		channel *Channel
+		userID  string
+		want    Permission
+	}{
+		{"member without channel", nil, "member", PermSendMessages},
+		{"member with role allow", channel, "member", PermSendMessages | PermPinMessages},
+		{"user deny after role allow", channel, "muted", PermPinMessages},
+		{"user allow after role deny", channel, "admin", PermAll},
+		{"owner ignores overrides", channel, "owner", PermAll},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PermissionsOf(chat, tt.channel, tt.userID); got != tt.want {
+				t.Errorf("PermissionsOf(%q) = %b, want %b", tt.userID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelCanViewAndCanPost(t *testing.T) {
+	chat := Chat{Type: "group", MemberIDs: []string{"owner", "member", "mod", "poster"}, Roles: map[string]string{"owner": RoleOwner, "mod": "mod"}}
+	private := Channel{Private: true, MemberIDs: []string{"member"}, AllowedRoles: []string{"mod"}}
+	announcement := Channel{Mode: ChannelModeAnnouncement, PosterIDs: []string{"poster"}}
+
+	for userID, want := range map[string]bool{"owner": true, "member": true, "mod": true, "poster": false} {
+		if got := private.CanView(chat, userID); got != want {
+			t.Errorf("CanView(%q) = %v, want %v", userID, got, want)
+		}
+	}
+	for userID, want := range map[string]bool{"owner": true, "member": false, "poster": true} {
+		if got := announcement.CanPost(chat, userID); got != want {
+			t.Errorf("CanPost(%q) = %v, want %v", userID, got, want)
+		}
+	}
+
+	if got := private.ViewerIDs(chat); len(got) != 3 {
+		t.Errorf("ViewerIDs() = %v, want 3 viewers", got)
+	}
+}
+
+func TestOutranks(t *testing.T) {
+	if !Outranks(RoleOwner, RoleAdmin) || !Outranks(RoleAdmin, RoleMember) {
+		t.Error("higher roles must outrank lower ones")
+	}
+	if Outranks(RoleAdmin, RoleAdmin) || Outranks(RoleMember, RoleOwner) {
+		t.Error("equal and lower roles must not outrank")
+	}
+}
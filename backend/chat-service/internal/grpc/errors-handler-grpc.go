@@ -1,3 +1,125 @@
 package grpccontroller
 
-// TODO: error handler
+import (
+	"errors"
+
+	"chat-service/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func getStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrMsgNotFound):
+		return status.Error(codes.NotFound, "message not found")
+	case errors.Is(err, domain.ErrChatNotFound):
+		return status.Error(codes.NotFound, "chat not found")
+	case errors.Is(err, domain.ErrCommunityNotFound):
+		return status.Error(codes.NotFound, "community not found")
+	case errors.Is(err, domain.ErrChannelNotFound):
+		return status.Error(codes.NotFound, "channel not found")
+	case errors.Is(err, domain.ErrPostNotFound):
+		return status.Error(codes.NotFound, "post not found")
+	case errors.Is(err, domain.ErrNotVoiceParticipant):
+		return status.Error(codes.FailedPrecondition, "user is not connected to this voice channel")
+	case errors.Is(err, domain.ErrCallNotFound):
+		return status.Error(codes.NotFound, "call not found")
+	case errors.Is(err, domain.ErrCallInProgress):
+		return status.Error(codes.FailedPrecondition, "user is already in a call")
+	case errors.Is(err, domain.ErrInvalidCallState):
+		return status.Error(codes.FailedPrecondition, "action is not allowed in the current call state")
+	case errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, domain.ErrUserBlocked):
+		return status.Error(codes.PermissionDenied, "user is blocked")
+	case errors.Is(err, domain.ErrInviteNotFound):
+		return status.Error(codes.NotFound, "invite not found")
+	case errors.Is(err, domain.ErrJoinReqNotFound):
+		return status.Error(codes.NotFound, "join request not found")
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		return status.Error(codes.NotFound, "webhook not found")
+	case errors.Is(err, domain.ErrNotChatMember):
+		return status.Error(codes.NotFound, "user is not a member of this chat")
+	case errors.Is(err, domain.ErrNotCommunityMember):
+		return status.Error(codes.NotFound, "user is not a member of this community")
+	case errors.Is(err, domain.ErrNotChannelMember):
+		return status.Error(codes.NotFound, "user is not a member of this channel")
+
+	case errors.Is(err, domain.ErrChatExists):
+		return status.Error(codes.AlreadyExists, "chat already exists")
+
+	case errors.Is(err, domain.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "user is not in this chat")
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "not enough permissions")
+	case errors.Is(err, domain.ErrAnnouncementOnly):
+		return status.Error(codes.PermissionDenied, "only admins and allowed posters can post in announcement channel")
+	case errors.Is(err, domain.ErrCommunityChat):
+		return status.Error(codes.FailedPrecondition, "members of community chats are managed by the community")
+	case errors.Is(err, domain.ErrDefaultCommunityChat):
+		return status.Error(codes.FailedPrecondition, "default chat of a community cannot be deleted")
+	case errors.Is(err, domain.ErrOwnerCannotLeave):
+		return status.Error(codes.FailedPrecondition, "owner must transfer ownership before leaving")
+	case errors.Is(err, domain.ErrArchived):
+		return status.Error(codes.FailedPrecondition, "chat or channel is archived")
+	case errors.Is(err, domain.ErrLastChannel):
+		return status.Error(codes.FailedPrecondition, "cannot delete the last channel of a chat")
+
+	case errors.Is(err, domain.ErrSameUser):
+		return status.Error(codes.InvalidArgument, "cannot create private chat with yourself")
+	case errors.Is(err, domain.ErrEmptyCommunityName):
+		return status.Error(codes.InvalidArgument, "community name must be not empty")
+	case errors.Is(err, domain.ErrEmptyGroupName):
+		return status.Error(codes.InvalidArgument, "group name must be not empty")
+	case errors.Is(err, domain.ErrInvalidChannelType):
+		return status.Error(codes.InvalidArgument, "invalid channel type")
+	case errors.Is(err, domain.ErrInvalidChatType):
+		return status.Error(codes.InvalidArgument, "chat type must be only private or group")
+	case errors.Is(err, domain.ErrInvalidUserCountPrivateChat):
+		return status.Error(codes.InvalidArgument, "private chat must contain only 2 users")
+	case errors.Is(err, domain.ErrPrivateChatMembers):
+		return status.Error(codes.FailedPrecondition, "private chat must keep exactly two members")
+	case errors.Is(err, domain.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, "role must be only admin or member")
+	case errors.Is(err, domain.ErrInvalidPermission):
+		return status.Error(codes.InvalidArgument, "invalid permission override")
+	case errors.Is(err, domain.ErrInvalidInvite):
+		return status.Error(codes.FailedPrecondition, "invite is revoked, expired or used up")
+	case errors.Is(err, domain.ErrInvalidInviteParams):
+		return status.Error(codes.InvalidArgument, "expires_at must be in the future and max_uses must be not negative")
+	case errors.Is(err, domain.ErrEmptyName):
+		return status.Error(codes.InvalidArgument, "name must be not empty")
+	case errors.Is(err, domain.ErrPrivateChatName):
+		return status.Error(codes.InvalidArgument, "private chat cannot be renamed")
+	case errors.Is(err, domain.ErrInvalidChannelMode):
+		return status.Error(codes.InvalidArgument, "channel mode must be only normal or announcement")
+	case errors.Is(err, domain.ErrNotForumChannel):
+		return status.Error(codes.FailedPrecondition, "channel is not a forum")
+	case errors.Is(err, domain.ErrForumPostRequired):
+		return status.Error(codes.FailedPrecondition, "messages in forum channel must be replies to a post")
+	case errors.Is(err, domain.ErrNotVoiceChannel):
+		return status.Error(codes.FailedPrecondition, "channel is not a voice channel")
+	case errors.Is(err, domain.ErrInvalidExportFormat):
+		return status.Error(codes.InvalidArgument, "export format must be json, html or text")
+	case errors.Is(err, domain.ErrInvalidRetention):
+		return status.Error(codes.InvalidArgument, "max_age_days must be not negative")
+	case errors.Is(err, domain.ErrInvalidAvatarURL):
+		return status.Error(codes.InvalidArgument, "avatar_url must be an absolute http or https url")
+	case errors.Is(err, domain.ErrInvalidSignal):
+		return status.Error(codes.InvalidArgument, "signal type must be offer, answer or candidate and to_user_id is required")
+	case errors.Is(err, domain.ErrInvalidTag):
+		return status.Error(codes.InvalidArgument, "tag is empty or not defined in this channel")
+	case errors.Is(err, domain.ErrEmptyTitle):
+		return status.Error(codes.InvalidArgument, "post title must be not empty")
+	case errors.Is(err, domain.ErrInvalidPosition):
+		return status.Error(codes.InvalidArgument, "channel position must be not negative")
+	case errors.Is(err, domain.ErrInvalidMessage):
+		return status.Error(codes.InvalidArgument, "invalid message length")
+	case errors.Is(err, domain.ErrInvalidPage):
+		return status.Error(codes.InvalidArgument, "invalid pagination params")
+
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,70 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) ArchiveChat(ctx context.Context, req *chatpb.ArchiveChatRequest) (*chatpb.ArchiveChatResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	if err := s.managerService.ArchiveChat(ctx, req.GetChatId(), req.GetArchived()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ArchiveChatResponse{}, nil
+}
+
+func (s *serverAPI) DeleteChat(ctx context.Context, req *chatpb.DeleteChatRequest) (*chatpb.DeleteChatResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	if err := s.managerService.DeleteChat(ctx, req.GetChatId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.DeleteChatResponse{}, nil
+}
+
+func (s *serverAPI) HideChat(ctx context.Context, req *chatpb.HideChatRequest) (*chatpb.HideChatResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	if err := s.managerService.HideChat(ctx, req.GetChatId(), req.GetHidden()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.HideChatResponse{}, nil
+}
+
+func (s *serverAPI) ArchiveChannel(ctx context.Context, req *chatpb.ArchiveChannelRequest) (*chatpb.ArchiveChannelResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	if err := s.managerService.ArchiveChannel(ctx, req.GetChannelId(), req.GetArchived()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ArchiveChannelResponse{}, nil
+}
+
+func (s *serverAPI) DeleteChannel(ctx context.Context, req *chatpb.DeleteChannelRequest) (*chatpb.DeleteChannelResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	if err := s.managerService.DeleteChannel(ctx, req.GetChannelId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.DeleteChannelResponse{}, nil
+}
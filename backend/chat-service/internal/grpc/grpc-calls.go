@@ -0,0 +1,76 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) StartCall(ctx context.Context, req *chatpb.StartCallRequest) (*chatpb.StartCallResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	call, err := s.voiceService.StartCall(ctx, req.GetChatId(), req.GetVideo())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.StartCallResponse{Call: mapper.ConvertCallToProto(call)}, nil
+}
+
+func (s *serverAPI) AcceptCall(ctx context.Context, req *chatpb.AcceptCallRequest) (*chatpb.AcceptCallResponse, error) {
+	if req.GetCallId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "call_id is required")
+	}
+
+	call, err := s.voiceService.AcceptCall(ctx, req.GetCallId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.AcceptCallResponse{Call: mapper.ConvertCallToProto(call)}, nil
+}
+
+func (s *serverAPI) DeclineCall(ctx context.Context, req *chatpb.DeclineCallRequest) (*chatpb.DeclineCallResponse, error) {
+	if req.GetCallId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "call_id is required")
+	}
+
+	call, err := s.voiceService.DeclineCall(ctx, req.GetCallId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.DeclineCallResponse{Call: mapper.ConvertCallToProto(call)}, nil
+}
+
+func (s *serverAPI) CancelCall(ctx context.Context, req *chatpb.CancelCallRequest) (*chatpb.CancelCallResponse, error) {
+	if req.GetCallId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "call_id is required")
+	}
+
+	call, err := s.voiceService.CancelCall(ctx, req.GetCallId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.CancelCallResponse{Call: mapper.ConvertCallToProto(call)}, nil
+}
+
+func (s *serverAPI) HangUpCall(ctx context.Context, req *chatpb.HangUpCallRequest) (*chatpb.HangUpCallResponse, error) {
+	if req.GetCallId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "call_id is required")
+	}
+
+	call, err := s.voiceService.HangUpCall(ctx, req.GetCallId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.HangUpCallResponse{Call: mapper.ConvertCallToProto(call)}, nil
+}
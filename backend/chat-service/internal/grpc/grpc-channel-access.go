@@ -0,0 +1,75 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) SetChannelAccess(ctx context.Context, req *chatpb.SetChannelAccessRequest) (*chatpb.SetChannelAccessResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	channel, err := s.managerService.SetChannelAccess(ctx, req.GetChannelId(), req.GetPrivate(), req.GetAllowedRoles())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetChannelAccessResponse{
+		Channel: mapper.ConvertChannelToProto(channel),
+	}, nil
+}
+
+func (s *serverAPI) AddChannelMembers(ctx context.Context, req *chatpb.AddChannelMembersRequest) (*chatpb.AddChannelMembersResponse, error) {
+	if err := validateAddChannelMembers(req); err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := s.managerService.AddChannelMembers(ctx, req.GetChannelId(), req.GetUserIds())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.AddChannelMembersResponse{
+		MemberIds: memberIDs,
+	}, nil
+}
+
+func (s *serverAPI) RemoveChannelMember(ctx context.Context, req *chatpb.RemoveChannelMemberRequest) (*chatpb.RemoveChannelMemberResponse, error) {
+	if err := validateRemoveChannelMember(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.managerService.RemoveChannelMember(ctx, req.GetChannelId(), req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.RemoveChannelMemberResponse{}, nil
+}
+
+func validateAddChannelMembers(req *chatpb.AddChannelMembersRequest) error {
+	switch {
+	case req.GetChannelId() == "":
+		return status.Error(codes.InvalidArgument, "channel_id is required")
+	case len(req.GetUserIds()) == 0:
+		return status.Error(codes.InvalidArgument, "user_ids are required")
+	default:
+		return nil
+	}
+}
+
+func validateRemoveChannelMember(req *chatpb.RemoveChannelMemberRequest) error {
+	switch {
+	case req.GetChannelId() == "":
+		return status.Error(codes.InvalidArgument, "channel_id is required")
+	case req.GetUserId() == "":
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	default:
+		return nil
+	}
+}
@@ -7,6 +7,7 @@ import (
 	chatpb "chat-service/gen"
 	"chat-service/internal/domain"
 	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/mapper"
 	"chat-service/internal/lib/utils"
 
 	"google.golang.org/grpc/codes"
@@ -15,7 +16,7 @@ import (
 
 // TODO: move to domain
 type Channel interface {
-	CreateChannel(ctx context.Context, chatID string, name string, chanType string) (channelID string, err error)
+	CreateChannel(ctx context.Context, chatID string, name string, chanType string, mode string, posterIDs []string) (channelID string, err error)
 	SubscribeToChannelEvents(ctx context.Context, channelID string, userID string, sendEvent func(*chatpb.ChatStreamResponse)) error
 }
 
@@ -25,15 +26,23 @@ func (s *serverAPI) CreateChannel(ctx context.Context, req *chatpb.CreateChannel
 	}
 
 	// TODO: implement error handler
-	channelID, err := s.managerService.CreateChannel(ctx, req.GetChatId(), req.GetName(), req.GetType())
+	channelID, err := s.managerService.CreateChannel(ctx, req.GetChatId(), req.GetName(), req.GetType(), req.GetMode(), req.GetPosterIds())
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrChatNotFound):
 			return nil, status.Error(codes.NotFound, "chat not found")
 		case errors.Is(err, domain.ErrAccessDenied):
 			return nil, status.Error(codes.PermissionDenied, "user is not in this chat")
+		case errors.Is(err, domain.ErrPermissionDenied):
+			return nil, status.Error(codes.PermissionDenied, "not enough permissions")
+		case errors.Is(err, domain.ErrArchived):
+			return nil, status.Error(codes.FailedPrecondition, "chat is archived")
 		case errors.Is(err, domain.ErrInvalidChannelType):
 			return nil, status.Error(codes.InvalidArgument, "invalid channel type")
+		case errors.Is(err, domain.ErrInvalidChannelMode):
+			return nil, status.Error(codes.InvalidArgument, "channel mode must be only normal or announcement")
+		case errors.Is(err, domain.ErrNotChatMember):
+			return nil, status.Error(codes.NotFound, "user is not a member of this chat")
 		default:
 			return nil, status.Error(codes.Internal, "internal error")
 		}
@@ -44,6 +53,34 @@ func (s *serverAPI) CreateChannel(ctx context.Context, req *chatpb.CreateChannel
 	}, nil
 }
 
+func (s *serverAPI) UpdateChannel(ctx context.Context, req *chatpb.UpdateChannelRequest) (*chatpb.UpdateChannelResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	patch := domain.ChannelPatch{
+		Name:        req.Name,
+		Topic:       req.Topic,
+		Description: req.Description,
+		Position:    req.Position,
+		Category:    req.Category,
+		Mode:        req.Mode,
+	}
+	if req.GetPosters() != nil {
+		posterIDs := req.GetPosters().GetUserIds()
+		patch.PosterIDs = &posterIDs
+	}
+
+	channel, err := s.managerService.UpdateChannel(ctx, req.GetChannelId(), patch)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.UpdateChannelResponse{
+		Channel: mapper.ConvertChannelToProto(channel),
+	}, nil
+}
+
 func (s *serverAPI) ChatStream(req *chatpb.ChatStreamRequest, stream chatpb.Conversation_ChatStreamServer) error {
 	// TODO: Валидация входных данных
 
@@ -6,6 +6,7 @@ import (
 
 	chatpb "chat-service/gen"
 	"chat-service/internal/domain"
+	"chat-service/internal/lib/mapper"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -14,8 +15,8 @@ import (
 // TODO: move to domain
 type Chat interface {
 	CreateChat(ctx context.Context, chatType string, name string, user_ids []string) (chatID string, err error)
-	GetUserChats(ctx context.Context, chatType string) (chatPreviews []*chatpb.ChatPreview, err error)
-	GetChatInfo(ctx context.Context, chatID string) (chatInfo domain.ChatInfo, err error)
+	GetUserChats(ctx context.Context, chatType string, communityID string, includeArchived bool) (chatPreviews []*chatpb.ChatPreview, err error)
+	GetChatInfo(ctx context.Context, chatID string, includeArchived bool) (chatInfo domain.ChatInfo, err error)
 }
 
 func (s *serverAPI) CreateChat(ctx context.Context, req *chatpb.CreateChatRequest) (*chatpb.CreateChatResponse, error) {
@@ -37,6 +38,10 @@ func (s *serverAPI) CreateChat(ctx context.Context, req *chatpb.CreateChatReques
 			return nil, status.Error(codes.InvalidArgument, "group name must be not empty")
 		case errors.Is(err, domain.ErrChatExists):
 			return nil, status.Error(codes.AlreadyExists, "chat already exists")
+		case errors.Is(err, domain.ErrUserNotFound):
+			return nil, status.Error(codes.NotFound, "user not found")
+		case errors.Is(err, domain.ErrUserBlocked):
+			return nil, status.Error(codes.PermissionDenied, "user is blocked")
 		default:
 			return nil, status.Error(codes.Internal, "internal error")
 		}
@@ -53,11 +58,15 @@ func (s *serverAPI) GetUserChats(ctx context.Context, req *chatpb.GetUserChatsRe
 	}
 
 	// TODO: implement error handler
-	ChatPrews, err := s.viewService.GetUserChats(ctx, req.GetType())
+	ChatPrews, err := s.viewService.GetUserChats(ctx, req.GetType(), req.GetCommunityId(), req.GetIncludeArchived())
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrInvalidChatType):
 			return nil, status.Error(codes.InvalidArgument, "chat type must be only private or group")
+		case errors.Is(err, domain.ErrCommunityNotFound):
+			return nil, status.Error(codes.NotFound, "community not found")
+		case errors.Is(err, domain.ErrAccessDenied):
+			return nil, status.Error(codes.PermissionDenied, "user is not in this community")
 		default:
 			return nil, status.Error(codes.Internal, "internal error")
 		}
@@ -74,7 +83,7 @@ func (s *serverAPI) GetChatInfo(ctx context.Context, req *chatpb.GetChatInfoRequ
 	}
 
 	// TODO: implement error handler
-	chatInfo, err := s.viewService.GetChatInfo(ctx, req.GetChatId())
+	chatInfo, err := s.viewService.GetChatInfo(ctx, req.GetChatId(), req.GetIncludeArchived())
 	if err != nil {
 		switch {
 		case errors.Is(err, domain.ErrAccessDenied):
@@ -85,11 +94,38 @@ func (s *serverAPI) GetChatInfo(ctx context.Context, req *chatpb.GetChatInfoRequ
 	}
 
 	return &chatpb.GetChatInfoResponse{
-		ChatId:    chatInfo.ID,
-		Type:      chatInfo.Type,
-		Name:      chatInfo.Name,
-		MemberIds: chatInfo.MemberIDs,
-		Channels:  chatInfo.ProtoChannels,
+		ChatId:      chatInfo.ID,
+		Type:        chatInfo.Type,
+		Name:        chatInfo.Name,
+		MemberIds:   chatInfo.MemberIDs,
+		MemberNames: chatInfo.MemberNames,
+		Channels:    chatInfo.ProtoChannels,
+		Roles:       chatInfo.Roles,
+		Topic:       chatInfo.Topic,
+		Description: chatInfo.Description,
+		Archived:    chatInfo.Archived,
+		CommunityId: chatInfo.CommunityID,
+	}, nil
+}
+
+func (s *serverAPI) UpdateChat(ctx context.Context, req *chatpb.UpdateChatRequest) (*chatpb.UpdateChatResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	patch := domain.ChatPatch{
+		Name:        req.Name,
+		Topic:       req.Topic,
+		Description: req.Description,
+	}
+
+	chat, err := s.managerService.UpdateChat(ctx, req.GetChatId(), patch)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.UpdateChatResponse{
+		Chat: mapper.ConvertChatToProto(&chat),
 	}, nil
 }
 
@@ -109,7 +145,7 @@ func validateGetChatInfo(req *chatpb.GetChatInfoRequest) error {
 }
 
 func validateGetUserChats(req *chatpb.GetUserChatsRequest) error {
-	if req.GetType() == "" {
+	if req.GetType() == "" && req.GetCommunityId() == "" {
 		return status.Error(codes.InvalidArgument, "chat type is required")
 	}
 	return nil
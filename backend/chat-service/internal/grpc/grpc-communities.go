@@ -0,0 +1,152 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) CreateCommunity(ctx context.Context, req *chatpb.CreateCommunityRequest) (*chatpb.CreateCommunityResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	communityID, defaultChatID, err := s.managerService.CreateCommunity(ctx, req.GetName(), req.GetDescription(), req.GetMemberIds())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.CreateCommunityResponse{
+		CommunityId:   communityID,
+		DefaultChatId: defaultChatID,
+	}, nil
+}
+
+func (s *serverAPI) GetCommunity(ctx context.Context, req *chatpb.GetCommunityRequest) (*chatpb.GetCommunityResponse, error) {
+	if req.GetCommunityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id is required")
+	}
+
+	community, err := s.viewService.GetCommunity(ctx, req.GetCommunityId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.GetCommunityResponse{
+		Community: mapper.ConvertCommunityToProto(&community),
+	}, nil
+}
+
+func (s *serverAPI) GetUserCommunities(ctx context.Context, req *chatpb.GetUserCommunitiesRequest) (*chatpb.GetUserCommunitiesResponse, error) {
+	communities, err := s.viewService.GetUserCommunities(ctx)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.GetUserCommunitiesResponse{
+		Communities: mapper.ConvertCommunitiesToProto(communities),
+	}, nil
+}
+
+func (s *serverAPI) UpdateCommunity(ctx context.Context, req *chatpb.UpdateCommunityRequest) (*chatpb.UpdateCommunityResponse, error) {
+	if req.GetCommunityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id is required")
+	}
+
+	patch := domain.CommunityPatch{
+		Name:          req.Name,
+		Description:   req.Description,
+		DefaultChatID: req.DefaultChatId,
+	}
+
+	community, err := s.managerService.UpdateCommunity(ctx, req.GetCommunityId(), patch)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.UpdateCommunityResponse{
+		Community: mapper.ConvertCommunityToProto(&community),
+	}, nil
+}
+
+func (s *serverAPI) CreateCommunityChat(ctx context.Context, req *chatpb.CreateCommunityChatRequest) (*chatpb.CreateCommunityChatResponse, error) {
+	if req.GetCommunityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id is required")
+	}
+
+	chatID, err := s.managerService.CreateCommunityChat(ctx, req.GetCommunityId(), req.GetName())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.CreateCommunityChatResponse{ChatId: chatID}, nil
+}
+
+func (s *serverAPI) AddCommunityMembers(ctx context.Context, req *chatpb.AddCommunityMembersRequest) (*chatpb.AddCommunityMembersResponse, error) {
+	if req.GetCommunityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id is required")
+	}
+	if len(req.GetUserIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_ids are required")
+	}
+
+	memberIDs, err := s.managerService.AddCommunityMembers(ctx, req.GetCommunityId(), req.GetUserIds())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.AddCommunityMembersResponse{MemberIds: memberIDs}, nil
+}
+
+func (s *serverAPI) RemoveCommunityMember(ctx context.Context, req *chatpb.RemoveCommunityMemberRequest) (*chatpb.RemoveCommunityMemberResponse, error) {
+	if req.GetCommunityId() == "" || req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id and user_id are required")
+	}
+
+	if err := s.managerService.RemoveCommunityMember(ctx, req.GetCommunityId(), req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.RemoveCommunityMemberResponse{}, nil
+}
+
+func (s *serverAPI) LeaveCommunity(ctx context.Context, req *chatpb.LeaveCommunityRequest) (*chatpb.LeaveCommunityResponse, error) {
+	if req.GetCommunityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id is required")
+	}
+
+	if err := s.managerService.LeaveCommunity(ctx, req.GetCommunityId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.LeaveCommunityResponse{}, nil
+}
+
+func (s *serverAPI) SetCommunityRole(ctx context.Context, req *chatpb.SetCommunityRoleRequest) (*chatpb.SetCommunityRoleResponse, error) {
+	if req.GetCommunityId() == "" || req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id and user_id are required")
+	}
+
+	if err := s.managerService.SetCommunityRole(ctx, req.GetCommunityId(), req.GetUserId(), req.GetRole()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetCommunityRoleResponse{}, nil
+}
+
+func (s *serverAPI) TransferCommunityOwnership(ctx context.Context, req *chatpb.TransferCommunityOwnershipRequest) (*chatpb.TransferCommunityOwnershipResponse, error) {
+	if req.GetCommunityId() == "" || req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "community_id and user_id are required")
+	}
+
+	if err := s.managerService.TransferCommunityOwnership(ctx, req.GetCommunityId(), req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.TransferCommunityOwnershipResponse{}, nil
+}
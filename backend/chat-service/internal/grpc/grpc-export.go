@@ -0,0 +1,66 @@
+package grpccontroller
+
+import (
+	"io"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) ExportChannel(req *chatpb.ExportChannelRequest, stream chatpb.Conversation_ExportChannelServer) error {
+	if err := validateExportChannel(req); err != nil {
+		return err
+	}
+
+	open := func(file domain.ExportFile) (io.Writer, error) {
+		err := stream.Send(&chatpb.ExportChannelResponse{
+			Payload: &chatpb.ExportChannelResponse_File{
+				File: &chatpb.ExportFile{
+					Name:        file.Name,
+					ContentType: file.ContentType,
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return exportStreamWriter{stream: stream}, nil
+	}
+
+	if err := s.viewService.ExportChannel(stream.Context(), req.GetChannelId(), req.GetFormat(), req.GetZip(), open); err != nil {
+		return getStatusError(err)
+	}
+
+	return nil
+}
+
+func validateExportChannel(req *chatpb.ExportChannelRequest) error {
+	switch {
+	case req.GetChannelId() == "":
+		return status.Error(codes.InvalidArgument, "channel_id is required")
+	case req.GetFormat() == "":
+		return status.Error(codes.InvalidArgument, "format is required")
+	default:
+		return nil
+	}
+}
+
+// exportStreamWriter sends every write as one data chunk, the service buffers writes into chunks
+type exportStreamWriter struct {
+	stream chatpb.Conversation_ExportChannelServer
+}
+
+func (w exportStreamWriter) Write(p []byte) (int, error) {
+	err := w.stream.Send(&chatpb.ExportChannelResponse{
+		Payload: &chatpb.ExportChannelResponse_Data{Data: p},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
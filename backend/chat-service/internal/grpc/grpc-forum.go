@@ -0,0 +1,108 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) SetForumTags(ctx context.Context, req *chatpb.SetForumTagsRequest) (*chatpb.SetForumTagsResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	channel, err := s.managerService.SetForumTags(ctx, req.GetChannelId(), req.GetTags())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetForumTagsResponse{
+		Channel: mapper.ConvertChannelToProto(channel),
+	}, nil
+}
+
+func (s *serverAPI) CreateForumPost(ctx context.Context, req *chatpb.CreateForumPostRequest) (*chatpb.CreateForumPostResponse, error) {
+	if err := validateCreateForumPost(req); err != nil {
+		return nil, err
+	}
+
+	post, err := s.conversationService.CreateForumPost(ctx, req.GetChannelId(), req.GetTitle(), req.GetText(), req.GetTags())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.CreateForumPostResponse{
+		Post: mapper.ConvertPostToProto(&post),
+	}, nil
+}
+
+func (s *serverAPI) ReplyToPost(ctx context.Context, req *chatpb.ReplyToPostRequest) (*chatpb.ReplyToPostResponse, error) {
+	if err := validateReplyToPost(req); err != nil {
+		return nil, err
+	}
+
+	messageID, err := s.conversationService.ReplyToPost(ctx, req.GetPostId(), req.GetText())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ReplyToPostResponse{
+		MessageId: messageID,
+	}, nil
+}
+
+func (s *serverAPI) ListForumPosts(ctx context.Context, req *chatpb.ListForumPostsRequest) (*chatpb.ListForumPostsResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	posts, err := s.viewService.ListForumPosts(ctx, req.GetChannelId(), req.GetTag(), req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ListForumPostsResponse{
+		Posts: posts,
+	}, nil
+}
+
+func (s *serverAPI) GetPostReplies(ctx context.Context, req *chatpb.GetPostRepliesRequest) (*chatpb.GetPostRepliesResponse, error) {
+	if req.GetPostId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "post_id is required")
+	}
+
+	messages, err := s.viewService.GetPostReplies(ctx, req.GetPostId(), req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.GetPostRepliesResponse{
+		Messages: messages,
+	}, nil
+}
+
+func validateCreateForumPost(req *chatpb.CreateForumPostRequest) error {
+	switch {
+	case req.GetChannelId() == "":
+		return status.Error(codes.InvalidArgument, "channel_id is required")
+	case req.GetTitle() == "":
+		return status.Error(codes.InvalidArgument, "title is required")
+	default:
+		return nil
+	}
+}
+
+func validateReplyToPost(req *chatpb.ReplyToPostRequest) error {
+	switch {
+	case req.GetPostId() == "":
+		return status.Error(codes.InvalidArgument, "post_id is required")
+	case req.GetText() == "":
+		return status.Error(codes.InvalidArgument, "text is required")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,103 @@
+package grpccontroller
+
+import (
+	"context"
+	"time"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) CreateInvite(ctx context.Context, req *chatpb.CreateInviteRequest) (*chatpb.CreateInviteResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	var expiresAt *time.Time
+	if req.GetExpiresAt() != nil {
+		t := req.GetExpiresAt().AsTime()
+		expiresAt = &t
+	}
+
+	invite, err := s.managerService.CreateInvite(ctx, req.GetChatId(), expiresAt, req.GetMaxUses(), req.GetRequiresApproval())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.CreateInviteResponse{
+		Invite: mapper.ConvertInviteToProto(&invite),
+	}, nil
+}
+
+func (s *serverAPI) ListInvites(ctx context.Context, req *chatpb.ListInvitesRequest) (*chatpb.ListInvitesResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	invites, err := s.managerService.ListInvites(ctx, req.GetChatId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ListInvitesResponse{
+		Invites: mapper.ConvertInvitesToProto(invites),
+	}, nil
+}
+
+func (s *serverAPI) RevokeInvite(ctx context.Context, req *chatpb.RevokeInviteRequest) (*chatpb.RevokeInviteResponse, error) {
+	if req.GetInviteId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "invite_id is required")
+	}
+
+	if err := s.managerService.RevokeInvite(ctx, req.GetInviteId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.RevokeInviteResponse{}, nil
+}
+
+func (s *serverAPI) JoinByInvite(ctx context.Context, req *chatpb.JoinByInviteRequest) (*chatpb.JoinByInviteResponse, error) {
+	if req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	chatID, pending, err := s.managerService.JoinByInvite(ctx, req.GetCode())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.JoinByInviteResponse{
+		ChatId:          chatID,
+		PendingApproval: pending,
+	}, nil
+}
+
+func (s *serverAPI) ListJoinRequests(ctx context.Context, req *chatpb.ListJoinRequestsRequest) (*chatpb.ListJoinRequestsResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	requests, err := s.managerService.ListJoinRequests(ctx, req.GetChatId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ListJoinRequestsResponse{
+		Requests: mapper.ConvertJoinRecordsToProto(requests),
+	}, nil
+}
+
+func (s *serverAPI) ReviewJoinRequest(ctx context.Context, req *chatpb.ReviewJoinRequestRequest) (*chatpb.ReviewJoinRequestResponse, error) {
+	if req.GetRequestId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "request_id is required")
+	}
+
+	if err := s.managerService.ReviewJoinRequest(ctx, req.GetRequestId(), req.GetApprove()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ReviewJoinRequestResponse{}, nil
+}
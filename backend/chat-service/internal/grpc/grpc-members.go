@@ -0,0 +1,71 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) AddMembers(ctx context.Context, req *chatpb.AddMembersRequest) (*chatpb.AddMembersResponse, error) {
+	if err := validateAddMembers(req); err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := s.managerService.AddMembers(ctx, req.GetChatId(), req.GetUserIds())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.AddMembersResponse{
+		MemberIds: memberIDs,
+	}, nil
+}
+
+func (s *serverAPI) RemoveMember(ctx context.Context, req *chatpb.RemoveMemberRequest) (*chatpb.RemoveMemberResponse, error) {
+	if err := validateRemoveMember(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.managerService.RemoveMember(ctx, req.GetChatId(), req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.RemoveMemberResponse{}, nil
+}
+
+func (s *serverAPI) LeaveChat(ctx context.Context, req *chatpb.LeaveChatRequest) (*chatpb.LeaveChatResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	if err := s.managerService.LeaveChat(ctx, req.GetChatId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.LeaveChatResponse{}, nil
+}
+
+func validateAddMembers(req *chatpb.AddMembersRequest) error {
+	switch {
+	case req.GetChatId() == "":
+		return status.Error(codes.InvalidArgument, "chat_id is required")
+	case len(req.GetUserIds()) == 0:
+		return status.Error(codes.InvalidArgument, "user_ids are required")
+	default:
+		return nil
+	}
+}
+
+func validateRemoveMember(req *chatpb.RemoveMemberRequest) error {
+	switch {
+	case req.GetChatId() == "":
+		return status.Error(codes.InvalidArgument, "chat_id is required")
+	case req.GetUserId() == "":
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	default:
+		return nil
+	}
+}
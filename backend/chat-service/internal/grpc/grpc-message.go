@@ -32,6 +32,14 @@ func (s *serverAPI) SendMessage(ctx context.Context, req *chatpb.SendMessageRequ
 			return nil, status.Error(codes.NotFound, "chat not found")
 		case errors.Is(err, domain.ErrAccessDenied):
 			return nil, status.Error(codes.PermissionDenied, "user is not in this chat")
+		case errors.Is(err, domain.ErrPermissionDenied):
+			return nil, status.Error(codes.PermissionDenied, "not enough permissions")
+		case errors.Is(err, domain.ErrAnnouncementOnly):
+			return nil, status.Error(codes.PermissionDenied, "only admins and allowed posters can post in announcement channel")
+		case errors.Is(err, domain.ErrForumPostRequired):
+			return nil, status.Error(codes.FailedPrecondition, "messages in forum channel must be replies to a post")
+		case errors.Is(err, domain.ErrArchived):
+			return nil, status.Error(codes.FailedPrecondition, "chat or channel is archived")
 		case errors.Is(err, domain.ErrInvalidMessage):
 			return nil, status.Error(codes.InvalidArgument, "invalid message length")
 		default:
@@ -0,0 +1,35 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) SetChatRetention(ctx context.Context, req *chatpb.SetChatRetentionRequest) (*chatpb.SetChatRetentionResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "chat_id is required")
+	}
+
+	if err := s.managerService.SetChatRetention(ctx, req.GetChatId(), mapper.ConvertRetentionFromProto(req.GetPolicy())); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetChatRetentionResponse{}, nil
+}
+
+func (s *serverAPI) SetChannelRetention(ctx context.Context, req *chatpb.SetChannelRetentionRequest) (*chatpb.SetChannelRetentionResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	if err := s.managerService.SetChannelRetention(ctx, req.GetChannelId(), mapper.ConvertRetentionFromProto(req.GetPolicy())); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetChannelRetentionResponse{}, nil
+}
@@ -0,0 +1,76 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) SetMemberRole(ctx context.Context, req *chatpb.SetMemberRoleRequest) (*chatpb.SetMemberRoleResponse, error) {
+	if err := validateSetMemberRole(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.managerService.SetMemberRole(ctx, req.GetChatId(), req.GetUserId(), req.GetRole()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetMemberRoleResponse{}, nil
+}
+
+func (s *serverAPI) TransferOwnership(ctx context.Context, req *chatpb.TransferOwnershipRequest) (*chatpb.TransferOwnershipResponse, error) {
+	if err := validateTransferOwnership(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.managerService.TransferOwnership(ctx, req.GetChatId(), req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.TransferOwnershipResponse{}, nil
+}
+
+func (s *serverAPI) SetChannelPermissions(ctx context.Context, req *chatpb.SetChannelPermissionsRequest) (*chatpb.SetChannelPermissionsResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	overrides, err := mapper.ConvertPermissionOverridesFromProto(req.GetOverrides())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	if err := s.managerService.SetChannelPermissions(ctx, req.GetChannelId(), overrides); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.SetChannelPermissionsResponse{}, nil
+}
+
+func validateSetMemberRole(req *chatpb.SetMemberRoleRequest) error {
+	switch {
+	case req.GetChatId() == "":
+		return status.Error(codes.InvalidArgument, "chat_id is required")
+	case req.GetUserId() == "":
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	case req.GetRole() == "":
+		return status.Error(codes.InvalidArgument, "role is required")
+	default:
+		return nil
+	}
+}
+
+func validateTransferOwnership(req *chatpb.TransferOwnershipRequest) error {
+	switch {
+	case req.GetChatId() == "":
+		return status.Error(codes.InvalidArgument, "chat_id is required")
+	case req.GetUserId() == "":
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) GetUpdates(ctx context.Context, req *chatpb.GetUpdatesRequest) (*chatpb.GetUpdatesResponse, error) {
+	if err := validateGetUpdates(req); err != nil {
+		return nil, err
+	}
+
+	diff, err := s.viewService.GetUpdates(ctx, req.GetSinceSeq(), req.GetLimit())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.GetUpdatesResponse{
+		Updates:        mapper.ConvertUpdatesToProto(diff.Updates),
+		Seq:            diff.Seq,
+		ResyncRequired: diff.ResyncRequired,
+		HasMore:        diff.HasMore,
+	}, nil
+}
+
+func validateGetUpdates(req *chatpb.GetUpdatesRequest) error {
+	switch {
+	case req.GetSinceSeq() < 0:
+		return status.Error(codes.InvalidArgument, "since_seq must be not negative")
+	case req.GetLimit() < 0:
+		return status.Error(codes.InvalidArgument, "limit must be not negative")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) JoinVoiceChannel(ctx context.Context, req *chatpb.JoinVoiceChannelRequest) (*chatpb.JoinVoiceChannelResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	participants, err := s.voiceService.JoinVoiceChannel(ctx, req.GetChannelId(), req.GetMuted(), req.GetDeafened())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.JoinVoiceChannelResponse{
+		Participants: mapper.ConvertVoiceParticipantsToProto(participants),
+	}, nil
+}
+
+func (s *serverAPI) LeaveVoiceChannel(ctx context.Context, req *chatpb.LeaveVoiceChannelRequest) (*chatpb.LeaveVoiceChannelResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	if err := s.voiceService.LeaveVoiceChannel(ctx, req.GetChannelId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.LeaveVoiceChannelResponse{}, nil
+}
+
+func (s *serverAPI) UpdateVoiceState(ctx context.Context, req *chatpb.UpdateVoiceStateRequest) (*chatpb.UpdateVoiceStateResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	participant, err := s.voiceService.UpdateVoiceState(ctx, req.GetChannelId(), req.Muted, req.Deafened)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.UpdateVoiceStateResponse{
+		Participant: mapper.ConvertVoiceParticipantToProto(participant),
+	}, nil
+}
+
+func (s *serverAPI) GetVoiceParticipants(ctx context.Context, req *chatpb.GetVoiceParticipantsRequest) (*chatpb.GetVoiceParticipantsResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	participants, err := s.voiceService.GetVoiceParticipants(ctx, req.GetChannelId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.GetVoiceParticipantsResponse{
+		Participants: mapper.ConvertVoiceParticipantsToProto(participants),
+	}, nil
+}
+
+func (s *serverAPI) VoiceSignaling(stream chatpb.Conversation_VoiceSignalingServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "first signaling message with channel_id or call_id is required")
+	}
+	if first.GetChannelId() == "" && first.GetCallId() == "" {
+		return status.Error(codes.InvalidArgument, "channel_id or call_id is required")
+	}
+
+	recv := func() (domain.Signal, error) {
+		req, err := stream.Recv()
+		if err != nil {
+			return domain.Signal{}, err
+		}
+		return mapper.ConvertSignalFromProto(req), nil
+	}
+
+	send := func(signal domain.Signal) error {
+		return stream.Send(mapper.ConvertSignalToProto(signal))
+	}
+
+	if first.GetCallId() != "" {
+		err = s.voiceService.RelayCallSignals(stream.Context(), first.GetCallId(), recv, send)
+	} else {
+		err = s.voiceService.RelaySignals(stream.Context(), first.GetChannelId(), recv, send)
+	}
+	if err != nil {
+		return getStatusError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package grpccontroller
+
+import (
+	"context"
+
+	chatpb "chat-service/gen"
+	"chat-service/internal/lib/mapper"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) CreateWebhook(ctx context.Context, req *chatpb.CreateWebhookRequest) (*chatpb.CreateWebhookResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	webhook, err := s.managerService.CreateWebhook(ctx, req.GetChannelId(), req.GetName(), req.GetAvatarUrl())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.CreateWebhookResponse{
+		Webhook: mapper.ConvertWebhookToProto(&webhook),
+	}, nil
+}
+
+func (s *serverAPI) ListWebhooks(ctx context.Context, req *chatpb.ListWebhooksRequest) (*chatpb.ListWebhooksResponse, error) {
+	if req.GetChannelId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "channel_id is required")
+	}
+
+	webhooks, err := s.managerService.ListWebhooks(ctx, req.GetChannelId())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.ListWebhooksResponse{
+		Webhooks: mapper.ConvertWebhooksToProto(webhooks),
+	}, nil
+}
+
+func (s *serverAPI) RevokeWebhook(ctx context.Context, req *chatpb.RevokeWebhookRequest) (*chatpb.RevokeWebhookResponse, error) {
+	if req.GetWebhookId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "webhook_id is required")
+	}
+
+	if err := s.managerService.RevokeWebhook(ctx, req.GetWebhookId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &chatpb.RevokeWebhookResponse{}, nil
+}
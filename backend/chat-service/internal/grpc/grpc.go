@@ -12,8 +12,9 @@ type serverAPI struct {
 	conversationService interfaces.ConversationService
 	viewService         interfaces.ViewService
 	managerService      interfaces.ManagerService
+	voiceService        interfaces.VoiceService
 }
 
-func Register(gRPC *grpc.Server, conversationService interfaces.ConversationService, viewService interfaces.ViewService, managerService interfaces.ManagerService) {
-	chatpb.RegisterConversationServer(gRPC, &serverAPI{conversationService: conversationService, viewService: viewService, managerService: managerService})
+func Register(gRPC *grpc.Server, conversationService interfaces.ConversationService, viewService interfaces.ViewService, managerService interfaces.ManagerService, voiceService interfaces.VoiceService) {
+	chatpb.RegisterConversationServer(gRPC, &serverAPI{conversationService: conversationService, viewService: viewService, managerService: managerService, voiceService: voiceService})
 }
@@ -0,0 +1,31 @@
+package httpcontroller
+
+import (
+	"errors"
+	"net/http"
+
+	"chat-service/internal/domain"
+)
+
+func getStatusError(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		return http.StatusNotFound, "webhook not found"
+	case errors.Is(err, domain.ErrChannelNotFound):
+		return http.StatusNotFound, "channel not found"
+	case errors.Is(err, domain.ErrChatNotFound):
+		return http.StatusNotFound, "chat not found"
+
+	case errors.Is(err, domain.ErrAccessDenied), errors.Is(err, domain.ErrPermissionDenied):
+		return http.StatusForbidden, "creator of the webhook may no longer post in the channel"
+
+	case errors.Is(err, domain.ErrArchived):
+		return http.StatusConflict, "chat or channel is archived"
+
+	case errors.Is(err, domain.ErrInvalidMessage):
+		return http.StatusBadRequest, "text must be not empty and not longer than the message length limit"
+
+	default:
+		return http.StatusInternalServerError, "internal error"
+	}
+}
@@ -0,0 +1,82 @@
+package httpcontroller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"chat-service/internal/lib/logger"
+)
+
+// maxWebhookBodySize limits the JSON payload of a webhook
+const maxWebhookBodySize = 64 << 10
+
+type executeWebhookRequest struct {
+	Text string `json:"text"`
+}
+
+type executeWebhookResponse struct {
+	MessageID string `json:"message_id"`
+}
+
+// ExecuteWebhook posts the text of a JSON payload into the channel of the webhook. A webhook is
+// rate limited once it posted and only posted messages are charged, so requests with a wrong
+// token neither get a bucket nor drain the bucket of the webhook
+func (s *serverAPI) ExecuteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := r.PathValue("webhook_id")
+	token := r.PathValue("token")
+
+	if retryAfter, ok := s.limiter.allow(webhookID); !ok {
+		if retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	var req executeWebhookRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err := decoder.Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "payload is too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "payload must be a JSON object")
+		return
+	}
+	if req.Text == "" {
+		writeError(w, http.StatusBadRequest, "text is required")
+		return
+	}
+
+	messageID, err := s.conversationService.ExecuteWebhook(r.Context(), webhookID, token, req.Text)
+	if err != nil {
+		code, message := getStatusError(err)
+		if code == http.StatusInternalServerError {
+			s.log.Error("failed to execute webhook", slog.String("webhook_id", webhookID), logger.Err(err))
+		}
+		writeError(w, code, message)
+		return
+	}
+	s.limiter.charge(webhookID)
+
+	writeJSON(w, http.StatusOK, executeWebhookResponse{MessageID: messageID})
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,135 @@
+package httpcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+)
+
+// fakeConversations accepts the webhook "hook" with the token "secret"
+type fakeConversations struct {
+	interfaces.ConversationService
+}
+
+func (f *fakeConversations) ExecuteWebhook(ctx context.Context, webhookID string, token string, text string) (string, error) {
+	if webhookID != "hook" || token != "secret" {
+		return "", domain.ErrWebhookNotFound
+	}
+	return "message", nil
+}
+
+// newTestServer serves webhooks with burst messages and next to no refill
+func newTestServer(burst int) (*http.ServeMux, *serverAPI) {
+	s := &serverAPI{
+		log:                 slog.New(slog.NewTextHandler(io.Discard, nil)),
+		conversationService: &fakeConversations{},
+		limiter:             newRateLimiter(0.001, burst, limiterIdleTTL),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhooks/{webhook_id}/{token}", s.ExecuteWebhook)
+	return mux, s
+}
+
+func postWebhook(mux *http.ServeMux, webhookID string, token string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/"+webhookID+"/"+token, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestExecuteWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		webhookID string
+		token     string
+		body      string
+		wantCode  int
+	}{
+		{"posts text", "hook", "secret", `{"text":"deployed"}`, http.StatusOK},
+		{"wrong token", "hook", "guess", `{"text":"deployed"}`, http.StatusNotFound},
+		{"unknown webhook", "other", "secret", `{"text":"deployed"}`, http.StatusNotFound},
+		{"not json", "hook", "secret", `deployed`, http.StatusBadRequest},
+		{"no text", "hook", "secret", `{}`, http.StatusBadRequest},
+		{"too large", "hook", "secret", `{"text":"` + strings.Repeat("a", maxWebhookBodySize) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newTestServer(10)
+
+			w := postWebhook(mux, tt.webhookID, tt.token, tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantCode, w.Body)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var resp executeWebhookResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.MessageID != "message" {
+				t.Errorf("response = %+v, %v, want message_id of the posted message", resp, err)
+			}
+		})
+	}
+}
+
+func TestExecuteWebhookRateLimit(t *testing.T) {
+	mux, s := newTestServer(2)
+
+	for i := range 100 {
+		if w := postWebhook(mux, fmt.Sprintf("guess%d", i), "secret", `{"text":"spam"}`); w.Code != http.StatusNotFound {
+			t.Fatalf("status of an unknown webhook = %d, want %d", w.Code, http.StatusNotFound)
+		}
+	}
+	if w := postWebhook(mux, "hook", "guess", `{"text":"spam"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("status of a wrong token = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if buckets := len(s.limiter.limiters); buckets != 0 {
+		t.Fatalf("%d buckets after unverified requests, want none", buckets)
+	}
+
+	for i := range 2 {
+		if w := postWebhook(mux, "hook", "secret", `{"text":"deployed"}`); w.Code != http.StatusOK {
+			t.Fatalf("status of message %d = %d, want %d", i, w.Code, http.StatusOK)
+		}
+	}
+	w := postWebhook(mux, "hook", "secret", `{"text":"deployed"}`)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status after the burst = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After is not set")
+	}
+}
+
+func TestExecuteWebhookBadTokensDoNotThrottle(t *testing.T) {
+	mux, _ := newTestServer(2)
+
+	if w := postWebhook(mux, "hook", "secret", `{"text":"deployed"}`); w.Code != http.StatusOK {
+		t.Fatalf("status of the first message = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	for i := range 100 {
+		if w := postWebhook(mux, "hook", fmt.Sprintf("guess%d", i), `{"text":"spam"}`); w.Code != http.StatusNotFound {
+			t.Fatalf("status of a wrong token = %d, want %d", w.Code, http.StatusNotFound)
+		}
+		if w := postWebhook(mux, "hook", "secret", `spam`); w.Code != http.StatusBadRequest {
+			t.Fatalf("status of an invalid payload = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	}
+
+	if w := postWebhook(mux, "hook", "secret", `{"text":"deployed"}`); w.Code != http.StatusOK {
+		t.Fatalf("status after rejected requests = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := postWebhook(mux, "hook", "secret", `{"text":"deployed"}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("status after the burst = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,32 @@
+package httpcontroller
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"chat-service/internal/domain/interfaces"
+
+	"golang.org/x/time/rate"
+)
+
+type serverAPI struct {
+	log                 *slog.Logger
+	conversationService interfaces.ConversationService
+	limiter             *rateLimiter
+}
+
+// Register adds the webhook endpoint to the mux, every webhook may post limit messages
+// per second with bursts of burst messages
+func Register(mux *http.ServeMux, log *slog.Logger, conversationService interfaces.ConversationService, limit float64, burst int) {
+	s := &serverAPI{
+		log:                 log,
+		conversationService: conversationService,
+		limiter:             newRateLimiter(rate.Limit(limit), burst, limiterIdleTTL),
+	}
+
+	mux.HandleFunc("POST /webhooks/{webhook_id}/{token}", s.ExecuteWebhook)
+}
+
+// limiterIdleTTL is how long the limiter of a webhook that stopped posting is kept
+const limiterIdleTTL = 10 * time.Minute
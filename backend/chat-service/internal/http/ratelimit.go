@@ -0,0 +1,93 @@
+package httpcontroller
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimiter keeps a token bucket per tracked key. Requests are checked against the bucket before
+// they are verified and charged only after, so unverified input never allocates a bucket or takes
+// a token. Buckets idle for longer than idleTTL are dropped, so keys that stop coming back, like ids
+// of revoked webhooks, do not pile up
+type rateLimiter struct {
+	limit   rate.Limit
+	burst   int
+	idleTTL time.Duration
+
+	mu        sync.Mutex
+	limiters  map[string]*keyLimiter
+	lastSweep time.Time
+}
+
+type keyLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newRateLimiter(limit rate.Limit, burst int, idleTTL time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:     limit,
+		burst:     burst,
+		idleTTL:   idleTTL,
+		limiters:  make(map[string]*keyLimiter),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow reports whether the key has a token left without taking it and returns how long to wait
+// before retrying when there is none. Keys that are not tracked are allowed
+func (l *rateLimiter) allow(key string) (time.Duration, bool) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	kl, ok := l.limiters[key]
+	if !ok {
+		return 0, true
+	}
+
+	tokens := kl.limiter.TokensAt(now)
+	if tokens >= 1 {
+		return 0, true
+	}
+	if l.limit <= 0 {
+		return 0, false
+	}
+	return time.Duration((1 - tokens) / float64(l.limit) * float64(time.Second)), false
+}
+
+// charge takes a token of the verified key, giving it a bucket on the first request. Concurrent
+// requests allowed on the same last token are all charged, the bucket goes into debt and
+// the next requests wait it off
+func (l *rateLimiter) charge(key string) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	kl, ok := l.limiters[key]
+	if !ok {
+		kl = &keyLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = kl
+	}
+	kl.lastSeen = now
+	kl.limiter.ReserveN(now, 1)
+}
+
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) <= l.idleTTL {
+		return
+	}
+
+	for k, kl := range l.limiters {
+		if now.Sub(kl.lastSeen) > l.idleTTL {
+			delete(l.limiters, k)
+		}
+	}
+	l.lastSweep = now
+}
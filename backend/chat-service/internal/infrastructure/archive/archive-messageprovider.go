@@ -0,0 +1,160 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+)
+
+// MessageProvider reads channel history through to the archive when paging goes past the hot
+// storage. Archived messages are older than the hot ones, so the archive continues history
+// newest first in GetMessages and precedes it in GetMessagesAfter
+type MessageProvider struct {
+	interfaces.MessageProvider
+	archive *Archive
+}
+
+// MessageProvider wraps the hot message provider the archive was created with
+func (a *Archive) MessageProvider() *MessageProvider {
+	return &MessageProvider{MessageProvider: a.messages, archive: a}
+}
+
+func (p *MessageProvider) GetMessages(ctx context.Context, channelID string, limit int32, offset int32) ([]*domain.Message, error) {
+	const op = "infrastructure.archive.GetMessages"
+
+	messages, err := p.MessageProvider.GetMessages(ctx, channelID, limit, offset)
+	if err != nil || (limit > 0 && len(messages) >= int(limit)) {
+		return messages, err
+	}
+
+	m, err := p.archive.manifest(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(m.Segments) == 0 {
+		return messages, nil
+	}
+
+	// a page that ends in the hot storage continues from the newest archived message,
+	// a page past it skips the rest of the offset in the archive
+	skip := 0
+	if len(messages) == 0 && offset > 1 {
+		channel, err := p.archive.channels.FindChannelByID(ctx, channelID)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		hot := max(int(channel.MessageCount)-m.messages(), 0)
+		skip = max(int(offset-1)-hot, 0)
+	}
+
+	remaining := -1
+	if limit > 0 {
+		remaining = int(limit) - len(messages)
+	}
+
+	for i := len(m.Segments) - 1; i >= 0 && remaining != 0; i-- {
+		segment := m.Segments[i]
+		if skip >= segment.Messages {
+			skip -= segment.Messages
+			continue
+		}
+
+		archived, err := p.archive.readSegment(ctx, channelID, segment.Start)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		for j := len(archived) - 1 - skip; j >= 0 && remaining != 0; j-- {
+			messages = append(messages, archived[j])
+			remaining--
+		}
+		skip = 0
+	}
+
+	return messages, nil
+}
+
+func (p *MessageProvider) GetMessagesAfter(ctx context.Context, channelID string, afterTime time.Time, afterID string, limit int32) ([]*domain.Message, error) {
+	const op = "infrastructure.archive.GetMessagesAfter"
+
+	m, err := p.archive.manifest(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(m.Segments) == 0 || afterTime.After(m.Segments[len(m.Segments)-1].Newest) {
+		return p.MessageProvider.GetMessagesAfter(ctx, channelID, afterTime, afterID, limit)
+	}
+
+	var messages []*domain.Message
+	started := afterTime.IsZero()
+	for _, segment := range m.Segments {
+		if limit > 0 && len(messages) >= int(limit) {
+			break
+		}
+		if segment.Newest.Before(afterTime) {
+			continue
+		}
+
+		archived, err := p.archive.readSegment(ctx, channelID, segment.Start)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		for _, message := range archived {
+			if limit > 0 && len(messages) >= int(limit) {
+				break
+			}
+			if !started {
+				// messages with the time of the cursor follow the message it points to
+				started = message.CreatedAt.After(afterTime)
+				if !started {
+					started = message.ID == afterID
+					continue
+				}
+			}
+			messages = append(messages, message)
+		}
+	}
+
+	if limit > 0 && len(messages) >= int(limit) {
+		return messages, nil
+	}
+
+	if len(messages) > 0 {
+		last := messages[len(messages)-1]
+		afterTime, afterID = last.CreatedAt, last.ID
+	}
+	hotLimit := limit
+	if limit > 0 {
+		hotLimit = limit - int32(len(messages))
+	}
+
+	hot, err := p.MessageProvider.GetMessagesAfter(ctx, channelID, afterTime, afterID, hotLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(messages, hot...), nil
+}
+
+// DeleteChannelsMessages deletes archived messages of the channels with the hot ones. Inside
+// a transaction the archived ones are deleted by the Transactor after the commit
+func (p *MessageProvider) DeleteChannelsMessages(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.archive.DeleteChannelsMessages"
+
+	if err := p.MessageProvider.DeleteChannelsMessages(ctx, channelIDs); err != nil {
+		return err
+	}
+
+	if pending := pendingFrom(ctx); pending != nil {
+		pending.add(channelIDs)
+		return nil
+	}
+
+	if err := p.archive.deleteChannels(ctx, channelIDs); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+// ArchiveMessagesBefore moves up to limit oldest messages of the channel created before the time
+// to the archive and returns how many were moved. The newest message of the channel always stays
+// in the hot storage, it is the last message of the channel
+func (a *Archive) ArchiveMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) (int, error) {
+	const op = "infrastructure.archive.ArchiveMessagesBefore"
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	oldest, err := a.messages.GetMessagesAfter(ctx, channelID, time.Time{}, "", limit+1)
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var moving []*domain.Message
+	for _, message := range oldest {
+		if !message.CreatedAt.Before(before) || len(moving) == int(limit) {
+			break
+		}
+		moving = append(moving, message)
+	}
+	if len(moving) > 0 && len(moving) == len(oldest) {
+		moving = moving[:len(moving)-1]
+	}
+	if len(moving) == 0 {
+		return 0, nil
+	}
+
+	m, err := a.loadManifest(ctx, channelID)
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	// moving is sorted oldest first, so messages of one segment follow each other
+	for start := 0; start < len(moving); {
+		segmentStart := moving[start].CreatedAt.UTC().Truncate(a.span)
+		end := start
+		for end < len(moving) && moving[end].CreatedAt.UTC().Truncate(a.span).Equal(segmentStart) {
+			end++
+		}
+
+		info, err := a.mergeSegment(ctx, channelID, m, segmentStart, moving[start:end])
+		if err != nil {
+			return 0, fmt.Errorf("%s : %w", op, err)
+		}
+		m = withSegment(m, info)
+		start = end
+	}
+
+	if err := a.saveManifest(ctx, channelID, m); err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	messageIDs, err := a.messages.DeleteArchivedMessages(ctx, channelID, before, int32(len(moving)))
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return len(messageIDs), nil
+}
+
+// PurgeBefore deletes archived messages of the channel created before the time and returns how many
+// were deleted. The manifest is saved before the message count of the channel is decreased, so an
+// interrupted purge never decreases it twice
+func (a *Archive) PurgeBefore(ctx context.Context, channelID string, before time.Time) (int, error) {
+	const op = "infrastructure.archive.PurgeBefore"
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m, err := a.loadManifest(ctx, channelID)
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	purged := 0
+	var emptied []time.Time
+	for _, segment := range m.Segments {
+		if !segment.Oldest.Before(before) {
+			continue
+		}
+
+		info := segmentInfo{Start: segment.Start}
+		if !segment.Newest.Before(before) {
+			messages, err := a.readSegment(ctx, channelID, segment.Start)
+			if err != nil {
+				return purged, fmt.Errorf("%s : %w", op, err)
+			}
+
+			var kept []*domain.Message
+			for _, message := range messages {
+				if !message.CreatedAt.Before(before) {
+					kept = append(kept, message)
+				}
+			}
+			if err := a.writeSegment(ctx, channelID, segment.Start, kept); err != nil {
+				return purged, fmt.Errorf("%s : %w", op, err)
+			}
+			info = segmentInfo{Start: segment.Start, Messages: len(kept), Oldest: kept[0].CreatedAt, Newest: kept[len(kept)-1].CreatedAt}
+		} else {
+			emptied = append(emptied, segment.Start)
+		}
+
+		purged += segment.Messages - info.Messages
+		m = withSegment(m, info)
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err := a.saveManifest(ctx, channelID, m); err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+	for _, start := range emptied {
+		if err := a.store.Delete(ctx, segmentKey(channelID, start)); err != nil {
+			return purged, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	if err := a.messages.DecreaseMessageCount(ctx, channelID, int64(purged)); err != nil {
+		return purged, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return purged, nil
+}
+
+// deleteChannels removes segments and manifests of the channels
+func (a *Archive) deleteChannels(ctx context.Context, channelIDs []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, channelID := range channelIDs {
+		m, err := a.loadManifest(ctx, channelID)
+		if err != nil {
+			return err
+		}
+		if len(m.Segments) == 0 {
+			continue
+		}
+
+		if err := a.saveManifest(ctx, channelID, manifest{}); err != nil {
+			return err
+		}
+		for _, segment := range m.Segments {
+			if err := a.store.Delete(ctx, segmentKey(channelID, segment.Start)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
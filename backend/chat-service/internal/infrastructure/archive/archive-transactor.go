@@ -0,0 +1,62 @@
+package archive
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+)
+
+type pendingKey struct{}
+
+// pendingDeletion collects channels whose messages were deleted inside a transaction
+type pendingDeletion struct {
+	mu         sync.Mutex
+	channelIDs []string
+}
+
+func (p *pendingDeletion) add(channelIDs []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.channelIDs = append(p.channelIDs, channelIDs...)
+}
+
+func pendingFrom(ctx context.Context) *pendingDeletion {
+	pending, _ := ctx.Value(pendingKey{}).(*pendingDeletion)
+	return pending
+}
+
+// Transactor deletes archived messages of channels deleted inside a transaction once it commits.
+// The store is not part of the transaction, so a rolled back deletion keeps the archived history
+type Transactor struct {
+	interfaces.Transactor
+	archive *Archive
+	log     *slog.Logger
+}
+
+func (a *Archive) Transactor(transactor interfaces.Transactor, log *slog.Logger) *Transactor {
+	return &Transactor{Transactor: transactor, archive: a, log: log}
+}
+
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if pendingFrom(ctx) != nil {
+		return t.Transactor.WithinTransaction(ctx, fn)
+	}
+
+	pending := &pendingDeletion{}
+	if err := t.Transactor.WithinTransaction(context.WithValue(ctx, pendingKey{}, pending), fn); err != nil {
+		return err
+	}
+	if len(pending.channelIDs) == 0 {
+		return nil
+	}
+
+	// the channels are gone after the commit, segments left by a failed deletion are never read again
+	if err := t.archive.deleteChannels(ctx, pending.channelIDs); err != nil {
+		t.log.Error("failed to delete archived messages of deleted channels", slog.Any("channel_ids", pending.channelIDs), logger.Err(err))
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+)
+
+// ErrObjectNotFound is returned by a Store for a key that was never put or was deleted
+var ErrObjectNotFound = errors.New("archive object not found")
+
+// Store keeps archive objects by key, keys are slash separated paths
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Archive keeps old messages of a channel in segments, gzip compressed JSON lines of the messages
+// created within one span of time, oldest first. The manifest of the channel lists its segments.
+// Messages are copied to a segment and the manifest before they are deleted from the hot storage,
+// so an interrupted move leaves messages in both places and the next move merges them by id.
+// Segments of a channel are changed by one process at a time, the archive job must run on one replica
+type Archive struct {
+	store    Store
+	messages interfaces.MessageProvider
+	channels interfaces.ChannelProvider
+	span     time.Duration
+
+	// mu serializes changes of segments made by this process
+	mu sync.Mutex
+
+	manifestTTL time.Duration
+	manifestsMu sync.Mutex
+	manifests   map[string]cachedManifest
+}
+
+// New creates the archive over the hot message and channel providers, manifests read from the store
+// are cached for manifestTTL, so channels without archived messages don't hit the store on every page
+func New(store Store, messages interfaces.MessageProvider, channels interfaces.ChannelProvider, span time.Duration, manifestTTL time.Duration) *Archive {
+	return &Archive{
+		store:    store,
+		messages: messages,
+		channels: channels,
+		span:     span,
+
+		manifestTTL: manifestTTL,
+		manifests:   make(map[string]cachedManifest),
+	}
+}
+
+type manifest struct {
+	// Segments are sorted by Start
+	Segments []segmentInfo `json:"segments"`
+}
+
+type segmentInfo struct {
+	Start    time.Time `json:"start"`
+	Messages int       `json:"messages"`
+	Oldest   time.Time `json:"oldest"`
+	Newest   time.Time `json:"newest"`
+}
+
+func (m manifest) messages() int {
+	total := 0
+	for _, segment := range m.Segments {
+		total += segment.Messages
+	}
+	return total
+}
+
+type cachedManifest struct {
+	manifest  manifest
+	expiresAt time.Time
+}
+
+// record is a message line of a segment
+type record struct {
+	ID        string             `json:"id"`
+	SenderID  string             `json:"sender_id"`
+	Text      string             `json:"text"`
+	Type      string             `json:"type,omitempty"`
+	PostID    string             `json:"post_id,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	Call      *domain.CallRecord `json:"call,omitempty"`
+
+	Webhook *domain.WebhookSender `json:"webhook,omitempty"`
+}
+
+func manifestKey(channelID string) string {
+	return fmt.Sprintf("channels/%s/manifest.json", channelID)
+}
+
+func segmentKey(channelID string, start time.Time) string {
+	return fmt.Sprintf("channels/%s/%s.jsonl.gz", channelID, start.UTC().Format("20060102T150405Z"))
+}
+
+// manifest returns the cached manifest of the channel, a channel without archived messages has an empty one
+func (a *Archive) manifest(ctx context.Context, channelID string) (manifest, error) {
+	a.manifestsMu.Lock()
+	cached, ok := a.manifests[channelID]
+	a.manifestsMu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.manifest, nil
+	}
+
+	return a.loadManifest(ctx, channelID)
+}
+
+// loadManifest reads the manifest from the store, changes of segments start from it
+func (a *Archive) loadManifest(ctx context.Context, channelID string) (manifest, error) {
+	var m manifest
+	data, err := a.store.Get(ctx, manifestKey(channelID))
+	switch {
+	case errors.Is(err, ErrObjectNotFound):
+	case err != nil:
+		return manifest{}, err
+	default:
+		if err := json.Unmarshal(data, &m); err != nil {
+			return manifest{}, err
+		}
+	}
+
+	a.cacheManifest(channelID, m)
+	return m, nil
+}
+
+func (a *Archive) saveManifest(ctx context.Context, channelID string, m manifest) error {
+	if len(m.Segments) == 0 {
+		if err := a.store.Delete(ctx, manifestKey(channelID)); err != nil {
+			return err
+		}
+	} else {
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if err := a.store.Put(ctx, manifestKey(channelID), data); err != nil {
+			return err
+		}
+	}
+
+	a.cacheManifest(channelID, m)
+	return nil
+}
+
+func (a *Archive) cacheManifest(channelID string, m manifest) {
+	a.manifestsMu.Lock()
+	defer a.manifestsMu.Unlock()
+
+	now := time.Now()
+	for id, cached := range a.manifests {
+		if !now.Before(cached.expiresAt) {
+			delete(a.manifests, id)
+		}
+	}
+	a.manifests[channelID] = cachedManifest{manifest: m, expiresAt: now.Add(a.manifestTTL)}
+}
+
+// readSegment returns messages of the segment oldest first
+func (a *Archive) readSegment(ctx context.Context, channelID string, start time.Time) ([]*domain.Message, error) {
+	data, err := a.store.Get(ctx, segmentKey(channelID, start))
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var messages []*domain.Message
+	decoder := json.NewDecoder(reader)
+	for decoder.More() {
+		var line record
+		if err := decoder.Decode(&line); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &domain.Message{
+			ID:        line.ID,
+			ChannelID: channelID,
+			SenderID:  line.SenderID,
+			Text:      line.Text,
+			Type:      line.Type,
+			PostID:    line.PostID,
+			CreatedAt: line.CreatedAt,
+			Call:      line.Call,
+			Webhook:   line.Webhook,
+		})
+	}
+
+	return messages, nil
+}
+
+// writeSegment replaces the segment with the messages, which are sorted oldest first
+func (a *Archive) writeSegment(ctx context.Context, channelID string, start time.Time, messages []*domain.Message) error {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	lines := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(lines)
+	for _, message := range messages {
+		err := encoder.Encode(record{
+			ID:        message.ID,
+			SenderID:  message.SenderID,
+			Text:      message.Text,
+			Type:      message.Type,
+			PostID:    message.PostID,
+			CreatedAt: message.CreatedAt.UTC(),
+			Call:      message.Call,
+			Webhook:   message.Webhook,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := lines.Flush(); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return a.store.Put(ctx, segmentKey(channelID, start), buf.Bytes())
+}
+
+// mergeSegment adds the messages to the segment starting at start and returns its new info
+func (a *Archive) mergeSegment(ctx context.Context, channelID string, m manifest, start time.Time, messages []*domain.Message) (segmentInfo, error) {
+	var merged []*domain.Message
+	if slices.ContainsFunc(m.Segments, func(segment segmentInfo) bool { return segment.Start.Equal(start) }) {
+		existing, err := a.readSegment(ctx, channelID, start)
+		if err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return segmentInfo{}, err
+		}
+		merged = existing
+	}
+
+	// a message left in the hot storage by an interrupted move is already in the segment
+	archived := make(map[string]struct{}, len(merged))
+	for _, message := range merged {
+		archived[message.ID] = struct{}{}
+	}
+	for _, message := range messages {
+		if _, ok := archived[message.ID]; !ok {
+			merged = append(merged, message)
+		}
+	}
+	slices.SortStableFunc(merged, func(a, b *domain.Message) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	if err := a.writeSegment(ctx, channelID, start, merged); err != nil {
+		return segmentInfo{}, err
+	}
+
+	return segmentInfo{
+		Start:    start,
+		Messages: len(merged),
+		Oldest:   merged[0].CreatedAt,
+		Newest:   merged[len(merged)-1].CreatedAt,
+	}, nil
+}
+
+// withSegment returns the manifest with the segment added or replaced
+func withSegment(m manifest, info segmentInfo) manifest {
+	segments := slices.DeleteFunc(slices.Clone(m.Segments), func(segment segmentInfo) bool {
+		return segment.Start.Equal(info.Start)
+	})
+	if info.Messages > 0 {
+		segments = append(segments, info)
+	}
+	slices.SortFunc(segments, func(a, b segmentInfo) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return manifest{Segments: segments}
+}
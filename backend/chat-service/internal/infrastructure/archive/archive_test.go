@@ -0,0 +1,334 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/boltdb"
+)
+
+// base is aligned to testSpan, so messages fall into segments of four hours each
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const testSpan = 4 * time.Hour
+
+type testArchive struct {
+	archive   *Archive
+	store     *FileStore
+	storage   *boltdb.BoltDB
+	channelID string
+	// messageIDs are oldest first, message i was created i hours after base
+	messageIDs []string
+}
+
+// newTestArchive creates a channel with count messages an hour apart over the in-memory storage
+func newTestArchive(t *testing.T, count int) *testArchive {
+	t.Helper()
+	ctx := context.Background()
+
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+
+	chatID, err := storage.SaveChat(ctx, domain.Chat{Type: "group", Name: "team", MemberIDs: []string{"alice"}})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+	channelID, err := storage.SaveChannel(ctx, domain.Channel{ChatID: chatID, Name: "general"})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+
+	ta := &testArchive{store: NewFileStore(t.TempDir()), storage: storage, channelID: channelID}
+	for i := range count {
+		message := domain.Message{ChannelID: channelID, SenderID: "alice", Text: "hi", CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		if i == 1 {
+			message.Type = domain.MessageTypeCall
+			message.Call = &domain.CallRecord{CallID: "call", CallerID: "alice", EndReason: "ended", DurationSeconds: 42}
+		}
+		messageID, err := storage.SaveMessage(ctx, message)
+		if err != nil {
+			t.Fatalf("SaveMessage() error = %v", err)
+		}
+		ta.messageIDs = append(ta.messageIDs, messageID)
+	}
+
+	ta.archive = New(ta.store, storage, storage, testSpan, time.Minute)
+	return ta
+}
+
+func (ta *testArchive) hours(n int) time.Time {
+	return base.Add(time.Duration(n) * time.Hour)
+}
+
+func messageIDs(messages []*domain.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+// reversed returns ids newest first
+func reversed(ids []string) []string {
+	ids = slices.Clone(ids)
+	slices.Reverse(ids)
+	return ids
+}
+
+func TestArchiveMessagesBefore(t *testing.T) {
+	tests := []struct {
+		name         string
+		before       time.Time
+		limits       []int32
+		wantMoved    []int
+		wantSegments []int
+	}{
+		{"newest message stays", base.Add(24 * time.Hour), []int32{100}, []int{9}, []int{4, 4, 1}},
+		{"only older messages", base.Add(5 * time.Hour), []int32{100}, []int{5}, []int{4, 1}},
+		{"limited batches merge", base.Add(24 * time.Hour), []int32{2, 3, 100}, []int{2, 3, 4}, []int{4, 4, 1}},
+		{"nothing before", base, []int32{100}, []int{0}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestArchive(t, 10)
+			ctx := context.Background()
+
+			moved := 0
+			for i, limit := range tt.limits {
+				n, err := ta.archive.ArchiveMessagesBefore(ctx, ta.channelID, tt.before, limit)
+				if err != nil {
+					t.Fatalf("ArchiveMessagesBefore() error = %v", err)
+				}
+				if n != tt.wantMoved[i] {
+					t.Errorf("batch %d moved %d messages, want %d", i, n, tt.wantMoved[i])
+				}
+				moved += n
+			}
+
+			m, err := ta.archive.loadManifest(ctx, ta.channelID)
+			if err != nil {
+				t.Fatalf("loadManifest() error = %v", err)
+			}
+			var segments []int
+			for _, segment := range m.Segments {
+				segments = append(segments, segment.Messages)
+			}
+			if !slices.Equal(segments, tt.wantSegments) {
+				t.Errorf("segments = %v, want %v", segments, tt.wantSegments)
+			}
+
+			hot, err := ta.storage.GetMessages(ctx, ta.channelID, 0, 0)
+			if err != nil {
+				t.Fatalf("GetMessages() error = %v", err)
+			}
+			if want := reversed(ta.messageIDs[moved:]); !slices.Equal(messageIDs(hot), want) {
+				t.Errorf("hot messages = %v, want %v", messageIDs(hot), want)
+			}
+		})
+	}
+}
+
+func TestMessageProviderGetMessages(t *testing.T) {
+	ta := newTestArchive(t, 10)
+	ctx := context.Background()
+	if _, err := ta.archive.ArchiveMessagesBefore(ctx, ta.channelID, ta.hours(7), 100); err != nil {
+		t.Fatalf("ArchiveMessagesBefore() error = %v", err)
+	}
+	provider := ta.archive.MessageProvider()
+	newest := reversed(ta.messageIDs)
+
+	tests := []struct {
+		name   string
+		limit  int32
+		offset int32
+		want   []string
+	}{
+		{"hot page", 3, 1, newest[:3]},
+		{"page across the archive", 3, 2, newest[1:4]},
+		{"archived page", 3, 5, newest[4:7]},
+		{"last page", 5, 8, newest[7:]},
+		{"past the end", 5, 11, nil},
+		{"no limit", 0, 0, newest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := provider.GetMessages(ctx, ta.channelID, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("GetMessages() error = %v", err)
+			}
+			if got := messageIDs(messages); !slices.Equal(got, tt.want) {
+				t.Errorf("GetMessages(%d, %d) = %v, want %v", tt.limit, tt.offset, got, tt.want)
+			}
+		})
+	}
+
+	messages, err := provider.GetMessages(ctx, ta.channelID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages() error = %v", err)
+	}
+	call := messages[len(messages)-2]
+	if call.ChannelID != ta.channelID || call.Type != domain.MessageTypeCall || call.Call == nil || call.Call.DurationSeconds != 42 {
+		t.Errorf("archived call message = %+v, want the call kept", call)
+	}
+}
+
+func TestMessageProviderGetMessagesAfter(t *testing.T) {
+	ta := newTestArchive(t, 10)
+	ctx := context.Background()
+	if _, err := ta.archive.ArchiveMessagesBefore(ctx, ta.channelID, ta.hours(7), 100); err != nil {
+		t.Fatalf("ArchiveMessagesBefore() error = %v", err)
+	}
+	provider := ta.archive.MessageProvider()
+	ids := ta.messageIDs
+
+	tests := []struct {
+		name      string
+		afterTime time.Time
+		afterID   string
+		limit     int32
+		want      []string
+	}{
+		{"from the start", time.Time{}, "", 3, ids[:3]},
+		{"across the archive", ta.hours(5), ids[5], 4, ids[6:]},
+		{"everything", time.Time{}, "", 0, ids},
+		{"hot only", ta.hours(8), ids[8], 0, ids[9:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := provider.GetMessagesAfter(ctx, ta.channelID, tt.afterTime, tt.afterID, tt.limit)
+			if err != nil {
+				t.Fatalf("GetMessagesAfter() error = %v", err)
+			}
+			if got := messageIDs(messages); !slices.Equal(got, tt.want) {
+				t.Errorf("GetMessagesAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurgeBefore(t *testing.T) {
+	ta := newTestArchive(t, 10)
+	ctx := context.Background()
+	if _, err := ta.archive.ArchiveMessagesBefore(ctx, ta.channelID, ta.hours(24), 100); err != nil {
+		t.Fatalf("ArchiveMessagesBefore() error = %v", err)
+	}
+
+	purged, err := ta.archive.PurgeBefore(ctx, ta.channelID, ta.hours(5))
+	if err != nil {
+		t.Fatalf("PurgeBefore() error = %v", err)
+	}
+	if purged != 5 {
+		t.Errorf("purged %d messages, want 5", purged)
+	}
+
+	if _, err := ta.store.Get(ctx, segmentKey(ta.channelID, base)); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("emptied segment error = %v, want %v", err, ErrObjectNotFound)
+	}
+	channel, err := ta.storage.FindChannelByID(ctx, ta.channelID)
+	if err != nil {
+		t.Fatalf("FindChannelByID() error = %v", err)
+	}
+	if channel.MessageCount != 5 {
+		t.Errorf("message count = %d, want 5", channel.MessageCount)
+	}
+
+	messages, err := ta.archive.MessageProvider().GetMessages(ctx, ta.channelID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages() error = %v", err)
+	}
+	if want := reversed(ta.messageIDs[5:]); !slices.Equal(messageIDs(messages), want) {
+		t.Errorf("messages = %v, want %v", messageIDs(messages), want)
+	}
+
+	if purged, err := ta.archive.PurgeBefore(ctx, ta.channelID, ta.hours(5)); err != nil || purged != 0 {
+		t.Errorf("second PurgeBefore() = %d, %v, want nothing purged", purged, err)
+	}
+}
+
+func TestDeleteChannelsMessages(t *testing.T) {
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name        string
+		transaction bool
+		fail        bool
+		wantDeleted bool
+	}{
+		{"outside a transaction", false, false, true},
+		{"committed", true, false, true},
+		{"rolled back", true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := newTestArchive(t, 10)
+			ctx := context.Background()
+			if _, err := ta.archive.ArchiveMessagesBefore(ctx, ta.channelID, ta.hours(24), 100); err != nil {
+				t.Fatalf("ArchiveMessagesBefore() error = %v", err)
+			}
+			provider := ta.archive.MessageProvider()
+
+			deleteMessages := func(ctx context.Context) error {
+				if err := provider.DeleteChannelsMessages(ctx, []string{ta.channelID}); err != nil {
+					return err
+				}
+				if tt.fail {
+					return errRollback
+				}
+				return nil
+			}
+
+			var err error
+			if tt.transaction {
+				transactor := ta.archive.Transactor(ta.storage, slog.New(slog.NewTextHandler(io.Discard, nil)))
+				err = transactor.WithinTransaction(ctx, deleteMessages)
+			} else {
+				err = deleteMessages(ctx)
+			}
+			if tt.fail != errors.Is(err, errRollback) {
+				t.Fatalf("error = %v, want rollback %v", err, tt.fail)
+			}
+
+			for _, key := range []string{manifestKey(ta.channelID), segmentKey(ta.channelID, base), segmentKey(ta.channelID, ta.hours(8))} {
+				_, err := ta.store.Get(ctx, key)
+				if deleted := errors.Is(err, ErrObjectNotFound); deleted != tt.wantDeleted {
+					t.Errorf("Get(%s) error = %v, want deleted %v", key, err, tt.wantDeleted)
+				}
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "channels/a/manifest.json"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() of a missing key error = %v, want %v", err, ErrObjectNotFound)
+	}
+	if err := store.Delete(ctx, "channels/a/manifest.json"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v", err)
+	}
+
+	for _, data := range []string{"first", "second"} {
+		if err := store.Put(ctx, "channels/a/manifest.json", []byte(data)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		got, err := store.Get(ctx, "channels/a/manifest.json")
+		if err != nil || string(got) != data {
+			t.Errorf("Get() = %q, %v, want %q", got, err, data)
+		}
+	}
+
+	if err := store.Delete(ctx, "channels/a/manifest.json"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "channels/a/manifest.json"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrObjectNotFound)
+	}
+}
@@ -0,0 +1,293 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) SaveChannel(ctx context.Context, channel domain.Channel) (string, error) {
+	const op = "infrastructure.boltdb.channel.SaveChannel"
+
+	doc := domain.Channel{
+		ChatID:       channel.ChatID,
+		Name:         channel.Name,
+		Type:         channel.Type,
+		MessageCount: channel.MessageCount,
+		Topic:        channel.Topic,
+		Description:  channel.Description,
+		Position:     channel.Position,
+		Category:     channel.Category,
+		Private:      channel.Private,
+		MemberIDs:    channel.MemberIDs,
+		AllowedRoles: channel.AllowedRoles,
+		Mode:         channel.Mode,
+		PosterIDs:    channel.PosterIDs,
+	}
+
+	var channelID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		channelID, _, err = insert(tx.Bucket(channelsBucket), &doc, func(id string) { doc.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channelID, nil
+}
+
+func (b *BoltDB) FindChannelByID(ctx context.Context, channelID string) (domain.Channel, error) {
+	const op = "infrastructure.boltdb.channel.FindChannelByID"
+
+	key, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return domain.Channel{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var channel domain.Channel
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		channel, found, err = get[domain.Channel](tx.Bucket(channelsBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.Channel{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Channel{}, domain.ErrChannelNotFound
+	}
+
+	return channel, nil
+}
+
+func (b *BoltDB) FindChannelsByIDs(ctx context.Context, channelIDs []string) ([]domain.Channel, error) {
+	const op = "infrastructure.boltdb.channel.FindChannelsByIDs"
+
+	var channels []domain.Channel
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		channels, err = getMany[domain.Channel](tx.Bucket(channelsBucket), channelIDs, domain.ErrChannelNotFound)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+// FindChatChannels lists channels of the chat in key order, which is creation order
+func (b *BoltDB) FindChatChannels(ctx context.Context, chatID string) ([]domain.Channel, error) {
+	const op = "infrastructure.boltdb.channel.FindChatChannels"
+
+	var channels []domain.Channel
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		channels, err = filter(tx.Bucket(channelsBucket), func(channel domain.Channel) bool {
+			return channel.ChatID == chatID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+func (b *BoltDB) SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error {
+	const op = "infrastructure.boltdb.channel.SetChannelPermissions"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.PermissionOverrides = overrides
+	})
+}
+
+func (b *BoltDB) UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) error {
+	const op = "infrastructure.boltdb.channel.UpdateChannel"
+
+	if patch == (domain.ChannelPatch{}) {
+		return nil
+	}
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		if patch.Name != nil {
+			channel.Name = *patch.Name
+		}
+		if patch.Topic != nil {
+			channel.Topic = *patch.Topic
+		}
+		if patch.Description != nil {
+			channel.Description = *patch.Description
+		}
+		if patch.Position != nil {
+			channel.Position = *patch.Position
+		}
+		if patch.Category != nil {
+			channel.Category = *patch.Category
+		}
+		if patch.Archived != nil {
+			channel.Archived = *patch.Archived
+		}
+		if patch.Mode != nil {
+			channel.Mode = *patch.Mode
+		}
+		if patch.PosterIDs != nil {
+			channel.PosterIDs = *patch.PosterIDs
+		}
+	})
+}
+
+// DeleteChannel removes the channel and its id from the chat
+func (b *BoltDB) DeleteChannel(ctx context.Context, channelID string) error {
+	const op = "infrastructure.boltdb.channel.DeleteChannel"
+
+	key, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(channelsBucket)
+
+		found = bucket.Get(key) != nil
+		if !found {
+			return nil
+		}
+		return bucket.Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (b *BoltDB) DeleteChatChannels(ctx context.Context, chatID string) error {
+	const op = "infrastructure.boltdb.channel.DeleteChatChannels"
+
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		_, err := deleteWhere(tx.Bucket(channelsBucket), func(channel domain.Channel) bool {
+			return channel.ChatID == chatID
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (b *BoltDB) SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) error {
+	const op = "infrastructure.boltdb.channel.SetChannelAccess"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.Private = private
+		channel.AllowedRoles = allowedRoles
+	})
+}
+
+func (b *BoltDB) AddChannelMembers(ctx context.Context, channelID string, userIDs []string) error {
+	const op = "infrastructure.boltdb.channel.AddChannelMembers"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.MemberIDs = addToSet(channel.MemberIDs, userIDs...)
+	})
+}
+
+func (b *BoltDB) RemoveChannelMember(ctx context.Context, channelID string, userID string) error {
+	const op = "infrastructure.boltdb.channel.RemoveChannelMember"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.MemberIDs = pull(channel.MemberIDs, userID)
+	})
+}
+
+// SetChannelRetention sets the retention policy of the channel, nil removes it
+func (b *BoltDB) SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error {
+	const op = "infrastructure.boltdb.channel.SetChannelRetention"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.Retention = policy
+	})
+}
+
+func (b *BoltDB) FindChannelsPage(ctx context.Context, afterID string, limit int32) ([]domain.Channel, error) {
+	const op = "infrastructure.boltdb.channel.FindChannelsPage"
+
+	var start []byte
+	if afterID != "" {
+		afterKey, err := parseID(afterID, domain.ErrChannelNotFound)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		start = afterKey
+	}
+
+	var channels []domain.Channel
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(channelsBucket).Cursor()
+
+		key, data := cursor.First()
+		if start != nil {
+			key, data = cursor.Seek(start)
+			if key != nil && slices.Equal(key, start) {
+				key, data = cursor.Next()
+			}
+		}
+
+		for ; key != nil && (limit <= 0 || len(channels) < int(limit)); key, data = cursor.Next() {
+			channel, err := decode[domain.Channel](data)
+			if err != nil {
+				return err
+			}
+			channels = append(channels, channel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+func (b *BoltDB) SetChannelTags(ctx context.Context, channelID string, tags []string) error {
+	const op = "infrastructure.boltdb.channel.SetChannelTags"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.Tags = tags
+	})
+}
+
+func (b *BoltDB) modifyChannel(ctx context.Context, op string, channelID string, change func(channel *domain.Channel)) error {
+	key, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		found, err = modify(tx.Bucket(channelsBucket), key, change)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) FindChat(ctx context.Context, userIDs []string) (*domain.Chat, error) {
+	const op = "infrastructure.boltdb.chat.FindChat"
+
+	var found *domain.Chat
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		return scan(tx.Bucket(chatsBucket), func(_ []byte, chat domain.Chat) bool {
+			if hasAll(chat.MemberIDs, userIDs) {
+				found = &chat
+				return false
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return found, nil
+}
+
+func (b *BoltDB) FindChatByID(ctx context.Context, chatID string, userID string) (domain.Chat, error) {
+	const op = "infrastructure.boltdb.chat.FindChatByID"
+
+	key, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return domain.Chat{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var chat domain.Chat
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		chat, found, err = get[domain.Chat](tx.Bucket(chatsBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.Chat{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Chat{}, domain.ErrChatNotFound
+	}
+
+	if chat.Type == "private" {
+		chat.Name = peerOf(chat.MemberIDs, userID)
+	}
+
+	return chat, nil
+}
+
+// FindUserChats lists chats of the community when communityID is set, otherwise chats outside communities
+func (b *BoltDB) FindUserChats(ctx context.Context, userID string, chatType string, communityID string, includeArchived bool) ([]*domain.ChatPreview, error) {
+	const op = "infrastructure.boltdb.chat.FindUserChats"
+
+	var chats []domain.Chat
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		chats, err = filter(tx.Bucket(chatsBucket), func(chat domain.Chat) bool {
+			switch {
+			case !slices.Contains(chat.MemberIDs, userID), chat.Type != chatType, chat.CommunityID != communityID:
+				return false
+			case !includeArchived && (chat.Archived || slices.Contains(chat.HiddenFor, userID)):
+				return false
+			default:
+				return true
+			}
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	previews := make([]*domain.ChatPreview, 0, len(chats))
+	for _, chat := range chats {
+		chatName := chat.Name
+		var peerID string
+		if chatType == "private" {
+			peerID = peerOf(chat.MemberIDs, userID)
+			chatName = peerID
+		}
+
+		previews = append(previews, &domain.ChatPreview{
+			ID:          chat.ID,
+			Name:        chatName,
+			Archived:    chat.Archived,
+			CommunityID: chat.CommunityID,
+			PeerID:      peerID,
+		})
+	}
+
+	return previews, nil
+}
+
+func (b *BoltDB) SaveChat(ctx context.Context, chat domain.Chat) (string, error) {
+	const op = "infrastructure.boltdb.chat.SaveChat"
+
+	doc := domain.Chat{
+		Type:        chat.Type,
+		Name:        chat.Name,
+		Topic:       chat.Topic,
+		Description: chat.Description,
+		MemberIDs:   chat.MemberIDs,
+		Roles:       chat.Roles,
+		CommunityID: chat.CommunityID,
+	}
+
+	var chatID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		chatID, _, err = insert(tx.Bucket(chatsBucket), &doc, func(id string) { doc.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return chatID, nil
+}
+
+func (b *BoltDB) AddChatMembers(ctx context.Context, chatID string, userIDs []string) error {
+	const op = "infrastructure.boltdb.chat.AddChatMembers"
+
+	return b.modifyChat(ctx, op, chatID, func(chat *domain.Chat) {
+		chat.MemberIDs = addToSet(chat.MemberIDs, userIDs...)
+	})
+}
+
+func (b *BoltDB) RemoveChatMember(ctx context.Context, chatID string, userID string) error {
+	return b.WithinTransaction(ctx, func(ctx context.Context) error {
+		return b.removeChatMember(ctx, chatID, userID)
+	})
+}
+
+func (b *BoltDB) removeChatMember(ctx context.Context, chatID string, userID string) error {
+	const op = "infrastructure.boltdb.chat.RemoveChatMember"
+
+	err := b.modifyChat(ctx, op, chatID, func(chat *domain.Chat) {
+		chat.MemberIDs = pull(chat.MemberIDs, userID)
+		delete(chat.Roles, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// the member also loses access to private channels of the chat
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		return modifyWhere(tx.Bucket(channelsBucket), func(channel *domain.Channel) bool {
+			if channel.ChatID != chatID || !slices.Contains(channel.MemberIDs, userID) {
+				return false
+			}
+			channel.MemberIDs = pull(channel.MemberIDs, userID)
+			return true
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// SetChatRoles sets roles of the given members, RoleMember removes the entry
+func (b *BoltDB) SetChatRoles(ctx context.Context, chatID string, roles map[string]string) error {
+	const op = "infrastructure.boltdb.chat.SetChatRoles"
+
+	return b.modifyChat(ctx, op, chatID, func(chat *domain.Chat) {
+		chat.Roles = applyRoles(chat.Roles, roles)
+	})
+}
+
+func (b *BoltDB) UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) error {
+	const op = "infrastructure.boltdb.chat.UpdateChat"
+
+	if patch == (domain.ChatPatch{}) {
+		return nil
+	}
+
+	return b.modifyChat(ctx, op, chatID, func(chat *domain.Chat) {
+		if patch.Name != nil {
+			chat.Name = *patch.Name
+		}
+		if patch.Topic != nil {
+			chat.Topic = *patch.Topic
+		}
+		if patch.Description != nil {
+			chat.Description = *patch.Description
+		}
+		if patch.Archived != nil {
+			chat.Archived = *patch.Archived
+		}
+	})
+}
+
+// SetChatHidden hides or shows the chat in the user's chat list
+func (b *BoltDB) SetChatHidden(ctx context.Context, chatID string, userID string, hidden bool) error {
+	const op = "infrastructure.boltdb.chat.SetChatHidden"
+
+	return b.modifyChat(ctx, op, chatID, func(chat *domain.Chat) {
+		if hidden {
+			chat.HiddenFor = addToSet(chat.HiddenFor, userID)
+		} else {
+			chat.HiddenFor = pull(chat.HiddenFor, userID)
+		}
+	})
+}
+
+// UnhideChat shows the chat to every member again, used when a new message arrives
+func (b *BoltDB) UnhideChat(ctx context.Context, chatID string) error {
+	const op = "infrastructure.boltdb.chat.UnhideChat"
+
+	key, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	// most chats are not hidden, the write is skipped for them
+	var hidden bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		chat, _, err := get[domain.Chat](tx.Bucket(chatsBucket), key)
+		hidden = len(chat.HiddenFor) > 0
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !hidden {
+		return nil
+	}
+
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		_, err := modify(tx.Bucket(chatsBucket), key, func(chat *domain.Chat) {
+			chat.HiddenFor = nil
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (b *BoltDB) DeleteChat(ctx context.Context, chatID string) error {
+	const op = "infrastructure.boltdb.chat.DeleteChat"
+
+	key, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(chatsBucket)
+		if found = bucket.Get(key) != nil; !found {
+			return nil
+		}
+		return bucket.Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// SetChatRetention sets the retention policy of the chat, nil removes it
+func (b *BoltDB) SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error {
+	const op = "infrastructure.boltdb.chat.SetChatRetention"
+
+	return b.modifyChat(ctx, op, chatID, func(chat *domain.Chat) {
+		chat.Retention = policy
+	})
+}
+
+func (b *BoltDB) FindChatsByIDs(ctx context.Context, chatIDs []string) ([]domain.Chat, error) {
+	const op = "infrastructure.boltdb.chat.FindChatsByIDs"
+
+	var chats []domain.Chat
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		chats, err = getMany[domain.Chat](tx.Bucket(chatsBucket), chatIDs, domain.ErrChatNotFound)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return chats, nil
+}
+
+func (b *BoltDB) modifyChat(ctx context.Context, op string, chatID string, change func(chat *domain.Chat)) error {
+	key, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		found, err = modify(tx.Bucket(chatsBucket), key, change)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// applyRoles sets roles of the given members, RoleMember removes the entry
+func applyRoles(current map[string]string, roles map[string]string) map[string]string {
+	for userID, role := range roles {
+		if role == domain.RoleMember {
+			delete(current, userID)
+			continue
+		}
+		if current == nil {
+			current = make(map[string]string)
+		}
+		current[userID] = role
+	}
+	return current
+}
+
+func hasAll(set []string, values []string) bool {
+	for _, value := range values {
+		if !slices.Contains(set, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// peerOf returns the first member other than the user
+func peerOf(memberIDs []string, userID string) string {
+	for _, id := range memberIDs {
+		if id != userID {
+			return id
+		}
+	}
+	return ""
+}
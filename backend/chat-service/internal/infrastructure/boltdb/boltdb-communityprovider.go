@@ -0,0 +1,228 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) SaveCommunity(ctx context.Context, community domain.Community) (string, error) {
+	const op = "infrastructure.boltdb.community.SaveCommunity"
+
+	community.ID = ""
+
+	var communityID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		communityID, _, err = insert(tx.Bucket(communitiesBucket), &community, func(id string) { community.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return communityID, nil
+}
+
+func (b *BoltDB) FindCommunityByID(ctx context.Context, communityID string) (domain.Community, error) {
+	const op = "infrastructure.boltdb.community.FindCommunityByID"
+
+	key, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return domain.Community{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var community domain.Community
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		community, found, err = get[domain.Community](tx.Bucket(communitiesBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.Community{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Community{}, domain.ErrCommunityNotFound
+	}
+
+	return community, nil
+}
+
+func (b *BoltDB) FindUserCommunities(ctx context.Context, userID string) ([]*domain.Community, error) {
+	const op = "infrastructure.boltdb.community.FindUserCommunities"
+
+	var communities []*domain.Community
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		return scan(tx.Bucket(communitiesBucket), func(_ []byte, community domain.Community) bool {
+			if slices.Contains(community.MemberIDs, userID) {
+				communities = append(communities, &community)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return communities, nil
+}
+
+func (b *BoltDB) UpdateCommunity(ctx context.Context, communityID string, patch domain.CommunityPatch) error {
+	const op = "infrastructure.boltdb.community.UpdateCommunity"
+
+	if patch == (domain.CommunityPatch{}) {
+		return nil
+	}
+
+	return b.update(ctx, func(tx *bbolt.Tx) error {
+		return modifyCommunity(tx, op, communityID, func(community *domain.Community) {
+			if patch.Name != nil {
+				community.Name = *patch.Name
+			}
+			if patch.Description != nil {
+				community.Description = *patch.Description
+			}
+			if patch.DefaultChatID != nil {
+				community.DefaultChatID = *patch.DefaultChatID
+			}
+		})
+	})
+}
+
+func (b *BoltDB) AddCommunityChat(ctx context.Context, communityID string, chatID string) error {
+	const op = "infrastructure.boltdb.community.AddCommunityChat"
+
+	return b.update(ctx, func(tx *bbolt.Tx) error {
+		return modifyCommunity(tx, op, communityID, func(community *domain.Community) {
+			community.ChatIDs = addToSet(community.ChatIDs, chatID)
+		})
+	})
+}
+
+func (b *BoltDB) RemoveCommunityChat(ctx context.Context, communityID string, chatID string) error {
+	const op = "infrastructure.boltdb.community.RemoveCommunityChat"
+
+	return b.update(ctx, func(tx *bbolt.Tx) error {
+		return modifyCommunity(tx, op, communityID, func(community *domain.Community) {
+			community.ChatIDs = pull(community.ChatIDs, chatID)
+		})
+	})
+}
+
+// AddCommunityMembers adds the users to the community and to every community chat
+func (b *BoltDB) AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) error {
+	const op = "infrastructure.boltdb.community.AddCommunityMembers"
+
+	return b.update(ctx, func(tx *bbolt.Tx) error {
+		err := modifyCommunity(tx, op, communityID, func(community *domain.Community) {
+			community.MemberIDs = addToSet(community.MemberIDs, userIDs...)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = modifyWhere(tx.Bucket(chatsBucket), func(chat *domain.Chat) bool {
+			if chat.CommunityID != communityID {
+				return false
+			}
+			chat.MemberIDs = addToSet(chat.MemberIDs, userIDs...)
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+
+		return nil
+	})
+}
+
+// RemoveCommunityMember removes the user with their roles from the community,
+// every community chat and private channels of these chats
+func (b *BoltDB) RemoveCommunityMember(ctx context.Context, communityID string, userID string) error {
+	const op = "infrastructure.boltdb.community.RemoveCommunityMember"
+
+	return b.update(ctx, func(tx *bbolt.Tx) error {
+		var chatIDs []string
+		err := modifyCommunity(tx, op, communityID, func(community *domain.Community) {
+			community.MemberIDs = pull(community.MemberIDs, userID)
+			delete(community.Roles, userID)
+			chatIDs = community.ChatIDs
+		})
+		if err != nil {
+			return err
+		}
+
+		err = modifyWhere(tx.Bucket(chatsBucket), func(chat *domain.Chat) bool {
+			if chat.CommunityID != communityID {
+				return false
+			}
+			chat.MemberIDs = pull(chat.MemberIDs, userID)
+			delete(chat.Roles, userID)
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+
+		err = modifyWhere(tx.Bucket(channelsBucket), func(channel *domain.Channel) bool {
+			if !slices.Contains(chatIDs, channel.ChatID) || !slices.Contains(channel.MemberIDs, userID) {
+				return false
+			}
+			channel.MemberIDs = pull(channel.MemberIDs, userID)
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+
+		return nil
+	})
+}
+
+// SetCommunityRoles sets roles of the given members in the community and every community chat
+func (b *BoltDB) SetCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error {
+	const op = "infrastructure.boltdb.community.SetCommunityRoles"
+
+	return b.update(ctx, func(tx *bbolt.Tx) error {
+		err := modifyCommunity(tx, op, communityID, func(community *domain.Community) {
+			community.Roles = applyRoles(community.Roles, roles)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = modifyWhere(tx.Bucket(chatsBucket), func(chat *domain.Chat) bool {
+			if chat.CommunityID != communityID {
+				return false
+			}
+			chat.Roles = applyRoles(chat.Roles, roles)
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+
+		return nil
+	})
+}
+
+func modifyCommunity(tx *bbolt.Tx, op string, communityID string, change func(community *domain.Community)) error {
+	key, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	found, err := modify(tx.Bucket(communitiesBucket), key, change)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	return nil
+}
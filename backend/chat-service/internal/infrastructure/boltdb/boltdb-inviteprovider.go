@@ -0,0 +1,289 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) SaveInvite(ctx context.Context, invite domain.Invite) (string, error) {
+	const op = "infrastructure.boltdb.invite.SaveInvite"
+
+	invite.ID = ""
+
+	var inviteID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		inviteID, _, err = insert(tx.Bucket(invitesBucket), &invite, func(id string) { invite.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return inviteID, nil
+}
+
+func (b *BoltDB) FindInviteByID(ctx context.Context, inviteID string) (domain.Invite, error) {
+	const op = "infrastructure.boltdb.invite.FindInviteByID"
+
+	key, err := parseID(inviteID, domain.ErrInviteNotFound)
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var invite domain.Invite
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		invite, found, err = get[domain.Invite](tx.Bucket(invitesBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Invite{}, domain.ErrInviteNotFound
+	}
+
+	return invite, nil
+}
+
+func (b *BoltDB) FindInviteByCode(ctx context.Context, code string) (domain.Invite, error) {
+	const op = "infrastructure.boltdb.invite.FindInviteByCode"
+
+	var invite domain.Invite
+	var found bool
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		return scan(tx.Bucket(invitesBucket), func(_ []byte, doc domain.Invite) bool {
+			if doc.Code == code {
+				invite, found = doc, true
+				return false
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Invite{}, domain.ErrInviteNotFound
+	}
+
+	return invite, nil
+}
+
+func (b *BoltDB) FindChatInvites(ctx context.Context, chatID string) ([]*domain.Invite, error) {
+	const op = "infrastructure.boltdb.invite.FindChatInvites"
+
+	var invites []domain.Invite
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		invites, err = filter(tx.Bucket(invitesBucket), func(invite domain.Invite) bool {
+			return invite.ChatID == chatID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return newestFirst(invites, func(invite domain.Invite) time.Time { return invite.CreatedAt }), nil
+}
+
+// UseInvite increments uses of the invite only if it is still usable,
+// so concurrent joins cannot exceed max_uses
+func (b *BoltDB) UseInvite(ctx context.Context, inviteID string, now time.Time) error {
+	const op = "infrastructure.boltdb.invite.UseInvite"
+
+	key, err := parseID(inviteID, domain.ErrInviteNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	// writes are serialized, so the check and the increment cannot interleave with another join
+	var usable bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		_, err := modify(tx.Bucket(invitesBucket), key, func(invite *domain.Invite) {
+			if usable = invite.Usable(now); usable {
+				invite.Uses++
+			}
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !usable {
+		return domain.ErrInvalidInvite
+	}
+
+	return nil
+}
+
+func (b *BoltDB) RevokeInvite(ctx context.Context, inviteID string) error {
+	const op = "infrastructure.boltdb.invite.RevokeInvite"
+
+	key, err := parseID(inviteID, domain.ErrInviteNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		found, err = modify(tx.Bucket(invitesBucket), key, func(invite *domain.Invite) {
+			invite.Revoked = true
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+// DeleteChatInvites removes invites and join records of the chat
+func (b *BoltDB) DeleteChatInvites(ctx context.Context, chatID string) error {
+	const op = "infrastructure.boltdb.invite.DeleteChatInvites"
+
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		if _, err := deleteWhere(tx.Bucket(invitesBucket), func(invite domain.Invite) bool { return invite.ChatID == chatID }); err != nil {
+			return err
+		}
+		_, err := deleteWhere(tx.Bucket(joinsBucket), func(record domain.JoinRecord) bool { return record.ChatID == chatID })
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (b *BoltDB) SaveJoinRecord(ctx context.Context, record domain.JoinRecord) (string, error) {
+	const op = "infrastructure.boltdb.invite.SaveJoinRecord"
+
+	record.ID = ""
+
+	var recordID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		recordID, _, err = insert(tx.Bucket(joinsBucket), &record, func(id string) { record.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return recordID, nil
+}
+
+func (b *BoltDB) FindJoinRecordByID(ctx context.Context, recordID string) (domain.JoinRecord, error) {
+	const op = "infrastructure.boltdb.invite.FindJoinRecordByID"
+
+	key, err := parseID(recordID, domain.ErrJoinReqNotFound)
+	if err != nil {
+		return domain.JoinRecord{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var record domain.JoinRecord
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		record, found, err = get[domain.JoinRecord](tx.Bucket(joinsBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.JoinRecord{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.JoinRecord{}, domain.ErrJoinReqNotFound
+	}
+
+	return record, nil
+}
+
+func (b *BoltDB) FindJoinRecords(ctx context.Context, chatID string, status string) ([]*domain.JoinRecord, error) {
+	const op = "infrastructure.boltdb.invite.FindJoinRecords"
+
+	var records []domain.JoinRecord
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		records, err = filter(tx.Bucket(joinsBucket), func(record domain.JoinRecord) bool {
+			return record.ChatID == chatID && (status == "" || record.Status == status)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return newestFirst(records, func(record domain.JoinRecord) time.Time { return record.CreatedAt }), nil
+}
+
+func (b *BoltDB) FindPendingJoinRecord(ctx context.Context, chatID string, userID string) (*domain.JoinRecord, error) {
+	const op = "infrastructure.boltdb.invite.FindPendingJoinRecord"
+
+	var found *domain.JoinRecord
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		return scan(tx.Bucket(joinsBucket), func(_ []byte, record domain.JoinRecord) bool {
+			if record.ChatID == chatID && record.UserID == userID && record.Status == domain.JoinStatusPending {
+				found = &record
+				return false
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return found, nil
+}
+
+func (b *BoltDB) SetJoinRecordStatus(ctx context.Context, recordID string, status string, reviewerID string) error {
+	const op = "infrastructure.boltdb.invite.SetJoinRecordStatus"
+
+	key, err := parseID(recordID, domain.ErrJoinReqNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		found, err = modify(tx.Bucket(joinsBucket), key, func(record *domain.JoinRecord) {
+			record.Status = status
+			record.ReviewerID = reviewerID
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrJoinReqNotFound
+	}
+
+	return nil
+}
+
+// newestFirst sorts docs by createdAt descending, docs created at once keep the newest id first
+func newestFirst[T any](docs []T, createdAt func(doc T) time.Time) []*T {
+	slices.Reverse(docs)
+	slices.SortStableFunc(docs, func(a, b T) int {
+		return createdAt(b).Compare(createdAt(a))
+	})
+
+	sorted := make([]*T, 0, len(docs))
+	for i := range docs {
+		sorted = append(sorted, &docs[i])
+	}
+	return sorted
+}
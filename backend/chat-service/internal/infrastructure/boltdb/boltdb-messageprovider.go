@@ -0,0 +1,414 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+// Messages of a channel are kept in a nested bucket of messagesBucket named by the channel id,
+// replies of a forum post are copied to a nested bucket of postMessagesBucket named by the post id.
+// Both are keyed by timeKey, so they are read in chronological order
+
+func (b *BoltDB) SaveMessage(ctx context.Context, message domain.Message) (string, error) {
+	const op = "infrastructure.boltdb.message.SaveMessage"
+
+	channelKey, err := parseID(message.ChannelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	doc := domain.Message{
+		ChannelID: message.ChannelID,
+		SenderID:  message.SenderID,
+		Text:      message.Text,
+		CreatedAt: message.CreatedAt,
+		Type:      message.Type,
+		PostID:    message.PostID,
+		Call:      message.Call,
+		Webhook:   message.Webhook,
+	}
+
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		if err := insertMessage(tx, &doc); err != nil {
+			return err
+		}
+
+		_, err := modify(tx.Bucket(channelsBucket), channelKey, func(channel *domain.Channel) {
+			lastMessage := domain.NewLastMessage(doc)
+			channel.MessageCount++
+			channel.LastMessage = &lastMessage
+		})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return doc.ID, nil
+}
+
+// saveMessagesBatch limits the number of messages inserted in one transaction by SaveMessages
+const saveMessagesBatch = 1000
+
+func (b *BoltDB) SaveMessages(ctx context.Context, channelID string, messages []domain.Message) ([]string, error) {
+	const op = "infrastructure.boltdb.message.SaveMessages"
+
+	channelKey, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	messageIDs := make([]string, 0, len(messages))
+	for start := 0; start < len(messages); start += saveMessagesBatch {
+		end := min(start+saveMessagesBatch, len(messages))
+
+		// every batch is inserted together with its message_count increment
+		err := b.update(ctx, func(tx *bbolt.Tx) error {
+			var last *domain.Message
+			for _, message := range messages[start:end] {
+				doc := domain.Message{
+					ChannelID: channelID,
+					SenderID:  message.SenderID,
+					Text:      message.Text,
+					CreatedAt: message.CreatedAt,
+					Type:      message.Type,
+				}
+				if err := insertMessage(tx, &doc); err != nil {
+					return err
+				}
+				messageIDs = append(messageIDs, doc.ID)
+
+				if last == nil || doc.CreatedAt.After(last.CreatedAt) {
+					last = &doc
+				}
+			}
+
+			_, err := modify(tx.Bucket(channelsBucket), channelKey, func(channel *domain.Channel) {
+				channel.MessageCount += int64(end - start)
+
+				// imported messages may be older than the ones already in the channel
+				if channel.LastMessage == nil || channel.LastMessage.CreatedAt.Before(last.CreatedAt) {
+					lastMessage := domain.NewLastMessage(*last)
+					channel.LastMessage = &lastMessage
+				}
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return messageIDs, nil
+}
+
+// insertMessage sets the id of the message and stores it in the channel and the post buckets
+func insertMessage(tx *bbolt.Tx, message *domain.Message) error {
+	root := tx.Bucket(messagesBucket)
+	seq, err := root.NextSequence()
+	if err != nil {
+		return err
+	}
+	idKey := itob(seq)
+	message.ID = formatID(idKey)
+	key := timeKey(message.CreatedAt, idKey)
+
+	channelMessages, err := root.CreateBucketIfNotExists([]byte(message.ChannelID))
+	if err != nil {
+		return err
+	}
+	if err := put(channelMessages, key, message); err != nil {
+		return err
+	}
+
+	if message.PostID == "" {
+		return nil
+	}
+	postMessages, err := tx.Bucket(postMessagesBucket).CreateBucketIfNotExists([]byte(message.PostID))
+	if err != nil {
+		return err
+	}
+	return put(postMessages, key, message)
+}
+
+func (b *BoltDB) GetMessages(ctx context.Context, channelID string, limit int32, offset int32) ([]*domain.Message, error) {
+	const op = "infrastructure.boltdb.message.GetMessages"
+
+	var messages []*domain.Message
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		channelMessages := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if channelMessages == nil {
+			return nil
+		}
+
+		// offsets are counted from 1 like in the Mongo storage
+		skip := max(offset-1, 0)
+		cursor := channelMessages.Cursor()
+		for key, data := cursor.Last(); key != nil && (limit <= 0 || len(messages) < int(limit)); key, data = cursor.Prev() {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			message, err := decode[domain.Message](data)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, &message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// DeleteMessagesBefore deletes the oldest messages first, so a purge interrupted between
+// batches leaves the channel without gaps in its history
+func (b *BoltDB) DeleteMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.boltdb.message.DeleteMessagesBefore"
+
+	channelKey, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var messageIDs []string
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		channelMessages := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if channelMessages == nil {
+			return nil
+		}
+
+		messageIDs, err = deleteOldestMessages(tx, channelMessages, before, limit)
+		if err != nil || len(messageIDs) == 0 {
+			return err
+		}
+
+		var lastMessage *domain.LastMessage
+		if _, data := channelMessages.Cursor().Last(); data != nil {
+			message, err := decode[domain.Message](data)
+			if err != nil {
+				return err
+			}
+			last := domain.NewLastMessage(message)
+			lastMessage = &last
+		}
+
+		_, err := modify(tx.Bucket(channelsBucket), channelKey, func(channel *domain.Channel) {
+			channel.MessageCount -= int64(len(messageIDs))
+			channel.LastMessage = lastMessage
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messageIDs, nil
+}
+
+// DeleteArchivedMessages deletes the oldest messages copied to the archive,
+// the message count and the last message of the channel stay as they are
+func (b *BoltDB) DeleteArchivedMessages(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.boltdb.message.DeleteArchivedMessages"
+
+	var messageIDs []string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		channelMessages := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if channelMessages == nil {
+			return nil
+		}
+
+		var err error
+		messageIDs, err = deleteOldestMessages(tx, channelMessages, before, limit)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messageIDs, nil
+}
+
+// DecreaseMessageCount subtracts messages deleted from the archive from the message count of the channel
+func (b *BoltDB) DecreaseMessageCount(ctx context.Context, channelID string, count int64) error {
+	const op = "infrastructure.boltdb.message.DecreaseMessageCount"
+
+	return b.modifyChannel(ctx, op, channelID, func(channel *domain.Channel) {
+		channel.MessageCount -= count
+	})
+}
+
+// deleteOldestMessages deletes up to limit oldest messages of the channel created before the time
+func deleteOldestMessages(tx *bbolt.Tx, channelMessages *bbolt.Bucket, before time.Time, limit int32) ([]string, error) {
+	end := timeKey(before, nil)[:8]
+	var keys [][]byte
+	var deleted []domain.Message
+	cursor := channelMessages.Cursor()
+	for key, data := cursor.First(); key != nil && bytes.Compare(key[:8], end) < 0 && len(keys) < int(limit); key, data = cursor.Next() {
+		message, err := decode[domain.Message](data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, slices.Clone(key))
+		deleted = append(deleted, message)
+	}
+
+	messageIDs := make([]string, 0, len(keys))
+	for i, key := range keys {
+		if err := deleteMessage(tx, channelMessages, key, deleted[i]); err != nil {
+			return nil, err
+		}
+		messageIDs = append(messageIDs, deleted[i].ID)
+	}
+
+	return messageIDs, nil
+}
+
+// deleteMessage removes the message stored under key from the channel and the post buckets
+func deleteMessage(tx *bbolt.Tx, channelMessages *bbolt.Bucket, key []byte, message domain.Message) error {
+	if err := channelMessages.Delete(key); err != nil {
+		return err
+	}
+	if message.PostID == "" {
+		return nil
+	}
+	if postMessages := tx.Bucket(postMessagesBucket).Bucket([]byte(message.PostID)); postMessages != nil {
+		return postMessages.Delete(key)
+	}
+	return nil
+}
+
+func (b *BoltDB) DeleteChannelsMessages(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.boltdb.message.DeleteChannelsMessages"
+
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		root := tx.Bucket(messagesBucket)
+		postMessages := tx.Bucket(postMessagesBucket)
+
+		for _, channelID := range channelIDs {
+			channelMessages := root.Bucket([]byte(channelID))
+			if channelMessages == nil {
+				continue
+			}
+
+			// replies are removed with their posts, the channel bucket goes away as a whole
+			postIDs := make(map[string]struct{})
+			err := scan(channelMessages, func(_ []byte, message domain.Message) bool {
+				if message.PostID != "" {
+					postIDs[message.PostID] = struct{}{}
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			for postID := range postIDs {
+				if postMessages.Bucket([]byte(postID)) == nil {
+					continue
+				}
+				if err := postMessages.DeleteBucket([]byte(postID)); err != nil {
+					return err
+				}
+			}
+
+			if err := root.DeleteBucket([]byte(channelID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// GetPostMessages returns replies of a forum post in chronological order
+func (b *BoltDB) GetPostMessages(ctx context.Context, postID string, limit int32, offset int32) ([]*domain.Message, error) {
+	const op = "infrastructure.boltdb.message.GetPostMessages"
+
+	var messages []*domain.Message
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		postMessages := tx.Bucket(postMessagesBucket).Bucket([]byte(postID))
+		if postMessages == nil {
+			return nil
+		}
+
+		skip := max(offset, 0)
+		cursor := postMessages.Cursor()
+		for key, data := cursor.First(); key != nil && (limit <= 0 || len(messages) < int(limit)); key, data = cursor.Next() {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			message, err := decode[domain.Message](data)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, &message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// GetMessagesAfter returns channel messages in chronological order starting after the message with
+// afterTime and afterID, zero afterTime starts from the beginning. Messages with equal time are ordered by id
+func (b *BoltDB) GetMessagesAfter(ctx context.Context, channelID string, afterTime time.Time, afterID string, limit int32) ([]*domain.Message, error) {
+	const op = "infrastructure.boltdb.message.GetMessagesAfter"
+
+	var after []byte
+	if !afterTime.IsZero() {
+		afterKey, err := parseID(afterID, domain.ErrMsgNotFound)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		after = timeKey(afterTime, afterKey)
+	}
+
+	var messages []*domain.Message
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		channelMessages := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if channelMessages == nil {
+			return nil
+		}
+
+		cursor := channelMessages.Cursor()
+		key, data := cursor.First()
+		if after != nil {
+			key, data = cursor.Seek(after)
+			if key != nil && bytes.Equal(key, after) {
+				key, data = cursor.Next()
+			}
+		}
+
+		for ; key != nil && (limit <= 0 || len(messages) < int(limit)); key, data = cursor.Next() {
+			message, err := decode[domain.Message](data)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, &message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,143 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) SavePost(ctx context.Context, post domain.Post) (string, error) {
+	const op = "infrastructure.boltdb.post.SavePost"
+
+	post.ID = ""
+
+	var postID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		postID, _, err = insert(tx.Bucket(postsBucket), &post, func(id string) { post.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return postID, nil
+}
+
+func (b *BoltDB) FindPostByID(ctx context.Context, postID string) (domain.Post, error) {
+	const op = "infrastructure.boltdb.post.FindPostByID"
+
+	key, err := parseID(postID, domain.ErrPostNotFound)
+	if err != nil {
+		return domain.Post{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var post domain.Post
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		post, found, err = get[domain.Post](tx.Bucket(postsBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.Post{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Post{}, domain.ErrPostNotFound
+	}
+
+	return post, nil
+}
+
+// FindChannelPosts returns posts of the channel sorted by latest activity, an empty tag matches every post
+func (b *BoltDB) FindChannelPosts(ctx context.Context, channelID string, tag string, limit int32, offset int32) ([]*domain.Post, error) {
+	const op = "infrastructure.boltdb.post.FindChannelPosts"
+
+	var posts []domain.Post
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		posts, err = filter(tx.Bucket(postsBucket), func(post domain.Post) bool {
+			return post.ChannelID == channelID && (tag == "" || slices.Contains(post.Tags, tag))
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	// posts are scanned in id order, so the stable sort keeps newer posts first among equal activity
+	slices.Reverse(posts)
+	slices.SortStableFunc(posts, func(a, b domain.Post) int {
+		return b.LastActivityAt.Compare(a.LastActivityAt)
+	})
+
+	var result []*domain.Post
+	for _, post := range page(posts, limit, offset) {
+		result = append(result, &post)
+	}
+
+	return result, nil
+}
+
+// TouchPost counts a new reply and moves the post up in the activity order
+func (b *BoltDB) TouchPost(ctx context.Context, postID string, activityAt time.Time) error {
+	const op = "infrastructure.boltdb.post.TouchPost"
+
+	key, err := parseID(postID, domain.ErrPostNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		found, err = modify(tx.Bucket(postsBucket), key, func(post *domain.Post) {
+			post.ReplyCount++
+			if activityAt.After(post.LastActivityAt) {
+				post.LastActivityAt = activityAt
+			}
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrPostNotFound
+	}
+
+	return nil
+}
+
+func (b *BoltDB) DeleteChannelsPosts(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.boltdb.post.DeleteChannelsPosts"
+
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		keys, err := deleteWhere(tx.Bucket(postsBucket), func(post domain.Post) bool {
+			return slices.Contains(channelIDs, post.ChannelID)
+		})
+		if err != nil {
+			return err
+		}
+
+		postMessages := tx.Bucket(postMessagesBucket)
+		for _, key := range keys {
+			name := []byte(formatID(key))
+			if postMessages.Bucket(name) == nil {
+				continue
+			}
+			if err := postMessages.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+// Updates of a user are kept in a nested bucket of updatesBucket named by the user id,
+// the sequence of the bucket is the seq of the user and keys are seqs
+
+// SaveUpdates appends the update to the logs of the users in one write transaction and returns their seqs
+func (b *BoltDB) SaveUpdates(ctx context.Context, update domain.Update, userIDs []string) (map[string]int64, error) {
+	const op = "infrastructure.boltdb.update.SaveUpdates"
+
+	doc := domain.Update{
+		Type:      update.Type,
+		ChatID:    update.ChatID,
+		ChannelID: update.ChannelID,
+		MessageID: update.MessageID,
+		Message:   update.Message,
+		UserIDs:   update.UserIDs,
+		Chat:      update.Chat,
+		Channel:   update.Channel,
+		Post:      update.Post,
+		Call:      update.Call,
+		CreatedAt: update.CreatedAt,
+
+		PurgedBefore: update.PurgedBefore,
+	}
+
+	seqs := make(map[string]int64, len(userIDs))
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		for _, userID := range userIDs {
+			if _, saved := seqs[userID]; saved {
+				continue
+			}
+
+			userUpdates, err := tx.Bucket(updatesBucket).CreateBucketIfNotExists([]byte(userID))
+			if err != nil {
+				return err
+			}
+
+			seq, err := userUpdates.NextSequence()
+			if err != nil {
+				return err
+			}
+			doc.UserID = userID
+			doc.Seq = int64(seq)
+
+			if err := put(userUpdates, itob(seq), &doc); err != nil {
+				return err
+			}
+			seqs[userID] = doc.Seq
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return seqs, nil
+}
+
+func (b *BoltDB) GetUpdates(ctx context.Context, userID string, sinceSeq int64, limit int32) ([]*domain.Update, error) {
+	const op = "infrastructure.boltdb.update.GetUpdates"
+
+	var updates []*domain.Update
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		userUpdates := tx.Bucket(updatesBucket).Bucket([]byte(userID))
+		if userUpdates == nil {
+			return nil
+		}
+
+		cursor := userUpdates.Cursor()
+		for key, data := cursor.Seek(itob(uint64(max(sinceSeq, 0)) + 1)); key != nil && (limit <= 0 || len(updates) < int(limit)); key, data = cursor.Next() {
+			update, err := decode[domain.Update](data)
+			if err != nil {
+				return err
+			}
+			updates = append(updates, &update)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return updates, nil
+}
+
+func (b *BoltDB) GetUpdatesState(ctx context.Context, userID string) (int64, error) {
+	const op = "infrastructure.boltdb.update.GetUpdatesState"
+
+	var seq uint64
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		if userUpdates := tx.Bucket(updatesBucket).Bucket([]byte(userID)); userUpdates != nil {
+			seq = userUpdates.Sequence()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return int64(seq), nil
+}
+
+// DeleteUpdatesBefore walks updates of every user from the oldest one, updates are saved
+// in creation order so the walk stops at the first update that is kept
+func (b *BoltDB) DeleteUpdatesBefore(ctx context.Context, before time.Time) (int64, error) {
+	const op = "infrastructure.boltdb.update.DeleteUpdatesBefore"
+
+	var deleted int64
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		return tx.Bucket(updatesBucket).ForEachBucket(func(userID []byte) error {
+			cursor := tx.Bucket(updatesBucket).Bucket(userID).Cursor()
+			for key, data := cursor.First(); key != nil; key, data = cursor.First() {
+				update, err := decode[domain.Update](data)
+				if err != nil {
+					return err
+				}
+				if !update.CreatedAt.Before(before) {
+					return nil
+				}
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+				deleted++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return deleted, nil
+}
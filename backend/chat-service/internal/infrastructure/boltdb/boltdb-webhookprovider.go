@@ -0,0 +1,113 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) SaveWebhook(ctx context.Context, webhook domain.Webhook) (string, error) {
+	const op = "infrastructure.boltdb.webhook.SaveWebhook"
+
+	webhook.ID = ""
+
+	var webhookID string
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		webhookID, _, err = insert(tx.Bucket(webhooksBucket), &webhook, func(id string) { webhook.ID = id })
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return webhookID, nil
+}
+
+func (b *BoltDB) FindWebhookByID(ctx context.Context, webhookID string) (domain.Webhook, error) {
+	const op = "infrastructure.boltdb.webhook.FindWebhookByID"
+
+	key, err := parseID(webhookID, domain.ErrWebhookNotFound)
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var webhook domain.Webhook
+	var found bool
+	err = b.view(ctx, func(tx *bbolt.Tx) error {
+		webhook, found, err = get[domain.Webhook](tx.Bucket(webhooksBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.Webhook{}, domain.ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func (b *BoltDB) FindChannelWebhooks(ctx context.Context, channelID string) ([]*domain.Webhook, error) {
+	const op = "infrastructure.boltdb.webhook.FindChannelWebhooks"
+
+	var webhooks []domain.Webhook
+	err := b.view(ctx, func(tx *bbolt.Tx) error {
+		var err error
+		webhooks, err = filter(tx.Bucket(webhooksBucket), func(webhook domain.Webhook) bool {
+			return webhook.ChannelID == channelID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return newestFirst(webhooks, func(webhook domain.Webhook) time.Time { return webhook.CreatedAt }), nil
+}
+
+func (b *BoltDB) RevokeWebhook(ctx context.Context, webhookID string) error {
+	const op = "infrastructure.boltdb.webhook.RevokeWebhook"
+
+	key, err := parseID(webhookID, domain.ErrWebhookNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	var found bool
+	err = b.update(ctx, func(tx *bbolt.Tx) error {
+		found, err = modify(tx.Bucket(webhooksBucket), key, func(webhook *domain.Webhook) {
+			webhook.Revoked = true
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (b *BoltDB) DeleteChannelsWebhooks(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.boltdb.webhook.DeleteChannelsWebhooks"
+
+	err := b.update(ctx, func(tx *bbolt.Tx) error {
+		_, err := deleteWhere(tx.Bucket(webhooksBucket), func(webhook domain.Webhook) bool {
+			return slices.Contains(channelIDs, webhook.ChannelID)
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
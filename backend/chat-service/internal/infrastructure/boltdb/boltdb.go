@@ -0,0 +1,330 @@
+package boltdb
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MemoryPath opens a database that lives only as long as the process
+const MemoryPath = ":memory:"
+
+var (
+	chatsBucket        = []byte("chats")
+	channelsBucket     = []byte("channels")
+	messagesBucket     = []byte("messages")
+	postMessagesBucket = []byte("post_messages")
+	postsBucket        = []byte("posts")
+	updatesBucket      = []byte("updates")
+	invitesBucket      = []byte("invites")
+	joinsBucket        = []byte("joins")
+	communitiesBucket  = []byte("communities")
+	webhooksBucket     = []byte("webhooks")
+)
+
+// BoltDB keeps every document of the service in a single bbolt file, documents are encoded
+// the same way as in MongoDB. Messages are kept in a bucket per channel ordered by time,
+// other lookups scan their bucket, which is fine for the small deployments it is meant for.
+// IDs are sequence numbers passed around as decimal strings
+type BoltDB struct {
+	db *bbolt.DB
+	// temporary is set for MemoryPath, the file is removed on close
+	temporary bool
+}
+
+// New opens the database file at storagePath, MemoryPath opens a database in a temporary file
+// without fsync that is removed on close, so nothing outlives the process
+func New(storagePath string) *BoltDB {
+	options := &bbolt.Options{Timeout: time.Second}
+
+	temporary := storagePath == MemoryPath
+	if temporary {
+		file, err := os.CreateTemp("", "chat-service-*.db")
+		if err != nil {
+			panic(err)
+		}
+		file.Close()
+
+		storagePath = file.Name()
+		options.NoSync = true
+		options.NoFreelistSync = true
+	}
+
+	db, err := bbolt.Open(storagePath, 0o600, options)
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{
+			chatsBucket, channelsBucket, messagesBucket, postMessagesBucket, postsBucket,
+			updatesBucket, invitesBucket, joinsBucket, communitiesBucket, webhooksBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return &BoltDB{db: db, temporary: temporary}
+}
+
+func (b *BoltDB) Close() error {
+	path := b.db.Path()
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+	if b.temporary {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+type txKey struct{}
+
+// WithinTransaction runs fn in a read-write transaction. Calls made with a ctx that already
+// carries a transaction join it. bbolt allows one writer at a time, so fn blocks other writes
+func (b *BoltDB) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*bbolt.Tx); ok {
+		return fn(ctx)
+	}
+
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// view runs fn in the transaction of ctx or in a new read-only one
+func (b *BoltDB) view(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*bbolt.Tx); ok {
+		return fn(tx)
+	}
+	return b.db.View(fn)
+}
+
+// update runs fn in the transaction of ctx or in a new read-write one
+func (b *BoltDB) update(ctx context.Context, fn func(tx *bbolt.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*bbolt.Tx); ok {
+		return fn(tx)
+	}
+	return b.db.Update(fn)
+}
+
+func itob(n uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, n)
+	return key
+}
+
+func formatID(key []byte) string {
+	return strconv.FormatUint(binary.BigEndian.Uint64(key), 10)
+}
+
+// parseID converts an id to its key, malformed ids are reported as notFound
+func parseID(id string, notFound error) ([]byte, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, notFound
+	}
+	return itob(n), nil
+}
+
+// timeKey orders documents by time and then by id, times are truncated to milliseconds
+// like they are in stored documents
+func timeKey(t time.Time, id []byte) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixMilli())^(1<<63))
+	copy(key[8:], id)
+	return key
+}
+
+// insert stores doc under the next id of the bucket, setID puts the id into the doc before it is encoded
+func insert[T any](bucket *bbolt.Bucket, doc *T, setID func(id string)) (string, []byte, error) {
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return "", nil, err
+	}
+	key := itob(seq)
+	id := formatID(key)
+	setID(id)
+
+	if err := put(bucket, key, doc); err != nil {
+		return "", nil, err
+	}
+	return id, key, nil
+}
+
+func put(bucket *bbolt.Bucket, key []byte, doc any) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// get decodes the document stored under key, found is false if there is none
+func get[T any](bucket *bbolt.Bucket, key []byte) (doc T, found bool, err error) {
+	data := bucket.Get(key)
+	if data == nil {
+		return doc, false, nil
+	}
+	doc, err = decode[T](data)
+	return doc, err == nil, err
+}
+
+func decode[T any](data []byte) (doc T, err error) {
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// modify decodes the document stored under key, applies change and stores it back,
+// found is false if there is none
+func modify[T any](bucket *bbolt.Bucket, key []byte, change func(doc *T)) (found bool, err error) {
+	doc, found, err := get[T](bucket, key)
+	if err != nil || !found {
+		return found, err
+	}
+	change(&doc)
+	return true, put(bucket, key, &doc)
+}
+
+// getMany decodes documents stored under the ids skipping missing ones,
+// malformed ids are reported as notFound
+func getMany[T any](bucket *bbolt.Bucket, ids []string, notFound error) ([]T, error) {
+	docs := make([]T, 0, len(ids))
+	for _, id := range ids {
+		key, err := parseID(id, notFound)
+		if err != nil {
+			return nil, err
+		}
+		doc, found, err := get[T](bucket, key)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// modifyWhere applies change to every document of the bucket and stores back
+// the ones it reports as changed
+func modifyWhere[T any](bucket *bbolt.Bucket, change func(doc *T) bool) error {
+	var keys [][]byte
+	var docs []T
+	err := scan(bucket, func(key []byte, doc T) bool {
+		if change(&doc) {
+			keys = append(keys, slices.Clone(key))
+			docs = append(docs, doc)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	// the bucket is written after the scan, a cursor is invalidated by writes
+	for i, key := range keys {
+		if err := put(bucket, key, &docs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteWhere deletes every document of the bucket matching match and returns their keys
+func deleteWhere[T any](bucket *bbolt.Bucket, match func(doc T) bool) ([][]byte, error) {
+	var keys [][]byte
+	err := scan(bucket, func(key []byte, doc T) bool {
+		if match(doc) {
+			keys = append(keys, slices.Clone(key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if err := bucket.Delete(key); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// scan decodes every document of the bucket in key order until fn returns false
+func scan[T any](bucket *bbolt.Bucket, fn func(key []byte, doc T) bool) error {
+	cursor := bucket.Cursor()
+	for key, data := cursor.First(); key != nil; key, data = cursor.Next() {
+		if data == nil {
+			continue
+		}
+		doc, err := decode[T](data)
+		if err != nil {
+			return err
+		}
+		if !fn(key, doc) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// filter returns documents of the bucket matching keep in key order
+func filter[T any](bucket *bbolt.Bucket, keep func(doc T) bool) ([]T, error) {
+	var docs []T
+	err := scan(bucket, func(_ []byte, doc T) bool {
+		if keep(doc) {
+			docs = append(docs, doc)
+		}
+		return true
+	})
+	return docs, err
+}
+
+// page applies offset and limit to docs, negative values are treated as zero
+func page[T any](docs []T, limit int32, offset int32) []T {
+	offset = max(offset, 0)
+	if int(offset) >= len(docs) {
+		return nil
+	}
+	docs = docs[offset:]
+	if limit > 0 && int(limit) < len(docs) {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+func addToSet(set []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(set, value) {
+			set = append(set, value)
+		}
+	}
+	return set
+}
+
+func pull(set []string, value string) []string {
+	kept := set[:0]
+	for _, item := range set {
+		if item != value {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
@@ -0,0 +1,15 @@
+package boltdb
+
+import (
+	"testing"
+
+	"chat-service/internal/infrastructure/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		b := New(MemoryPath)
+		t.Cleanup(func() { b.Close() })
+		return b
+	})
+}
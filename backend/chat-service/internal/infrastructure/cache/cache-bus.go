@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/utils"
+)
+
+// busRetryInterval is the pause before listening again after the bus disconnected
+const busRetryInterval = 5 * time.Second
+
+const originSize = 16
+
+// Bus delivers messages to every replica of the service, the sender included
+type Bus interface {
+	Publish(ctx context.Context, message []byte) error
+	// Listen calls ready once it listens and then handle with every published message,
+	// until ctx is done or the connection fails
+	Listen(ctx context.Context, ready func(), handle func(message []byte)) error
+}
+
+// busMessage is an invalidation on the bus, origin tells replicas apart so they skip their own
+type busMessage struct {
+	Origin string `json:"origin"`
+	Invalidation
+}
+
+type busBroadcaster struct {
+	bus    Bus
+	origin string
+}
+
+func (b busBroadcaster) Broadcast(ctx context.Context, invalidation Invalidation) error {
+	message, err := json.Marshal(busMessage{Origin: b.origin, Invalidation: invalidation})
+	if err != nil {
+		return err
+	}
+	return b.bus.Publish(ctx, message)
+}
+
+// Share broadcasts invalidations of the cache over the bus and applies invalidations of other
+// replicas until ctx is done. Invalidations sent while the bus is disconnected are lost, so the
+// cache is bypassed until Share listens again and starts over with no entries
+func (c *Cache) Share(ctx context.Context, bus Bus) {
+	origin, err := utils.RandomCode(originSize)
+	if err != nil {
+		c.log.Error("failed to generate cache origin, invalidations are not shared", logger.Err(err))
+		return
+	}
+
+	c.setOffline(true)
+	c.SetBroadcaster(busBroadcaster{bus: bus, origin: origin})
+	defer c.SetBroadcaster(nil)
+
+	for {
+		err := bus.Listen(ctx, func() {
+			c.setOffline(false)
+			c.log.Info("listening to cache invalidations of other replicas")
+		}, func(message []byte) {
+			c.receive(origin, message)
+		})
+		c.setOffline(true)
+
+		if ctx.Err() != nil {
+			return
+		}
+		c.log.Warn("cache invalidation bus disconnected, cache bypassed until it reconnects", logger.Err(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(busRetryInterval):
+		}
+	}
+}
+
+func (c *Cache) receive(origin string, message []byte) {
+	var received busMessage
+	if err := json.Unmarshal(message, &received); err != nil {
+		c.log.Warn("failed to decode cache invalidation", logger.Err(err))
+		return
+	}
+	if received.Origin == origin || received.Invalidation.empty() {
+		return
+	}
+
+	c.Apply(received.Invalidation)
+}
+
+// setOffline switches the cache off or back on, either way it starts over with no entries
+func (c *Cache) setOffline(offline bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.offline = offline
+	// the maps are cleared in place, lookups take them before the lock
+	clear(c.chats)
+	clear(c.channels)
+	clear(c.chatChannels)
+	clear(c.communityChats)
+}
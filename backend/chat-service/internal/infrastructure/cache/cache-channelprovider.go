@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+)
+
+// ChannelProvider reads channels by id through the cache and drops channels it changes.
+// Message counters and the last message of a cached channel may be behind by the TTL
+type ChannelProvider struct {
+	interfaces.ChannelProvider
+	cache *Cache
+}
+
+func (c *Cache) ChannelProvider(channels interfaces.ChannelProvider) *ChannelProvider {
+	return &ChannelProvider{ChannelProvider: channels, cache: c}
+}
+
+func (p *ChannelProvider) FindChannelByID(ctx context.Context, channelID string) (domain.Channel, error) {
+	if pendingFrom(ctx) != nil {
+		return p.ChannelProvider.FindChannelByID(ctx, channelID)
+	}
+
+	var channel domain.Channel
+	hit, version := p.cache.lookup(p.cache.channels, channelID, &channel)
+	if hit {
+		p.cache.channelHits.Add(1)
+		return channel, nil
+	}
+	p.cache.channelMisses.Add(1)
+
+	channel, err := p.ChannelProvider.FindChannelByID(ctx, channelID)
+	if err != nil {
+		return domain.Channel{}, err
+	}
+	p.cache.storeChannel(channel, version)
+
+	return channel, nil
+}
+
+func (p *ChannelProvider) SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.SetChannelPermissions(ctx, channelID, overrides))
+}
+
+func (p *ChannelProvider) UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.UpdateChannel(ctx, channelID, patch))
+}
+
+func (p *ChannelProvider) DeleteChannel(ctx context.Context, channelID string) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.DeleteChannel(ctx, channelID))
+}
+
+// DeleteChatChannels drops the chat together with its cached channels
+func (p *ChannelProvider) DeleteChatChannels(ctx context.Context, chatID string) error {
+	err := p.ChannelProvider.DeleteChatChannels(ctx, chatID)
+	p.cache.invalidate(ctx, Invalidation{ChatIDs: []string{chatID}})
+	return err
+}
+
+func (p *ChannelProvider) SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.SetChannelAccess(ctx, channelID, private, allowedRoles))
+}
+
+func (p *ChannelProvider) AddChannelMembers(ctx context.Context, channelID string, userIDs []string) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.AddChannelMembers(ctx, channelID, userIDs))
+}
+
+func (p *ChannelProvider) RemoveChannelMember(ctx context.Context, channelID string, userID string) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.RemoveChannelMember(ctx, channelID, userID))
+}
+
+func (p *ChannelProvider) SetChannelTags(ctx context.Context, channelID string, tags []string) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.SetChannelTags(ctx, channelID, tags))
+}
+
+func (p *ChannelProvider) SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error {
+	return p.changeChannel(ctx, channelID, p.ChannelProvider.SetChannelRetention(ctx, channelID, policy))
+}
+
+// changeChannel drops the channel after the write, a failed write may still have changed it
+func (p *ChannelProvider) changeChannel(ctx context.Context, channelID string, err error) error {
+	p.cache.invalidate(ctx, Invalidation{ChannelIDs: []string{channelID}})
+	return err
+}
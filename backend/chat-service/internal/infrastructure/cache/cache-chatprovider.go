@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+)
+
+// ChatProvider reads chats by id through the cache and drops chats it changes
+type ChatProvider struct {
+	interfaces.ChatProvider
+	cache *Cache
+}
+
+func (c *Cache) ChatProvider(chats interfaces.ChatProvider) *ChatProvider {
+	return &ChatProvider{ChatProvider: chats, cache: c}
+}
+
+func (p *ChatProvider) FindChatByID(ctx context.Context, chatID string, userID string) (domain.Chat, error) {
+	if pendingFrom(ctx) != nil {
+		return p.ChatProvider.FindChatByID(ctx, chatID, userID)
+	}
+
+	var chat domain.Chat
+	hit, version := p.cache.lookup(p.cache.chats, chatID, &chat)
+	if hit {
+		p.cache.chatHits.Add(1)
+		return withPeerName(chat, userID), nil
+	}
+	p.cache.chatMisses.Add(1)
+
+	chat, err := p.ChatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return domain.Chat{}, err
+	}
+	p.cache.storeChat(chat, version)
+
+	return chat, nil
+}
+
+// withPeerName names a private chat after the other member like the storage does
+func withPeerName(chat domain.Chat, userID string) domain.Chat {
+	if chat.Type != "private" {
+		return chat
+	}
+
+	chat.Name = ""
+	for _, id := range chat.MemberIDs {
+		if id != userID {
+			chat.Name = id
+			break
+		}
+	}
+	return chat
+}
+
+func (p *ChatProvider) AddChatMembers(ctx context.Context, chatID string, userIDs []string) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.AddChatMembers(ctx, chatID, userIDs))
+}
+
+func (p *ChatProvider) RemoveChatMember(ctx context.Context, chatID string, userID string) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.RemoveChatMember(ctx, chatID, userID))
+}
+
+func (p *ChatProvider) SetChatRoles(ctx context.Context, chatID string, roles map[string]string) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.SetChatRoles(ctx, chatID, roles))
+}
+
+func (p *ChatProvider) UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.UpdateChat(ctx, chatID, patch))
+}
+
+func (p *ChatProvider) SetChatHidden(ctx context.Context, chatID string, userID string, hidden bool) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.SetChatHidden(ctx, chatID, userID, hidden))
+}
+
+func (p *ChatProvider) UnhideChat(ctx context.Context, chatID string) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.UnhideChat(ctx, chatID))
+}
+
+func (p *ChatProvider) DeleteChat(ctx context.Context, chatID string) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.DeleteChat(ctx, chatID))
+}
+
+func (p *ChatProvider) SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error {
+	return p.changeChat(ctx, chatID, p.ChatProvider.SetChatRetention(ctx, chatID, policy))
+}
+
+// changeChat drops the chat after the write, a failed write may still have changed it
+func (p *ChatProvider) changeChat(ctx context.Context, chatID string, err error) error {
+	p.cache.invalidate(ctx, Invalidation{ChatIDs: []string{chatID}})
+	return err
+}
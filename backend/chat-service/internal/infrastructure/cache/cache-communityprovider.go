@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+
+	"chat-service/internal/domain/interfaces"
+)
+
+// CommunityProvider drops cached chats of a community when the community changes members or roles
+// of its chats
+type CommunityProvider struct {
+	interfaces.CommunityProvider
+	cache *Cache
+}
+
+func (c *Cache) CommunityProvider(communities interfaces.CommunityProvider) *CommunityProvider {
+	return &CommunityProvider{CommunityProvider: communities, cache: c}
+}
+
+func (p *CommunityProvider) AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) error {
+	return p.changeCommunity(ctx, communityID, p.CommunityProvider.AddCommunityMembers(ctx, communityID, userIDs))
+}
+
+func (p *CommunityProvider) RemoveCommunityMember(ctx context.Context, communityID string, userID string) error {
+	return p.changeCommunity(ctx, communityID, p.CommunityProvider.RemoveCommunityMember(ctx, communityID, userID))
+}
+
+func (p *CommunityProvider) SetCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error {
+	return p.changeCommunity(ctx, communityID, p.CommunityProvider.SetCommunityRoles(ctx, communityID, roles))
+}
+
+func (p *CommunityProvider) changeCommunity(ctx context.Context, communityID string, err error) error {
+	p.cache.invalidate(ctx, Invalidation{CommunityIDs: []string{communityID}})
+	return err
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"chat-service/internal/domain/interfaces"
+)
+
+type pendingKey struct{}
+
+// pendingInvalidation collects invalidations made inside a transaction
+type pendingInvalidation struct {
+	mu           sync.Mutex
+	invalidation Invalidation
+}
+
+func (p *pendingInvalidation) add(invalidation Invalidation) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.invalidation.ChatIDs = append(p.invalidation.ChatIDs, invalidation.ChatIDs...)
+	p.invalidation.ChannelIDs = append(p.invalidation.ChannelIDs, invalidation.ChannelIDs...)
+	p.invalidation.CommunityIDs = append(p.invalidation.CommunityIDs, invalidation.CommunityIDs...)
+}
+
+func pendingFrom(ctx context.Context) *pendingInvalidation {
+	pending, _ := ctx.Value(pendingKey{}).(*pendingInvalidation)
+	return pending
+}
+
+// Transactor repeats invalidations made inside a transaction when it ends. Reads inside
+// a transaction bypass the cache, so uncommitted documents are never cached
+type Transactor struct {
+	interfaces.Transactor
+	cache *Cache
+}
+
+func (c *Cache) Transactor(transactor interfaces.Transactor) *Transactor {
+	return &Transactor{Transactor: transactor, cache: c}
+}
+
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if pendingFrom(ctx) != nil {
+		return t.Transactor.WithinTransaction(ctx, fn)
+	}
+
+	pending := &pendingInvalidation{}
+	err := t.Transactor.WithinTransaction(context.WithValue(ctx, pendingKey{}, pending), fn)
+
+	// a rolled back transaction changed nothing, dropping the entries once more is harmless
+	if !pending.invalidation.empty() {
+		t.cache.drop(pending.invalidation)
+		if err == nil {
+			t.cache.broadcast(ctx, pending.invalidation)
+		}
+	}
+
+	return err
+}
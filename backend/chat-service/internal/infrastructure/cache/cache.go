@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Invalidation lists cache entries changed by a write. Dropping a chat drops cached channels
+// of the chat, dropping a community drops every cached chat of the community
+type Invalidation struct {
+	ChatIDs      []string `json:"chat_ids,omitempty"`
+	ChannelIDs   []string `json:"channel_ids,omitempty"`
+	CommunityIDs []string `json:"community_ids,omitempty"`
+}
+
+func (i Invalidation) empty() bool {
+	return len(i.ChatIDs) == 0 && len(i.ChannelIDs) == 0 && len(i.CommunityIDs) == 0
+}
+
+// Broadcaster delivers invalidations to other replicas of the service, they pass them to Cache.Apply.
+// Broadcast is called once the write is committed
+type Broadcaster interface {
+	Broadcast(ctx context.Context, invalidation Invalidation) error
+}
+
+// Stats counts lookups of the cache since the service started
+type Stats struct {
+	ChatHits      uint64 `json:"chat_hits"`
+	ChatMisses    uint64 `json:"chat_misses"`
+	ChannelHits   uint64 `json:"channel_hits"`
+	ChannelMisses uint64 `json:"channel_misses"`
+	Invalidations uint64 `json:"invalidations"`
+	Entries       int    `json:"entries"`
+	// Offline is set while invalidations of other replicas may be missed and the cache is bypassed
+	Offline bool `json:"offline"`
+}
+
+type entry struct {
+	// data is the bson document, so callers never share slices and maps of a cached chat or channel
+	data      []byte
+	expiresAt time.Time
+}
+
+// Cache keeps chats and channels read when checking access to a channel, so the check does not go
+// to the storage on every message. Writes made through the wrappers of the cache drop the entries
+// they change, writes inside a transaction drop them again when the transaction ends, so a read of
+// the old state racing with the transaction does not stay cached. Replicas share invalidations
+// through Share. Entries expire after the TTL, which bounds staleness of changes the wrappers
+// don't see: message counters of channels and invalidations that failed to reach other replicas
+type Cache struct {
+	log        *slog.Logger
+	ttl        time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	chats    map[string]entry
+	channels map[string]entry
+	// chatChannels and communityChats index cached channels by chat_id and cached chats by community_id
+	chatChannels   map[string]map[string]struct{}
+	communityChats map[string]map[string]struct{}
+	// version grows with every invalidation, documents loaded before it changed are not stored
+	version     uint64
+	broadcaster Broadcaster
+	// offline bypasses the cache while Share is not listening to other replicas
+	offline bool
+
+	chatHits      atomic.Uint64
+	chatMisses    atomic.Uint64
+	channelHits   atomic.Uint64
+	channelMisses atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+func New(log *slog.Logger, ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		log:        log,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+
+		chats:          make(map[string]entry),
+		channels:       make(map[string]entry),
+		chatChannels:   make(map[string]map[string]struct{}),
+		communityChats: make(map[string]map[string]struct{}),
+	}
+}
+
+// SetBroadcaster sets the hook sharing invalidations with other replicas, nil keeps them local
+func (c *Cache) SetBroadcaster(broadcaster Broadcaster) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.broadcaster = broadcaster
+}
+
+// Apply drops entries changed by another replica without broadcasting the invalidation again
+func (c *Cache) Apply(invalidation Invalidation) {
+	c.drop(invalidation)
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := len(c.chats) + len(c.channels)
+	offline := c.offline
+	c.mu.Unlock()
+
+	return Stats{
+		ChatHits:      c.chatHits.Load(),
+		ChatMisses:    c.chatMisses.Load(),
+		ChannelHits:   c.channelHits.Load(),
+		ChannelMisses: c.channelMisses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+		Offline:       offline,
+	}
+}
+
+// Var exposes Stats as an expvar, so they are served with the other metrics of the service
+func (c *Cache) Var() expvar.Var {
+	return expvar.Func(func() any { return c.Stats() })
+}
+
+// invalidate drops the entries now. Inside a transaction the invalidation is repeated and broadcast
+// when the transaction ends, otherwise it is broadcast right away
+func (c *Cache) invalidate(ctx context.Context, invalidation Invalidation) {
+	if invalidation.empty() {
+		return
+	}
+
+	c.drop(invalidation)
+
+	if pending := pendingFrom(ctx); pending != nil {
+		pending.add(invalidation)
+		return
+	}
+	c.broadcast(ctx, invalidation)
+}
+
+func (c *Cache) broadcast(ctx context.Context, invalidation Invalidation) {
+	c.mu.Lock()
+	broadcaster := c.broadcaster
+	c.mu.Unlock()
+
+	if broadcaster == nil {
+		return
+	}
+	if err := broadcaster.Broadcast(ctx, invalidation); err != nil {
+		c.log.Warn("failed to broadcast cache invalidation", logger.Err(err))
+	}
+}
+
+func (c *Cache) drop(invalidation Invalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.invalidations.Add(1)
+
+	for _, communityID := range invalidation.CommunityIDs {
+		for chatID := range c.communityChats[communityID] {
+			c.dropChat(chatID)
+		}
+	}
+	for _, chatID := range invalidation.ChatIDs {
+		c.dropChat(chatID)
+	}
+	for _, channelID := range invalidation.ChannelIDs {
+		c.dropChannel(channelID)
+	}
+}
+
+// dropChat removes the chat with its channels, the caller holds the lock
+func (c *Cache) dropChat(chatID string) {
+	if cached, ok := c.chats[chatID]; ok {
+		var chat domain.Chat
+		if bson.Unmarshal(cached.data, &chat) == nil && chat.CommunityID != "" {
+			unindex(c.communityChats, chat.CommunityID, chatID)
+		}
+		delete(c.chats, chatID)
+	}
+
+	for channelID := range c.chatChannels[chatID] {
+		delete(c.channels, channelID)
+	}
+	delete(c.chatChannels, chatID)
+}
+
+// dropChannel removes the channel, the caller holds the lock
+func (c *Cache) dropChannel(channelID string) {
+	cached, ok := c.channels[channelID]
+	if !ok {
+		return
+	}
+
+	var channel domain.Channel
+	if bson.Unmarshal(cached.data, &channel) == nil {
+		unindex(c.chatChannels, channel.ChatID, channelID)
+	}
+	delete(c.channels, channelID)
+}
+
+// lookup decodes the cached document into doc. After a miss the returned version is passed to store
+func (c *Cache) lookup(entries map[string]entry, id string, doc any) (bool, uint64) {
+	c.mu.Lock()
+	cached, ok := entries[id]
+	version := c.version
+	offline := c.offline
+	c.mu.Unlock()
+
+	if offline || !ok || !time.Now().Before(cached.expiresAt) {
+		return false, version
+	}
+	if err := bson.Unmarshal(cached.data, doc); err != nil {
+		return false, version
+	}
+
+	return true, version
+}
+
+func (c *Cache) storeChat(chat domain.Chat, version uint64) {
+	c.store(chat, version, func(data []byte, expiresAt time.Time) {
+		c.chats[chat.ID] = entry{data: data, expiresAt: expiresAt}
+		if chat.CommunityID != "" {
+			index(c.communityChats, chat.CommunityID, chat.ID)
+		}
+	})
+}
+
+func (c *Cache) storeChannel(channel domain.Channel, version uint64) {
+	c.store(channel, version, func(data []byte, expiresAt time.Time) {
+		c.channels[channel.ID] = entry{data: data, expiresAt: expiresAt}
+		index(c.chatChannels, channel.ChatID, channel.ID)
+	})
+}
+
+// store saves the document unless an invalidation happened after it was loaded
+func (c *Cache) store(doc any, version uint64, save func(data []byte, expiresAt time.Time)) {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version != version || c.offline {
+		return
+	}
+	now := time.Now()
+	if len(c.chats)+len(c.channels) >= c.maxEntries {
+		c.evict(now)
+	}
+	save(data, now.Add(c.ttl))
+}
+
+// evict drops expired entries and, if the cache is still full, a half of it, the caller holds the lock
+func (c *Cache) evict(now time.Time) {
+	for chatID, cached := range c.chats {
+		if !now.Before(cached.expiresAt) {
+			c.dropChat(chatID)
+		}
+	}
+	for channelID, cached := range c.channels {
+		if !now.Before(cached.expiresAt) {
+			c.dropChannel(channelID)
+		}
+	}
+
+	// map iteration order is random, so this drops arbitrary entries
+	for chatID := range c.chats {
+		if len(c.chats)+len(c.channels) < c.maxEntries/2 {
+			break
+		}
+		c.dropChat(chatID)
+	}
+	for channelID := range c.channels {
+		if len(c.chats)+len(c.channels) < c.maxEntries/2 {
+			break
+		}
+		c.dropChannel(channelID)
+	}
+}
+
+func index(idx map[string]map[string]struct{}, key string, id string) {
+	ids, ok := idx[key]
+	if !ok {
+		ids = make(map[string]struct{})
+		idx[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func unindex(idx map[string]map[string]struct{}, key string, id string) {
+	delete(idx[key], id)
+	if len(idx[key]) == 0 {
+		delete(idx, key)
+	}
+}
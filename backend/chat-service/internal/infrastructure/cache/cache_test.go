@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/boltdb"
+)
+
+// memoryBus delivers published messages to listeners of the process, like a database shared by replicas
+type memoryBus struct {
+	mu        sync.Mutex
+	listeners map[int]func(message []byte)
+	next      int
+}
+
+func (b *memoryBus) Publish(ctx context.Context, message []byte) error {
+	b.mu.Lock()
+	listeners := make([]func([]byte), 0, len(b.listeners))
+	for _, handle := range b.listeners {
+		listeners = append(listeners, handle)
+	}
+	b.mu.Unlock()
+
+	for _, handle := range listeners {
+		handle(message)
+	}
+	return nil
+}
+
+func (b *memoryBus) Listen(ctx context.Context, ready func(), handle func(message []byte)) error {
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.listeners[id] = handle
+	b.mu.Unlock()
+
+	ready()
+	<-ctx.Done()
+
+	b.mu.Lock()
+	delete(b.listeners, id)
+	b.mu.Unlock()
+	return nil
+}
+
+func testCache() *Cache {
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), time.Minute, 100)
+}
+
+func newTestStorage(t *testing.T) (*boltdb.BoltDB, string) {
+	t.Helper()
+
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+
+	chatID, err := storage.SaveChat(context.Background(), domain.Chat{Type: "group", Name: "team", MemberIDs: []string{"alice"}})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+	return storage, chatID
+}
+
+// waitListeners waits until count caches listen to the bus
+func (b *memoryBus) waitListeners(t *testing.T, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		b.mu.Lock()
+		listening := len(b.listeners)
+		b.mu.Unlock()
+		if listening == count {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d caches listen to the bus, want %d", listening, count)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShareDropsEntriesChangedByOtherReplica(t *testing.T) {
+	storage, chatID := newTestStorage(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	bus := &memoryBus{listeners: make(map[int]func([]byte))}
+	writer, reader := testCache(), testCache()
+	go writer.Share(ctx, bus)
+	go reader.Share(ctx, bus)
+	bus.waitListeners(t, 2)
+
+	readerChats := reader.ChatProvider(storage)
+	for range 2 {
+		if _, err := readerChats.FindChatByID(ctx, chatID, "alice"); err != nil {
+			t.Fatalf("FindChatByID() error = %v", err)
+		}
+	}
+	if stats := reader.Stats(); stats.ChatHits != 1 || stats.ChatMisses != 1 {
+		t.Fatalf("hits = %d, misses = %d, want 1 and 1", stats.ChatHits, stats.ChatMisses)
+	}
+
+	name := "renamed"
+	if err := writer.ChatProvider(storage).UpdateChat(ctx, chatID, domain.ChatPatch{Name: &name}); err != nil {
+		t.Fatalf("UpdateChat() error = %v", err)
+	}
+
+	chat, err := readerChats.FindChatByID(ctx, chatID, "alice")
+	if err != nil {
+		t.Fatalf("FindChatByID() error = %v", err)
+	}
+	if chat.Name != name {
+		t.Errorf("chat name on the other replica = %q, want %q", chat.Name, name)
+	}
+	if invalidations := writer.Stats().Invalidations; invalidations != 1 {
+		t.Errorf("writer applied %d invalidations, want only its own", invalidations)
+	}
+}
+
+func TestCacheBypassedWhileOffline(t *testing.T) {
+	storage, chatID := newTestStorage(t)
+	c := testCache()
+	c.setOffline(true)
+
+	chats := c.ChatProvider(storage)
+	for range 2 {
+		if _, err := chats.FindChatByID(context.Background(), chatID, "alice"); err != nil {
+			t.Fatalf("FindChatByID() error = %v", err)
+		}
+	}
+
+	if stats := c.Stats(); stats.ChatHits != 0 || stats.Entries != 0 {
+		t.Errorf("hits = %d, entries = %d while offline, want none", stats.ChatHits, stats.Entries)
+	}
+}
+
+func TestVarServesStats(t *testing.T) {
+	storage, chatID := newTestStorage(t)
+	c := testCache()
+
+	if _, err := c.ChatProvider(storage).FindChatByID(context.Background(), chatID, "alice"); err != nil {
+		t.Fatalf("FindChatByID() error = %v", err)
+	}
+
+	var stats map[string]any
+	if err := json.Unmarshal([]byte(c.Var().String()), &stats); err != nil {
+		t.Fatalf("Var() is not json: %v", err)
+	}
+	if stats["chat_misses"] != float64(1) || stats["entries"] != float64(1) {
+		t.Errorf("Var() = %v, want chat_misses and entries of 1", stats)
+	}
+}
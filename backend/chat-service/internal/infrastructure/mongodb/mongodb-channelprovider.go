@@ -10,12 +10,27 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (m *MongoDB) SaveChannel(ctx context.Context, channel domain.Channel) (string, error) {
 	const op = "infrastructure.mongodb.channel.SaveChannel"
 
-	res, err := m.channelsCol.InsertOne(ctx, bson.M{"chat_id": channel.ChatID, "name": channel.Name, "type": channel.Type, "message_ids": channel.MessageIDs})
+	res, err := m.channelsCol.InsertOne(ctx, bson.M{
+		"chat_id":       channel.ChatID,
+		"name":          channel.Name,
+		"type":          channel.Type,
+		"message_count": channel.MessageCount,
+		"topic":         channel.Topic,
+		"description":   channel.Description,
+		"position":      channel.Position,
+		"category":      channel.Category,
+		"private":       channel.Private,
+		"member_ids":    channel.MemberIDs,
+		"allowed_roles": channel.AllowedRoles,
+		"mode":          channel.Mode,
+		"poster_ids":    channel.PosterIDs,
+	})
 	if err != nil {
 		return "", fmt.Errorf("%s : %w", op, err)
 	}
@@ -24,22 +39,8 @@ func (m *MongoDB) SaveChannel(ctx context.Context, channel domain.Channel) (stri
 	if !ok {
 		return "", fmt.Errorf("%s : %w", op, err)
 	}
-	chanID := objectID.Hex()
 
-	update := bson.M{
-		"$push": bson.M{"channel_ids": chanID},
-	}
-
-	objChatID, err := primitive.ObjectIDFromHex(channel.ChatID)
-	if err != nil {
-		return "", fmt.Errorf("%s : %w", op, err)
-	}
-
-	if _, err = m.chatsCol.UpdateOne(ctx, bson.M{"_id": objChatID}, update); err != nil {
-		return "", fmt.Errorf("%s : %w", op, err)
-	}
-
-	return chanID, nil
+	return objectID.Hex(), nil
 }
 
 func (m *MongoDB) FindChannelByID(ctx context.Context, channelID string) (domain.Channel, error) {
@@ -54,7 +55,7 @@ func (m *MongoDB) FindChannelByID(ctx context.Context, channelID string) (domain
 
 	if err = m.channelsCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&channel); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return domain.Channel{}, nil
+			return domain.Channel{}, domain.ErrChannelNotFound
 		}
 		return domain.Channel{}, fmt.Errorf("%s : %w", op, err)
 	}
@@ -87,3 +88,257 @@ func (m *MongoDB) FindChannelsByIDs(ctx context.Context, channelIDs []string) ([
 
 	return channels, nil
 }
+
+// FindChatChannels lists channels of the chat in creation order
+func (m *MongoDB) FindChatChannels(ctx context.Context, chatID string) ([]domain.Channel, error) {
+	const op = "infrastructure.mongodb.channel.FindChatChannels"
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := m.channelsCol.Find(ctx, bson.M{"chat_id": chatID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var channels []domain.Channel
+	if err = cursor.All(ctx, &channels); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+func (m *MongoDB) SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error {
+	const op = "infrastructure.mongodb.channel.SetChannelPermissions"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"permission_overrides": overrides}})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) error {
+	const op = "infrastructure.mongodb.channel.UpdateChannel"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	set := bson.M{}
+	if patch.Name != nil {
+		set["name"] = *patch.Name
+	}
+	if patch.Topic != nil {
+		set["topic"] = *patch.Topic
+	}
+	if patch.Description != nil {
+		set["description"] = *patch.Description
+	}
+	if patch.Position != nil {
+		set["position"] = *patch.Position
+	}
+	if patch.Category != nil {
+		set["category"] = *patch.Category
+	}
+	if patch.Archived != nil {
+		set["archived"] = *patch.Archived
+	}
+	if patch.Mode != nil {
+		set["mode"] = *patch.Mode
+	}
+	if patch.PosterIDs != nil {
+		set["poster_ids"] = *patch.PosterIDs
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) DeleteChannel(ctx context.Context, channelID string) error {
+	const op = "infrastructure.mongodb.channel.DeleteChannel"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	res, err := m.channelsCol.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) DeleteChatChannels(ctx context.Context, chatID string) error {
+	const op = "infrastructure.mongodb.channel.DeleteChatChannels"
+
+	if _, err := m.channelsCol.DeleteMany(ctx, bson.M{"chat_id": chatID}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *MongoDB) SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) error {
+	const op = "infrastructure.mongodb.channel.SetChannelAccess"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{"private": private, "allowed_roles": allowedRoles},
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) AddChannelMembers(ctx context.Context, channelID string, userIDs []string) error {
+	const op = "infrastructure.mongodb.channel.AddChannelMembers"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{"member_ids": bson.M{"$each": userIDs}},
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) RemoveChannelMember(ctx context.Context, channelID string, userID string) error {
+	const op = "infrastructure.mongodb.channel.RemoveChannelMember"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$pull": bson.M{"member_ids": userID}})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+// SetChannelRetention sets the retention policy of the channel, nil removes it
+func (m *MongoDB) SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error {
+	const op = "infrastructure.mongodb.channel.SetChannelRetention"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{"$unset": bson.M{"retention": ""}}
+	if policy != nil {
+		update = bson.M{"$set": bson.M{"retention": policy}}
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) FindChannelsPage(ctx context.Context, afterID string, limit int32) ([]domain.Channel, error) {
+	const op = "infrastructure.mongodb.channel.FindChannelsPage"
+
+	filter := bson.M{}
+	if afterID != "" {
+		objID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		filter["_id"] = bson.M{"$gt": objID}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.channelsCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var channels []domain.Channel
+	if err = cursor.All(ctx, &channels); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+func (m *MongoDB) SetChannelTags(ctx context.Context, channelID string, tags []string) error {
+	const op = "infrastructure.mongodb.channel.SetChannelTags"
+
+	objID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	res, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"tags": tags}})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
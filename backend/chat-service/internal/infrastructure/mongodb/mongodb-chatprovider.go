@@ -65,12 +65,21 @@ func (m *MongoDB) FindChatByID(ctx context.Context, chatID string, userID string
 	return chat, nil
 }
 
-func (m *MongoDB) FindUserChats(ctx context.Context, userID string, chatType string) ([]*domain.ChatPreview, error) {
+// FindUserChats lists chats of the community when communityID is set, otherwise chats outside communities
+func (m *MongoDB) FindUserChats(ctx context.Context, userID string, chatType string, communityID string, includeArchived bool) ([]*domain.ChatPreview, error) {
 	const op = "infrastructure.mongodb.chat.FindUserChats"
 
 	filter := bson.M{
-		"member_ids": bson.M{"$all": []string{userID}},
-		"type":       chatType,
+		"member_ids":   bson.M{"$all": []string{userID}},
+		"type":         chatType,
+		"community_id": bson.M{"$exists": false},
+	}
+	if communityID != "" {
+		filter["community_id"] = communityID
+	}
+	if !includeArchived {
+		filter["archived"] = bson.M{"$ne": true}
+		filter["hidden_for"] = bson.M{"$ne": userID}
 	}
 
 	cursor, err := m.chatsCol.Find(ctx, filter)
@@ -82,9 +91,11 @@ func (m *MongoDB) FindUserChats(ctx context.Context, userID string, chatType str
 	var previews []*domain.ChatPreview
 	for cursor.Next(ctx) {
 		var chat struct {
-			ID        primitive.ObjectID `bson:"_id"`
-			Name      string             `bson:"name"`
-			MemberIDs []string           `bson:"member_ids"`
+			ID          primitive.ObjectID `bson:"_id"`
+			Name        string             `bson:"name"`
+			MemberIDs   []string           `bson:"member_ids"`
+			Archived    bool               `bson:"archived"`
+			CommunityID string             `bson:"community_id"`
 		}
 
 		if err := cursor.Decode(&chat); err != nil {
@@ -92,20 +103,23 @@ func (m *MongoDB) FindUserChats(ctx context.Context, userID string, chatType str
 		}
 
 		chatName := chat.Name
+		var peerID string
 		if chatType == "private" {
-			var notUserID string
 			for _, id := range chat.MemberIDs {
 				if id != userID {
-					notUserID = id
+					peerID = id
 					break
 				}
 			}
-			chatName = notUserID
+			chatName = peerID
 		}
 
 		previews = append(previews, &domain.ChatPreview{
-			ID:   chat.ID.Hex(),
-			Name: chatName,
+			ID:          chat.ID.Hex(),
+			Name:        chatName,
+			Archived:    chat.Archived,
+			CommunityID: chat.CommunityID,
+			PeerID:      peerID,
 		})
 	}
 	if err := cursor.Err(); err != nil {
@@ -118,7 +132,19 @@ func (m *MongoDB) FindUserChats(ctx context.Context, userID string, chatType str
 func (m *MongoDB) SaveChat(ctx context.Context, chat domain.Chat) (string, error) {
 	const op = "infrastructure.mongodb.chat.SaveChat"
 
-	res, err := m.chatsCol.InsertOne(ctx, bson.M{"type": chat.Type, "name": chat.Name, "member_ids": chat.MemberIDs, "channel_ids": chat.ChannelIDs})
+	doc := bson.M{
+		"type":        chat.Type,
+		"name":        chat.Name,
+		"topic":       chat.Topic,
+		"description": chat.Description,
+		"member_ids":  chat.MemberIDs,
+		"roles":       chat.Roles,
+	}
+	if chat.CommunityID != "" {
+		doc["community_id"] = chat.CommunityID
+	}
+
+	res, err := m.chatsCol.InsertOne(ctx, doc)
 	if err != nil {
 		return "", fmt.Errorf("%s : %w", op, err)
 	}
@@ -130,3 +156,251 @@ func (m *MongoDB) SaveChat(ctx context.Context, chat domain.Chat) (string, error
 
 	return objectID.Hex(), nil
 }
+
+func (m *MongoDB) AddChatMembers(ctx context.Context, chatID string, userIDs []string) error {
+	const op = "infrastructure.mongodb.chat.AddChatMembers"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{"member_ids": bson.M{"$each": userIDs}},
+	}
+
+	res, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) RemoveChatMember(ctx context.Context, chatID string, userID string) error {
+	return m.WithinTransaction(ctx, func(ctx context.Context) error {
+		return m.removeChatMember(ctx, chatID, userID)
+	})
+}
+
+func (m *MongoDB) removeChatMember(ctx context.Context, chatID string, userID string) error {
+	const op = "infrastructure.mongodb.chat.RemoveChatMember"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{
+		"$pull":  bson.M{"member_ids": userID},
+		"$unset": bson.M{"roles." + userID: ""},
+	}
+
+	res, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	// the member also loses access to private channels of the chat
+	if _, err = m.channelsCol.UpdateMany(ctx, bson.M{"chat_id": chatID}, bson.M{"$pull": bson.M{"member_ids": userID}}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// SetChatRoles sets roles of the given members, RoleMember removes the entry
+func (m *MongoDB) SetChatRoles(ctx context.Context, chatID string, roles map[string]string) error {
+	const op = "infrastructure.mongodb.chat.SetChatRoles"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	res, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objID}, rolesUpdate(roles))
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) error {
+	const op = "infrastructure.mongodb.chat.UpdateChat"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	set := bson.M{}
+	if patch.Name != nil {
+		set["name"] = *patch.Name
+	}
+	if patch.Topic != nil {
+		set["topic"] = *patch.Topic
+	}
+	if patch.Description != nil {
+		set["description"] = *patch.Description
+	}
+	if patch.Archived != nil {
+		set["archived"] = *patch.Archived
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	res, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// SetChatHidden hides or shows the chat in the user's chat list
+func (m *MongoDB) SetChatHidden(ctx context.Context, chatID string, userID string, hidden bool) error {
+	const op = "infrastructure.mongodb.chat.SetChatHidden"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{"$pull": bson.M{"hidden_for": userID}}
+	if hidden {
+		update = bson.M{"$addToSet": bson.M{"hidden_for": userID}}
+	}
+
+	res, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// UnhideChat shows the chat to every member again, used when a new message arrives
+func (m *MongoDB) UnhideChat(ctx context.Context, chatID string) error {
+	const op = "infrastructure.mongodb.chat.UnhideChat"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	filter := bson.M{"_id": objID, "hidden_for.0": bson.M{"$exists": true}}
+	if _, err = m.chatsCol.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"hidden_for": ""}}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *MongoDB) DeleteChat(ctx context.Context, chatID string) error {
+	const op = "infrastructure.mongodb.chat.DeleteChat"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	res, err := m.chatsCol.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// SetChatRetention sets the retention policy of the chat, nil removes it
+func (m *MongoDB) SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error {
+	const op = "infrastructure.mongodb.chat.SetChatRetention"
+
+	objID, err := primitive.ObjectIDFromHex(chatID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	update := bson.M{"$unset": bson.M{"retention": ""}}
+	if policy != nil {
+		update = bson.M{"$set": bson.M{"retention": policy}}
+	}
+
+	res, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) FindChatsByIDs(ctx context.Context, chatIDs []string) ([]domain.Chat, error) {
+	const op = "infrastructure.mongodb.chat.FindChatsByIDs"
+
+	var objIDs []primitive.ObjectID
+	for _, id := range chatIDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		objIDs = append(objIDs, objID)
+	}
+
+	cursor, err := m.chatsCol.Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var chats []domain.Chat
+	if err = cursor.All(ctx, &chats); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return chats, nil
+}
+
+// rolesUpdate sets roles of the given members, RoleMember removes the entry
+func rolesUpdate(roles map[string]string) bson.M {
+	set := bson.M{}
+	unset := bson.M{}
+	for userID, role := range roles {
+		if role == domain.RoleMember {
+			unset["roles."+userID] = ""
+			continue
+		}
+		set["roles."+userID] = role
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update
+}
@@ -0,0 +1,194 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"chat-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (m *MongoDB) SaveCommunity(ctx context.Context, community domain.Community) (string, error) {
+	const op = "infrastructure.mongodb.community.SaveCommunity"
+
+	res, err := m.communitiesCol.InsertOne(ctx, community)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	objectID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%s : internal error", op)
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (m *MongoDB) FindCommunityByID(ctx context.Context, communityID string) (domain.Community, error) {
+	const op = "infrastructure.mongodb.community.FindCommunityByID"
+
+	objID, err := primitive.ObjectIDFromHex(communityID)
+	if err != nil {
+		return domain.Community{}, fmt.Errorf("%s : %w", op, domain.ErrCommunityNotFound)
+	}
+
+	var community domain.Community
+	if err = m.communitiesCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&community); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Community{}, domain.ErrCommunityNotFound
+		}
+		return domain.Community{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return community, nil
+}
+
+func (m *MongoDB) FindUserCommunities(ctx context.Context, userID string) ([]*domain.Community, error) {
+	const op = "infrastructure.mongodb.community.FindUserCommunities"
+
+	cursor, err := m.communitiesCol.Find(ctx, bson.M{"member_ids": userID})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var communities []*domain.Community
+	if err := cursor.All(ctx, &communities); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return communities, nil
+}
+
+func (m *MongoDB) UpdateCommunity(ctx context.Context, communityID string, patch domain.CommunityPatch) error {
+	const op = "infrastructure.mongodb.community.UpdateCommunity"
+
+	set := bson.M{}
+	if patch.Name != nil {
+		set["name"] = *patch.Name
+	}
+	if patch.Description != nil {
+		set["description"] = *patch.Description
+	}
+	if patch.DefaultChatID != nil {
+		set["default_chat_id"] = *patch.DefaultChatID
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	return m.updateCommunity(ctx, op, communityID, bson.M{"$set": set})
+}
+
+func (m *MongoDB) AddCommunityChat(ctx context.Context, communityID string, chatID string) error {
+	const op = "infrastructure.mongodb.community.AddCommunityChat"
+
+	return m.updateCommunity(ctx, op, communityID, bson.M{"$addToSet": bson.M{"chat_ids": chatID}})
+}
+
+func (m *MongoDB) RemoveCommunityChat(ctx context.Context, communityID string, chatID string) error {
+	const op = "infrastructure.mongodb.community.RemoveCommunityChat"
+
+	return m.updateCommunity(ctx, op, communityID, bson.M{"$pull": bson.M{"chat_ids": chatID}})
+}
+
+// AddCommunityMembers adds the users to the community and to every community chat
+func (m *MongoDB) AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) error {
+	return m.WithinTransaction(ctx, func(ctx context.Context) error {
+		return m.addCommunityMembers(ctx, communityID, userIDs)
+	})
+}
+
+func (m *MongoDB) addCommunityMembers(ctx context.Context, communityID string, userIDs []string) error {
+	const op = "infrastructure.mongodb.community.AddCommunityMembers"
+
+	update := bson.M{"$addToSet": bson.M{"member_ids": bson.M{"$each": userIDs}}}
+	if err := m.updateCommunity(ctx, op, communityID, update); err != nil {
+		return err
+	}
+
+	if _, err := m.chatsCol.UpdateMany(ctx, bson.M{"community_id": communityID}, update); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveCommunityMember removes the user with their roles from the community,
+// every community chat and private channels of these chats
+func (m *MongoDB) RemoveCommunityMember(ctx context.Context, communityID string, userID string) error {
+	return m.WithinTransaction(ctx, func(ctx context.Context) error {
+		return m.removeCommunityMember(ctx, communityID, userID)
+	})
+}
+
+func (m *MongoDB) removeCommunityMember(ctx context.Context, communityID string, userID string) error {
+	const op = "infrastructure.mongodb.community.RemoveCommunityMember"
+
+	update := bson.M{
+		"$pull":  bson.M{"member_ids": userID},
+		"$unset": bson.M{"roles." + userID: ""},
+	}
+	if err := m.updateCommunity(ctx, op, communityID, update); err != nil {
+		return err
+	}
+
+	if _, err := m.chatsCol.UpdateMany(ctx, bson.M{"community_id": communityID}, update); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	community, err := m.FindCommunityByID(ctx, communityID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	filter := bson.M{"chat_id": bson.M{"$in": community.ChatIDs}}
+	if _, err := m.channelsCol.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"member_ids": userID}}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// SetCommunityRoles sets roles of the given members in the community and every community chat
+func (m *MongoDB) SetCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error {
+	return m.WithinTransaction(ctx, func(ctx context.Context) error {
+		return m.setCommunityRoles(ctx, communityID, roles)
+	})
+}
+
+func (m *MongoDB) setCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error {
+	const op = "infrastructure.mongodb.community.SetCommunityRoles"
+
+	update := rolesUpdate(roles)
+	if err := m.updateCommunity(ctx, op, communityID, update); err != nil {
+		return err
+	}
+
+	if _, err := m.chatsCol.UpdateMany(ctx, bson.M{"community_id": communityID}, update); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *MongoDB) updateCommunity(ctx context.Context, op string, communityID string, update bson.M) error {
+	objID, err := primitive.ObjectIDFromHex(communityID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrCommunityNotFound)
+	}
+
+	res, err := m.communitiesCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrCommunityNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (m *MongoDB) SaveInvite(ctx context.Context, invite domain.Invite) (string, error) {
+	const op = "infrastructure.mongodb.invite.SaveInvite"
+
+	res, err := m.invitesCol.InsertOne(ctx, invite)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	objectID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%s : internal error", op)
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (m *MongoDB) FindInviteByID(ctx context.Context, inviteID string) (domain.Invite, error) {
+	const op = "infrastructure.mongodb.invite.FindInviteByID"
+
+	objID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return domain.Invite{}, fmt.Errorf("%s : %w", op, domain.ErrInviteNotFound)
+	}
+
+	return m.findInvite(ctx, op, bson.M{"_id": objID})
+}
+
+func (m *MongoDB) FindInviteByCode(ctx context.Context, code string) (domain.Invite, error) {
+	const op = "infrastructure.mongodb.invite.FindInviteByCode"
+
+	return m.findInvite(ctx, op, bson.M{"code": code})
+}
+
+func (m *MongoDB) findInvite(ctx context.Context, op string, filter bson.M) (domain.Invite, error) {
+	var invite domain.Invite
+
+	err := m.invitesCol.FindOne(ctx, filter).Decode(&invite)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return domain.Invite{}, domain.ErrInviteNotFound
+		default:
+			return domain.Invite{}, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return invite, nil
+}
+
+func (m *MongoDB) FindChatInvites(ctx context.Context, chatID string) ([]*domain.Invite, error) {
+	const op = "infrastructure.mongodb.invite.FindChatInvites"
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := m.invitesCol.Find(ctx, bson.M{"chat_id": chatID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var invites []*domain.Invite
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// UseInvite increments uses of the invite only if it is still usable,
+// so concurrent joins cannot exceed max_uses
+func (m *MongoDB) UseInvite(ctx context.Context, inviteID string, now time.Time) error {
+	const op = "infrastructure.mongodb.invite.UseInvite"
+
+	objID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrInviteNotFound)
+	}
+
+	filter := bson.M{
+		"_id":     objID,
+		"revoked": false,
+		"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"expires_at": bson.M{"$exists": false}},
+				bson.M{"expires_at": bson.M{"$gt": now}},
+			}},
+			bson.M{"$or": bson.A{
+				bson.M{"max_uses": 0},
+				bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}},
+			}},
+		},
+	}
+
+	res, err := m.invitesCol.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"uses": 1}})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.ModifiedCount == 0 {
+		return domain.ErrInvalidInvite
+	}
+
+	return nil
+}
+
+func (m *MongoDB) RevokeInvite(ctx context.Context, inviteID string) error {
+	const op = "infrastructure.mongodb.invite.RevokeInvite"
+
+	objID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrInviteNotFound)
+	}
+
+	res, err := m.invitesCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+// DeleteChatInvites removes invites and join records of the chat
+func (m *MongoDB) DeleteChatInvites(ctx context.Context, chatID string) error {
+	return m.WithinTransaction(ctx, func(ctx context.Context) error {
+		return m.deleteChatInvites(ctx, chatID)
+	})
+}
+
+func (m *MongoDB) deleteChatInvites(ctx context.Context, chatID string) error {
+	const op = "infrastructure.mongodb.invite.DeleteChatInvites"
+
+	if _, err := m.invitesCol.DeleteMany(ctx, bson.M{"chat_id": chatID}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if _, err := m.joinsCol.DeleteMany(ctx, bson.M{"chat_id": chatID}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *MongoDB) SaveJoinRecord(ctx context.Context, record domain.JoinRecord) (string, error) {
+	const op = "infrastructure.mongodb.invite.SaveJoinRecord"
+
+	res, err := m.joinsCol.InsertOne(ctx, record)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	objectID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%s : internal error", op)
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (m *MongoDB) FindJoinRecordByID(ctx context.Context, recordID string) (domain.JoinRecord, error) {
+	const op = "infrastructure.mongodb.invite.FindJoinRecordByID"
+
+	objID, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		return domain.JoinRecord{}, fmt.Errorf("%s : %w", op, domain.ErrJoinReqNotFound)
+	}
+
+	var record domain.JoinRecord
+
+	err = m.joinsCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&record)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return domain.JoinRecord{}, domain.ErrJoinReqNotFound
+		default:
+			return domain.JoinRecord{}, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return record, nil
+}
+
+func (m *MongoDB) FindJoinRecords(ctx context.Context, chatID string, status string) ([]*domain.JoinRecord, error) {
+	const op = "infrastructure.mongodb.invite.FindJoinRecords"
+
+	filter := bson.M{"chat_id": chatID}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := m.joinsCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []*domain.JoinRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return records, nil
+}
+
+func (m *MongoDB) FindPendingJoinRecord(ctx context.Context, chatID string, userID string) (*domain.JoinRecord, error) {
+	const op = "infrastructure.mongodb.invite.FindPendingJoinRecord"
+
+	var record domain.JoinRecord
+
+	filter := bson.M{"chat_id": chatID, "user_id": userID, "status": domain.JoinStatusPending}
+
+	err := m.joinsCol.FindOne(ctx, filter).Decode(&record)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return &record, nil
+}
+
+func (m *MongoDB) SetJoinRecordStatus(ctx context.Context, recordID string, status string, reviewerID string) error {
+	const op = "infrastructure.mongodb.invite.SetJoinRecordStatus"
+
+	objID, err := primitive.ObjectIDFromHex(recordID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrJoinReqNotFound)
+	}
+
+	update := bson.M{"$set": bson.M{"status": status, "reviewer_id": reviewerID}}
+
+	res, err := m.joinsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrJoinReqNotFound
+	}
+
+	return nil
+}
@@ -2,20 +2,41 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"chat-service/internal/domain"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (m *MongoDB) SaveMessage(ctx context.Context, message domain.Message) (string, error) {
+func (m *MongoDB) SaveMessage(ctx context.Context, message domain.Message) (messageID string, err error) {
+	err = m.WithinTransaction(ctx, func(ctx context.Context) error {
+		messageID, err = m.saveMessage(ctx, message)
+		return err
+	})
+	return messageID, err
+}
+
+func (m *MongoDB) saveMessage(ctx context.Context, message domain.Message) (string, error) {
 	const op = "infrastructure.mongodb.message.SaveMessage"
 
-	// TODO: проверить, можно ли каскадно обновлять список сообщений в канале сразу же
-	res, err := m.messagesCol.InsertOne(ctx, bson.M{"channel_id": message.ChannelID, "sender_id": message.SenderID, "text": message.Text, "created_at": message.CreatedAt})
+	doc := bson.M{"channel_id": message.ChannelID, "sender_id": message.SenderID, "text": message.Text, "created_at": message.CreatedAt, "type": message.Type}
+	if message.PostID != "" {
+		doc["post_id"] = message.PostID
+	}
+	if message.Call != nil {
+		doc["call"] = message.Call
+	}
+	if message.Webhook != nil {
+		doc["webhook"] = message.Webhook
+	}
+
+	res, err := m.messagesCol.InsertOne(ctx, doc)
 	if err != nil {
 		return "", fmt.Errorf("%s : %w", op, err)
 	}
@@ -25,9 +46,11 @@ func (m *MongoDB) SaveMessage(ctx context.Context, message domain.Message) (stri
 		return "", fmt.Errorf("%s : internal error", op)
 	}
 	messageID := objectID.Hex()
+	message.ID = messageID
 
 	update := bson.M{
-		"$push": bson.M{"message_ids": messageID},
+		"$inc": bson.M{"message_count": 1},
+		"$set": bson.M{"last_message": domain.NewLastMessage(message)},
 	}
 
 	objChannelID, err := primitive.ObjectIDFromHex(message.ChannelID)
@@ -42,6 +65,81 @@ func (m *MongoDB) SaveMessage(ctx context.Context, message domain.Message) (stri
 	return messageID, nil
 }
 
+// saveMessagesBatch limits the size of one InsertMany in SaveMessages
+const saveMessagesBatch = 1000
+
+func (m *MongoDB) SaveMessages(ctx context.Context, channelID string, messages []domain.Message) ([]string, error) {
+	const op = "infrastructure.mongodb.message.SaveMessages"
+
+	objChannelID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, domain.ErrChannelNotFound)
+	}
+
+	messageIDs := make([]string, 0, len(messages))
+	var last *domain.Message
+	for start := 0; start < len(messages); start += saveMessagesBatch {
+		end := min(start+saveMessagesBatch, len(messages))
+
+		docs := make([]any, 0, end-start)
+		for _, message := range messages[start:end] {
+			doc := bson.M{"channel_id": channelID, "sender_id": message.SenderID, "text": message.Text, "created_at": message.CreatedAt, "type": message.Type}
+			docs = append(docs, doc)
+		}
+
+		// every batch is inserted together with its message_count increment
+		var batchIDs []string
+		err := m.WithinTransaction(ctx, func(ctx context.Context) error {
+			res, err := m.messagesCol.InsertMany(ctx, docs)
+			if err != nil {
+				return err
+			}
+
+			batchIDs = make([]string, 0, len(res.InsertedIDs))
+			for _, insertedID := range res.InsertedIDs {
+				objectID, ok := insertedID.(primitive.ObjectID)
+				if !ok {
+					return errors.New("internal error")
+				}
+				batchIDs = append(batchIDs, objectID.Hex())
+			}
+
+			update := bson.M{"$inc": bson.M{"message_count": len(res.InsertedIDs)}}
+			_, err = m.channelsCol.UpdateOne(ctx, bson.M{"_id": objChannelID}, update)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		messageIDs = append(messageIDs, batchIDs...)
+
+		for i, messageID := range batchIDs {
+			if message := messages[start+i]; last == nil || message.CreatedAt.After(last.CreatedAt) {
+				message.ID = messageID
+				last = &message
+			}
+		}
+	}
+
+	if last == nil {
+		return messageIDs, nil
+	}
+
+	// imported messages may be older than the ones already in the channel
+	filter := bson.M{
+		"_id": objChannelID,
+		"$or": bson.A{
+			bson.M{"last_message": bson.M{"$exists": false}},
+			bson.M{"last_message.created_at": bson.M{"$lt": last.CreatedAt}},
+		},
+	}
+	if _, err = m.channelsCol.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"last_message": domain.NewLastMessage(*last)}}); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messageIDs, nil
+}
+
 func (m *MongoDB) GetMessages(ctx context.Context, channelID string, limit int32, offset int32) ([]*domain.Message, error) {
 	const op = "infrastructure.mongodb.message.GetMessages"
 
@@ -65,3 +163,187 @@ func (m *MongoDB) GetMessages(ctx context.Context, channelID string, limit int32
 
 	return messages, nil
 }
+
+// DeleteMessagesBefore deletes the oldest messages first, so a purge interrupted between
+// batches leaves the channel without gaps in its history
+func (m *MongoDB) DeleteMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) (messageIDs []string, err error) {
+	err = m.WithinTransaction(ctx, func(ctx context.Context) error {
+		messageIDs, err = m.deleteMessagesBefore(ctx, channelID, before, limit)
+		return err
+	})
+	return messageIDs, err
+}
+
+func (m *MongoDB) deleteMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.mongodb.message.DeleteMessagesBefore"
+
+	objChannelID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, domain.ErrChannelNotFound)
+	}
+
+	messageIDs, err := m.deleteOldestMessages(ctx, channelID, before, limit)
+	if err != nil || len(messageIDs) == 0 {
+		return nil, err
+	}
+
+	update := bson.M{"$inc": bson.M{"message_count": -len(messageIDs)}}
+	if _, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objChannelID}, update); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	if err := m.refreshLastMessage(ctx, objChannelID, channelID); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messageIDs, nil
+}
+
+// DeleteArchivedMessages deletes the oldest messages copied to the archive,
+// message_count and last_message of the channel stay as they are
+func (m *MongoDB) DeleteArchivedMessages(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	return m.deleteOldestMessages(ctx, channelID, before, limit)
+}
+
+// DecreaseMessageCount subtracts messages deleted from the archive from message_count of the channel
+func (m *MongoDB) DecreaseMessageCount(ctx context.Context, channelID string, count int64) error {
+	const op = "infrastructure.mongodb.message.DecreaseMessageCount"
+
+	objChannelID, err := primitive.ObjectIDFromHex(channelID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrChannelNotFound)
+	}
+
+	update := bson.M{"$inc": bson.M{"message_count": -count}}
+	if _, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": objChannelID}, update); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// deleteOldestMessages deletes up to limit oldest messages of the channel created before the time
+func (m *MongoDB) deleteOldestMessages(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.mongodb.message.deleteOldestMessages"
+
+	filter := bson.M{"channel_id": channelID, "created_at": bson.M{"$lt": before}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"_id": 1})
+
+	cursor, err := m.messagesCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	objIDs := make([]primitive.ObjectID, 0, len(docs))
+	messageIDs := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		objIDs = append(objIDs, doc.ID)
+		messageIDs = append(messageIDs, doc.ID.Hex())
+	}
+
+	if _, err := m.messagesCol.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": objIDs}}); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messageIDs, nil
+}
+
+// refreshLastMessage sets last_message of the channel to its newest message or unsets it in an empty channel
+func (m *MongoDB) refreshLastMessage(ctx context.Context, objChannelID primitive.ObjectID, channelID string) error {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	var message domain.Message
+	err := m.messagesCol.FindOne(ctx, bson.M{"channel_id": channelID}, opts).Decode(&message)
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		_, err = m.channelsCol.UpdateOne(ctx, bson.M{"_id": objChannelID}, bson.M{"$unset": bson.M{"last_message": ""}})
+		return err
+	case err != nil:
+		return err
+	}
+
+	_, err = m.channelsCol.UpdateOne(ctx, bson.M{"_id": objChannelID}, bson.M{"$set": bson.M{"last_message": domain.NewLastMessage(message)}})
+	return err
+}
+
+func (m *MongoDB) DeleteChannelsMessages(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.mongodb.message.DeleteChannelsMessages"
+
+	if _, err := m.messagesCol.DeleteMany(ctx, bson.M{"channel_id": bson.M{"$in": channelIDs}}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// GetPostMessages returns replies of a forum post in chronological order
+func (m *MongoDB) GetPostMessages(ctx context.Context, postID string, limit int32, offset int32) ([]*domain.Message, error) {
+	const op = "infrastructure.mongodb.message.GetPostMessages"
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset))
+
+	cursor, err := m.messagesCol.Find(ctx, bson.M{"post_id": postID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*domain.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// GetMessagesAfter returns channel messages in chronological order starting after the message with
+// afterTime and afterID, zero afterTime starts from the beginning. Messages with equal time are ordered by id
+func (m *MongoDB) GetMessagesAfter(ctx context.Context, channelID string, afterTime time.Time, afterID string, limit int32) ([]*domain.Message, error) {
+	const op = "infrastructure.mongodb.message.GetMessagesAfter"
+
+	filter := bson.M{"channel_id": channelID}
+	if !afterTime.IsZero() {
+		objAfterID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, domain.ErrMsgNotFound)
+		}
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$gt": afterTime}},
+			bson.M{"created_at": afterTime, "_id": bson.M{"$gt": objAfterID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.messagesCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*domain.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,439 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is a versioned change of the database. Applied versions are recorded in the
+// migrations collection, so every migration runs once. Up must be safe to repeat because
+// two instances starting at the same time may both run it
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, m *MongoDB) error
+	Down    func(ctx context.Context, m *MongoDB) error
+}
+
+// MigrationStatus tells whether the migration was applied and when
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type migrationRecord struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "strip_channel_message_ids",
+		Up:      stripChannelMessageIDs,
+		// message_ids arrays are not restored, channels are read through message_count and last_message
+		Down: func(ctx context.Context, m *MongoDB) error { return nil },
+	},
+	{
+		Version: 2,
+		Name:    "create_indexes",
+		Up: func(ctx context.Context, m *MongoDB) error {
+			return m.createIndexes(ctx, initialIndexes(m))
+		},
+		Down: func(ctx context.Context, m *MongoDB) error {
+			return m.dropIndexes(ctx, initialIndexes(m))
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_webhooks_indexes",
+		Up: func(ctx context.Context, m *MongoDB) error {
+			return m.createIndexes(ctx, webhooksIndexes(m))
+		},
+		Down: func(ctx context.Context, m *MongoDB) error {
+			return m.dropIndexes(ctx, webhooksIndexes(m))
+		},
+	},
+	{
+		Version: 4,
+		Name:    "assign_legacy_chat_owners",
+		Up:      assignLegacyChatOwners,
+		// assigned roles are not told apart from roles set later, so they are kept
+		Down: func(ctx context.Context, m *MongoDB) error { return nil },
+	},
+	{
+		Version: 5,
+		Name:    "create_updates_created_at_index",
+		Up: func(ctx context.Context, m *MongoDB) error {
+			return m.createIndexes(ctx, updatesTrimIndexes(m))
+		},
+		Down: func(ctx context.Context, m *MongoDB) error {
+			return m.dropIndexes(ctx, updatesTrimIndexes(m))
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_notifications_ttl_index",
+		Up: func(ctx context.Context, m *MongoDB) error {
+			return m.createIndexes(ctx, notificationsIndexes(m))
+		},
+		Down: func(ctx context.Context, m *MongoDB) error {
+			return m.dropIndexes(ctx, notificationsIndexes(m))
+		},
+	},
+	{
+		Version: 7,
+		Name:    "strip_chat_channel_ids",
+		Up: func(ctx context.Context, m *MongoDB) error {
+			_, err := m.chatsCol.UpdateMany(ctx, bson.M{"channel_ids": bson.M{"$exists": true}}, bson.M{"$unset": bson.M{"channel_ids": ""}})
+			return err
+		},
+		Down: restoreChatChannelIDs,
+	},
+}
+
+// collectionIndexes are indexes of one collection, every index has a name so Down can drop it
+type collectionIndexes struct {
+	col     *mongo.Collection
+	indexes []mongo.IndexModel
+}
+
+func initialIndexes(m *MongoDB) []collectionIndexes {
+	index := func(name string, keys bson.D) mongo.IndexModel {
+		return mongo.IndexModel{Keys: keys, Options: options.Index().SetName(name)}
+	}
+
+	return []collectionIndexes{
+		{m.messagesCol, []mongo.IndexModel{
+			index("channel_id_created_at", bson.D{{Key: "channel_id", Value: 1}, {Key: "created_at", Value: -1}}),
+			index("post_id_created_at", bson.D{{Key: "post_id", Value: 1}, {Key: "created_at", Value: 1}}),
+		}},
+		{m.chatsCol, []mongo.IndexModel{
+			index("member_ids", bson.D{{Key: "member_ids", Value: 1}}),
+		}},
+		{m.channelsCol, []mongo.IndexModel{
+			index("chat_id", bson.D{{Key: "chat_id", Value: 1}}),
+		}},
+		{m.updatesCol, []mongo.IndexModel{
+			index("user_id_seq", bson.D{{Key: "user_id", Value: 1}, {Key: "seq", Value: 1}}),
+		}},
+		{m.invitesCol, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "code", Value: 1}},
+				Options: options.Index().SetName("code").SetUnique(true),
+			},
+			index("chat_id_created_at", bson.D{{Key: "chat_id", Value: 1}, {Key: "created_at", Value: -1}}),
+		}},
+		{m.joinsCol, []mongo.IndexModel{
+			index("chat_id_status", bson.D{{Key: "chat_id", Value: 1}, {Key: "status", Value: 1}}),
+		}},
+		{m.postsCol, []mongo.IndexModel{
+			index("channel_id_last_activity_at", bson.D{{Key: "channel_id", Value: 1}, {Key: "last_activity_at", Value: -1}}),
+		}},
+		{m.communitiesCol, []mongo.IndexModel{
+			index("member_ids", bson.D{{Key: "member_ids", Value: 1}}),
+		}},
+	}
+}
+
+func webhooksIndexes(m *MongoDB) []collectionIndexes {
+	return []collectionIndexes{
+		{m.webhooksCol, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "channel_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("channel_id_created_at"),
+		}}},
+	}
+}
+
+// updatesTrimIndexes serve the job that deletes expired updates of all users
+func updatesTrimIndexes(m *MongoDB) []collectionIndexes {
+	return []collectionIndexes{
+		{m.updatesCol, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("created_at"),
+		}}},
+	}
+}
+
+// notificationsIndexes expire messages of the Notifier, listeners read them from the change stream
+func notificationsIndexes(m *MongoDB) []collectionIndexes {
+	return []collectionIndexes{
+		{m.notificationsCol, []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("created_at").SetExpireAfterSeconds(3600),
+		}}},
+	}
+}
+
+// MigrateUp applies pending migrations in version order and returns the applied ones
+func (m *MongoDB) MigrateUp(ctx context.Context) ([]Migration, error) {
+	const op = "infrastructure.mongodb.migrations.MigrateUp"
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var done []Migration
+	for _, migration := range sortedMigrations() {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx, m); err != nil {
+			return done, fmt.Errorf("%s : migration %d %s : %w", op, migration.Version, migration.Name, err)
+		}
+
+		record := migrationRecord{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+		if _, err := m.migrationsCol.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return done, fmt.Errorf("%s : %w", op, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// MigrateDown reverts up to steps latest applied migrations and returns the reverted ones
+func (m *MongoDB) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	const op = "infrastructure.mongodb.migrations.MigrateDown"
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	sorted := sortedMigrations()
+	var done []Migration
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := sorted[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := migration.Down(ctx, m); err != nil {
+			return done, fmt.Errorf("%s : migration %d %s : %w", op, migration.Version, migration.Name, err)
+		}
+
+		if _, err := m.migrationsCol.DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return done, fmt.Errorf("%s : %w", op, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// MigrationStatuses lists all known migrations in version order
+func (m *MongoDB) MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "infrastructure.mongodb.migrations.MigrationStatuses"
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range sortedMigrations() {
+		record, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: record.AppliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *MongoDB) appliedMigrations(ctx context.Context) (map[int]migrationRecord, error) {
+	cursor, err := m.migrationsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []migrationRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]migrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+func (m *MongoDB) createIndexes(ctx context.Context, indexes []collectionIndexes) error {
+	for _, col := range indexes {
+		if _, err := col.col.Indexes().CreateMany(ctx, col.indexes); err != nil {
+			return fmt.Errorf("%s : %w", col.col.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (m *MongoDB) dropIndexes(ctx context.Context, indexes []collectionIndexes) error {
+	for _, col := range indexes {
+		for _, index := range col.indexes {
+			_, err := col.col.Indexes().DropOne(ctx, *index.Options.Name)
+			var cmdErr mongo.CommandError
+			if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound") {
+				return fmt.Errorf("%s : %w", col.col.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// assignLegacyChatOwners gives roles to group chats saved before roles, whose members all acted as admins.
+// CreateChat appended the creator after the invited users, so the last member becomes the owner
+// and everyone else a member
+func assignLegacyChatOwners(ctx context.Context, m *MongoDB) error {
+	filter := bson.M{
+		"type": "group",
+		"$or": bson.A{
+			bson.M{"roles": bson.M{"$exists": false}},
+			bson.M{"roles": bson.M{}},
+		},
+	}
+	cursor, err := m.chatsCol.Find(ctx, filter, options.Find().SetProjection(bson.M{"member_ids": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			MemberIDs []string           `bson:"member_ids"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if len(doc.MemberIDs) == 0 {
+			continue
+		}
+
+		roles := make(map[string]string, len(doc.MemberIDs))
+		for _, memberID := range doc.MemberIDs {
+			roles[memberID] = domain.RoleMember
+		}
+		roles[doc.MemberIDs[len(doc.MemberIDs)-1]] = domain.RoleOwner
+
+		// the filter is repeated so a chat given roles in the meantime is left as is
+		update := bson.M{"$set": bson.M{"roles": roles}}
+		if _, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": doc.ID, "$or": filter["$or"]}, update); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// restoreChatChannelIDs writes channel_ids of every chat back from the channels collection,
+// in creation order like they were pushed
+func restoreChatChannelIDs(ctx context.Context, m *MongoDB) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetProjection(bson.M{"chat_id": 1})
+	cursor, err := m.channelsCol.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var chatIDs []string
+	channelIDs := make(map[string][]string)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID     primitive.ObjectID `bson:"_id"`
+			ChatID string             `bson:"chat_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if _, ok := channelIDs[doc.ChatID]; !ok {
+			chatIDs = append(chatIDs, doc.ChatID)
+		}
+		channelIDs[doc.ChatID] = append(channelIDs[doc.ChatID], doc.ID.Hex())
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if _, err := m.chatsCol.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{"channel_ids": []string{}}}); err != nil {
+		return err
+	}
+	for _, chatID := range chatIDs {
+		objChatID, err := primitive.ObjectIDFromHex(chatID)
+		if err != nil {
+			continue
+		}
+		update := bson.M{"$set": bson.M{"channel_ids": channelIDs[chatID]}}
+		if _, err := m.chatsCol.UpdateOne(ctx, bson.M{"_id": objChatID}, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stripChannelMessageIDs replaces message_ids arrays of channels saved before message counters
+// with message_count and last_message
+func stripChannelMessageIDs(ctx context.Context, m *MongoDB) error {
+	filter := bson.M{"message_ids": bson.M{"$exists": true}}
+	cursor, err := m.channelsCol.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		channelID := doc.ID.Hex()
+
+		count, err := m.messagesCol.CountDocuments(ctx, bson.M{"channel_id": channelID})
+		if err != nil {
+			return err
+		}
+
+		update := bson.M{
+			"$set":   bson.M{"message_count": count},
+			"$unset": bson.M{"message_ids": ""},
+		}
+		if _, err := m.channelsCol.UpdateOne(ctx, bson.M{"_id": doc.ID}, update); err != nil {
+			return err
+		}
+
+		if err := m.refreshLastMessage(ctx, doc.ID, channelID); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Notifier passes messages between instances sharing the database. Messages are inserted into
+// the notifications collection and read back with a change stream, a TTL index removes them
+type Notifier struct {
+	col     *mongo.Collection
+	channel string
+}
+
+type notification struct {
+	Channel   string    `bson:"channel"`
+	Message   []byte    `bson:"message"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func (m *MongoDB) Notifier(channel string) *Notifier {
+	return &Notifier{col: m.notificationsCol, channel: channel}
+}
+
+func (n *Notifier) Publish(ctx context.Context, message []byte) error {
+	const op = "infrastructure.mongodb.notifier.Publish"
+
+	if _, err := n.col.InsertOne(ctx, notification{Channel: n.channel, Message: message, CreatedAt: time.Now()}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// Listen watches inserts of messages on the channel until ctx is done or the change stream fails
+func (n *Notifier) Listen(ctx context.Context, ready func(), handle func(message []byte)) error {
+	const op = "infrastructure.mongodb.notifier.Listen"
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType":        "insert",
+		"fullDocument.channel": n.channel,
+	}}}}
+	stream, err := n.col.Watch(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	defer stream.Close(context.Background())
+	ready()
+
+	for stream.Next(ctx) {
+		var event struct {
+			FullDocument notification `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+
+		handle(event.FullDocument.Message)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return fmt.Errorf("%s : %w", op, stream.Err())
+}
@@ -0,0 +1,113 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (m *MongoDB) SavePost(ctx context.Context, post domain.Post) (string, error) {
+	const op = "infrastructure.mongodb.post.SavePost"
+
+	res, err := m.postsCol.InsertOne(ctx, post)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	objectID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%s : internal error", op)
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (m *MongoDB) FindPostByID(ctx context.Context, postID string) (domain.Post, error) {
+	const op = "infrastructure.mongodb.post.FindPostByID"
+
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return domain.Post{}, fmt.Errorf("%s : %w", op, domain.ErrPostNotFound)
+	}
+
+	var post domain.Post
+	if err = m.postsCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&post); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.Post{}, domain.ErrPostNotFound
+		}
+		return domain.Post{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return post, nil
+}
+
+// FindChannelPosts returns posts of the channel sorted by latest activity, an empty tag matches every post
+func (m *MongoDB) FindChannelPosts(ctx context.Context, channelID string, tag string, limit int32, offset int32) ([]*domain.Post, error) {
+	const op = "infrastructure.mongodb.post.FindChannelPosts"
+
+	filter := bson.M{"channel_id": channelID}
+	if tag != "" {
+		filter["tags"] = tag
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_activity_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)).
+		SetSkip(int64(offset))
+
+	cursor, err := m.postsCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var posts []*domain.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return posts, nil
+}
+
+// TouchPost counts a new reply and moves the post up in the activity order
+func (m *MongoDB) TouchPost(ctx context.Context, postID string, activityAt time.Time) error {
+	const op = "infrastructure.mongodb.post.TouchPost"
+
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrPostNotFound)
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"reply_count": 1},
+		"$max": bson.M{"last_activity_at": activityAt},
+	}
+
+	res, err := m.postsCol.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrPostNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) DeleteChannelsPosts(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.mongodb.post.DeleteChannelsPosts"
+
+	if _, err := m.postsCol.DeleteMany(ctx, bson.M{"channel_id": bson.M{"$in": channelIDs}}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// supportsTransactions reports whether the deployment is a replica set or a sharded cluster,
+// standalone servers reject transactions
+func supportsTransactions(ctx context.Context, client *mongo.Client) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// Transactional reports whether WithinTransaction runs in real transactions
+func (m *MongoDB) Transactional() bool {
+	return m.transactions
+}
+
+// WithinTransaction runs fn in a transaction of a new session. Calls made with a ctx that already
+// carries a session join its transaction. Standalone servers reject transactions, the service
+// refuses to start on them
+func (m *MongoDB) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "infrastructure.mongodb.transactions.WithinTransaction"
+
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
@@ -0,0 +1,35 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+func TestWithinTransactionRollsBack(t *testing.T) {
+	m := newTestMongoDB(t)
+	ctx := context.Background()
+
+	errRollback := errors.New("rollback")
+	err := m.WithinTransaction(ctx, func(ctx context.Context) error {
+		update := domain.Update{Type: domain.UpdateNewMessage, ChatID: "chat", CreatedAt: time.Now()}
+		if _, err := m.SaveUpdates(ctx, update, []string{"user"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTransaction() error = %v, want %v", err, errRollback)
+	}
+
+	seq, err := m.GetUpdatesState(ctx, "user")
+	if err != nil {
+		t.Fatalf("GetUpdatesState() error = %v", err)
+	}
+	if seq != 0 {
+		t.Errorf("seq = %d after rollback, want 0", seq)
+	}
+}
@@ -0,0 +1,170 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const updatesCounterPrefix = "updates:"
+
+// SaveUpdates appends the update to the logs of the users and returns their seqs. Counters are
+// incremented with one bulk write and the updates are inserted with one insert in one transaction.
+// Transactions that increment the same counter conflict, so updates of a user become visible
+// in seq order and a failed insert gives the seqs back instead of leaving gaps
+func (m *MongoDB) SaveUpdates(ctx context.Context, update domain.Update, userIDs []string) (map[string]int64, error) {
+	const op = "infrastructure.mongodb.update.SaveUpdates"
+
+	counterIDs := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if counterID := updatesCounterPrefix + userID; !slices.Contains(counterIDs, counterID) {
+			counterIDs = append(counterIDs, counterID)
+		}
+	}
+	if len(counterIDs) == 0 {
+		return map[string]int64{}, nil
+	}
+
+	seqs := make(map[string]int64, len(counterIDs))
+	err := m.WithinTransaction(ctx, func(ctx context.Context) error {
+		increments := make([]mongo.WriteModel, len(counterIDs))
+		for i, counterID := range counterIDs {
+			increments[i] = mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": counterID}).
+				SetUpdate(bson.M{"$inc": bson.M{"seq": int64(1)}}).
+				SetUpsert(true)
+		}
+		if _, err := m.countersCol.BulkWrite(ctx, increments); err != nil {
+			return err
+		}
+
+		cursor, err := m.countersCol.Find(ctx, bson.M{"_id": bson.M{"$in": counterIDs}})
+		if err != nil {
+			return err
+		}
+		var counters []struct {
+			ID  string `bson:"_id"`
+			Seq int64  `bson:"seq"`
+		}
+		if err := cursor.All(ctx, &counters); err != nil {
+			return err
+		}
+
+		docs := make([]any, 0, len(counters))
+		for _, counter := range counters {
+			memberUpdate := update
+			memberUpdate.UserID = strings.TrimPrefix(counter.ID, updatesCounterPrefix)
+			memberUpdate.Seq = counter.Seq
+
+			seqs[memberUpdate.UserID] = memberUpdate.Seq
+			docs = append(docs, updateDocument(memberUpdate))
+		}
+
+		_, err = m.updatesCol.InsertMany(ctx, docs)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return seqs, nil
+}
+
+func updateDocument(update domain.Update) bson.M {
+	doc := bson.M{
+		"user_id":    update.UserID,
+		"seq":        update.Seq,
+		"type":       update.Type,
+		"chat_id":    update.ChatID,
+		"channel_id": update.ChannelID,
+		"message_id": update.MessageID,
+		"user_ids":   update.UserIDs,
+		"created_at": update.CreatedAt,
+	}
+	if update.Message != nil {
+		doc["message"] = update.Message
+	}
+	if update.Chat != nil {
+		doc["chat"] = update.Chat
+	}
+	if update.Channel != nil {
+		doc["channel"] = update.Channel
+	}
+	if update.Post != nil {
+		doc["post"] = update.Post
+	}
+	if update.Call != nil {
+		doc["call"] = update.Call
+	}
+	if update.PurgedBefore != nil {
+		doc["purged_before"] = update.PurgedBefore
+	}
+
+	return doc
+}
+
+func (m *MongoDB) GetUpdates(ctx context.Context, userID string, sinceSeq int64, limit int32) ([]*domain.Update, error) {
+	const op = "infrastructure.mongodb.update.GetUpdates"
+
+	filter := bson.M{
+		"user_id": userID,
+		"seq":     bson.M{"$gt": sinceSeq},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "seq", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := m.updatesCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var updates []*domain.Update
+	if err := cursor.All(ctx, &updates); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return updates, nil
+}
+
+func (m *MongoDB) GetUpdatesState(ctx context.Context, userID string) (int64, error) {
+	const op = "infrastructure.mongodb.update.GetUpdatesState"
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+
+	err := m.countersCol.FindOne(ctx, bson.M{"_id": updatesCounterPrefix + userID}).Decode(&counter)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return 0, nil
+		default:
+			return 0, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return counter.Seq, nil
+}
+
+func (m *MongoDB) DeleteUpdatesBefore(ctx context.Context, before time.Time) (int64, error) {
+	const op = "infrastructure.mongodb.update.DeleteUpdatesBefore"
+
+	res, err := m.updatesCol.DeleteMany(ctx, bson.M{"created_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,100 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"chat-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (m *MongoDB) SaveWebhook(ctx context.Context, webhook domain.Webhook) (string, error) {
+	const op = "infrastructure.mongodb.webhook.SaveWebhook"
+
+	res, err := m.webhooksCol.InsertOne(ctx, webhook)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	objectID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%s : internal error", op)
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (m *MongoDB) FindWebhookByID(ctx context.Context, webhookID string) (domain.Webhook, error) {
+	const op = "infrastructure.mongodb.webhook.FindWebhookByID"
+
+	objID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s : %w", op, domain.ErrWebhookNotFound)
+	}
+
+	var webhook domain.Webhook
+	err = m.webhooksCol.FindOne(ctx, bson.M{"_id": objID}).Decode(&webhook)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return domain.Webhook{}, domain.ErrWebhookNotFound
+		default:
+			return domain.Webhook{}, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return webhook, nil
+}
+
+func (m *MongoDB) FindChannelWebhooks(ctx context.Context, channelID string) ([]*domain.Webhook, error) {
+	const op = "infrastructure.mongodb.webhook.FindChannelWebhooks"
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := m.webhooksCol.Find(ctx, bson.M{"channel_id": channelID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	var webhooks []*domain.Webhook
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return webhooks, nil
+}
+
+func (m *MongoDB) RevokeWebhook(ctx context.Context, webhookID string) error {
+	const op = "infrastructure.mongodb.webhook.RevokeWebhook"
+
+	objID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, domain.ErrWebhookNotFound)
+	}
+
+	res, err := m.webhooksCol.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (m *MongoDB) DeleteChannelsWebhooks(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.mongodb.webhook.DeleteChannelsWebhooks"
+
+	if _, err := m.webhooksCol.DeleteMany(ctx, bson.M{"channel_id": bson.M{"$in": channelIDs}}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
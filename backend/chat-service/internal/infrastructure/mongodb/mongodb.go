@@ -3,39 +3,59 @@ package mongodb
 import (
 	"context"
 
+	"chat-service/internal/config"
+
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoDB struct {
-	client      *mongo.Client
-	database    *mongo.Database
-	chatsCol    *mongo.Collection
-	channelsCol *mongo.Collection
-	messagesCol *mongo.Collection
+	client         *mongo.Client
+	database       *mongo.Database
+	chatsCol       *mongo.Collection
+	channelsCol    *mongo.Collection
+	messagesCol    *mongo.Collection
+	updatesCol     *mongo.Collection
+	countersCol    *mongo.Collection
+	invitesCol     *mongo.Collection
+	joinsCol       *mongo.Collection
+	postsCol       *mongo.Collection
+	communitiesCol *mongo.Collection
+	webhooksCol    *mongo.Collection
+	migrationsCol  *mongo.Collection
+	// notificationsCol keeps messages of the Notifier
+	notificationsCol *mongo.Collection
+
+	transactions bool
 }
 
-func New(
-	storagePath string,
-	dbName string,
-	chatsColName string,
-	channelsColName string,
-	messagesColName string,
-) *MongoDB {
+func New(storagePath string, storageCfg config.YamlStorage) *MongoDB {
 	clientOpts := options.Client().ApplyURI(storagePath)
 	client, err := mongo.Connect(context.Background(), clientOpts)
 	if err != nil {
 		panic(err)
 	}
 
-	db := client.Database(dbName)
+	db := client.Database(storageCfg.StorageName)
 
 	return &MongoDB{
-		client:      client,
-		database:    db,
-		chatsCol:    db.Collection(chatsColName),
-		channelsCol: db.Collection(channelsColName),
-		messagesCol: db.Collection(messagesColName),
+		client:         client,
+		database:       db,
+		chatsCol:       db.Collection(storageCfg.ChatsColName),
+		channelsCol:    db.Collection(storageCfg.ChannelsColName),
+		messagesCol:    db.Collection(storageCfg.MessagesColName),
+		updatesCol:     db.Collection(storageCfg.UpdatesColName),
+		countersCol:    db.Collection(storageCfg.CountersColName),
+		invitesCol:     db.Collection(storageCfg.InvitesColName),
+		joinsCol:       db.Collection(storageCfg.JoinsColName),
+		postsCol:       db.Collection(storageCfg.PostsColName),
+		communitiesCol: db.Collection(storageCfg.CommunitiesColName),
+		webhooksCol:    db.Collection(storageCfg.WebhooksColName),
+		migrationsCol:  db.Collection(storageCfg.MigrationsColName),
+
+		notificationsCol: db.Collection(storageCfg.NotificationsColName),
+
+		transactions: supportsTransactions(context.Background(), client),
 	}
 }
 
@@ -0,0 +1,64 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"chat-service/internal/config"
+	"chat-service/internal/infrastructure/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestMongoDB(t)
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	m := newTestMongoDB(t)
+	storagetest.RunNotifier(t, m.Notifier("test"))
+}
+
+// newTestMongoDB connects to MONGO_TEST_URI and uses a fresh database dropped after the test,
+// tests are skipped when the variable is not set
+func newTestMongoDB(t *testing.T) *MongoDB {
+	t.Helper()
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	m := New(uri, config.YamlStorage{
+		StorageName:        fmt.Sprintf("chat_service_test_%d", time.Now().UnixNano()),
+		ChatsColName:       "chats",
+		ChannelsColName:    "channels",
+		MessagesColName:    "messages",
+		UpdatesColName:     "updates",
+		CountersColName:    "counters",
+		InvitesColName:     "invites",
+		JoinsColName:       "joins",
+		PostsColName:       "posts",
+		CommunitiesColName: "communities",
+		WebhooksColName:    "webhooks",
+		MigrationsColName:  "migrations",
+
+		NotificationsColName: "notifications",
+	})
+	t.Cleanup(func() {
+		m.database.Drop(context.Background())
+		m.Close()
+	})
+	if !m.Transactional() {
+		t.Skip("MONGO_TEST_URI must point to a replica set")
+	}
+
+	if _, err := m.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("MigrateUp() error = %v", err)
+	}
+
+	return m
+}
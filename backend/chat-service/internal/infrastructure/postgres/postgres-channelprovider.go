@@ -0,0 +1,483 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+// selectChannels reads channels with the newest message of each as their last message
+const selectChannels = `
+SELECT ch.id, ch.chat_id, ch.name, ch.type, ch.message_count, ch.topic, ch.description, ch.position, ch.category,
+	ch.archived, ch.private, ch.allowed_roles, ch.mode, ch.poster_ids, ch.tags, ch.retention_max_age, ch.retention_legal_hold,
+	lm.id, lm.sender_id, lm.text, lm.type, lm.created_at
+FROM channels ch
+LEFT JOIN LATERAL (
+	SELECT id, sender_id, text, type, created_at FROM messages
+	WHERE channel_id = ch.id
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1
+) lm ON TRUE`
+
+func (p *Postgres) SaveChannel(ctx context.Context, channel domain.Channel) (channelID string, err error) {
+	err = p.WithinTransaction(ctx, func(ctx context.Context) error {
+		channelID, err = p.saveChannel(ctx, channel)
+		return err
+	})
+	return channelID, err
+}
+
+func (p *Postgres) saveChannel(ctx context.Context, channel domain.Channel) (string, error) {
+	const op = "infrastructure.postgres.channel.SaveChannel"
+
+	chatID, err := parseID(channel.ChatID, domain.ErrChatNotFound)
+	if err != nil {
+		return "", err
+	}
+
+	q := p.conn(ctx)
+
+	var id int64
+	err = q.QueryRow(ctx, `
+INSERT INTO channels (chat_id, name, type, message_count, topic, description, position, category, private, allowed_roles, mode, poster_ids)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id`,
+		chatID, channel.Name, channel.Type, channel.MessageCount, channel.Topic, channel.Description, channel.Position,
+		channel.Category, channel.Private, textArray(channel.AllowedRoles), channel.Mode, textArray(channel.PosterIDs),
+	).Scan(&id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return "", domain.ErrChatNotFound
+		}
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	if err := addChannelMembers(ctx, q, id, channel.MemberIDs); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) FindChannelByID(ctx context.Context, channelID string) (domain.Channel, error) {
+	const op = "infrastructure.postgres.channel.FindChannelByID"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return domain.Channel{}, err
+	}
+
+	channels, err := p.findChannels(ctx, " WHERE ch.id = $1", id)
+	if err != nil {
+		return domain.Channel{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(channels) == 0 {
+		return domain.Channel{}, domain.ErrChannelNotFound
+	}
+
+	return channels[0], nil
+}
+
+func (p *Postgres) FindChannelsByIDs(ctx context.Context, channelIDs []string) ([]domain.Channel, error) {
+	const op = "infrastructure.postgres.channel.FindChannelsByIDs"
+
+	ids, err := parseIDs(channelIDs, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	channels, err := p.findChannels(ctx, " WHERE ch.id = ANY($1) ORDER BY ch.id", ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return channels, nil
+}
+
+// FindChatChannels lists channels of the chat in creation order
+func (p *Postgres) FindChatChannels(ctx context.Context, chatID string) ([]domain.Channel, error) {
+	const op = "infrastructure.postgres.channel.FindChatChannels"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	channels, err := p.findChannels(ctx, " WHERE ch.chat_id = $1 ORDER BY ch.id", id)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+// SetChannelPermissions replaces permission overrides of the channel
+func (p *Postgres) SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.setChannelPermissions(ctx, channelID, overrides)
+	})
+}
+
+func (p *Postgres) setChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error {
+	const op = "infrastructure.postgres.channel.SetChannelPermissions"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "channels", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	if _, err := q.Exec(ctx, "DELETE FROM channel_permission_overrides WHERE channel_id = $1", id); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	for position, override := range overrides {
+		_, err := q.Exec(ctx, `
+INSERT INTO channel_permission_overrides (channel_id, position, role, user_id, allow, deny)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+			id, position, override.Role, override.UserID, int64(override.Allow), int64(override.Deny))
+		if err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Postgres) UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) error {
+	const op = "infrastructure.postgres.channel.UpdateChannel"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	var set updateSet
+	if patch.Name != nil {
+		set.add("name", *patch.Name)
+	}
+	if patch.Topic != nil {
+		set.add("topic", *patch.Topic)
+	}
+	if patch.Description != nil {
+		set.add("description", *patch.Description)
+	}
+	if patch.Position != nil {
+		set.add("position", *patch.Position)
+	}
+	if patch.Category != nil {
+		set.add("category", *patch.Category)
+	}
+	if patch.Archived != nil {
+		set.add("archived", *patch.Archived)
+	}
+	if patch.Mode != nil {
+		set.add("mode", *patch.Mode)
+	}
+	if patch.PosterIDs != nil {
+		set.add("poster_ids", textArray(*patch.PosterIDs))
+	}
+	if set.empty() {
+		return nil
+	}
+
+	found, err := set.exec(ctx, p.conn(ctx), "channels", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+// DeleteChannel removes the channel, its members and messages go with it
+func (p *Postgres) DeleteChannel(ctx context.Context, channelID string) error {
+	const op = "infrastructure.postgres.channel.DeleteChannel"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, "DELETE FROM channels WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteChatChannels(ctx context.Context, chatID string) error {
+	const op = "infrastructure.postgres.channel.DeleteChatChannels"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	if _, err := p.conn(ctx).Exec(ctx, "DELETE FROM channels WHERE chat_id = $1", id); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) error {
+	const op = "infrastructure.postgres.channel.SetChannelAccess"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	var set updateSet
+	set.add("private", private)
+	set.add("allowed_roles", textArray(allowedRoles))
+
+	found, err := set.exec(ctx, p.conn(ctx), "channels", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) AddChannelMembers(ctx context.Context, channelID string, userIDs []string) error {
+	const op = "infrastructure.postgres.channel.AddChannelMembers"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := addChannelMembers(ctx, p.conn(ctx), id, userIDs); err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrChannelNotFound
+		}
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) RemoveChannelMember(ctx context.Context, channelID string, userID string) error {
+	const op = "infrastructure.postgres.channel.RemoveChannelMember"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "channels", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	if _, err := q.Exec(ctx, "DELETE FROM channel_members WHERE channel_id = $1 AND user_id = $2", id, userID); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) SetChannelTags(ctx context.Context, channelID string, tags []string) error {
+	const op = "infrastructure.postgres.channel.SetChannelTags"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	var set updateSet
+	set.add("tags", textArray(tags))
+
+	found, err := set.exec(ctx, p.conn(ctx), "channels", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+// SetChannelRetention sets the retention policy of the channel, nil removes it
+func (p *Postgres) SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error {
+	const op = "infrastructure.postgres.channel.SetChannelRetention"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return err
+	}
+
+	maxAge, legalHold := retentionColumns(policy)
+
+	var set updateSet
+	set.add("retention_max_age", maxAge)
+	set.add("retention_legal_hold", legalHold)
+
+	found, err := set.exec(ctx, p.conn(ctx), "channels", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChannelNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) FindChannelsPage(ctx context.Context, afterID string, limit int32) ([]domain.Channel, error) {
+	const op = "infrastructure.postgres.channel.FindChannelsPage"
+
+	var after int64
+	if afterID != "" {
+		var err error
+		if after, err = parseID(afterID, domain.ErrChannelNotFound); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	channels, err := p.findChannels(ctx, " WHERE ch.id > $1 ORDER BY ch.id LIMIT $2", after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return channels, nil
+}
+
+// findChannels loads channels matching the clause together with their members and permission overrides
+func (p *Postgres) findChannels(ctx context.Context, clause string, args ...any) ([]domain.Channel, error) {
+	q := p.conn(ctx)
+
+	rows, err := q.Query(ctx, selectChannels+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []domain.Channel
+	var ids []int64
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id, chatID int64
+		var channel domain.Channel
+		var maxAge *int64
+		var legalHold *bool
+		var lastID *int64
+		var lastSenderID, lastText, lastType *string
+		var lastCreatedAt *time.Time
+		err := rows.Scan(
+			&id, &chatID, &channel.Name, &channel.Type, &channel.MessageCount, &channel.Topic, &channel.Description,
+			&channel.Position, &channel.Category, &channel.Archived, &channel.Private, &channel.AllowedRoles, &channel.Mode,
+			&channel.PosterIDs, &channel.Tags, &maxAge, &legalHold,
+			&lastID, &lastSenderID, &lastText, &lastType, &lastCreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		channel.ID = formatID(id)
+		channel.ChatID = formatID(chatID)
+		channel.Retention = retentionPolicy(maxAge, legalHold)
+		if lastID != nil {
+			last := domain.NewLastMessage(domain.Message{
+				ID:        formatID(*lastID),
+				SenderID:  *lastSenderID,
+				Text:      *lastText,
+				Type:      *lastType,
+				CreatedAt: *lastCreatedAt,
+			})
+			channel.LastMessage = &last
+		}
+
+		index[id] = len(channels)
+		ids = append(ids, id)
+		channels = append(channels, channel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+
+	members, err := q.Query(ctx, "SELECT channel_id, user_id FROM channel_members WHERE channel_id = ANY($1) ORDER BY seq", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer members.Close()
+	for members.Next() {
+		var channelID int64
+		var userID string
+		if err := members.Scan(&channelID, &userID); err != nil {
+			return nil, err
+		}
+		channel := &channels[index[channelID]]
+		channel.MemberIDs = append(channel.MemberIDs, userID)
+	}
+	if err := members.Err(); err != nil {
+		return nil, err
+	}
+
+	overrides, err := q.Query(ctx, `
+SELECT channel_id, role, user_id, allow, deny FROM channel_permission_overrides
+WHERE channel_id = ANY($1) ORDER BY channel_id, position`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer overrides.Close()
+	for overrides.Next() {
+		var channelID, allow, deny int64
+		var override domain.PermissionOverride
+		if err := overrides.Scan(&channelID, &override.Role, &override.UserID, &allow, &deny); err != nil {
+			return nil, err
+		}
+		override.Allow = domain.Permission(allow)
+		override.Deny = domain.Permission(deny)
+
+		channel := &channels[index[channelID]]
+		channel.PermissionOverrides = append(channel.PermissionOverrides, override)
+	}
+	if err := overrides.Err(); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// addChannelMembers adds users to the channel keeping their order, existing members are skipped
+func addChannelMembers(ctx context.Context, q querier, channelID int64, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	_, err := q.Exec(ctx, `
+INSERT INTO channel_members (channel_id, user_id)
+SELECT $1, user_id FROM unnest($2::text[]) WITH ORDINALITY AS t (user_id, ord)
+ORDER BY ord
+ON CONFLICT DO NOTHING`, channelID, userIDs)
+	return err
+}
@@ -0,0 +1,488 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"chat-service/internal/domain"
+)
+
+const selectChats = `
+SELECT id, type, name, topic, description, archived, COALESCE(community_id, ''), retention_max_age, retention_legal_hold
+FROM chats`
+
+// FindChat returns a chat with all the users as members, nil when there is none
+func (p *Postgres) FindChat(ctx context.Context, userIDs []string) (*domain.Chat, error) {
+	const op = "infrastructure.postgres.chat.FindChat"
+
+	where := ` WHERE id = (
+	SELECT chat_id FROM chat_members WHERE user_id = ANY($1)
+	GROUP BY chat_id HAVING count(*) = cardinality(ARRAY(SELECT DISTINCT unnest($1::text[])))
+	ORDER BY chat_id LIMIT 1
+)`
+	chats, err := p.findChats(ctx, where, textArray(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(chats) == 0 {
+		return nil, nil
+	}
+
+	return &chats[0], nil
+}
+
+func (p *Postgres) FindChatByID(ctx context.Context, chatID string, userID string) (domain.Chat, error) {
+	const op = "infrastructure.postgres.chat.FindChatByID"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return domain.Chat{}, err
+	}
+
+	chats, err := p.findChats(ctx, " WHERE id = $1", id)
+	if err != nil {
+		return domain.Chat{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(chats) == 0 {
+		return domain.Chat{}, domain.ErrChatNotFound
+	}
+
+	chat := chats[0]
+	if chat.Type == "private" {
+		var notUserID string
+		for _, id := range chat.MemberIDs {
+			if id != userID {
+				notUserID = id
+				break
+			}
+		}
+		chat.Name = notUserID
+	}
+
+	return chat, nil
+}
+
+// FindUserChats lists chats of the community when communityID is set, otherwise chats outside communities
+func (p *Postgres) FindUserChats(ctx context.Context, userID string, chatType string, communityID string, includeArchived bool) ([]*domain.ChatPreview, error) {
+	const op = "infrastructure.postgres.chat.FindUserChats"
+
+	sql := `
+SELECT c.id, c.name, c.archived, COALESCE(c.community_id, ''),
+	ARRAY(SELECT user_id FROM chat_members WHERE chat_id = c.id ORDER BY seq)
+FROM chats c
+JOIN chat_members m ON m.chat_id = c.id AND m.user_id = $1
+WHERE c.type = $2 AND c.community_id IS NOT DISTINCT FROM $3::text`
+	if !includeArchived {
+		sql += " AND NOT c.archived AND NOT m.hidden"
+	}
+	sql += " ORDER BY c.id"
+
+	rows, err := p.conn(ctx).Query(ctx, sql, userID, chatType, nullString(communityID))
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var previews []*domain.ChatPreview
+	for rows.Next() {
+		var id int64
+		var chat domain.ChatPreview
+		var memberIDs []string
+		if err := rows.Scan(&id, &chat.Name, &chat.Archived, &chat.CommunityID, &memberIDs); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		chat.ID = formatID(id)
+
+		if chatType == "private" {
+			for _, memberID := range memberIDs {
+				if memberID != userID {
+					chat.PeerID = memberID
+					break
+				}
+			}
+			chat.Name = chat.PeerID
+		}
+
+		previews = append(previews, &chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return previews, nil
+}
+
+func (p *Postgres) SaveChat(ctx context.Context, chat domain.Chat) (chatID string, err error) {
+	err = p.WithinTransaction(ctx, func(ctx context.Context) error {
+		chatID, err = p.saveChat(ctx, chat)
+		return err
+	})
+	return chatID, err
+}
+
+func (p *Postgres) saveChat(ctx context.Context, chat domain.Chat) (string, error) {
+	const op = "infrastructure.postgres.chat.SaveChat"
+
+	q := p.conn(ctx)
+
+	var id int64
+	err := q.QueryRow(ctx, `
+INSERT INTO chats (type, name, topic, description, community_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id`,
+		chat.Type, chat.Name, chat.Topic, chat.Description, nullString(chat.CommunityID),
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	if err := addChatMembers(ctx, q, id, chat.MemberIDs); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+	if err := setChatRoles(ctx, q, id, chat.Roles); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) AddChatMembers(ctx context.Context, chatID string, userIDs []string) error {
+	const op = "infrastructure.postgres.chat.AddChatMembers"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	if err := addChatMembers(ctx, p.conn(ctx), id, userIDs); err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrChatNotFound
+		}
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) RemoveChatMember(ctx context.Context, chatID string, userID string) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.removeChatMember(ctx, chatID, userID)
+	})
+}
+
+func (p *Postgres) removeChatMember(ctx context.Context, chatID string, userID string) error {
+	const op = "infrastructure.postgres.chat.RemoveChatMember"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "chats", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	if _, err := q.Exec(ctx, "DELETE FROM chat_members WHERE chat_id = $1 AND user_id = $2", id, userID); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if _, err := q.Exec(ctx, "DELETE FROM chat_roles WHERE chat_id = $1 AND user_id = $2", id, userID); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	// the member also loses access to private channels of the chat
+	_, err = q.Exec(ctx, `
+DELETE FROM channel_members
+WHERE user_id = $2 AND channel_id IN (SELECT id FROM channels WHERE chat_id = $1)`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// SetChatRoles sets roles of the given members, RoleMember removes the entry
+func (p *Postgres) SetChatRoles(ctx context.Context, chatID string, roles map[string]string) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.setChatRoles(ctx, chatID, roles)
+	})
+}
+
+func (p *Postgres) setChatRoles(ctx context.Context, chatID string, roles map[string]string) error {
+	const op = "infrastructure.postgres.chat.SetChatRoles"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "chats", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	if err := setChatRoles(ctx, q, id, roles); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) error {
+	const op = "infrastructure.postgres.chat.UpdateChat"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	var set updateSet
+	if patch.Name != nil {
+		set.add("name", *patch.Name)
+	}
+	if patch.Topic != nil {
+		set.add("topic", *patch.Topic)
+	}
+	if patch.Description != nil {
+		set.add("description", *patch.Description)
+	}
+	if patch.Archived != nil {
+		set.add("archived", *patch.Archived)
+	}
+	if set.empty() {
+		return nil
+	}
+
+	found, err := set.exec(ctx, p.conn(ctx), "chats", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// SetChatHidden hides or shows the chat in the user's chat list
+func (p *Postgres) SetChatHidden(ctx context.Context, chatID string, userID string, hidden bool) error {
+	const op = "infrastructure.postgres.chat.SetChatHidden"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "chats", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	if _, err := q.Exec(ctx, "UPDATE chat_members SET hidden = $3 WHERE chat_id = $1 AND user_id = $2", id, userID, hidden); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// UnhideChat shows the chat to every member again, used when a new message arrives
+func (p *Postgres) UnhideChat(ctx context.Context, chatID string) error {
+	const op = "infrastructure.postgres.chat.UnhideChat"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	if _, err := p.conn(ctx).Exec(ctx, "UPDATE chat_members SET hidden = FALSE WHERE chat_id = $1 AND hidden", id); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteChat deletes the chat, its members, roles and whatever channels are left
+func (p *Postgres) DeleteChat(ctx context.Context, chatID string) error {
+	const op = "infrastructure.postgres.chat.DeleteChat"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, "DELETE FROM chats WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+// SetChatRetention sets the retention policy of the chat, nil removes it
+func (p *Postgres) SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error {
+	const op = "infrastructure.postgres.chat.SetChatRetention"
+
+	id, err := parseID(chatID, domain.ErrChatNotFound)
+	if err != nil {
+		return err
+	}
+
+	maxAge, legalHold := retentionColumns(policy)
+
+	var set updateSet
+	set.add("retention_max_age", maxAge)
+	set.add("retention_legal_hold", legalHold)
+
+	found, err := set.exec(ctx, p.conn(ctx), "chats", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrChatNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) FindChatsByIDs(ctx context.Context, chatIDs []string) ([]domain.Chat, error) {
+	const op = "infrastructure.postgres.chat.FindChatsByIDs"
+
+	ids, err := parseIDs(chatIDs, domain.ErrChatNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	chats, err := p.findChats(ctx, " WHERE id = ANY($1)", ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return chats, nil
+}
+
+// findChats loads chats matching the WHERE clause together with their members and roles
+func (p *Postgres) findChats(ctx context.Context, where string, args ...any) ([]domain.Chat, error) {
+	q := p.conn(ctx)
+
+	rows, err := q.Query(ctx, selectChats+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chats []domain.Chat
+	var ids []int64
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var chat domain.Chat
+		var maxAge *int64
+		var legalHold *bool
+		if err := rows.Scan(&id, &chat.Type, &chat.Name, &chat.Topic, &chat.Description, &chat.Archived, &chat.CommunityID, &maxAge, &legalHold); err != nil {
+			return nil, err
+		}
+		chat.ID = formatID(id)
+		chat.MemberIDs = []string{}
+		chat.Retention = retentionPolicy(maxAge, legalHold)
+
+		index[id] = len(chats)
+		ids = append(ids, id)
+		chats = append(chats, chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(chats) == 0 {
+		return nil, nil
+	}
+
+	members, err := q.Query(ctx, "SELECT chat_id, user_id, hidden FROM chat_members WHERE chat_id = ANY($1) ORDER BY seq", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer members.Close()
+	for members.Next() {
+		var chatID int64
+		var userID string
+		var hidden bool
+		if err := members.Scan(&chatID, &userID, &hidden); err != nil {
+			return nil, err
+		}
+		chat := &chats[index[chatID]]
+		chat.MemberIDs = append(chat.MemberIDs, userID)
+		if hidden {
+			chat.HiddenFor = append(chat.HiddenFor, userID)
+		}
+	}
+	if err := members.Err(); err != nil {
+		return nil, err
+	}
+
+	roles, err := q.Query(ctx, "SELECT chat_id, user_id, role FROM chat_roles WHERE chat_id = ANY($1)", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer roles.Close()
+	for roles.Next() {
+		var chatID int64
+		var userID, role string
+		if err := roles.Scan(&chatID, &userID, &role); err != nil {
+			return nil, err
+		}
+		chat := &chats[index[chatID]]
+		if chat.Roles == nil {
+			chat.Roles = make(map[string]string)
+		}
+		chat.Roles[userID] = role
+	}
+	if err := roles.Err(); err != nil {
+		return nil, err
+	}
+
+	return chats, nil
+}
+
+// addChatMembers adds users to the chat keeping their order, existing members are skipped
+func addChatMembers(ctx context.Context, q querier, chatID int64, userIDs []string) error {
+	_, err := q.Exec(ctx, `
+INSERT INTO chat_members (chat_id, user_id)
+SELECT $1, user_id FROM unnest($2::text[]) WITH ORDINALITY AS t (user_id, ord)
+ORDER BY ord
+ON CONFLICT DO NOTHING`, chatID, textArray(userIDs))
+	return err
+}
+
+// setChatRoles sets roles of the given members, RoleMember removes the entry
+func setChatRoles(ctx context.Context, q querier, chatID int64, roles map[string]string) error {
+	for userID, role := range roles {
+		if role == domain.RoleMember {
+			if _, err := q.Exec(ctx, "DELETE FROM chat_roles WHERE chat_id = $1 AND user_id = $2", chatID, userID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err := q.Exec(ctx, `
+INSERT INTO chat_roles (chat_id, user_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (chat_id, user_id) DO UPDATE SET role = EXCLUDED.role`, chatID, userID, role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"chat-service/internal/domain"
+)
+
+func (p *Postgres) SaveCommunity(ctx context.Context, community domain.Community) (communityID string, err error) {
+	err = p.WithinTransaction(ctx, func(ctx context.Context) error {
+		communityID, err = p.saveCommunity(ctx, community)
+		return err
+	})
+	return communityID, err
+}
+
+func (p *Postgres) saveCommunity(ctx context.Context, community domain.Community) (string, error) {
+	const op = "infrastructure.postgres.community.SaveCommunity"
+
+	q := p.conn(ctx)
+
+	var id int64
+	err := q.QueryRow(ctx, `
+INSERT INTO communities (name, description, default_chat_id)
+VALUES ($1, $2, $3)
+RETURNING id`,
+		community.Name, community.Description, community.DefaultChatID,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	if err := addCommunityRows(ctx, q, "community_members", "user_id", id, community.MemberIDs); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+	if err := addCommunityRows(ctx, q, "community_chats", "chat_id", id, community.ChatIDs); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+	if err := setCommunityRoles(ctx, q, id, community.Roles); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) FindCommunityByID(ctx context.Context, communityID string) (domain.Community, error) {
+	const op = "infrastructure.postgres.community.FindCommunityByID"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return domain.Community{}, err
+	}
+
+	communities, err := p.findCommunities(ctx, " WHERE id = $1", id)
+	if err != nil {
+		return domain.Community{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(communities) == 0 {
+		return domain.Community{}, domain.ErrCommunityNotFound
+	}
+
+	return communities[0], nil
+}
+
+func (p *Postgres) FindUserCommunities(ctx context.Context, userID string) ([]*domain.Community, error) {
+	const op = "infrastructure.postgres.community.FindUserCommunities"
+
+	communities, err := p.findCommunities(ctx, " WHERE id IN (SELECT community_id FROM community_members WHERE user_id = $1)", userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var result []*domain.Community
+	for i := range communities {
+		result = append(result, &communities[i])
+	}
+
+	return result, nil
+}
+
+func (p *Postgres) UpdateCommunity(ctx context.Context, communityID string, patch domain.CommunityPatch) error {
+	const op = "infrastructure.postgres.community.UpdateCommunity"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return err
+	}
+
+	var set updateSet
+	if patch.Name != nil {
+		set.add("name", *patch.Name)
+	}
+	if patch.Description != nil {
+		set.add("description", *patch.Description)
+	}
+	if patch.DefaultChatID != nil {
+		set.add("default_chat_id", *patch.DefaultChatID)
+	}
+	if set.empty() {
+		return nil
+	}
+
+	found, err := set.exec(ctx, p.conn(ctx), "communities", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) AddCommunityChat(ctx context.Context, communityID string, chatID string) error {
+	const op = "infrastructure.postgres.community.AddCommunityChat"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "communities", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	if err := addCommunityRows(ctx, q, "community_chats", "chat_id", id, []string{chatID}); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) RemoveCommunityChat(ctx context.Context, communityID string, chatID string) error {
+	const op = "infrastructure.postgres.community.RemoveCommunityChat"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "communities", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	if _, err := q.Exec(ctx, "DELETE FROM community_chats WHERE community_id = $1 AND chat_id = $2", id, chatID); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// AddCommunityMembers adds the users to the community and to every community chat
+func (p *Postgres) AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.addCommunityMembers(ctx, communityID, userIDs)
+	})
+}
+
+func (p *Postgres) addCommunityMembers(ctx context.Context, communityID string, userIDs []string) error {
+	const op = "infrastructure.postgres.community.AddCommunityMembers"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "communities", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	if err := addCommunityRows(ctx, q, "community_members", "user_id", id, userIDs); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	_, err = q.Exec(ctx, `
+INSERT INTO chat_members (chat_id, user_id)
+SELECT c.id, t.user_id FROM chats c, unnest($2::text[]) WITH ORDINALITY AS t (user_id, ord)
+WHERE c.community_id = $1
+ORDER BY c.id, t.ord
+ON CONFLICT DO NOTHING`, communityID, textArray(userIDs))
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveCommunityMember removes the user with their roles from the community,
+// every community chat and private channels of these chats
+func (p *Postgres) RemoveCommunityMember(ctx context.Context, communityID string, userID string) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.removeCommunityMember(ctx, communityID, userID)
+	})
+}
+
+func (p *Postgres) removeCommunityMember(ctx context.Context, communityID string, userID string) error {
+	const op = "infrastructure.postgres.community.RemoveCommunityMember"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "communities", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	for _, sql := range []string{
+		"DELETE FROM community_members WHERE community_id = $1 AND user_id = $2",
+		"DELETE FROM community_roles WHERE community_id = $1 AND user_id = $2",
+	} {
+		if _, err := q.Exec(ctx, sql, id, userID); err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	// chats keep the community id as text
+	for _, sql := range []string{
+		"DELETE FROM chat_members WHERE user_id = $2 AND chat_id IN (SELECT id FROM chats WHERE community_id = $1)",
+		"DELETE FROM chat_roles WHERE user_id = $2 AND chat_id IN (SELECT id FROM chats WHERE community_id = $1)",
+		`DELETE FROM channel_members WHERE user_id = $2 AND channel_id IN (
+	SELECT ch.id FROM channels ch JOIN chats c ON c.id = ch.chat_id WHERE c.community_id = $1
+)`,
+	} {
+		if _, err := q.Exec(ctx, sql, communityID, userID); err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// SetCommunityRoles sets roles of the given members in the community and every community chat
+func (p *Postgres) SetCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.setCommunityRoles(ctx, communityID, roles)
+	})
+}
+
+func (p *Postgres) setCommunityRoles(ctx context.Context, communityID string, roles map[string]string) error {
+	const op = "infrastructure.postgres.community.SetCommunityRoles"
+
+	id, err := parseID(communityID, domain.ErrCommunityNotFound)
+	if err != nil {
+		return err
+	}
+
+	q := p.conn(ctx)
+	found, err := exists(ctx, q, "communities", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.ErrCommunityNotFound
+	}
+
+	if err := setCommunityRoles(ctx, q, id, roles); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	for userID, role := range roles {
+		if role == domain.RoleMember {
+			_, err = q.Exec(ctx, `
+DELETE FROM chat_roles WHERE user_id = $2 AND chat_id IN (SELECT id FROM chats WHERE community_id = $1)`, communityID, userID)
+		} else {
+			_, err = q.Exec(ctx, `
+INSERT INTO chat_roles (chat_id, user_id, role)
+SELECT id, $2, $3 FROM chats WHERE community_id = $1
+ON CONFLICT (chat_id, user_id) DO UPDATE SET role = EXCLUDED.role`, communityID, userID, role)
+		}
+		if err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// findCommunities loads communities matching the WHERE clause together with their members, chats and roles
+func (p *Postgres) findCommunities(ctx context.Context, where string, args ...any) ([]domain.Community, error) {
+	q := p.conn(ctx)
+
+	rows, err := q.Query(ctx, "SELECT id, name, description, default_chat_id FROM communities"+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var communities []domain.Community
+	var ids []int64
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var community domain.Community
+		if err := rows.Scan(&id, &community.Name, &community.Description, &community.DefaultChatID); err != nil {
+			return nil, err
+		}
+		community.ID = formatID(id)
+		community.MemberIDs = []string{}
+		community.ChatIDs = []string{}
+
+		index[id] = len(communities)
+		ids = append(ids, id)
+		communities = append(communities, community)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(communities) == 0 {
+		return nil, nil
+	}
+
+	members, err := q.Query(ctx, "SELECT community_id, user_id FROM community_members WHERE community_id = ANY($1) ORDER BY seq", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer members.Close()
+	for members.Next() {
+		var communityID int64
+		var userID string
+		if err := members.Scan(&communityID, &userID); err != nil {
+			return nil, err
+		}
+		community := &communities[index[communityID]]
+		community.MemberIDs = append(community.MemberIDs, userID)
+	}
+	if err := members.Err(); err != nil {
+		return nil, err
+	}
+
+	chats, err := q.Query(ctx, "SELECT community_id, chat_id FROM community_chats WHERE community_id = ANY($1) ORDER BY seq", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer chats.Close()
+	for chats.Next() {
+		var communityID int64
+		var chatID string
+		if err := chats.Scan(&communityID, &chatID); err != nil {
+			return nil, err
+		}
+		community := &communities[index[communityID]]
+		community.ChatIDs = append(community.ChatIDs, chatID)
+	}
+	if err := chats.Err(); err != nil {
+		return nil, err
+	}
+
+	roles, err := q.Query(ctx, "SELECT community_id, user_id, role FROM community_roles WHERE community_id = ANY($1)", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer roles.Close()
+	for roles.Next() {
+		var communityID int64
+		var userID, role string
+		if err := roles.Scan(&communityID, &userID, &role); err != nil {
+			return nil, err
+		}
+		community := &communities[index[communityID]]
+		if community.Roles == nil {
+			community.Roles = make(map[string]string)
+		}
+		community.Roles[userID] = role
+	}
+	if err := roles.Err(); err != nil {
+		return nil, err
+	}
+
+	return communities, nil
+}
+
+// addCommunityRows adds members or chats to the community keeping their order, existing rows are skipped
+func addCommunityRows(ctx context.Context, q querier, table string, column string, communityID int64, values []string) error {
+	_, err := q.Exec(ctx, fmt.Sprintf(`
+INSERT INTO %s (community_id, %s)
+SELECT $1, value FROM unnest($2::text[]) WITH ORDINALITY AS t (value, ord)
+ORDER BY ord
+ON CONFLICT DO NOTHING`, table, column), communityID, textArray(values))
+	return err
+}
+
+// setCommunityRoles sets roles of the given members of the community, RoleMember removes the entry
+func setCommunityRoles(ctx context.Context, q querier, communityID int64, roles map[string]string) error {
+	for userID, role := range roles {
+		if role == domain.RoleMember {
+			if _, err := q.Exec(ctx, "DELETE FROM community_roles WHERE community_id = $1 AND user_id = $2", communityID, userID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err := q.Exec(ctx, `
+INSERT INTO community_roles (community_id, user_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (community_id, user_id) DO UPDATE SET role = EXCLUDED.role`, communityID, userID, role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
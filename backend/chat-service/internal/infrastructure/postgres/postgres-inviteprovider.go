@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const selectInvites = `
+SELECT id, chat_id, code, creator_id, expires_at, max_uses, uses, requires_approval, revoked, created_at
+FROM invites`
+
+const selectJoinRecords = `
+SELECT id, chat_id, user_id, invite_id, invite_code, status, reviewer_id, created_at
+FROM join_records`
+
+func (p *Postgres) SaveInvite(ctx context.Context, invite domain.Invite) (string, error) {
+	const op = "infrastructure.postgres.invite.SaveInvite"
+
+	var id int64
+	err := p.conn(ctx).QueryRow(ctx, `
+INSERT INTO invites (chat_id, code, creator_id, expires_at, max_uses, uses, requires_approval, revoked, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id`,
+		invite.ChatID, invite.Code, invite.CreatorID, invite.ExpiresAt, invite.MaxUses, invite.Uses,
+		invite.RequiresApproval, invite.Revoked, invite.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) FindInviteByID(ctx context.Context, inviteID string) (domain.Invite, error) {
+	const op = "infrastructure.postgres.invite.FindInviteByID"
+
+	id, err := parseID(inviteID, domain.ErrInviteNotFound)
+	if err != nil {
+		return domain.Invite{}, err
+	}
+
+	invite, err := scanInvite(p.conn(ctx).QueryRow(ctx, selectInvites+" WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Invite{}, domain.ErrInviteNotFound
+		}
+		return domain.Invite{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return invite, nil
+}
+
+func (p *Postgres) FindInviteByCode(ctx context.Context, code string) (domain.Invite, error) {
+	const op = "infrastructure.postgres.invite.FindInviteByCode"
+
+	invite, err := scanInvite(p.conn(ctx).QueryRow(ctx, selectInvites+" WHERE code = $1", code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Invite{}, domain.ErrInviteNotFound
+		}
+		return domain.Invite{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return invite, nil
+}
+
+func (p *Postgres) FindChatInvites(ctx context.Context, chatID string) ([]*domain.Invite, error) {
+	const op = "infrastructure.postgres.invite.FindChatInvites"
+
+	rows, err := p.conn(ctx).Query(ctx, selectInvites+" WHERE chat_id = $1 ORDER BY created_at DESC, id DESC", chatID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var invites []*domain.Invite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		invites = append(invites, &invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return invites, nil
+}
+
+// UseInvite increments uses of the invite only if it is still usable,
+// so concurrent joins cannot exceed max_uses
+func (p *Postgres) UseInvite(ctx context.Context, inviteID string, now time.Time) error {
+	const op = "infrastructure.postgres.invite.UseInvite"
+
+	id, err := parseID(inviteID, domain.ErrInviteNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, `
+UPDATE invites SET uses = uses + 1
+WHERE id = $1 AND NOT revoked AND (expires_at IS NULL OR expires_at > $2) AND (max_uses <= 0 OR uses < max_uses)`, id, now)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidInvite
+	}
+
+	return nil
+}
+
+func (p *Postgres) RevokeInvite(ctx context.Context, inviteID string) error {
+	const op = "infrastructure.postgres.invite.RevokeInvite"
+
+	id, err := parseID(inviteID, domain.ErrInviteNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, "UPDATE invites SET revoked = TRUE WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+// DeleteChatInvites removes invites and join records of the chat
+func (p *Postgres) DeleteChatInvites(ctx context.Context, chatID string) error {
+	return p.WithinTransaction(ctx, func(ctx context.Context) error {
+		return p.deleteChatInvites(ctx, chatID)
+	})
+}
+
+func (p *Postgres) deleteChatInvites(ctx context.Context, chatID string) error {
+	const op = "infrastructure.postgres.invite.DeleteChatInvites"
+
+	q := p.conn(ctx)
+	for _, sql := range []string{
+		"DELETE FROM invites WHERE chat_id = $1",
+		"DELETE FROM join_records WHERE chat_id = $1",
+	} {
+		if _, err := q.Exec(ctx, sql, chatID); err != nil {
+			return fmt.Errorf("%s : %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Postgres) SaveJoinRecord(ctx context.Context, record domain.JoinRecord) (string, error) {
+	const op = "infrastructure.postgres.invite.SaveJoinRecord"
+
+	var id int64
+	err := p.conn(ctx).QueryRow(ctx, `
+INSERT INTO join_records (chat_id, user_id, invite_id, invite_code, status, reviewer_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id`,
+		record.ChatID, record.UserID, record.InviteID, record.InviteCode, record.Status, record.ReviewerID, record.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) FindJoinRecordByID(ctx context.Context, recordID string) (domain.JoinRecord, error) {
+	const op = "infrastructure.postgres.invite.FindJoinRecordByID"
+
+	id, err := parseID(recordID, domain.ErrJoinReqNotFound)
+	if err != nil {
+		return domain.JoinRecord{}, err
+	}
+
+	record, err := scanJoinRecord(p.conn(ctx).QueryRow(ctx, selectJoinRecords+" WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.JoinRecord{}, domain.ErrJoinReqNotFound
+		}
+		return domain.JoinRecord{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return record, nil
+}
+
+func (p *Postgres) FindJoinRecords(ctx context.Context, chatID string, status string) ([]*domain.JoinRecord, error) {
+	const op = "infrastructure.postgres.invite.FindJoinRecords"
+
+	rows, err := p.conn(ctx).Query(ctx, selectJoinRecords+`
+WHERE chat_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY created_at DESC, id DESC`, chatID, status)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var records []*domain.JoinRecord
+	for rows.Next() {
+		record, err := scanJoinRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return records, nil
+}
+
+func (p *Postgres) FindPendingJoinRecord(ctx context.Context, chatID string, userID string) (*domain.JoinRecord, error) {
+	const op = "infrastructure.postgres.invite.FindPendingJoinRecord"
+
+	record, err := scanJoinRecord(p.conn(ctx).QueryRow(ctx, selectJoinRecords+`
+WHERE chat_id = $1 AND user_id = $2 AND status = $3
+ORDER BY id LIMIT 1`, chatID, userID, domain.JoinStatusPending))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return &record, nil
+}
+
+func (p *Postgres) SetJoinRecordStatus(ctx context.Context, recordID string, status string, reviewerID string) error {
+	const op = "infrastructure.postgres.invite.SetJoinRecordStatus"
+
+	id, err := parseID(recordID, domain.ErrJoinReqNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, "UPDATE join_records SET status = $2, reviewer_id = $3 WHERE id = $1", id, status, reviewerID)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrJoinReqNotFound
+	}
+
+	return nil
+}
+
+func scanInvite(row pgx.Row) (domain.Invite, error) {
+	var id int64
+	var invite domain.Invite
+	err := row.Scan(&id, &invite.ChatID, &invite.Code, &invite.CreatorID, &invite.ExpiresAt, &invite.MaxUses, &invite.Uses,
+		&invite.RequiresApproval, &invite.Revoked, &invite.CreatedAt)
+	if err != nil {
+		return domain.Invite{}, err
+	}
+	invite.ID = formatID(id)
+
+	return invite, nil
+}
+
+func scanJoinRecord(row pgx.Row) (domain.JoinRecord, error) {
+	var id int64
+	var record domain.JoinRecord
+	err := row.Scan(&id, &record.ChatID, &record.UserID, &record.InviteID, &record.InviteCode, &record.Status, &record.ReviewerID, &record.CreatedAt)
+	if err != nil {
+		return domain.JoinRecord{}, err
+	}
+	record.ID = formatID(id)
+
+	return record, nil
+}
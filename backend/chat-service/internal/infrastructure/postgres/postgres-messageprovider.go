@@ -0,0 +1,371 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const selectMessages = `
+SELECT id, channel_id, sender_id, text, type, COALESCE(post_id, ''), created_at,
+	call_id, call_caller_id, call_video, call_end_reason, call_duration_seconds,
+	webhook_id, webhook_name, webhook_avatar_url
+FROM messages`
+
+func (p *Postgres) SaveMessage(ctx context.Context, message domain.Message) (messageID string, err error) {
+	err = p.WithinTransaction(ctx, func(ctx context.Context) error {
+		messageID, err = p.saveMessage(ctx, message)
+		return err
+	})
+	return messageID, err
+}
+
+func (p *Postgres) saveMessage(ctx context.Context, message domain.Message) (string, error) {
+	const op = "infrastructure.postgres.message.SaveMessage"
+
+	channelID, err := parseID(message.ChannelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	q := p.conn(ctx)
+
+	var callID, callerID, endReason *string
+	var video *bool
+	var duration *int64
+	if call := message.Call; call != nil {
+		callID, callerID, endReason = &call.CallID, &call.CallerID, &call.EndReason
+		video, duration = &call.Video, &call.DurationSeconds
+	}
+
+	var webhookID, webhookName, webhookAvatarURL *string
+	if webhook := message.Webhook; webhook != nil {
+		webhookID, webhookName, webhookAvatarURL = &webhook.WebhookID, &webhook.Name, &webhook.AvatarURL
+	}
+
+	var id int64
+	err = q.QueryRow(ctx, `
+INSERT INTO messages (channel_id, sender_id, text, type, post_id, created_at, call_id, call_caller_id, call_video, call_end_reason, call_duration_seconds,
+	webhook_id, webhook_name, webhook_avatar_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id`,
+		channelID, message.SenderID, message.Text, message.Type, nullString(message.PostID), message.CreatedAt,
+		callID, callerID, video, endReason, duration,
+		webhookID, webhookName, webhookAvatarURL,
+	).Scan(&id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return "", fmt.Errorf("%s : %w", op, domain.ErrChannelNotFound)
+		}
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	if _, err := q.Exec(ctx, "UPDATE channels SET message_count = message_count + 1 WHERE id = $1", channelID); err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+// saveMessagesBatch limits the number of messages inserted in one transaction by SaveMessages
+const saveMessagesBatch = 1000
+
+func (p *Postgres) SaveMessages(ctx context.Context, channelID string, messages []domain.Message) ([]string, error) {
+	const op = "infrastructure.postgres.message.SaveMessages"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	messageIDs := make([]string, 0, len(messages))
+	for start := 0; start < len(messages); start += saveMessagesBatch {
+		end := min(start+saveMessagesBatch, len(messages))
+
+		// every batch is inserted together with its message_count increment
+		var batchIDs []string
+		err := p.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			batchIDs, err = p.saveMessagesBatch(ctx, id, messages[start:end])
+			return err
+		})
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return nil, fmt.Errorf("%s : %w", op, domain.ErrChannelNotFound)
+			}
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		messageIDs = append(messageIDs, batchIDs...)
+	}
+
+	return messageIDs, nil
+}
+
+// saveMessagesBatch takes ids from the identity sequence first, so they are returned in the order of messages
+func (p *Postgres) saveMessagesBatch(ctx context.Context, channelID int64, messages []domain.Message) ([]string, error) {
+	q := p.conn(ctx)
+
+	rows, err := q.Query(ctx, "SELECT nextval(pg_get_serial_sequence('messages', 'id')) FROM generate_series(1, $1)", len(messages))
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+
+	senderIDs := make([]string, 0, len(messages))
+	texts := make([]string, 0, len(messages))
+	types := make([]string, 0, len(messages))
+	createdAts := make([]time.Time, 0, len(messages))
+	for _, message := range messages {
+		senderIDs = append(senderIDs, message.SenderID)
+		texts = append(texts, message.Text)
+		types = append(types, message.Type)
+		createdAts = append(createdAts, message.CreatedAt)
+	}
+
+	_, err = q.Exec(ctx, `
+INSERT INTO messages (id, channel_id, sender_id, text, type, created_at)
+SELECT id, $2, sender_id, text, type, created_at
+FROM unnest($1::bigint[], $3::text[], $4::text[], $5::text[], $6::timestamptz[]) AS t (id, sender_id, text, type, created_at)`,
+		ids, channelID, senderIDs, texts, types, createdAts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := q.Exec(ctx, "UPDATE channels SET message_count = message_count + $2 WHERE id = $1", channelID, len(messages)); err != nil {
+		return nil, err
+	}
+
+	messageIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		messageIDs = append(messageIDs, formatID(id))
+	}
+	return messageIDs, nil
+}
+
+func (p *Postgres) GetMessages(ctx context.Context, channelID string, limit int32, offset int32) ([]*domain.Message, error) {
+	const op = "infrastructure.postgres.message.GetMessages"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	// offsets are counted from 1 like in the Mongo storage
+	messages, err := p.findMessages(ctx, " WHERE channel_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3",
+		id, limit, max(offset-1, 0))
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
+
+func (p *Postgres) DeleteChannelsMessages(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.postgres.message.DeleteChannelsMessages"
+
+	ids, err := parseIDs(channelIDs, domain.ErrChannelNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	if _, err := p.conn(ctx).Exec(ctx, "DELETE FROM messages WHERE channel_id = ANY($1)", ids); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// GetPostMessages returns replies of a forum post in chronological order
+func (p *Postgres) GetPostMessages(ctx context.Context, postID string, limit int32, offset int32) ([]*domain.Message, error) {
+	const op = "infrastructure.postgres.message.GetPostMessages"
+
+	messages, err := p.findMessages(ctx, " WHERE post_id = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3", postID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// GetMessagesAfter returns channel messages in chronological order starting after the message with
+// afterTime and afterID, zero afterTime starts from the beginning. Messages with equal time are ordered by id
+func (p *Postgres) GetMessagesAfter(ctx context.Context, channelID string, afterTime time.Time, afterID string, limit int32) ([]*domain.Message, error) {
+	const op = "infrastructure.postgres.message.GetMessagesAfter"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var messages []*domain.Message
+	if afterTime.IsZero() {
+		messages, err = p.findMessages(ctx, " WHERE channel_id = $1 ORDER BY created_at, id LIMIT $2", id, limit)
+	} else {
+		after, parseErr := parseID(afterID, domain.ErrMsgNotFound)
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s : %w", op, parseErr)
+		}
+		messages, err = p.findMessages(ctx, " WHERE channel_id = $1 AND (created_at, id) > ($3, $4) ORDER BY created_at, id LIMIT $2",
+			id, limit, afterTime, after)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// DeleteMessagesBefore deletes the oldest messages first, so a purge interrupted between
+// batches leaves the channel without gaps in its history
+func (p *Postgres) DeleteMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) (messageIDs []string, err error) {
+	err = p.WithinTransaction(ctx, func(ctx context.Context) error {
+		messageIDs, err = p.deleteMessagesBefore(ctx, channelID, before, limit)
+		return err
+	})
+	return messageIDs, err
+}
+
+func (p *Postgres) deleteMessagesBefore(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.postgres.message.DeleteMessagesBefore"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	messageIDs, err := p.deleteOldestMessages(ctx, id, before, limit)
+	if err != nil || len(messageIDs) == 0 {
+		return nil, err
+	}
+
+	if _, err := p.conn(ctx).Exec(ctx, "UPDATE channels SET message_count = message_count - $2 WHERE id = $1", id, len(messageIDs)); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return messageIDs, nil
+}
+
+// DeleteArchivedMessages deletes the oldest messages copied to the archive, message_count of the channel
+// stays as it is. The archive keeps the newest message hot, so the last message of the channel is kept too
+func (p *Postgres) DeleteArchivedMessages(ctx context.Context, channelID string, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.postgres.message.DeleteArchivedMessages"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return p.deleteOldestMessages(ctx, id, before, limit)
+}
+
+// DecreaseMessageCount subtracts messages deleted from the archive from message_count of the channel
+func (p *Postgres) DecreaseMessageCount(ctx context.Context, channelID string, count int64) error {
+	const op = "infrastructure.postgres.message.DecreaseMessageCount"
+
+	id, err := parseID(channelID, domain.ErrChannelNotFound)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	if _, err := p.conn(ctx).Exec(ctx, "UPDATE channels SET message_count = message_count - $2 WHERE id = $1", id, count); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// deleteOldestMessages deletes up to limit oldest messages of the channel created before the time
+func (p *Postgres) deleteOldestMessages(ctx context.Context, id int64, before time.Time, limit int32) ([]string, error) {
+	const op = "infrastructure.postgres.message.deleteOldestMessages"
+
+	rows, err := p.conn(ctx).Query(ctx, `
+DELETE FROM messages WHERE id IN (
+	SELECT id FROM messages
+	WHERE channel_id = $1 AND created_at < $2
+	ORDER BY created_at, id
+	LIMIT $3
+)
+RETURNING id`, id, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	messageIDs := make([]string, 0, len(ids))
+	for _, deletedID := range ids {
+		messageIDs = append(messageIDs, formatID(deletedID))
+	}
+	return messageIDs, nil
+}
+
+func (p *Postgres) findMessages(ctx context.Context, clause string, args ...any) ([]*domain.Message, error) {
+	rows, err := p.conn(ctx).Query(ctx, selectMessages+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.Message
+	for rows.Next() {
+		var id, channelID int64
+		var message domain.Message
+		var callID, callerID, endReason *string
+		var video *bool
+		var duration *int64
+		var webhookID, webhookName, webhookAvatarURL *string
+		err := rows.Scan(
+			&id, &channelID, &message.SenderID, &message.Text, &message.Type, &message.PostID, &message.CreatedAt,
+			&callID, &callerID, &video, &endReason, &duration,
+			&webhookID, &webhookName, &webhookAvatarURL,
+		)
+		if err != nil {
+			return nil, err
+		}
+		message.ID = formatID(id)
+		message.ChannelID = formatID(channelID)
+		if callID != nil {
+			message.Call = &domain.CallRecord{CallID: *callID}
+			if callerID != nil {
+				message.Call.CallerID = *callerID
+			}
+			if video != nil {
+				message.Call.Video = *video
+			}
+			if endReason != nil {
+				message.Call.EndReason = *endReason
+			}
+			if duration != nil {
+				message.Call.DurationSeconds = *duration
+			}
+		}
+		if webhookID != nil {
+			message.Webhook = &domain.WebhookSender{WebhookID: *webhookID}
+			if webhookName != nil {
+				message.Webhook.Name = *webhookName
+			}
+			if webhookAvatarURL != nil {
+				message.Webhook.AvatarURL = *webhookAvatarURL
+			}
+		}
+
+		messages = append(messages, &message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
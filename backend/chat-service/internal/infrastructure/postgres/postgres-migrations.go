@@ -0,0 +1,414 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Migration is a versioned change of the schema. A migration and its record in schema_migrations
+// are applied in one transaction under an advisory lock, so instances starting at the same time
+// do not apply it twice
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether the migration was applied and when
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// migrationsLock is the advisory lock key held while migrations run
+const migrationsLock = 7305661
+
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_chats_channels_messages",
+		Up: `
+CREATE TABLE chats (
+	id                   BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	type                 TEXT NOT NULL,
+	name                 TEXT NOT NULL DEFAULT '',
+	topic                TEXT NOT NULL DEFAULT '',
+	description          TEXT NOT NULL DEFAULT '',
+	archived             BOOLEAN NOT NULL DEFAULT FALSE,
+	community_id         TEXT,
+	retention_max_age    BIGINT,
+	retention_legal_hold BOOLEAN
+);
+CREATE INDEX chats_community_id ON chats (community_id) WHERE community_id IS NOT NULL;
+
+CREATE TABLE chat_members (
+	chat_id BIGINT NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL,
+	seq     BIGINT GENERATED ALWAYS AS IDENTITY,
+	hidden  BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (chat_id, user_id)
+);
+CREATE INDEX chat_members_user_id ON chat_members (user_id);
+
+CREATE TABLE chat_roles (
+	chat_id BIGINT NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL,
+	role    TEXT NOT NULL,
+	PRIMARY KEY (chat_id, user_id)
+);
+
+CREATE TABLE channels (
+	id                   BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	chat_id              BIGINT NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+	name                 TEXT NOT NULL,
+	type                 TEXT NOT NULL,
+	message_count        BIGINT NOT NULL DEFAULT 0,
+	topic                TEXT NOT NULL DEFAULT '',
+	description          TEXT NOT NULL DEFAULT '',
+	position             INTEGER NOT NULL DEFAULT 0,
+	category             TEXT NOT NULL DEFAULT '',
+	archived             BOOLEAN NOT NULL DEFAULT FALSE,
+	private              BOOLEAN NOT NULL DEFAULT FALSE,
+	allowed_roles        TEXT[] NOT NULL DEFAULT '{}',
+	mode                 TEXT NOT NULL DEFAULT '',
+	poster_ids           TEXT[] NOT NULL DEFAULT '{}',
+	tags                 TEXT[] NOT NULL DEFAULT '{}',
+	retention_max_age    BIGINT,
+	retention_legal_hold BOOLEAN
+);
+CREATE INDEX channels_chat_id ON channels (chat_id);
+
+CREATE TABLE channel_members (
+	channel_id BIGINT NOT NULL REFERENCES channels (id) ON DELETE CASCADE,
+	user_id    TEXT NOT NULL,
+	seq        BIGINT GENERATED ALWAYS AS IDENTITY,
+	PRIMARY KEY (channel_id, user_id)
+);
+
+CREATE TABLE channel_permission_overrides (
+	channel_id BIGINT NOT NULL REFERENCES channels (id) ON DELETE CASCADE,
+	position   INTEGER NOT NULL,
+	role       TEXT NOT NULL DEFAULT '',
+	user_id    TEXT NOT NULL DEFAULT '',
+	allow      BIGINT NOT NULL DEFAULT 0,
+	deny       BIGINT NOT NULL DEFAULT 0,
+	PRIMARY KEY (channel_id, position)
+);
+
+CREATE TABLE messages (
+	id                    BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	channel_id            BIGINT NOT NULL REFERENCES channels (id) ON DELETE CASCADE,
+	sender_id             TEXT NOT NULL,
+	text                  TEXT NOT NULL,
+	type                  TEXT NOT NULL DEFAULT '',
+	post_id               TEXT,
+	created_at            TIMESTAMPTZ NOT NULL,
+	call_id               TEXT,
+	call_caller_id        TEXT,
+	call_video            BOOLEAN,
+	call_end_reason       TEXT,
+	call_duration_seconds BIGINT
+);
+CREATE INDEX messages_channel_id_created_at ON messages (channel_id, created_at, id);
+CREATE INDEX messages_post_id_created_at ON messages (post_id, created_at) WHERE post_id IS NOT NULL;
+`,
+		Down: `
+DROP TABLE messages;
+DROP TABLE channel_permission_overrides;
+DROP TABLE channel_members;
+DROP TABLE channels;
+DROP TABLE chat_roles;
+DROP TABLE chat_members;
+DROP TABLE chats;
+`,
+	},
+	{
+		Version: 2,
+		Name:    "add_message_webhook",
+		Up: `
+ALTER TABLE messages
+	ADD COLUMN webhook_id         TEXT,
+	ADD COLUMN webhook_name       TEXT,
+	ADD COLUMN webhook_avatar_url TEXT;
+`,
+		Down: `
+ALTER TABLE messages
+	DROP COLUMN webhook_id,
+	DROP COLUMN webhook_name,
+	DROP COLUMN webhook_avatar_url;
+`,
+	},
+	{
+		Version: 3,
+		Name:    "create_communities_posts_updates_invites_webhooks",
+		Up: `
+CREATE TABLE communities (
+	id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	name            TEXT NOT NULL,
+	description     TEXT NOT NULL DEFAULT '',
+	default_chat_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE community_members (
+	community_id BIGINT NOT NULL REFERENCES communities (id) ON DELETE CASCADE,
+	user_id      TEXT NOT NULL,
+	seq          BIGINT GENERATED ALWAYS AS IDENTITY,
+	PRIMARY KEY (community_id, user_id)
+);
+CREATE INDEX community_members_user_id ON community_members (user_id);
+
+CREATE TABLE community_roles (
+	community_id BIGINT NOT NULL REFERENCES communities (id) ON DELETE CASCADE,
+	user_id      TEXT NOT NULL,
+	role         TEXT NOT NULL,
+	PRIMARY KEY (community_id, user_id)
+);
+
+CREATE TABLE community_chats (
+	community_id BIGINT NOT NULL REFERENCES communities (id) ON DELETE CASCADE,
+	chat_id      TEXT NOT NULL,
+	seq          BIGINT GENERATED ALWAYS AS IDENTITY,
+	PRIMARY KEY (community_id, chat_id)
+);
+
+CREATE TABLE posts (
+	id               BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	channel_id       TEXT NOT NULL,
+	author_id        TEXT NOT NULL,
+	title            TEXT NOT NULL,
+	text             TEXT NOT NULL,
+	tags             TEXT[] NOT NULL DEFAULT '{}',
+	created_at       TIMESTAMPTZ NOT NULL,
+	last_activity_at TIMESTAMPTZ NOT NULL,
+	reply_count      INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX posts_channel_id_last_activity_at ON posts (channel_id, last_activity_at DESC, id DESC);
+
+CREATE TABLE update_counters (
+	user_id TEXT PRIMARY KEY,
+	seq     BIGINT NOT NULL
+);
+
+CREATE TABLE updates (
+	user_id       TEXT NOT NULL,
+	seq           BIGINT NOT NULL,
+	type          TEXT NOT NULL,
+	chat_id       TEXT NOT NULL DEFAULT '',
+	channel_id    TEXT NOT NULL DEFAULT '',
+	message_id    TEXT NOT NULL DEFAULT '',
+	user_ids      TEXT[] NOT NULL DEFAULT '{}',
+	message       JSONB,
+	chat          JSONB,
+	channel       JSONB,
+	post          JSONB,
+	purged_before TIMESTAMPTZ,
+	created_at    TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, seq)
+);
+CREATE INDEX updates_created_at ON updates (created_at);
+
+CREATE TABLE invites (
+	id                BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	chat_id           TEXT NOT NULL,
+	code              TEXT NOT NULL UNIQUE,
+	creator_id        TEXT NOT NULL,
+	expires_at        TIMESTAMPTZ,
+	max_uses          INTEGER NOT NULL DEFAULT 0,
+	uses              INTEGER NOT NULL DEFAULT 0,
+	requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+	revoked           BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at        TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX invites_chat_id_created_at ON invites (chat_id, created_at DESC);
+
+CREATE TABLE join_records (
+	id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	chat_id     TEXT NOT NULL,
+	user_id     TEXT NOT NULL,
+	invite_id   TEXT NOT NULL,
+	invite_code TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	reviewer_id TEXT NOT NULL DEFAULT '',
+	created_at  TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX join_records_chat_id_status ON join_records (chat_id, status);
+
+CREATE TABLE webhooks (
+	id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	chat_id    TEXT NOT NULL,
+	channel_id TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	avatar_url TEXT NOT NULL DEFAULT '',
+	token_hash TEXT NOT NULL,
+	creator_id TEXT NOT NULL,
+	revoked    BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX webhooks_channel_id_created_at ON webhooks (channel_id, created_at DESC);
+`,
+		Down: `
+DROP TABLE webhooks;
+DROP TABLE join_records;
+DROP TABLE invites;
+DROP TABLE updates;
+DROP TABLE update_counters;
+DROP TABLE posts;
+DROP TABLE community_chats;
+DROP TABLE community_roles;
+DROP TABLE community_members;
+DROP TABLE communities;
+`,
+	},
+	{
+		Version: 4,
+		Name:    "add_updates_call",
+		Up: `
+ALTER TABLE updates ADD COLUMN call JSONB;
+`,
+		Down: `
+ALTER TABLE updates DROP COLUMN call;
+`,
+	},
+}
+
+// MigrateUp applies pending migrations in version order and returns the applied ones
+func (p *Postgres) MigrateUp(ctx context.Context) ([]Migration, error) {
+	const op = "infrastructure.postgres.migrations.MigrateUp"
+
+	var done []Migration
+	for _, migration := range sortedMigrations() {
+		applied := false
+		err := p.inMigrationLock(ctx, func(tx pgx.Tx) error {
+			var exists bool
+			if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", migration.Version).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return nil
+			}
+
+			if _, err := tx.Exec(ctx, migration.Up); err != nil {
+				return fmt.Errorf("migration %d %s : %w", migration.Version, migration.Name, err)
+			}
+
+			_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+				migration.Version, migration.Name, time.Now())
+			applied = err == nil
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("%s : %w", op, err)
+		}
+		if applied {
+			done = append(done, migration)
+		}
+	}
+
+	return done, nil
+}
+
+// MigrateDown reverts up to steps latest applied migrations and returns the reverted ones
+func (p *Postgres) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	const op = "infrastructure.postgres.migrations.MigrateDown"
+
+	sorted := sortedMigrations()
+	var done []Migration
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := sorted[i]
+		reverted := false
+		err := p.inMigrationLock(ctx, func(tx pgx.Tx) error {
+			tag, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			if err != nil || tag.RowsAffected() == 0 {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, migration.Down); err != nil {
+				return fmt.Errorf("migration %d %s : %w", migration.Version, migration.Name, err)
+			}
+			reverted = true
+			return nil
+		})
+		if err != nil {
+			return done, fmt.Errorf("%s : %w", op, err)
+		}
+		if reverted {
+			done = append(done, migration)
+		}
+	}
+
+	return done, nil
+}
+
+// MigrationStatuses lists all known migrations in version order
+func (p *Postgres) MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "infrastructure.postgres.migrations.MigrationStatuses"
+
+	applied := make(map[int]time.Time)
+	err := p.inMigrationLock(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var version int
+			var appliedAt time.Time
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return err
+			}
+			applied[version] = appliedAt
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range sortedMigrations() {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// inMigrationLock runs fn in a transaction holding the migrations lock,
+// schema_migrations is created on first use
+func (p *Postgres) inMigrationLock(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	return pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationsLock); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL
+)`)
+		if err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
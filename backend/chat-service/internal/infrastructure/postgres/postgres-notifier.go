@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Notifier passes messages between instances sharing the database with NOTIFY on a channel.
+// Payloads are limited to 8000 bytes
+type Notifier struct {
+	pool    *pgxpool.Pool
+	channel string
+}
+
+func (p *Postgres) Notifier(channel string) *Notifier {
+	return &Notifier{pool: p.pool, channel: channel}
+}
+
+// Publish sends the message outside of any transaction of ctx, so it is sent right away
+func (n *Notifier) Publish(ctx context.Context, message []byte) error {
+	const op = "infrastructure.postgres.notifier.Publish"
+
+	if _, err := n.pool.Exec(ctx, "SELECT pg_notify($1, $2)", n.channel, string(message)); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+// Listen takes a connection out of the pool and listens on the channel until ctx is done or
+// the connection fails. The connection is closed afterwards instead of going back to the pool
+func (n *Notifier) Listen(ctx context.Context, ready func(), handle func(message []byte)) error {
+	const op = "infrastructure.postgres.notifier.Listen"
+
+	pooled, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{n.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	ready()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("%s : %w", op, err)
+		}
+
+		handle([]byte(notification.Payload))
+	}
+}
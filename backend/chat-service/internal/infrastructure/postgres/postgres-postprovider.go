@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const selectPosts = `
+SELECT id, channel_id, author_id, title, text, tags, created_at, last_activity_at, reply_count
+FROM posts`
+
+func (p *Postgres) SavePost(ctx context.Context, post domain.Post) (string, error) {
+	const op = "infrastructure.postgres.post.SavePost"
+
+	var id int64
+	err := p.conn(ctx).QueryRow(ctx, `
+INSERT INTO posts (channel_id, author_id, title, text, tags, created_at, last_activity_at, reply_count)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id`,
+		post.ChannelID, post.AuthorID, post.Title, post.Text, textArray(post.Tags), post.CreatedAt, post.LastActivityAt, post.ReplyCount,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) FindPostByID(ctx context.Context, postID string) (domain.Post, error) {
+	const op = "infrastructure.postgres.post.FindPostByID"
+
+	id, err := parseID(postID, domain.ErrPostNotFound)
+	if err != nil {
+		return domain.Post{}, err
+	}
+
+	post, err := scanPost(p.conn(ctx).QueryRow(ctx, selectPosts+" WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Post{}, domain.ErrPostNotFound
+		}
+		return domain.Post{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return post, nil
+}
+
+// FindChannelPosts returns posts of the channel sorted by latest activity, an empty tag matches every post
+func (p *Postgres) FindChannelPosts(ctx context.Context, channelID string, tag string, limit int32, offset int32) ([]*domain.Post, error) {
+	const op = "infrastructure.postgres.post.FindChannelPosts"
+
+	rows, err := p.conn(ctx).Query(ctx, selectPosts+`
+WHERE channel_id = $1 AND ($2 = '' OR $2 = ANY(tags))
+ORDER BY last_activity_at DESC, id DESC
+LIMIT $3 OFFSET $4`, channelID, tag, limitArg(limit), max(offset, 0))
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var posts []*domain.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		posts = append(posts, &post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return posts, nil
+}
+
+// TouchPost counts a new reply and moves the post up in the activity order
+func (p *Postgres) TouchPost(ctx context.Context, postID string, activityAt time.Time) error {
+	const op = "infrastructure.postgres.post.TouchPost"
+
+	id, err := parseID(postID, domain.ErrPostNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, `
+UPDATE posts SET reply_count = reply_count + 1, last_activity_at = GREATEST(last_activity_at, $2)
+WHERE id = $1`, id, activityAt)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPostNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteChannelsPosts(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.postgres.post.DeleteChannelsPosts"
+
+	if _, err := p.conn(ctx).Exec(ctx, "DELETE FROM posts WHERE channel_id = ANY($1)", textArray(channelIDs)); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func scanPost(row pgx.Row) (domain.Post, error) {
+	var id int64
+	var post domain.Post
+	err := row.Scan(&id, &post.ChannelID, &post.AuthorID, &post.Title, &post.Text, &post.Tags, &post.CreatedAt, &post.LastActivityAt, &post.ReplyCount)
+	if err != nil {
+		return domain.Post{}, err
+	}
+	post.ID = formatID(id)
+	if len(post.Tags) == 0 {
+		post.Tags = nil
+	}
+
+	return post, nil
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveUpdates appends the update to the logs of the users and returns their seqs. Counters are
+// incremented and the updates are inserted in one transaction, the row locks of the counters
+// make updates of a user visible in seq order and a failed insert gives the seqs back
+func (p *Postgres) SaveUpdates(ctx context.Context, update domain.Update, userIDs []string) (seqs map[string]int64, err error) {
+	err = p.WithinTransaction(ctx, func(ctx context.Context) error {
+		seqs, err = p.saveUpdates(ctx, update, userIDs)
+		return err
+	})
+	return seqs, err
+}
+
+func (p *Postgres) saveUpdates(ctx context.Context, update domain.Update, userIDs []string) (map[string]int64, error) {
+	const op = "infrastructure.postgres.update.SaveUpdates"
+
+	seqs := make(map[string]int64, len(userIDs))
+	if len(userIDs) == 0 {
+		return seqs, nil
+	}
+
+	message, err := jsonColumn(update.Message)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	chat, err := jsonColumn(update.Chat)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	channel, err := jsonColumn(update.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	post, err := jsonColumn(update.Post)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	call, err := jsonColumn(update.Call)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	q := p.conn(ctx)
+
+	// counters are locked in user_id order, so transactions sharing users do not deadlock
+	rows, err := q.Query(ctx, `
+INSERT INTO update_counters (user_id, seq)
+SELECT DISTINCT user_id, 1 FROM unnest($1::text[]) AS t (user_id)
+ORDER BY user_id
+ON CONFLICT (user_id) DO UPDATE SET seq = update_counters.seq + 1
+RETURNING user_id, seq`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var users []string
+	var userSeqs []int64
+	for rows.Next() {
+		var userID string
+		var seq int64
+		if err := rows.Scan(&userID, &seq); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		seqs[userID] = seq
+		users = append(users, userID)
+		userSeqs = append(userSeqs, seq)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	_, err = q.Exec(ctx, `
+INSERT INTO updates (user_id, seq, type, chat_id, channel_id, message_id, user_ids, message, chat, channel, post, call, purged_before, created_at)
+SELECT user_id, seq, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+FROM unnest($1::text[], $2::bigint[]) AS t (user_id, seq)`,
+		users, userSeqs, update.Type, update.ChatID, update.ChannelID, update.MessageID, textArray(update.UserIDs),
+		message, chat, channel, post, call, update.PurgedBefore, update.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return seqs, nil
+}
+
+func (p *Postgres) GetUpdates(ctx context.Context, userID string, sinceSeq int64, limit int32) ([]*domain.Update, error) {
+	const op = "infrastructure.postgres.update.GetUpdates"
+
+	rows, err := p.conn(ctx).Query(ctx, `
+SELECT user_id, seq, type, chat_id, channel_id, message_id, user_ids, message, chat, channel, post, call, purged_before, created_at
+FROM updates
+WHERE user_id = $1 AND seq > $2
+ORDER BY seq
+LIMIT $3`, userID, sinceSeq, limitArg(limit))
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var updates []*domain.Update
+	for rows.Next() {
+		var update domain.Update
+		var message, chat, channel, post, call []byte
+		err := rows.Scan(&update.UserID, &update.Seq, &update.Type, &update.ChatID, &update.ChannelID, &update.MessageID, &update.UserIDs,
+			&message, &chat, &channel, &post, &call, &update.PurgedBefore, &update.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		if len(update.UserIDs) == 0 {
+			update.UserIDs = nil
+		}
+
+		if update.Message, err = fromJSONColumn[domain.Message](message); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		if update.Chat, err = fromJSONColumn[domain.Chat](chat); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		if update.Channel, err = fromJSONColumn[domain.Channel](channel); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		if update.Post, err = fromJSONColumn[domain.Post](post); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		if update.Call, err = fromJSONColumn[domain.Call](call); err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+
+		updates = append(updates, &update)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return updates, nil
+}
+
+func (p *Postgres) GetUpdatesState(ctx context.Context, userID string) (int64, error) {
+	const op = "infrastructure.postgres.update.GetUpdatesState"
+
+	var seq int64
+	err := p.conn(ctx).QueryRow(ctx, "SELECT seq FROM update_counters WHERE user_id = $1", userID).Scan(&seq)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return seq, nil
+}
+
+func (p *Postgres) DeleteUpdatesBefore(ctx context.Context, before time.Time) (int64, error) {
+	const op = "infrastructure.postgres.update.DeleteUpdatesBefore"
+
+	tag, err := p.conn(ctx).Exec(ctx, "DELETE FROM updates WHERE created_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// jsonColumn encodes a snapshot kept in a JSONB column, nil is stored as NULL
+func jsonColumn[T any](value *T) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+func fromJSONColumn[T any](data []byte) (*T, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"chat-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const selectWebhooks = `
+SELECT id, chat_id, channel_id, name, avatar_url, token_hash, creator_id, revoked, created_at
+FROM webhooks`
+
+func (p *Postgres) SaveWebhook(ctx context.Context, webhook domain.Webhook) (string, error) {
+	const op = "infrastructure.postgres.webhook.SaveWebhook"
+
+	var id int64
+	err := p.conn(ctx).QueryRow(ctx, `
+INSERT INTO webhooks (chat_id, channel_id, name, avatar_url, token_hash, creator_id, revoked, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id`,
+		webhook.ChatID, webhook.ChannelID, webhook.Name, webhook.AvatarURL, webhook.TokenHash, webhook.CreatorID, webhook.Revoked, webhook.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return formatID(id), nil
+}
+
+func (p *Postgres) FindWebhookByID(ctx context.Context, webhookID string) (domain.Webhook, error) {
+	const op = "infrastructure.postgres.webhook.FindWebhookByID"
+
+	id, err := parseID(webhookID, domain.ErrWebhookNotFound)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook, err := scanWebhook(p.conn(ctx).QueryRow(ctx, selectWebhooks+" WHERE id = $1", id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Webhook{}, domain.ErrWebhookNotFound
+		}
+		return domain.Webhook{}, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return webhook, nil
+}
+
+func (p *Postgres) FindChannelWebhooks(ctx context.Context, channelID string) ([]*domain.Webhook, error) {
+	const op = "infrastructure.postgres.webhook.FindChannelWebhooks"
+
+	rows, err := p.conn(ctx).Query(ctx, selectWebhooks+" WHERE channel_id = $1 ORDER BY created_at DESC, id DESC", channelID)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer rows.Close()
+
+	var webhooks []*domain.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", op, err)
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return webhooks, nil
+}
+
+func (p *Postgres) RevokeWebhook(ctx context.Context, webhookID string) error {
+	const op = "infrastructure.postgres.webhook.RevokeWebhook"
+
+	id, err := parseID(webhookID, domain.ErrWebhookNotFound)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.conn(ctx).Exec(ctx, "UPDATE webhooks SET revoked = TRUE WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteChannelsWebhooks(ctx context.Context, channelIDs []string) error {
+	const op = "infrastructure.postgres.webhook.DeleteChannelsWebhooks"
+
+	if _, err := p.conn(ctx).Exec(ctx, "DELETE FROM webhooks WHERE channel_id = ANY($1)", textArray(channelIDs)); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func scanWebhook(row pgx.Row) (domain.Webhook, error) {
+	var id int64
+	var webhook domain.Webhook
+	err := row.Scan(&id, &webhook.ChatID, &webhook.ChannelID, &webhook.Name, &webhook.AvatarURL, &webhook.TokenHash,
+		&webhook.CreatorID, &webhook.Revoked, &webhook.CreatedAt)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	webhook.ID = formatID(id)
+
+	return webhook, nil
+}
@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-service/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres keeps all chat-service data in relational tables. IDs are bigint
+// identities passed around as decimal strings
+type Postgres struct {
+	pool *pgxpool.Pool
+}
+
+func New(storagePath string) *Postgres {
+	pool, err := pgxpool.New(context.Background(), storagePath)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Postgres{pool: pool}
+}
+
+func (p *Postgres) Close() error {
+	p.pool.Close()
+	return nil
+}
+
+// querier is implemented by the pool and by a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// conn returns the transaction of ctx or the pool outside of transactions
+func (p *Postgres) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return p.pool
+}
+
+// WithinTransaction runs fn in a transaction. Calls made with a ctx that already
+// carries a transaction join it
+func (p *Postgres) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	return pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// parseID converts an id to its bigint form, malformed ids are reported as notFound
+func parseID(id string, notFound error) (int64, error) {
+	parsed, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, notFound
+	}
+	return parsed, nil
+}
+
+func parseIDs(ids []string, notFound error) ([]int64, error) {
+	parsed := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		parsedID, err := parseID(id, notFound)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, parsedID)
+	}
+	return parsed, nil
+}
+
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// textArray keeps NOT NULL array columns from receiving NULL for nil slices
+func textArray(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// nullString stores an empty string as NULL
+func nullString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// limitArg passes a limit of zero or less as NULL, LIMIT NULL returns every row
+func limitArg(limit int32) *int32 {
+	if limit <= 0 {
+		return nil
+	}
+	return &limit
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// retentionColumns splits a retention policy into the nullable max_age and legal_hold columns,
+// max_age is kept in seconds
+func retentionColumns(policy *domain.RetentionPolicy) (*int64, *bool) {
+	if policy == nil {
+		return nil, nil
+	}
+	maxAge := int64(policy.MaxAge / time.Second)
+	return &maxAge, &policy.LegalHold
+}
+
+func retentionPolicy(maxAge *int64, legalHold *bool) *domain.RetentionPolicy {
+	if maxAge == nil {
+		return nil
+	}
+
+	policy := &domain.RetentionPolicy{MaxAge: time.Duration(*maxAge) * time.Second}
+	if legalHold != nil {
+		policy.LegalHold = *legalHold
+	}
+	return policy
+}
+
+// updateSet builds the SET list of an UPDATE, $1 is reserved for the row id
+type updateSet struct {
+	columns []string
+	args    []any
+}
+
+func (set *updateSet) add(column string, value any) {
+	set.args = append(set.args, value)
+	set.columns = append(set.columns, fmt.Sprintf("%s = $%d", column, len(set.args)+1))
+}
+
+func (set *updateSet) empty() bool {
+	return len(set.columns) == 0
+}
+
+// exec runs UPDATE table SET ... WHERE id = $1 and reports whether the row exists
+func (set *updateSet) exec(ctx context.Context, q querier, table string, id int64) (bool, error) {
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE id = $1", table, strings.Join(set.columns, ", "))
+	tag, err := q.Exec(ctx, sql, append([]any{id}, set.args...)...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// exists reports whether the row with the id exists in the table
+func exists(ctx context.Context, q querier, table string, id int64) (bool, error) {
+	var found bool
+	err := q.QueryRow(ctx, fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", table), id).Scan(&found)
+	return found, err
+}
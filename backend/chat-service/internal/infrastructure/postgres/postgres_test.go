@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"chat-service/internal/infrastructure/storagetest"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestPostgres(t)
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	p := newTestPostgres(t)
+	storagetest.RunNotifier(t, p.Notifier(fmt.Sprintf("test_%d", time.Now().UnixNano())))
+}
+
+// newTestPostgres connects to POSTGRES_TEST_URI and uses a fresh schema dropped after the test,
+// tests are skipped when the variable is not set
+func newTestPostgres(t *testing.T) *Postgres {
+	t.Helper()
+
+	uri := os.Getenv("POSTGRES_TEST_URI")
+	if uri == "" {
+		t.Skip("POSTGRES_TEST_URI is not set")
+	}
+	ctx := context.Background()
+
+	admin := New(uri)
+	schema := fmt.Sprintf("chat_service_test_%d", time.Now().UnixNano())
+	if _, err := admin.pool.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("CREATE SCHEMA error = %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(uri)
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	p := &Postgres{pool: pool}
+	t.Cleanup(func() {
+		p.Close()
+		admin.pool.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close()
+	})
+
+	if _, err := p.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp() error = %v", err)
+	}
+
+	return p
+}
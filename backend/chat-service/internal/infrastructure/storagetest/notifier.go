@@ -0,0 +1,57 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Notifier is implemented by drivers passing messages between replicas
+type Notifier interface {
+	Publish(ctx context.Context, message []byte) error
+	Listen(ctx context.Context, ready func(), handle func(message []byte)) error
+}
+
+// RunNotifier checks that a published message reaches a listener and that Listen ends with ctx
+func RunNotifier(t *testing.T, notifier Notifier) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan struct{})
+	received := make(chan []byte, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.Listen(ctx, func() { close(ready) }, func(message []byte) { received <- message })
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("Listen() error = %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Listen() did not start listening")
+	}
+
+	if err := notifier.Publish(ctx, []byte("hello")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	select {
+	case message := <-received:
+		if string(message) != "hello" {
+			t.Errorf("received %q, want %q", message, "hello")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("published message was not received")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Listen() error after cancel = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Listen() did not return after cancel")
+	}
+}
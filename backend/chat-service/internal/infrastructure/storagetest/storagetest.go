@@ -0,0 +1,708 @@
+// Package storagetest holds the provider tests shared by every storage driver,
+// each driver runs them against its own database with Run
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+)
+
+// Storage is implemented by every storage driver
+type Storage interface {
+	interfaces.ChatProvider
+	interfaces.ChannelProvider
+	interfaces.CommunityProvider
+	interfaces.PostProvider
+	interfaces.UpdateProvider
+	interfaces.InviteProvider
+	interfaces.WebhookProvider
+}
+
+// Run runs every test against a fresh storage returned by newStorage
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s Storage)
+	}{
+		{"ChatChannels", testChatChannels},
+		{"Posts", testPosts},
+		{"Updates", testUpdates},
+		{"UpdateSnapshots", testUpdateSnapshots},
+		{"DeleteUpdatesBefore", testDeleteUpdatesBefore},
+		{"Invites", testInvites},
+		{"JoinRecords", testJoinRecords},
+		{"Webhooks", testWebhooks},
+		{"Communities", testCommunities},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStorage(t))
+		})
+	}
+}
+
+// now is truncated to milliseconds, the precision every driver keeps
+func now() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
+func testChatChannels(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	var chatIDs []string
+	for _, name := range []string{"team", "other"} {
+		chatID, err := s.SaveChat(ctx, domain.Chat{Type: "group", Name: name, MemberIDs: []string{"alice"}})
+		if err != nil {
+			t.Fatalf("SaveChat() error = %v", err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	var channelIDs []string
+	for i, name := range []string{"main", "random", "news"} {
+		channelID, err := s.SaveChannel(ctx, domain.Channel{ChatID: chatIDs[0], Name: name, Type: "text", Position: int32(2 - i)})
+		if err != nil {
+			t.Fatalf("SaveChannel() error = %v", err)
+		}
+		channelIDs = append(channelIDs, channelID)
+	}
+	if _, err := s.SaveChannel(ctx, domain.Channel{ChatID: chatIDs[1], Name: "elsewhere", Type: "text"}); err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+	if err := s.DeleteChannel(ctx, channelIDs[1]); err != nil {
+		t.Fatalf("DeleteChannel() error = %v", err)
+	}
+
+	channels, err := s.FindChatChannels(ctx, chatIDs[0])
+	if err != nil {
+		t.Fatalf("FindChatChannels() error = %v", err)
+	}
+	var names []string
+	for _, channel := range channels {
+		names = append(names, channel.Name)
+	}
+	// channels come in creation order whatever their position
+	if want := []string{"main", "news"}; !slices.Equal(names, want) {
+		t.Errorf("FindChatChannels() = %v, want %v", names, want)
+	}
+
+	if err := s.DeleteChannel(ctx, channelIDs[1]); !errors.Is(err, domain.ErrChannelNotFound) {
+		t.Errorf("DeleteChannel() of a deleted channel error = %v, want %v", err, domain.ErrChannelNotFound)
+	}
+}
+
+func testPosts(t *testing.T, s Storage) {
+	ctx := context.Background()
+	start := now()
+
+	var postIDs []string
+	for i, post := range []domain.Post{
+		{ChannelID: "forum", AuthorID: "alice", Title: "first", Tags: []string{"news"}},
+		{ChannelID: "forum", AuthorID: "bob", Title: "second"},
+		{ChannelID: "forum", AuthorID: "alice", Title: "third", Tags: []string{"news", "help"}},
+		{ChannelID: "other", AuthorID: "alice", Title: "elsewhere", Tags: []string{"news"}},
+	} {
+		post.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		post.LastActivityAt = post.CreatedAt
+		postID, err := s.SavePost(ctx, post)
+		if err != nil {
+			t.Fatalf("SavePost() error = %v", err)
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	if err := s.TouchPost(ctx, postIDs[0], start.Add(time.Minute)); err != nil {
+		t.Fatalf("TouchPost() error = %v", err)
+	}
+	// an older reply does not move the post back
+	if err := s.TouchPost(ctx, postIDs[0], start); err != nil {
+		t.Fatalf("TouchPost() error = %v", err)
+	}
+
+	post, err := s.FindPostByID(ctx, postIDs[0])
+	if err != nil {
+		t.Fatalf("FindPostByID() error = %v", err)
+	}
+	if post.Title != "first" || post.ReplyCount != 2 || !post.LastActivityAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("FindPostByID() = %+v, want 2 replies and the latest activity", post)
+	}
+
+	cases := []struct {
+		name   string
+		tag    string
+		limit  int32
+		offset int32
+		want   []string
+	}{
+		{"every post", "", 10, 0, []string{"first", "third", "second"}},
+		{"by tag", "news", 10, 0, []string{"first", "third"}},
+		{"page", "", 1, 1, []string{"third"}},
+		{"past the end", "", 10, 3, nil},
+	}
+	for _, tc := range cases {
+		posts, err := s.FindChannelPosts(ctx, "forum", tc.tag, tc.limit, tc.offset)
+		if err != nil {
+			t.Fatalf("%s: FindChannelPosts() error = %v", tc.name, err)
+		}
+		var titles []string
+		for _, post := range posts {
+			titles = append(titles, post.Title)
+		}
+		if !slices.Equal(titles, tc.want) {
+			t.Errorf("%s: FindChannelPosts() = %v, want %v", tc.name, titles, tc.want)
+		}
+	}
+
+	if err := s.DeleteChannelsPosts(ctx, []string{"forum"}); err != nil {
+		t.Fatalf("DeleteChannelsPosts() error = %v", err)
+	}
+	if _, err := s.FindPostByID(ctx, postIDs[0]); !errors.Is(err, domain.ErrPostNotFound) {
+		t.Errorf("FindPostByID() of a deleted post error = %v, want %v", err, domain.ErrPostNotFound)
+	}
+	if err := s.TouchPost(ctx, postIDs[0], start); !errors.Is(err, domain.ErrPostNotFound) {
+		t.Errorf("TouchPost() of a deleted post error = %v, want %v", err, domain.ErrPostNotFound)
+	}
+	if _, err := s.FindPostByID(ctx, postIDs[3]); err != nil {
+		t.Errorf("FindPostByID() of a post in another channel error = %v", err)
+	}
+}
+
+func testUpdates(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	first := domain.Update{Type: domain.UpdateNewMessage, ChatID: "chat", CreatedAt: now()}
+	if _, err := s.SaveUpdates(ctx, first, []string{"alice"}); err != nil {
+		t.Fatalf("SaveUpdates() error = %v", err)
+	}
+
+	second := domain.Update{Type: domain.UpdateMembersAdded, ChatID: "chat", UserIDs: []string{"bob"}, CreatedAt: now()}
+	seqs, err := s.SaveUpdates(ctx, second, []string{"alice", "bob", "alice"})
+	if err != nil {
+		t.Fatalf("SaveUpdates() error = %v", err)
+	}
+	if len(seqs) != 2 || seqs["alice"] != 2 || seqs["bob"] != 1 {
+		t.Errorf("SaveUpdates() = %v, want alice 2 and bob 1", seqs)
+	}
+
+	if seqs, err := s.SaveUpdates(ctx, first, nil); err != nil || len(seqs) != 0 {
+		t.Errorf("SaveUpdates() without users = %v, %v, want no seqs", seqs, err)
+	}
+
+	cases := []struct {
+		userID   string
+		sinceSeq int64
+		limit    int32
+		want     []int64
+	}{
+		{"alice", 0, 10, []int64{1, 2}},
+		{"alice", 1, 10, []int64{2}},
+		{"alice", 0, 1, []int64{1}},
+		{"alice", 2, 10, nil},
+		{"bob", 0, 10, []int64{1}},
+		{"carol", 0, 10, nil},
+	}
+	for _, tc := range cases {
+		updates, err := s.GetUpdates(ctx, tc.userID, tc.sinceSeq, tc.limit)
+		if err != nil {
+			t.Fatalf("GetUpdates(%s, %d, %d) error = %v", tc.userID, tc.sinceSeq, tc.limit, err)
+		}
+		var got []int64
+		for _, update := range updates {
+			if update.UserID != tc.userID {
+				t.Errorf("GetUpdates(%s) returned an update of %s", tc.userID, update.UserID)
+			}
+			got = append(got, update.Seq)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("GetUpdates(%s, %d, %d) seqs = %v, want %v", tc.userID, tc.sinceSeq, tc.limit, got, tc.want)
+		}
+	}
+
+	updates, err := s.GetUpdates(ctx, "bob", 0, 10)
+	if err != nil {
+		t.Fatalf("GetUpdates() error = %v", err)
+	}
+	if len(updates) != 1 || updates[0].Type != domain.UpdateMembersAdded || !slices.Equal(updates[0].UserIDs, []string{"bob"}) {
+		t.Errorf("bob updates = %+v, want members_added of bob", updates)
+	}
+
+	for userID, want := range map[string]int64{"alice": 2, "bob": 1, "carol": 0} {
+		seq, err := s.GetUpdatesState(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUpdatesState(%s) error = %v", userID, err)
+		}
+		if seq != want {
+			t.Errorf("GetUpdatesState(%s) = %d, want %d", userID, seq, want)
+		}
+	}
+}
+
+func testUpdateSnapshots(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	createdAt := now()
+	purgedBefore := createdAt.Add(-time.Hour)
+	message := &domain.Message{ID: "message", ChannelID: "channel", Text: "hi", SenderID: "alice", CreatedAt: createdAt}
+	chat := &domain.Chat{ID: "chat", Type: "group", Name: "team", MemberIDs: []string{"alice"}, Roles: map[string]string{"alice": domain.RoleOwner}}
+	channel := &domain.Channel{ID: "channel", ChatID: "chat", Name: "general", Type: "text", Private: true, MemberIDs: []string{"alice"}}
+	post := &domain.Post{ID: "post", ChannelID: "forum", AuthorID: "alice", Title: "release notes", Tags: []string{"news"}, CreatedAt: createdAt}
+	call := &domain.Call{ID: "call", ChatID: "chat", CallerID: "alice", CalleeID: "bob", State: domain.CallStateRinging, CreatedAt: createdAt}
+
+	for _, update := range []domain.Update{
+		{Type: domain.UpdateNewMessage, ChatID: "chat", ChannelID: "channel", MessageID: "message", Message: message, CreatedAt: createdAt},
+		{Type: domain.UpdateChatUpdated, ChatID: "chat", Chat: chat, CreatedAt: createdAt},
+		{Type: domain.UpdateChannelUpdated, ChatID: "chat", ChannelID: "channel", Channel: channel, CreatedAt: createdAt},
+		{Type: domain.UpdateNewPost, ChatID: "chat", ChannelID: "forum", Post: post, CreatedAt: createdAt},
+		{Type: domain.UpdateMessagesPurged, ChatID: "chat", ChannelID: "channel", PurgedBefore: &purgedBefore, CreatedAt: createdAt},
+		{Type: domain.UpdateCallUpdated, ChatID: "chat", Call: call, CreatedAt: createdAt},
+	} {
+		if _, err := s.SaveUpdates(ctx, update, []string{"alice"}); err != nil {
+			t.Fatalf("SaveUpdates(%s) error = %v", update.Type, err)
+		}
+	}
+
+	updates, err := s.GetUpdates(ctx, "alice", 0, 10)
+	if err != nil {
+		t.Fatalf("GetUpdates() error = %v", err)
+	}
+	if len(updates) != 6 {
+		t.Fatalf("GetUpdates() returned %d updates, want 6", len(updates))
+	}
+
+	if got := updates[0]; got.MessageID != "message" || got.Message == nil || got.Message.Text != message.Text || !got.Message.CreatedAt.Equal(createdAt) {
+		t.Errorf("new_message update = %+v, want message %+v", got, message)
+	}
+	if got := updates[1].Chat; got == nil || got.Name != chat.Name || got.Roles["alice"] != domain.RoleOwner {
+		t.Errorf("chat_updated snapshot = %+v, want %+v", got, chat)
+	}
+	if got := updates[2].Channel; got == nil || got.Name != channel.Name || !got.Private || !slices.Equal(got.MemberIDs, channel.MemberIDs) {
+		t.Errorf("channel_updated snapshot = %+v, want %+v", got, channel)
+	}
+	if got := updates[3].Post; got == nil || got.Title != post.Title || !slices.Equal(got.Tags, post.Tags) {
+		t.Errorf("new_post snapshot = %+v, want %+v", got, post)
+	}
+	if got := updates[4]; got.PurgedBefore == nil || !got.PurgedBefore.Equal(purgedBefore) || got.Message != nil || got.Chat != nil {
+		t.Errorf("messages_purged update = %+v, want only purged_before %v", got, purgedBefore)
+	}
+	if got := updates[5].Call; got == nil || got.ID != call.ID || got.CalleeID != call.CalleeID || got.State != call.State || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("call_updated snapshot = %+v, want %+v", got, call)
+	}
+	if !updates[0].CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v", updates[0].CreatedAt, createdAt)
+	}
+}
+
+func testDeleteUpdatesBefore(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	start := now()
+	for _, update := range []struct {
+		userID    string
+		createdAt time.Time
+	}{
+		{"alice", start.Add(-2 * time.Hour)},
+		{"alice", start},
+		{"bob", start.Add(-2 * time.Hour)},
+	} {
+		doc := domain.Update{Type: domain.UpdateNewMessage, ChatID: "chat", CreatedAt: update.createdAt}
+		if _, err := s.SaveUpdates(ctx, doc, []string{update.userID}); err != nil {
+			t.Fatalf("SaveUpdates() error = %v", err)
+		}
+	}
+
+	deleted, err := s.DeleteUpdatesBefore(ctx, start.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteUpdatesBefore() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteUpdatesBefore() = %d, want 2", deleted)
+	}
+
+	updates, err := s.GetUpdates(ctx, "alice", 0, 10)
+	if err != nil {
+		t.Fatalf("GetUpdates() error = %v", err)
+	}
+	if len(updates) != 1 || updates[0].Seq != 2 {
+		t.Errorf("alice updates = %+v, want only seq 2", updates)
+	}
+
+	// trimming keeps the counter, the next update of bob continues his seq
+	seqs, err := s.SaveUpdates(ctx, domain.Update{Type: domain.UpdateNewMessage, ChatID: "chat", CreatedAt: start}, []string{"bob"})
+	if err != nil {
+		t.Fatalf("SaveUpdates() error = %v", err)
+	}
+	if seqs["bob"] != 2 {
+		t.Errorf("bob seq after trimming = %d, want 2", seqs["bob"])
+	}
+}
+
+func testInvites(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	start := now()
+	expired := start.Add(-time.Minute)
+	var inviteIDs []string
+	for i, invite := range []domain.Invite{
+		{ChatID: "chat", Code: "limited", CreatorID: "alice", MaxUses: 1},
+		{ChatID: "chat", Code: "expired", CreatorID: "alice", ExpiresAt: &expired},
+		{ChatID: "chat", Code: "open", CreatorID: "bob", RequiresApproval: true},
+		{ChatID: "other", Code: "elsewhere", CreatorID: "alice"},
+	} {
+		invite.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		inviteID, err := s.SaveInvite(ctx, invite)
+		if err != nil {
+			t.Fatalf("SaveInvite() error = %v", err)
+		}
+		inviteIDs = append(inviteIDs, inviteID)
+	}
+
+	invite, err := s.FindInviteByCode(ctx, "open")
+	if err != nil {
+		t.Fatalf("FindInviteByCode() error = %v", err)
+	}
+	if invite.ID != inviteIDs[2] || invite.CreatorID != "bob" || !invite.RequiresApproval {
+		t.Errorf("FindInviteByCode() = %+v, want the open invite", invite)
+	}
+	if _, err := s.FindInviteByCode(ctx, "missing"); !errors.Is(err, domain.ErrInviteNotFound) {
+		t.Errorf("FindInviteByCode() of a missing code error = %v, want %v", err, domain.ErrInviteNotFound)
+	}
+
+	invites, err := s.FindChatInvites(ctx, "chat")
+	if err != nil {
+		t.Fatalf("FindChatInvites() error = %v", err)
+	}
+	var codes []string
+	for _, invite := range invites {
+		codes = append(codes, invite.Code)
+	}
+	if want := []string{"open", "expired", "limited"}; !slices.Equal(codes, want) {
+		t.Errorf("FindChatInvites() = %v, want %v", codes, want)
+	}
+
+	cases := []struct {
+		name     string
+		inviteID string
+		want     error
+	}{
+		{"first use", inviteIDs[0], nil},
+		{"max uses reached", inviteIDs[0], domain.ErrInvalidInvite},
+		{"expired", inviteIDs[1], domain.ErrInvalidInvite},
+		{"unlimited", inviteIDs[2], nil},
+		{"unlimited again", inviteIDs[2], nil},
+	}
+	for _, tc := range cases {
+		if err := s.UseInvite(ctx, tc.inviteID, start); !errors.Is(err, tc.want) {
+			t.Errorf("%s: UseInvite() error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	if err := s.RevokeInvite(ctx, inviteIDs[2]); err != nil {
+		t.Fatalf("RevokeInvite() error = %v", err)
+	}
+	if err := s.UseInvite(ctx, inviteIDs[2], start); !errors.Is(err, domain.ErrInvalidInvite) {
+		t.Errorf("UseInvite() of a revoked invite error = %v, want %v", err, domain.ErrInvalidInvite)
+	}
+
+	invite, err = s.FindInviteByID(ctx, inviteIDs[2])
+	if err != nil {
+		t.Fatalf("FindInviteByID() error = %v", err)
+	}
+	if invite.Uses != 2 || !invite.Revoked {
+		t.Errorf("FindInviteByID() = %+v, want 2 uses and revoked", invite)
+	}
+
+	if err := s.DeleteChatInvites(ctx, "chat"); err != nil {
+		t.Fatalf("DeleteChatInvites() error = %v", err)
+	}
+	if _, err := s.FindInviteByID(ctx, inviteIDs[0]); !errors.Is(err, domain.ErrInviteNotFound) {
+		t.Errorf("FindInviteByID() of a deleted invite error = %v, want %v", err, domain.ErrInviteNotFound)
+	}
+	if err := s.RevokeInvite(ctx, inviteIDs[0]); !errors.Is(err, domain.ErrInviteNotFound) {
+		t.Errorf("RevokeInvite() of a deleted invite error = %v, want %v", err, domain.ErrInviteNotFound)
+	}
+	if _, err := s.FindInviteByID(ctx, inviteIDs[3]); err != nil {
+		t.Errorf("FindInviteByID() of an invite of another chat error = %v", err)
+	}
+}
+
+func testJoinRecords(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	start := now()
+	var recordIDs []string
+	for i, record := range []domain.JoinRecord{
+		{ChatID: "chat", UserID: "alice", InviteID: "invite", InviteCode: "code", Status: domain.JoinStatusJoined},
+		{ChatID: "chat", UserID: "bob", InviteID: "invite", InviteCode: "code", Status: domain.JoinStatusPending},
+		{ChatID: "other", UserID: "bob", InviteID: "invite", InviteCode: "code", Status: domain.JoinStatusPending},
+	} {
+		record.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		recordID, err := s.SaveJoinRecord(ctx, record)
+		if err != nil {
+			t.Fatalf("SaveJoinRecord() error = %v", err)
+		}
+		recordIDs = append(recordIDs, recordID)
+	}
+
+	pending, err := s.FindPendingJoinRecord(ctx, "chat", "bob")
+	if err != nil {
+		t.Fatalf("FindPendingJoinRecord() error = %v", err)
+	}
+	if pending == nil || pending.ID != recordIDs[1] {
+		t.Fatalf("FindPendingJoinRecord() = %+v, want record %s", pending, recordIDs[1])
+	}
+
+	if err := s.SetJoinRecordStatus(ctx, recordIDs[1], domain.JoinStatusApproved, "alice"); err != nil {
+		t.Fatalf("SetJoinRecordStatus() error = %v", err)
+	}
+
+	record, err := s.FindJoinRecordByID(ctx, recordIDs[1])
+	if err != nil {
+		t.Fatalf("FindJoinRecordByID() error = %v", err)
+	}
+	if record.Status != domain.JoinStatusApproved || record.ReviewerID != "alice" {
+		t.Errorf("FindJoinRecordByID() = %+v, want approved by alice", record)
+	}
+
+	pending, err = s.FindPendingJoinRecord(ctx, "chat", "bob")
+	if err != nil {
+		t.Fatalf("FindPendingJoinRecord() error = %v", err)
+	}
+	if pending != nil {
+		t.Errorf("FindPendingJoinRecord() after approval = %+v, want nil", pending)
+	}
+
+	cases := []struct {
+		status string
+		want   []string
+	}{
+		{"", []string{"bob", "alice"}},
+		{domain.JoinStatusJoined, []string{"alice"}},
+		{domain.JoinStatusPending, nil},
+	}
+	for _, tc := range cases {
+		records, err := s.FindJoinRecords(ctx, "chat", tc.status)
+		if err != nil {
+			t.Fatalf("FindJoinRecords(%q) error = %v", tc.status, err)
+		}
+		var users []string
+		for _, record := range records {
+			users = append(users, record.UserID)
+		}
+		if !slices.Equal(users, tc.want) {
+			t.Errorf("FindJoinRecords(%q) = %v, want %v", tc.status, users, tc.want)
+		}
+	}
+
+	if err := s.DeleteChatInvites(ctx, "chat"); err != nil {
+		t.Fatalf("DeleteChatInvites() error = %v", err)
+	}
+	if _, err := s.FindJoinRecordByID(ctx, recordIDs[0]); !errors.Is(err, domain.ErrJoinReqNotFound) {
+		t.Errorf("FindJoinRecordByID() of a deleted record error = %v, want %v", err, domain.ErrJoinReqNotFound)
+	}
+	if err := s.SetJoinRecordStatus(ctx, recordIDs[0], domain.JoinStatusRejected, "alice"); !errors.Is(err, domain.ErrJoinReqNotFound) {
+		t.Errorf("SetJoinRecordStatus() of a deleted record error = %v, want %v", err, domain.ErrJoinReqNotFound)
+	}
+	if _, err := s.FindJoinRecordByID(ctx, recordIDs[2]); err != nil {
+		t.Errorf("FindJoinRecordByID() of a record of another chat error = %v", err)
+	}
+}
+
+func testWebhooks(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	start := now()
+	var webhookIDs []string
+	for i, webhook := range []domain.Webhook{
+		{ChatID: "chat", ChannelID: "channel", Name: "ci", AvatarURL: "https://example.com/ci.png", TokenHash: "hash", CreatorID: "alice"},
+		{ChatID: "chat", ChannelID: "channel", Name: "alerts", TokenHash: "hash", CreatorID: "alice"},
+		{ChatID: "chat", ChannelID: "other", Name: "deploys", TokenHash: "hash", CreatorID: "bob"},
+	} {
+		webhook.CreatedAt = start.Add(time.Duration(i) * time.Second)
+		webhookID, err := s.SaveWebhook(ctx, webhook)
+		if err != nil {
+			t.Fatalf("SaveWebhook() error = %v", err)
+		}
+		webhookIDs = append(webhookIDs, webhookID)
+	}
+
+	webhooks, err := s.FindChannelWebhooks(ctx, "channel")
+	if err != nil {
+		t.Fatalf("FindChannelWebhooks() error = %v", err)
+	}
+	var names []string
+	for _, webhook := range webhooks {
+		names = append(names, webhook.Name)
+	}
+	if want := []string{"alerts", "ci"}; !slices.Equal(names, want) {
+		t.Errorf("FindChannelWebhooks() = %v, want %v", names, want)
+	}
+
+	if err := s.RevokeWebhook(ctx, webhookIDs[0]); err != nil {
+		t.Fatalf("RevokeWebhook() error = %v", err)
+	}
+	webhook, err := s.FindWebhookByID(ctx, webhookIDs[0])
+	if err != nil {
+		t.Fatalf("FindWebhookByID() error = %v", err)
+	}
+	if !webhook.Revoked || webhook.AvatarURL != "https://example.com/ci.png" || webhook.TokenHash != "hash" {
+		t.Errorf("FindWebhookByID() = %+v, want the revoked ci webhook", webhook)
+	}
+
+	if err := s.DeleteChannelsWebhooks(ctx, []string{"channel"}); err != nil {
+		t.Fatalf("DeleteChannelsWebhooks() error = %v", err)
+	}
+	if _, err := s.FindWebhookByID(ctx, webhookIDs[1]); !errors.Is(err, domain.ErrWebhookNotFound) {
+		t.Errorf("FindWebhookByID() of a deleted webhook error = %v, want %v", err, domain.ErrWebhookNotFound)
+	}
+	if err := s.RevokeWebhook(ctx, webhookIDs[1]); !errors.Is(err, domain.ErrWebhookNotFound) {
+		t.Errorf("RevokeWebhook() of a deleted webhook error = %v, want %v", err, domain.ErrWebhookNotFound)
+	}
+	if _, err := s.FindWebhookByID(ctx, webhookIDs[2]); err != nil {
+		t.Errorf("FindWebhookByID() of a webhook of another channel error = %v", err)
+	}
+}
+
+func testCommunities(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	communityID, err := s.SaveCommunity(ctx, domain.Community{
+		Name:      "guild",
+		MemberIDs: []string{"alice", "bob"},
+		Roles:     map[string]string{"alice": domain.RoleOwner},
+	})
+	if err != nil {
+		t.Fatalf("SaveCommunity() error = %v", err)
+	}
+
+	chatID, err := s.SaveChat(ctx, domain.Chat{
+		Type:        "group",
+		Name:        "general",
+		MemberIDs:   []string{"alice", "bob"},
+		Roles:       map[string]string{"alice": domain.RoleOwner},
+		CommunityID: communityID,
+	})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+	if err := s.AddCommunityChat(ctx, communityID, chatID); err != nil {
+		t.Fatalf("AddCommunityChat() error = %v", err)
+	}
+	// adding the chat again keeps a single entry
+	if err := s.AddCommunityChat(ctx, communityID, chatID); err != nil {
+		t.Fatalf("AddCommunityChat() error = %v", err)
+	}
+	name := "guild hall"
+	if err := s.UpdateCommunity(ctx, communityID, domain.CommunityPatch{Name: &name, DefaultChatID: &chatID}); err != nil {
+		t.Fatalf("UpdateCommunity() error = %v", err)
+	}
+
+	channelID, err := s.SaveChannel(ctx, domain.Channel{ChatID: chatID, Name: "staff", Type: "text", Private: true, MemberIDs: []string{"alice"}})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+
+	if err := s.AddCommunityMembers(ctx, communityID, []string{"carol", "bob"}); err != nil {
+		t.Fatalf("AddCommunityMembers() error = %v", err)
+	}
+	if err := s.SetCommunityRoles(ctx, communityID, map[string]string{"carol": domain.RoleAdmin}); err != nil {
+		t.Fatalf("SetCommunityRoles() error = %v", err)
+	}
+	if err := s.AddChannelMembers(ctx, channelID, []string{"carol"}); err != nil {
+		t.Fatalf("AddChannelMembers() error = %v", err)
+	}
+
+	community, err := s.FindCommunityByID(ctx, communityID)
+	if err != nil {
+		t.Fatalf("FindCommunityByID() error = %v", err)
+	}
+	if community.Name != name || community.DefaultChatID != chatID || !slices.Equal(community.ChatIDs, []string{chatID}) {
+		t.Errorf("FindCommunityByID() = %+v, want the renamed community with one chat", community)
+	}
+	if want := []string{"alice", "bob", "carol"}; !slices.Equal(community.MemberIDs, want) {
+		t.Errorf("community members = %v, want %v", community.MemberIDs, want)
+	}
+	if community.Roles["alice"] != domain.RoleOwner || community.Roles["carol"] != domain.RoleAdmin {
+		t.Errorf("community roles = %v, want alice owner and carol admin", community.Roles)
+	}
+
+	chat, err := s.FindChatByID(ctx, chatID, "alice")
+	if err != nil {
+		t.Fatalf("FindChatByID() error = %v", err)
+	}
+	if want := []string{"alice", "bob", "carol"}; !slices.Equal(chat.MemberIDs, want) {
+		t.Errorf("chat members = %v, want %v", chat.MemberIDs, want)
+	}
+	if chat.Roles["carol"] != domain.RoleAdmin {
+		t.Errorf("chat roles = %v, want carol admin", chat.Roles)
+	}
+
+	communities, err := s.FindUserCommunities(ctx, "carol")
+	if err != nil {
+		t.Fatalf("FindUserCommunities() error = %v", err)
+	}
+	if len(communities) != 1 || communities[0].ID != communityID {
+		t.Errorf("FindUserCommunities() = %+v, want community %s", communities, communityID)
+	}
+
+	if err := s.RemoveCommunityMember(ctx, communityID, "carol"); err != nil {
+		t.Fatalf("RemoveCommunityMember() error = %v", err)
+	}
+
+	community, err = s.FindCommunityByID(ctx, communityID)
+	if err != nil {
+		t.Fatalf("FindCommunityByID() error = %v", err)
+	}
+	if slices.Contains(community.MemberIDs, "carol") || community.Roles["carol"] != "" {
+		t.Errorf("community after removal = %+v, want carol gone", community)
+	}
+	chat, err = s.FindChatByID(ctx, chatID, "alice")
+	if err != nil {
+		t.Fatalf("FindChatByID() error = %v", err)
+	}
+	if slices.Contains(chat.MemberIDs, "carol") || chat.Roles["carol"] != "" {
+		t.Errorf("chat after removal = %+v, want carol gone", chat)
+	}
+	channel, err := s.FindChannelByID(ctx, channelID)
+	if err != nil {
+		t.Fatalf("FindChannelByID() error = %v", err)
+	}
+	if !slices.Equal(channel.MemberIDs, []string{"alice"}) {
+		t.Errorf("private channel members after removal = %v, want only alice", channel.MemberIDs)
+	}
+
+	communities, err = s.FindUserCommunities(ctx, "carol")
+	if err != nil {
+		t.Fatalf("FindUserCommunities() error = %v", err)
+	}
+	if len(communities) != 0 {
+		t.Errorf("FindUserCommunities() after removal = %+v, want none", communities)
+	}
+
+	if err := s.SetCommunityRoles(ctx, communityID, map[string]string{"alice": domain.RoleMember}); err != nil {
+		t.Fatalf("SetCommunityRoles() error = %v", err)
+	}
+	if err := s.RemoveCommunityChat(ctx, communityID, chatID); err != nil {
+		t.Fatalf("RemoveCommunityChat() error = %v", err)
+	}
+	community, err = s.FindCommunityByID(ctx, communityID)
+	if err != nil {
+		t.Fatalf("FindCommunityByID() error = %v", err)
+	}
+	if len(community.ChatIDs) != 0 || community.Roles["alice"] != "" {
+		t.Errorf("community = %+v, want no chats and no roles for alice", community)
+	}
+
+	const missingID = "999999999"
+	if _, err := s.FindCommunityByID(ctx, missingID); !errors.Is(err, domain.ErrCommunityNotFound) {
+		t.Errorf("FindCommunityByID() of a missing community error = %v, want %v", err, domain.ErrCommunityNotFound)
+	}
+	if err := s.AddCommunityMembers(ctx, missingID, []string{"dave"}); !errors.Is(err, domain.ErrCommunityNotFound) {
+		t.Errorf("AddCommunityMembers() of a missing community error = %v, want %v", err, domain.ErrCommunityNotFound)
+	}
+}
@@ -0,0 +1,275 @@
+package usergrpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type cachedUser struct {
+	userID    string
+	username  string
+	expiresAt time.Time
+}
+
+type cachedBlockers struct {
+	blockerIDs []string
+	expiresAt  time.Time
+}
+
+// usernamesBatch collects user_ids requested during one batch window
+type usernamesBatch struct {
+	userIDs map[string]struct{}
+	done    chan struct{}
+	err     error
+}
+
+// CachedClient resolves users through UserClient. Username lookups made within the batch
+// window are sent to user-service as one request and results are cached for the TTL.
+// Unknown users are not cached so freshly registered users are found right away.
+// Blockers are cached for a shorter TTL, including users nobody blocked, so that
+// sending a message does not call user-service every time
+type CachedClient struct {
+	client      *UserClient
+	ttl         time.Duration
+	blocksTTL   time.Duration
+	batchWindow time.Duration
+	batchSize   int
+
+	mu sync.Mutex
+	// byID and byName are keyed by user_id and by username
+	byID    map[string]cachedUser
+	byName  map[string]cachedUser
+	pending *usernamesBatch
+	// blockers is keyed by the blocked user_id
+	blockers map[string]cachedBlockers
+}
+
+func NewCachedClient(client *UserClient, ttl time.Duration, blocksTTL time.Duration, batchWindow time.Duration, batchSize int) *CachedClient {
+	return &CachedClient{
+		client:      client,
+		ttl:         ttl,
+		blocksTTL:   blocksTTL,
+		batchWindow: batchWindow,
+		batchSize:   batchSize,
+
+		byID:     make(map[string]cachedUser),
+		byName:   make(map[string]cachedUser),
+		blockers: make(map[string]cachedBlockers),
+	}
+}
+
+// GetUsernames returns usernames of existing users, unknown user_ids are absent in the result
+func (c *CachedClient) GetUsernames(ctx context.Context, userIDs []string) (map[string]string, error) {
+	const op = "infrastructure.usergrpc.cache.GetUsernames"
+
+	usernames, missing := c.lookup(userIDs, c.byID, func(user cachedUser) string { return user.username })
+	if len(missing) == 0 {
+		return usernames, nil
+	}
+
+	batch := c.enqueue(missing)
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s : %w", op, ctx.Err())
+	case <-batch.done:
+	}
+	if batch.err != nil {
+		return nil, fmt.Errorf("%s : %w", op, batch.err)
+	}
+
+	found, _ := c.lookup(missing, c.byID, func(user cachedUser) string { return user.username })
+	for userID, username := range found {
+		usernames[userID] = username
+	}
+
+	return usernames, nil
+}
+
+// GetUserIDs returns user_ids of existing users by username, unknown usernames are absent in the result
+func (c *CachedClient) GetUserIDs(ctx context.Context, usernames []string) (map[string]string, error) {
+	const op = "infrastructure.usergrpc.cache.GetUserIDs"
+
+	userIDs, missing := c.lookup(usernames, c.byName, func(user cachedUser) string { return user.userID })
+	if len(missing) == 0 {
+		return userIDs, nil
+	}
+
+	found, err := c.client.GetUserIDs(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	c.mu.Lock()
+	expiresAt := time.Now().Add(c.ttl)
+	for username, userID := range found {
+		c.store(cachedUser{userID: userID, username: username, expiresAt: expiresAt})
+		userIDs[username] = userID
+	}
+	c.mu.Unlock()
+
+	return userIDs, nil
+}
+
+// CreatePlaceholderUsers creates users without credentials and returns user_ids of all the usernames
+func (c *CachedClient) CreatePlaceholderUsers(ctx context.Context, usernames []string) (map[string]string, error) {
+	const op = "infrastructure.usergrpc.cache.CreatePlaceholderUsers"
+
+	userIDs, err := c.client.CreatePlaceholderUsers(ctx, usernames)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	c.mu.Lock()
+	expiresAt := time.Now().Add(c.ttl)
+	for username, userID := range userIDs {
+		c.store(cachedUser{userID: userID, username: username, expiresAt: expiresAt})
+	}
+	c.mu.Unlock()
+
+	return userIDs, nil
+}
+
+// GetBlockedBy returns users who blocked each of the given users, users nobody blocked are absent in the result
+func (c *CachedClient) GetBlockedBy(ctx context.Context, userIDs []string) (map[string][]string, error) {
+	const op = "infrastructure.usergrpc.cache.GetBlockedBy"
+
+	blockers := make(map[string][]string, len(userIDs))
+	var missing []string
+
+	c.mu.Lock()
+	now := time.Now()
+	for _, userID := range userIDs {
+		cached, ok := c.blockers[userID]
+		if !ok || !now.Before(cached.expiresAt) {
+			missing = append(missing, userID)
+			continue
+		}
+		if len(cached.blockerIDs) > 0 {
+			blockers[userID] = cached.blockerIDs
+		}
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return blockers, nil
+	}
+
+	found, err := c.client.GetBlockedBy(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	c.mu.Lock()
+	expiresAt := time.Now().Add(c.blocksTTL)
+	for _, userID := range missing {
+		c.blockers[userID] = cachedBlockers{blockerIDs: found[userID], expiresAt: expiresAt}
+		if len(found[userID]) > 0 {
+			blockers[userID] = found[userID]
+		}
+	}
+	c.mu.Unlock()
+
+	return blockers, nil
+}
+
+// lookup splits keys into cached values and keys that have to be requested
+func (c *CachedClient) lookup(keys []string, index map[string]cachedUser, value func(cachedUser) string) (map[string]string, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	found := make(map[string]string, len(keys))
+	var missing []string
+	for _, key := range keys {
+		user, ok := index[key]
+		if ok && now.Before(user.expiresAt) {
+			found[key] = value(user)
+			continue
+		}
+		missing = append(missing, key)
+	}
+
+	return found, missing
+}
+
+// enqueue adds user_ids to the pending batch, the batch is sent when the window ends or it is full
+func (c *CachedClient) enqueue(userIDs []string) *usernamesBatch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	batch := c.pending
+	if batch == nil {
+		batch = &usernamesBatch{
+			userIDs: make(map[string]struct{}),
+			done:    make(chan struct{}),
+		}
+		c.pending = batch
+		time.AfterFunc(c.batchWindow, func() { c.flush(batch) })
+	}
+
+	for _, userID := range userIDs {
+		batch.userIDs[userID] = struct{}{}
+	}
+
+	if len(batch.userIDs) >= c.batchSize {
+		go c.flush(batch)
+	}
+
+	return batch
+}
+
+// flush sends the batch once, it is called by the window timer and when the batch is full
+func (c *CachedClient) flush(batch *usernamesBatch) {
+	c.mu.Lock()
+	if c.pending != batch {
+		c.mu.Unlock()
+		return
+	}
+	c.pending = nil
+
+	userIDs := make([]string, 0, len(batch.userIDs))
+	for userID := range batch.userIDs {
+		userIDs = append(userIDs, userID)
+	}
+	c.mu.Unlock()
+
+	// the batch is shared by several requests, so it does not depend on the context of any of them
+	usernames, err := c.client.GetUsernames(context.Background(), userIDs)
+
+	c.mu.Lock()
+	now := time.Now()
+	c.evictExpired(now)
+	for userID, username := range usernames {
+		c.store(cachedUser{userID: userID, username: username, expiresAt: now.Add(c.ttl)})
+	}
+	c.mu.Unlock()
+
+	batch.err = err
+	close(batch.done)
+}
+
+// evictExpired drops expired users and blockers, the caller holds the lock
+func (c *CachedClient) evictExpired(now time.Time) {
+	for userID, user := range c.byID {
+		if !now.Before(user.expiresAt) {
+			delete(c.byID, userID)
+			delete(c.byName, user.username)
+		}
+	}
+	for userID, cached := range c.blockers {
+		if !now.Before(cached.expiresAt) {
+			delete(c.blockers, userID)
+		}
+	}
+}
+
+// store caches the user under both keys, the caller holds the lock
+func (c *CachedClient) store(user cachedUser) {
+	if previous, ok := c.byID[user.userID]; ok && previous.username != user.username {
+		delete(c.byName, previous.username)
+	}
+	c.byID[user.userID] = user
+	c.byName[user.username] = user
+}
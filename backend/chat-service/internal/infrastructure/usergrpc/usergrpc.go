@@ -0,0 +1,123 @@
+package usergrpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	userpb "chat-service/gen/user"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serviceSecretHeader carries the secret required by the Internal service of user-service
+const serviceSecretHeader = "x-service-secret"
+
+type UserClient struct {
+	conn     *grpc.ClientConn
+	api      userpb.AuthClient
+	internal userpb.InternalClient
+	timeout  time.Duration
+
+	serviceSecret string
+}
+
+func New(address string, timeout time.Duration, serviceSecret string) *UserClient {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		panic(err)
+	}
+
+	return &UserClient{
+		conn:     conn,
+		api:      userpb.NewAuthClient(conn),
+		internal: userpb.NewInternalClient(conn),
+		timeout:  timeout,
+
+		serviceSecret: serviceSecret,
+	}
+}
+
+// GetUsernames returns usernames of existing users, unknown user_ids are absent in the result
+func (c *UserClient) GetUsernames(ctx context.Context, userIDs []string) (map[string]string, error) {
+	const op = "infrastructure.usergrpc.GetUsernames"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.api.GetUsernames(ctx, &userpb.GetUsernamesRequest{UserIds: userIDs})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return resp.GetUsernames(), nil
+}
+
+// GetUserIDs returns user_ids of existing users by username, unknown usernames are absent in the result
+func (c *UserClient) GetUserIDs(ctx context.Context, usernames []string) (map[string]string, error) {
+	const op = "infrastructure.usergrpc.GetUserIDs"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.api.GetUserIDs(ctx, &userpb.GetUserIDsRequest{Usernames: usernames})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return resp.GetUserIds(), nil
+}
+
+// GetBlockedBy returns users who blocked each of the given users, users nobody blocked are absent in the result
+func (c *UserClient) GetBlockedBy(ctx context.Context, userIDs []string) (map[string][]string, error) {
+	const op = "infrastructure.usergrpc.GetBlockedBy"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.internal.GetBlockedBy(c.withServiceSecret(ctx), &userpb.GetBlockedByRequest{UserIds: userIDs})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	blockers := make(map[string][]string, len(resp.GetBlockers()))
+	for userID, list := range resp.GetBlockers() {
+		blockers[userID] = list.GetUserIds()
+	}
+
+	return blockers, nil
+}
+
+// CreatePlaceholderUsers creates users without credentials and returns user_ids of all the usernames
+func (c *UserClient) CreatePlaceholderUsers(ctx context.Context, usernames []string) (map[string]string, error) {
+	const op = "infrastructure.usergrpc.CreatePlaceholderUsers"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.internal.CreatePlaceholderUsers(c.withServiceSecret(ctx), &userpb.CreatePlaceholderUsersRequest{Usernames: usernames})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return resp.GetUserIds(), nil
+}
+
+// withServiceSecret authenticates a call to the Internal service
+func (c *UserClient) withServiceSecret(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, serviceSecretHeader, c.serviceSecret)
+}
+
+func (c *UserClient) Close() error {
+	return c.conn.Close()
+}
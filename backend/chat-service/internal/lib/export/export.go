@@ -0,0 +1,102 @@
+package export
+
+import (
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+const (
+	FormatJSON = "json"
+	FormatHTML = "html"
+	FormatText = "text"
+)
+
+var formats = map[string]struct {
+	extension   string
+	contentType string
+}{
+	FormatJSON: {"json", "application/json"},
+	FormatHTML: {"html", "text/html; charset=utf-8"},
+	FormatText: {"txt", "text/plain; charset=utf-8"},
+}
+
+// Header is written before the messages of the channel
+type Header struct {
+	Chat       domain.Chat
+	Channel    domain.Channel
+	ExportedAt time.Time
+	// Posts are set for forum channels, replies refer to them by PostID
+	Posts []*domain.Post
+	// Names maps user_ids of post authors to usernames
+	Names map[string]string
+}
+
+// Renderer writes a channel export in one format. Begin is called once, then Message for
+// every message in chronological order and End after the last one
+type Renderer interface {
+	Begin(header Header) error
+	Message(message *domain.Message) error
+	End() error
+}
+
+// Valid reports whether the format is supported
+func Valid(format string) bool {
+	_, ok := formats[format]
+	return ok
+}
+
+// File describes the export file of the channel in the format
+func File(channel domain.Channel, format string) domain.ExportFile {
+	return domain.ExportFile{
+		Name:        "channel-" + channel.ID + "." + formats[format].extension,
+		ContentType: formats[format].contentType,
+	}
+}
+
+// Bundle describes the zip archive holding the export file
+func Bundle(file domain.ExportFile) domain.ExportFile {
+	return domain.ExportFile{
+		Name:        strings.TrimSuffix(file.Name, path.Ext(file.Name)) + ".zip",
+		ContentType: "application/zip",
+	}
+}
+
+// NewRenderer returns a renderer of the format writing into w
+func NewRenderer(format string, w io.Writer) Renderer {
+	switch format {
+	case FormatJSON:
+		return &jsonRenderer{w: w}
+	case FormatHTML:
+		return &htmlRenderer{w: w}
+	default:
+		return &textRenderer{w: w}
+	}
+}
+
+// senderName is the username of the sender or the user_id when user-service did not resolve it
+func senderName(message *domain.Message) string {
+	if message.SenderName != "" {
+		return message.SenderName
+	}
+	return message.SenderID
+}
+
+// postTitles maps post_ids to titles
+func postTitles(posts []*domain.Post) map[string]string {
+	titles := make(map[string]string, len(posts))
+	for _, post := range posts {
+		titles[post.ID] = post.Title
+	}
+	return titles
+}
+
+func nameOr(names map[string]string, userID string) string {
+	if name, ok := names[userID]; ok {
+		return name
+	}
+	return userID
+}
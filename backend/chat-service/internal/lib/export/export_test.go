@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+var exportedAt = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testHeader() Header {
+	return Header{
+		Chat:       domain.Chat{ID: "chat1", Type: "group", Name: "team"},
+		Channel:    domain.Channel{ID: "channel1", Name: "ideas", Type: domain.ChannelTypeForum, Topic: "<b>ideas</b>"},
+		ExportedAt: exportedAt,
+		Posts: []*domain.Post{
+			{ID: "post1", AuthorID: "u-alice", Title: "Dark mode", Text: "please\nsoon", Tags: []string{"ui"}, CreatedAt: exportedAt.Add(-2 * time.Hour)},
+		},
+		Names: map[string]string{"u-alice": "alice"},
+	}
+}
+
+func testMessages() []*domain.Message {
+	return []*domain.Message{
+		{ID: "m1", SenderID: "u-alice", Text: "alice added bob", CreatedAt: exportedAt.Add(-time.Hour), Type: domain.MessageTypeSystem},
+		{ID: "m2", SenderID: "u-bob", SenderName: "bob", Text: "+1\n<script>", CreatedAt: exportedAt.Add(-time.Minute), PostID: "post1"},
+		{
+			ID: "m3", SenderID: "u-carol", Text: "call", CreatedAt: exportedAt, Type: domain.MessageTypeCall,
+			Call: &domain.CallRecord{CallID: "call1", CallerID: "u-carol", EndReason: "ended", DurationSeconds: 42},
+		},
+	}
+}
+
+// render writes the header and the messages in the format
+func render(t *testing.T, format string, header Header, messages []*domain.Message) string {
+	t.Helper()
+
+	var b bytes.Buffer
+	renderer := NewRenderer(format, &b)
+	if err := renderer.Begin(header); err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	for _, message := range messages {
+		if err := renderer.Message(message); err != nil {
+			t.Fatalf("Message() error = %v", err)
+		}
+	}
+	if err := renderer.End(); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	return b.String()
+}
+
+func TestJSONRenderer(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []*domain.Message
+	}{
+		{"no messages", nil},
+		{"messages", testMessages()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc struct {
+				Chat        jsonChat         `json:"chat"`
+				Channel     jsonChannel      `json:"channel"`
+				ExportedAt  time.Time        `json:"exported_at"`
+				Posts       []jsonPost       `json:"posts"`
+				Messages    []jsonMessage    `json:"messages"`
+				Attachments []jsonAttachment `json:"attachments"`
+			}
+			out := render(t, FormatJSON, testHeader(), tt.messages)
+			if err := json.Unmarshal([]byte(out), &doc); err != nil {
+				t.Fatalf("export is not valid JSON: %v\n%s", err, out)
+			}
+
+			if doc.Chat.ID != "chat1" || doc.Channel.Topic != "<b>ideas</b>" || !doc.ExportedAt.Equal(exportedAt) {
+				t.Errorf("header = %+v %+v %v, want chat1 #ideas at %v", doc.Chat, doc.Channel, doc.ExportedAt, exportedAt)
+			}
+			if len(doc.Posts) != 1 || doc.Posts[0].AuthorName != "alice" {
+				t.Errorf("posts = %+v, want the post of alice", doc.Posts)
+			}
+			if doc.Attachments == nil || len(doc.Attachments) != 0 {
+				t.Errorf("attachments = %v, want an empty manifest", doc.Attachments)
+			}
+			if len(doc.Messages) != len(tt.messages) {
+				t.Fatalf("%d messages, want %d", len(doc.Messages), len(tt.messages))
+			}
+			if len(tt.messages) == 0 {
+				return
+			}
+
+			if names := []string{doc.Messages[0].SenderName, doc.Messages[1].SenderName}; names[0] != "u-alice" || names[1] != "bob" {
+				t.Errorf("sender names = %v, want the user_id when the name is unknown", names)
+			}
+			if doc.Messages[1].PostID != "post1" {
+				t.Errorf("post_id = %q, want post1", doc.Messages[1].PostID)
+			}
+			if call := doc.Messages[2].Call; call == nil || call.CallID != "call1" || call.DurationSeconds != 42 {
+				t.Errorf("call = %+v, want call1 of 42 seconds", call)
+			}
+		})
+	}
+}
+
+func TestTextRenderer(t *testing.T) {
+	want := `Chat: team
+Channel: #ideas
+Topic: <b>ideas</b>
+Exported at: 2024-03-01 12:00:00 UTC
+
+[2024-03-01 10:00:00] alice posted "Dark mode" (ui)
+    please
+    soon
+
+[2024-03-01 11:00:00] alice added bob
+[2024-03-01 11:59:00] bob in "Dark mode": +1
+    <script>
+[2024-03-01 12:00:00] u-carol: call
+`
+
+	if got := render(t, FormatText, testHeader(), testMessages()); got != want {
+		t.Errorf("text export =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHTMLRenderer(t *testing.T) {
+	out := render(t, FormatHTML, testHeader(), testMessages())
+
+	for _, want := range []string{
+		"<title>team #ideas</title>",
+		"<p>&lt;b&gt;ideas&lt;/b&gt;</p>",
+		`<section class="post" id="post-post1">`,
+		`<div class="message system"><span class="time">2024-03-01 11:00:00</span><span class="text">alice added bob</span></div>`,
+		`<span class="sender">bob</span><a class="reply" href="#post-post1">Dark mode</a><span class="text">+1` + "\n" + `&lt;script&gt;</span>`,
+		"</main>\n</body>\n</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html export has no %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>") || strings.Contains(out, "http://") || strings.Contains(out, "https://") {
+		t.Error("html export is not escaped or loads from the network")
+	}
+}
+
+func TestFile(t *testing.T) {
+	channel := domain.Channel{ID: "channel1"}
+
+	tests := []struct {
+		format          string
+		wantName        string
+		wantContentType string
+	}{
+		{FormatJSON, "channel-channel1.json", "application/json"},
+		{FormatHTML, "channel-channel1.html", "text/html; charset=utf-8"},
+		{FormatText, "channel-channel1.txt", "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			file := File(channel, tt.format)
+			if file.Name != tt.wantName || file.ContentType != tt.wantContentType {
+				t.Errorf("File() = %+v, want %s of %s", file, tt.wantName, tt.wantContentType)
+			}
+			if bundle := Bundle(file); bundle.Name != "channel-channel1.zip" || bundle.ContentType != "application/zip" {
+				t.Errorf("Bundle() = %+v, want channel-channel1.zip", bundle)
+			}
+		})
+	}
+
+	if Valid("pdf") {
+		t.Error("Valid(pdf) = true, want false")
+	}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"chat-service/internal/domain"
+)
+
+// htmlStyle keeps the transcript self-contained, it does not load anything from the network
+const htmlStyle = `body{font-family:sans-serif;max-width:900px;margin:24px auto;padding:0 16px;color:#222}
+header{border-bottom:1px solid #ddd;margin-bottom:16px}
+.meta{color:#777;font-size:13px}
+.post{background:#f6f6f6;border-radius:6px;padding:8px 12px;margin:8px 0}
+.message{margin:6px 0}
+.message.system{color:#777;font-style:italic}
+.time{color:#999;font-size:12px;margin-right:6px}
+.sender{font-weight:bold;margin-right:6px}
+.reply{color:#777;font-size:12px;margin-right:6px}
+.text{white-space:pre-wrap}`
+
+// htmlRenderer writes a single HTML page with inline styles
+type htmlRenderer struct {
+	w      io.Writer
+	titles map[string]string
+}
+
+func (r *htmlRenderer) Begin(header Header) error {
+	r.titles = postTitles(header.Posts)
+
+	var b strings.Builder
+	title := html.EscapeString(header.Chat.Name + " #" + header.Channel.Name)
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", title, htmlStyle)
+	fmt.Fprintf(&b, "<header>\n<h1>%s</h1>\n", title)
+	if header.Channel.Topic != "" {
+		fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(header.Channel.Topic))
+	}
+	fmt.Fprintf(&b, "<p class=\"meta\">Exported at %s UTC</p>\n</header>\n", header.ExportedAt.UTC().Format(timeLayout))
+
+	for _, post := range header.Posts {
+		fmt.Fprintf(&b, "<section class=\"post\" id=\"post-%s\">\n<h2>%s</h2>\n<p class=\"meta\">%s, %s UTC",
+			html.EscapeString(post.ID),
+			html.EscapeString(post.Title),
+			html.EscapeString(nameOr(header.Names, post.AuthorID)),
+			post.CreatedAt.UTC().Format(timeLayout),
+		)
+		if len(post.Tags) > 0 {
+			fmt.Fprintf(&b, ", %s", html.EscapeString(strings.Join(post.Tags, ", ")))
+		}
+		fmt.Fprintf(&b, "</p>\n<div class=\"text\">%s</div>\n</section>\n", html.EscapeString(post.Text))
+	}
+	b.WriteString("<main>\n")
+
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+func (r *htmlRenderer) Message(message *domain.Message) error {
+	var b strings.Builder
+	if message.Type == domain.MessageTypeSystem {
+		b.WriteString("<div class=\"message system\">")
+	} else {
+		b.WriteString("<div class=\"message\">")
+	}
+	fmt.Fprintf(&b, "<span class=\"time\">%s</span>", message.CreatedAt.UTC().Format(timeLayout))
+	if message.Type != domain.MessageTypeSystem {
+		fmt.Fprintf(&b, "<span class=\"sender\">%s</span>", html.EscapeString(senderName(message)))
+	}
+	if title, ok := r.titles[message.PostID]; ok {
+		fmt.Fprintf(&b, "<a class=\"reply\" href=\"#post-%s\">%s</a>", html.EscapeString(message.PostID), html.EscapeString(title))
+	}
+	fmt.Fprintf(&b, "<span class=\"text\">%s</span></div>\n", html.EscapeString(message.Text))
+
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+func (r *htmlRenderer) End() error {
+	_, err := io.WriteString(r.w, "</main>\n</body>\n</html>\n")
+	return err
+}
@@ -0,0 +1,161 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+type jsonChat struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type jsonChannel struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Topic       string `json:"topic,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type jsonPost struct {
+	ID         string    `json:"id"`
+	AuthorID   string    `json:"author_id"`
+	AuthorName string    `json:"author_name"`
+	Title      string    `json:"title"`
+	Text       string    `json:"text"`
+	Tags       []string  `json:"tags,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type jsonCall struct {
+	CallID          string `json:"call_id"`
+	CallerID        string `json:"caller_id"`
+	Video           bool   `json:"video"`
+	EndReason       string `json:"end_reason"`
+	DurationSeconds int64  `json:"duration_seconds"`
+}
+
+type jsonMessage struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type,omitempty"`
+	SenderID   string    `json:"sender_id"`
+	SenderName string    `json:"sender_name"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	PostID     string    `json:"post_id,omitempty"`
+	Call       *jsonCall `json:"call,omitempty"`
+}
+
+// jsonAttachment is an entry of the attachments manifest. With a zip bundle Path is the file
+// inside the archive
+type jsonAttachment struct {
+	MessageID string `json:"message_id"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Path      string `json:"path,omitempty"`
+}
+
+// jsonRenderer streams one JSON document:
+// {"chat": ..., "channel": ..., "exported_at": ..., "posts": [...], "messages": [...], "attachments": [...]}
+type jsonRenderer struct {
+	w     io.Writer
+	count int
+}
+
+func (r *jsonRenderer) Begin(header Header) error {
+	posts := make([]jsonPost, 0, len(header.Posts))
+	for _, post := range header.Posts {
+		posts = append(posts, jsonPost{
+			ID:         post.ID,
+			AuthorID:   post.AuthorID,
+			AuthorName: nameOr(header.Names, post.AuthorID),
+			Title:      post.Title,
+			Text:       post.Text,
+			Tags:       post.Tags,
+			CreatedAt:  post.CreatedAt,
+		})
+	}
+
+	head := struct {
+		Chat       jsonChat    `json:"chat"`
+		Channel    jsonChannel `json:"channel"`
+		ExportedAt time.Time   `json:"exported_at"`
+		Posts      []jsonPost  `json:"posts"`
+	}{
+		Chat: jsonChat{
+			ID:   header.Chat.ID,
+			Type: header.Chat.Type,
+			Name: header.Chat.Name,
+		},
+		Channel: jsonChannel{
+			ID:          header.Channel.ID,
+			Name:        header.Channel.Name,
+			Type:        header.Channel.Type,
+			Topic:       header.Channel.Topic,
+			Description: header.Channel.Description,
+		},
+		ExportedAt: header.ExportedAt,
+		Posts:      posts,
+	}
+
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+
+	// the closing brace is replaced by the messages array
+	_, err = fmt.Fprintf(r.w, "%s,\"messages\":[", data[:len(data)-1])
+	return err
+}
+
+func (r *jsonRenderer) Message(message *domain.Message) error {
+	entry := jsonMessage{
+		ID:         message.ID,
+		Type:       message.Type,
+		SenderID:   message.SenderID,
+		SenderName: senderName(message),
+		Text:       message.Text,
+		CreatedAt:  message.CreatedAt,
+		PostID:     message.PostID,
+	}
+	if message.Call != nil {
+		entry.Call = &jsonCall{
+			CallID:          message.Call.CallID,
+			CallerID:        message.Call.CallerID,
+			Video:           message.Call.Video,
+			EndReason:       message.Call.EndReason,
+			DurationSeconds: message.Call.DurationSeconds,
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if r.count == 0 {
+		separator = ""
+	}
+	r.count++
+
+	_, err = fmt.Fprintf(r.w, "%s\n%s", separator, data)
+	return err
+}
+
+// End closes the document. Messages do not carry files yet, so the attachments manifest is empty
+func (r *jsonRenderer) End() error {
+	attachments, err := json.Marshal([]jsonAttachment{})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(r.w, "\n],\"attachments\":%s}\n", attachments)
+	return err
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"chat-service/internal/domain"
+)
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// textRenderer writes a plain text transcript, one message per line with continuation lines indented
+type textRenderer struct {
+	w      io.Writer
+	titles map[string]string
+}
+
+func (r *textRenderer) Begin(header Header) error {
+	r.titles = postTitles(header.Posts)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Chat: %s\n", header.Chat.Name)
+	fmt.Fprintf(&b, "Channel: #%s\n", header.Channel.Name)
+	if header.Channel.Topic != "" {
+		fmt.Fprintf(&b, "Topic: %s\n", header.Channel.Topic)
+	}
+	fmt.Fprintf(&b, "Exported at: %s UTC\n", header.ExportedAt.UTC().Format(timeLayout))
+
+	for _, post := range header.Posts {
+		fmt.Fprintf(&b, "\n[%s] %s posted \"%s\"", post.CreatedAt.UTC().Format(timeLayout), nameOr(header.Names, post.AuthorID), post.Title)
+		if len(post.Tags) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(post.Tags, ", "))
+		}
+		fmt.Fprintf(&b, "\n%s\n", indent(post.Text))
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+func (r *textRenderer) Message(message *domain.Message) error {
+	prefix := fmt.Sprintf("[%s] ", message.CreatedAt.UTC().Format(timeLayout))
+	if message.Type != domain.MessageTypeSystem {
+		prefix += senderName(message)
+		if title, ok := r.titles[message.PostID]; ok {
+			prefix += fmt.Sprintf(" in \"%s\"", title)
+		}
+		prefix += ": "
+	}
+
+	_, err := fmt.Fprintf(r.w, "%s%s\n", prefix, strings.ReplaceAll(message.Text, "\n", "\n    "))
+	return err
+}
+
+func (r *textRenderer) End() error {
+	return nil
+}
+
+func indent(text string) string {
+	return "    " + strings.ReplaceAll(text, "\n", "\n    ")
+}
@@ -0,0 +1,84 @@
+// Package importer reads chat history exported from other messengers into an Archive
+// that the import service turns into chats, channels and messages
+package importer
+
+import "time"
+
+const (
+	SourceTelegram = "telegram"
+	SourceSlack    = "slack"
+)
+
+// Archive is the history read from one export
+type Archive struct {
+	Source string
+	// SelfID is the external id of the user who made the export, it maps to the importing user
+	SelfID  string
+	Users   []User
+	Chats   []Chat
+	Skipped []Skip
+}
+
+// User is an author or member in the export
+type User struct {
+	ExternalID string
+	// Username is the account name in the source messenger, empty when the export does not have it
+	Username string
+	Name     string
+}
+
+// Chat is a conversation of the export. Private chats have PeerID and one channel
+type Chat struct {
+	ExternalID string
+	Name       string
+	Type       string
+	PeerID     string
+	MemberIDs  []string
+	Channels   []Channel
+}
+
+type Channel struct {
+	Name    string
+	Topic   string
+	Private bool
+	// MemberIDs are set for private channels
+	MemberIDs []string
+	Messages  []Message
+}
+
+type Message struct {
+	SenderID  string
+	Text      string
+	CreatedAt time.Time
+	System    bool
+}
+
+// Skip is a part of the export that was not imported
+type Skip struct {
+	Chat   string
+	Reason string
+	Count  int
+}
+
+const (
+	ChatTypePrivate = "private"
+	ChatTypeGroup   = "group"
+)
+
+// skips counts skipped items by chat and reason keeping the order they were first seen in
+type skips struct {
+	list []Skip
+}
+
+func (s *skips) add(chat string, reason string, count int) {
+	if count == 0 {
+		return
+	}
+	for i := range s.list {
+		if s.list[i].Chat == chat && s.list[i].Reason == reason {
+			s.list[i].Count += count
+			return
+		}
+	}
+	s.list = append(s.list, Skip{Chat: chat, Reason: reason, Count: count})
+}
@@ -0,0 +1,332 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Profile  struct {
+		RealName string `json:"real_name"`
+	} `json:"profile"`
+}
+
+type slackChannel struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Members   []string `json:"members"`
+	IsGeneral bool     `json:"is_general"`
+	Topic     struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+}
+
+type slackMessage struct {
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype"`
+	User     string `json:"user"`
+	BotID    string `json:"bot_id"`
+	Username string `json:"username"`
+	Text     string `json:"text"`
+	Ts       string `json:"ts"`
+	Files    []struct {
+		Name string `json:"name"`
+	} `json:"files"`
+}
+
+// slackSystemSubtypes are imported as system messages
+var slackSystemSubtypes = map[string]bool{
+	"channel_join":    true,
+	"channel_leave":   true,
+	"channel_topic":   true,
+	"channel_purpose": true,
+	"channel_name":    true,
+	"group_join":      true,
+	"group_leave":     true,
+}
+
+// slackReference matches <@U123>, <#C123|name>, <!here> and <https://link|label> in message text
+var slackReference = regexp.MustCompile(`<([^<>]+)>`)
+
+// ReadSlack reads a Slack workspace export zip. The workspace becomes one group chat named
+// chatName with a channel for every public and private Slack channel. Direct messages are skipped
+func ReadSlack(zipPath string, chatName string) (Archive, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return Archive{}, fmt.Errorf("failed to open slack export: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	root := ""
+	for _, file := range reader.File {
+		files[file.Name] = file
+		if path.Base(file.Name) == "users.json" {
+			root = path.Dir(file.Name)
+		}
+	}
+	if root == "." {
+		root = ""
+	}
+	name := func(parts ...string) string {
+		return path.Join(append([]string{root}, parts...)...)
+	}
+
+	var slackUsers []slackUser
+	if err := readZipJSON(files, name("users.json"), &slackUsers); err != nil {
+		return Archive{}, err
+	}
+
+	var public, private []slackChannel
+	if err := readZipJSON(files, name("channels.json"), &public); err != nil {
+		return Archive{}, err
+	}
+	if _, ok := files[name("groups.json")]; ok {
+		if err := readZipJSON(files, name("groups.json"), &private); err != nil {
+			return Archive{}, err
+		}
+	}
+
+	var skipped skips
+	for _, dms := range []string{"dms.json", "mpims.json"} {
+		if _, ok := files[name(dms)]; !ok {
+			continue
+		}
+		var conversations []slackChannel
+		if err := readZipJSON(files, name(dms), &conversations); err != nil {
+			return Archive{}, err
+		}
+		skipped.add(chatName, "direct message conversations are not imported", len(conversations))
+	}
+
+	archive := Archive{Source: SourceSlack}
+	names := make(map[string]string, len(slackUsers))
+	for _, user := range slackUsers {
+		realName := user.RealName
+		if realName == "" {
+			realName = user.Profile.RealName
+		}
+		archive.Users = append(archive.Users, User{ExternalID: user.ID, Username: user.Name, Name: realName})
+		names[user.ID] = user.Name
+	}
+	knownUsers := len(archive.Users)
+
+	chat := Chat{ExternalID: chatName, Name: chatName, Type: ChatTypeGroup}
+	members := make(map[string]struct{})
+	addMember := func(id string) {
+		if _, ok := members[id]; !ok {
+			members[id] = struct{}{}
+			chat.MemberIDs = append(chat.MemberIDs, id)
+		}
+	}
+
+	// the general channel goes first, it is imported into the main channel of the chat
+	for _, slackChannels := range [][]slackChannel{public, private} {
+		sort.SliceStable(slackChannels, func(i, j int) bool {
+			if slackChannels[i].IsGeneral != slackChannels[j].IsGeneral {
+				return slackChannels[i].IsGeneral
+			}
+			return slackChannels[i].Name < slackChannels[j].Name
+		})
+	}
+
+	for i, slackChannels := range [][]slackChannel{public, private} {
+		for _, slackChannel := range slackChannels {
+			channel := Channel{
+				Name:    slackChannel.Name,
+				Topic:   slackChannel.Topic.Value,
+				Private: i == 1,
+			}
+			if channel.Topic == "" {
+				channel.Topic = slackChannel.Purpose.Value
+			}
+			for _, memberID := range slackChannel.Members {
+				addMember(memberID)
+			}
+			if channel.Private {
+				channel.MemberIDs = slackChannel.Members
+			}
+
+			messages, err := readSlackMessages(files, name(slackChannel.Name), names, &skipped, chatName+" #"+slackChannel.Name)
+			if err != nil {
+				return Archive{}, err
+			}
+			for _, message := range messages {
+				addMember(message.SenderID)
+				if _, ok := names[message.SenderID]; !ok {
+					// bots and integrations are not in users.json
+					names[message.SenderID] = message.SenderID
+					archive.Users = append(archive.Users, User{ExternalID: message.SenderID})
+				}
+			}
+			channel.Messages = messages
+			chat.Channels = append(chat.Channels, channel)
+		}
+	}
+
+	if len(archive.Users) > knownUsers {
+		sort.SliceStable(archive.Users[knownUsers:], func(i, j int) bool {
+			return archive.Users[knownUsers+i].ExternalID < archive.Users[knownUsers+j].ExternalID
+		})
+	}
+
+	archive.Chats = []Chat{chat}
+	archive.Skipped = skipped.list
+	return archive, nil
+}
+
+// readSlackMessages reads the daily files of the channel directory in chronological order
+func readSlackMessages(files map[string]*zip.File, dir string, names map[string]string, skipped *skips, channelName string) ([]Message, error) {
+	var days []string
+	for fileName := range files {
+		if path.Dir(fileName) == dir && path.Ext(fileName) == ".json" {
+			days = append(days, fileName)
+		}
+	}
+	sort.Strings(days)
+
+	var messages []Message
+	for _, day := range days {
+		var slackMessages []slackMessage
+		if err := readZipJSON(files, day, &slackMessages); err != nil {
+			return nil, err
+		}
+
+		for _, slackMessage := range slackMessages {
+			message, ok := convertSlackMessage(slackMessage, names, skipped, channelName)
+			if ok {
+				messages = append(messages, message)
+			}
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, nil
+}
+
+func convertSlackMessage(slackMessage slackMessage, names map[string]string, skipped *skips, channelName string) (Message, bool) {
+	if slackMessage.Type != "message" {
+		skipped.add(channelName, "unsupported message type "+slackMessage.Type, 1)
+		return Message{}, false
+	}
+
+	createdAt, err := slackTime(slackMessage.Ts)
+	if err != nil {
+		skipped.add(channelName, "invalid message timestamp", 1)
+		return Message{}, false
+	}
+
+	senderID := slackMessage.User
+	if senderID == "" && slackMessage.BotID != "" {
+		senderID = slackMessage.BotID
+	}
+
+	text := slackText(slackMessage.Text, names)
+	if len(slackMessage.Files) > 0 {
+		skipped.add(channelName, "attachments are not imported, a placeholder is kept in the text", len(slackMessage.Files))
+		for _, file := range slackMessage.Files {
+			text = strings.TrimSpace(text + " [file " + file.Name + "]")
+		}
+	}
+
+	if senderID == "" || text == "" {
+		skipped.add(channelName, "messages without author or content", 1)
+		return Message{}, false
+	}
+
+	return Message{
+		SenderID:  senderID,
+		Text:      text,
+		CreatedAt: createdAt,
+		System:    slackSystemSubtypes[slackMessage.Subtype],
+	}, true
+}
+
+// slackText replaces Slack references with plain text
+func slackText(text string, names map[string]string) string {
+	text = slackReference.ReplaceAllStringFunc(text, func(ref string) string {
+		ref = strings.Trim(ref, "<>")
+		target, label, hasLabel := strings.Cut(ref, "|")
+
+		switch {
+		case strings.HasPrefix(target, "@"):
+			if name, ok := names[target[1:]]; ok {
+				return "@" + name
+			}
+			if hasLabel {
+				return "@" + label
+			}
+			return target
+		case strings.HasPrefix(target, "#"):
+			if hasLabel {
+				return "#" + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			special := strings.TrimPrefix(target, "!")
+			if special == "channel" || special == "everyone" {
+				return "@all"
+			}
+			return "@" + special
+		case hasLabel:
+			return label + " (" + target + ")"
+		default:
+			return target
+		}
+	})
+
+	replacer := strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+	return replacer.Replace(text)
+}
+
+// slackTime parses ts like 1355517523.000005
+func slackTime(ts string) (time.Time, error) {
+	secondsPart, microsPart, _ := strings.Cut(ts, ".")
+
+	seconds, err := strconv.ParseInt(secondsPart, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var micros int64
+	if microsPart != "" {
+		if micros, err = strconv.ParseInt(microsPart, 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Unix(seconds, micros*int64(time.Microsecond)).UTC(), nil
+}
+
+func readZipJSON(files map[string]*zip.File, name string, v any) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("slack export has no %s", name)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeSlackExport zips files into a temporary export and returns its path
+func writeSlackExport(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	zipPath := filepath.Join(t.TempDir(), "export.zip")
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer out.Close()
+
+	writer := zip.NewWriter(out)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Create(%s) error = %v", name, err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatalf("Write(%s) error = %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return zipPath
+}
+
+func TestReadSlack(t *testing.T) {
+	zipPath := writeSlackExport(t, map[string]string{
+		"workspace/users.json": `[
+			{"id": "U1", "name": "alice", "real_name": "Alice"},
+			{"id": "U2", "name": "bob", "profile": {"real_name": "Bob"}}
+		]`,
+		"workspace/channels.json": `[
+			{"id": "C2", "name": "random", "members": ["U2"], "purpose": {"value": "anything"}},
+			{"id": "C1", "name": "general", "is_general": true, "members": ["U1", "U2"], "topic": {"value": "news"}}
+		]`,
+		"workspace/groups.json": `[{"id": "G1", "name": "staff", "members": ["U1"]}]`,
+		"workspace/dms.json":    `[{"id": "D1", "members": ["U1", "U2"]}, {"id": "D2", "members": ["U1", "U1"]}]`,
+		"workspace/general/2024-01-02.json": `[
+			{"type": "message", "user": "U2", "text": "<!channel> see <https://example.com|the docs>", "ts": "1704153600.000100"}
+		]`,
+		"workspace/general/2024-01-01.json": `[
+			{"type": "message", "subtype": "channel_join", "user": "U2", "text": "<@U2> has joined the channel", "ts": "1704067200.000000"},
+			{"type": "message", "user": "U1", "text": "hi <@U2> &amp; <#C2|random>", "ts": "1704067260.000000"}
+		]`,
+		"workspace/random/2024-01-01.json": `[
+			{"type": "message", "subtype": "bot_message", "bot_id": "B1", "text": "deployed", "ts": "1704067200.000000"},
+			{"type": "message", "user": "U2", "text": "", "files": [{"name": "cat.png"}], "ts": "1704067260.000000"},
+			{"type": "message", "user": "U2", "text": "", "ts": "1704067320.000000"},
+			{"type": "reaction", "user": "U2", "ts": "1704067380.000000"}
+		]`,
+		"workspace/staff/2024-01-01.json": `[
+			{"type": "message", "user": "U1", "text": "secret", "ts": "bad"}
+		]`,
+	})
+
+	archive, err := ReadSlack(zipPath, "acme")
+	if err != nil {
+		t.Fatalf("ReadSlack() error = %v", err)
+	}
+	if archive.Source != SourceSlack || len(archive.Chats) != 1 {
+		t.Fatalf("archive = %+v, want one slack chat", archive)
+	}
+
+	chat := archive.Chats[0]
+	if chat.Name != "acme" || chat.Type != ChatTypeGroup {
+		t.Errorf("chat = %q of type %q, want the acme group", chat.Name, chat.Type)
+	}
+	if want := []string{"U1", "U2", "B1"}; !reflect.DeepEqual(chat.MemberIDs, want) {
+		t.Errorf("members = %v, want %v", chat.MemberIDs, want)
+	}
+
+	var channels []string
+	for _, channel := range chat.Channels {
+		channels = append(channels, channel.Name)
+	}
+	if want := []string{"general", "random", "staff"}; !reflect.DeepEqual(channels, want) {
+		t.Fatalf("channels = %v, want %v", channels, want)
+	}
+
+	general := chat.Channels[0]
+	if general.Topic != "news" || general.Private || general.MemberIDs != nil {
+		t.Errorf("general = %+v, want a public channel with the topic", general)
+	}
+	wantGeneral := []Message{
+		{SenderID: "U2", Text: "@bob has joined the channel", CreatedAt: time.Unix(1704067200, 0).UTC(), System: true},
+		{SenderID: "U1", Text: "hi @bob & #random", CreatedAt: time.Unix(1704067260, 0).UTC()},
+		{SenderID: "U2", Text: "@all see the docs (https://example.com)", CreatedAt: time.Unix(1704153600, 100000).UTC()},
+	}
+	if !reflect.DeepEqual(general.Messages, wantGeneral) {
+		t.Errorf("general messages = %+v, want %+v", general.Messages, wantGeneral)
+	}
+
+	random := chat.Channels[1]
+	if random.Topic != "anything" {
+		t.Errorf("random topic = %q, want the purpose", random.Topic)
+	}
+	var texts []string
+	for _, message := range random.Messages {
+		texts = append(texts, message.Text)
+	}
+	if want := []string{"deployed", "[file cat.png]"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("random messages = %q, want %q", texts, want)
+	}
+
+	staff := chat.Channels[2]
+	if !staff.Private || !reflect.DeepEqual(staff.MemberIDs, []string{"U1"}) {
+		t.Errorf("staff = %+v, want a private channel of U1", staff)
+	}
+
+	wantUsers := []User{
+		{ExternalID: "U1", Username: "alice", Name: "Alice"},
+		{ExternalID: "U2", Username: "bob", Name: "Bob"},
+		{ExternalID: "B1"},
+	}
+	if !reflect.DeepEqual(archive.Users, wantUsers) {
+		t.Errorf("users = %+v, want %+v", archive.Users, wantUsers)
+	}
+
+	wantSkipped := []Skip{
+		{Chat: "acme", Reason: "direct message conversations are not imported", Count: 2},
+		{Chat: "acme #random", Reason: "attachments are not imported, a placeholder is kept in the text", Count: 1},
+		{Chat: "acme #random", Reason: "messages without author or content", Count: 1},
+		{Chat: "acme #random", Reason: "unsupported message type reaction", Count: 1},
+		{Chat: "acme #staff", Reason: "invalid message timestamp", Count: 1},
+	}
+	if !reflect.DeepEqual(archive.Skipped, wantSkipped) {
+		t.Errorf("skipped = %+v, want %+v", archive.Skipped, wantSkipped)
+	}
+}
+
+func TestReadSlackMissingFiles(t *testing.T) {
+	zipPath := writeSlackExport(t, map[string]string{"users.json": `[]`})
+
+	if _, err := ReadSlack(zipPath, "acme"); err == nil {
+		t.Error("ReadSlack() error = nil, want an error about channels.json")
+	}
+}
+
+func TestSlackText(t *testing.T) {
+	names := map[string]string{"U1": "alice"}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"known user", "hi <@U1>", "hi @alice"},
+		{"unknown user with label", "hi <@U9|carol>", "hi @carol"},
+		{"unknown user", "hi <@U9>", "hi @U9"},
+		{"channel", "see <#C1|general>", "see #general"},
+		{"everyone", "<!everyone> hi", "@all hi"},
+		{"here", "<!here> hi", "@here hi"},
+		{"link with label", "<https://example.com|docs>", "docs (https://example.com)"},
+		{"bare link", "<https://example.com>", "https://example.com"},
+		{"escapes", "a &lt;b&gt; &amp; c", "a <b> & c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackText(tt.text, names); got != tt.want {
+				t.Errorf("slackText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// telegramExport is result.json of Telegram Desktop. A single chat export is the chat object itself,
+// a full export has personal_information and lists of chats
+type telegramExport struct {
+	telegramChat
+
+	PersonalInformation *struct {
+		UserID int64 `json:"user_id"`
+	} `json:"personal_information"`
+	Chats *struct {
+		List []telegramChat `json:"list"`
+	} `json:"chats"`
+	LeftChats *struct {
+		List []telegramChat `json:"list"`
+	} `json:"left_chats"`
+}
+
+type telegramChat struct {
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Messages []telegramMessage `json:"messages"`
+}
+
+type telegramMessage struct {
+	Type         string       `json:"type"`
+	Date         string       `json:"date"`
+	DateUnixtime string       `json:"date_unixtime"`
+	From         string       `json:"from"`
+	FromID       string       `json:"from_id"`
+	Actor        string       `json:"actor"`
+	ActorID      string       `json:"actor_id"`
+	Action       string       `json:"action"`
+	Title        string       `json:"title"`
+	Members      []string     `json:"members"`
+	Text         telegramText `json:"text"`
+	Photo        string       `json:"photo"`
+	File         string       `json:"file"`
+	FileName     string       `json:"file_name"`
+	MediaType    string       `json:"media_type"`
+	StickerEmoji string       `json:"sticker_emoji"`
+}
+
+// telegramText is either a string or a list of strings and entities with text
+type telegramText string
+
+func (t *telegramText) UnmarshalJSON(data []byte) error {
+	var plain string
+	if err := json.Unmarshal(data, &plain); err == nil {
+		*t = telegramText(plain)
+		return nil
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &plain); err == nil {
+			b.WriteString(plain)
+		} else if err := json.Unmarshal(part, &entity); err == nil {
+			b.WriteString(entity.Text)
+		}
+	}
+	*t = telegramText(b.String())
+	return nil
+}
+
+var telegramChatTypes = map[string]string{
+	"personal_chat":      ChatTypePrivate,
+	"private_group":      ChatTypeGroup,
+	"public_group":       ChatTypeGroup,
+	"private_supergroup": ChatTypeGroup,
+	"public_supergroup":  ChatTypeGroup,
+	"private_channel":    ChatTypeGroup,
+	"public_channel":     ChatTypeGroup,
+}
+
+// ReadTelegram reads a Telegram Desktop JSON export. selfID is the Telegram user id of the exporting
+// user, it is needed for single chat exports of private chats and may be empty for full exports
+func ReadTelegram(r io.Reader, selfID string) (Archive, error) {
+	var export telegramExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Archive{}, fmt.Errorf("failed to decode telegram export: %w", err)
+	}
+
+	archive := Archive{Source: SourceTelegram, SelfID: telegramUserID(selfID)}
+	if export.PersonalInformation != nil {
+		archive.SelfID = "user" + strconv.FormatInt(export.PersonalInformation.UserID, 10)
+	}
+
+	var chats []telegramChat
+	if export.Chats != nil {
+		chats = append(chats, export.Chats.List...)
+	}
+	if export.LeftChats != nil {
+		chats = append(chats, export.LeftChats.List...)
+	}
+	if export.Chats == nil && export.LeftChats == nil {
+		chats = append(chats, export.telegramChat)
+	}
+
+	var skipped skips
+	users := make(map[string]*User)
+	var order []string
+	addUser := func(id string, name string) {
+		if id == "" {
+			return
+		}
+		if _, ok := users[id]; !ok {
+			users[id] = &User{ExternalID: id, Name: name}
+			order = append(order, id)
+		}
+	}
+
+	for _, tgChat := range chats {
+		name := tgChat.Name
+		if name == "" {
+			name = strconv.FormatInt(tgChat.ID, 10)
+		}
+
+		chatType, ok := telegramChatTypes[tgChat.Type]
+		if !ok {
+			skipped.add(name, "unsupported chat type "+tgChat.Type, len(tgChat.Messages))
+			continue
+		}
+
+		chat := Chat{
+			ExternalID: strconv.FormatInt(tgChat.ID, 10),
+			Name:       name,
+			Type:       chatType,
+		}
+		if chatType == ChatTypePrivate {
+			// the id of a personal chat is the id of the other user
+			chat.PeerID = "user" + chat.ExternalID
+			addUser(chat.PeerID, name)
+			if archive.SelfID == "" {
+				skipped.add(name, "own telegram user id is unknown", len(tgChat.Messages))
+				continue
+			}
+		}
+
+		channel := Channel{Name: "Main"}
+		members := make(map[string]struct{})
+		for _, tgMessage := range tgChat.Messages {
+			message, ok := convertTelegramMessage(tgMessage, &skipped, name)
+			if !ok {
+				continue
+			}
+
+			senderName := tgMessage.From
+			if message.System {
+				senderName = tgMessage.Actor
+			}
+			addUser(message.SenderID, senderName)
+			if _, ok := members[message.SenderID]; !ok {
+				members[message.SenderID] = struct{}{}
+				chat.MemberIDs = append(chat.MemberIDs, message.SenderID)
+			}
+			channel.Messages = append(channel.Messages, message)
+		}
+		chat.Channels = []Channel{channel}
+		archive.Chats = append(archive.Chats, chat)
+	}
+
+	for _, id := range order {
+		archive.Users = append(archive.Users, *users[id])
+	}
+	archive.Skipped = skipped.list
+
+	return archive, nil
+}
+
+func convertTelegramMessage(tgMessage telegramMessage, skipped *skips, chatName string) (Message, bool) {
+	createdAt, err := telegramDate(tgMessage)
+	if err != nil {
+		skipped.add(chatName, "invalid message date", 1)
+		return Message{}, false
+	}
+
+	switch tgMessage.Type {
+	case "message":
+		text := string(tgMessage.Text)
+		if media := telegramMedia(tgMessage); media != "" {
+			skipped.add(chatName, "attachments are not imported, a placeholder is kept in the text", 1)
+			text = strings.TrimSpace(media + " " + text)
+		}
+		if tgMessage.FromID == "" || text == "" {
+			skipped.add(chatName, "messages without author or content", 1)
+			return Message{}, false
+		}
+
+		return Message{SenderID: tgMessage.FromID, Text: text, CreatedAt: createdAt}, true
+
+	case "service":
+		if tgMessage.ActorID == "" {
+			skipped.add(chatName, "service messages without actor", 1)
+			return Message{}, false
+		}
+
+		text := strings.TrimSpace(tgMessage.Actor + " " + strings.ReplaceAll(tgMessage.Action, "_", " "))
+		if tgMessage.Title != "" {
+			text += " \"" + tgMessage.Title + "\""
+		}
+		if len(tgMessage.Members) > 0 {
+			text += " " + strings.Join(tgMessage.Members, ", ")
+		}
+
+		return Message{SenderID: tgMessage.ActorID, Text: text, CreatedAt: createdAt, System: true}, true
+
+	default:
+		skipped.add(chatName, "unsupported message type "+tgMessage.Type, 1)
+		return Message{}, false
+	}
+}
+
+// telegramDate prefers date_unixtime of newer exports, older exports only have local time without zone
+func telegramDate(tgMessage telegramMessage) (time.Time, error) {
+	if tgMessage.DateUnixtime != "" {
+		seconds, err := strconv.ParseInt(tgMessage.DateUnixtime, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Parse("2006-01-02T15:04:05", tgMessage.Date)
+}
+
+func telegramMedia(tgMessage telegramMessage) string {
+	switch {
+	case tgMessage.StickerEmoji != "":
+		return "[sticker " + tgMessage.StickerEmoji + "]"
+	case tgMessage.Photo != "":
+		return "[photo]"
+	case tgMessage.FileName != "":
+		return "[file " + tgMessage.FileName + "]"
+	case tgMessage.MediaType != "":
+		return "[" + strings.ReplaceAll(tgMessage.MediaType, "_", " ") + "]"
+	case tgMessage.File != "":
+		return "[file]"
+	default:
+		return ""
+	}
+}
+
+// telegramUserID turns a numeric user id into the from_id form used in exports
+func telegramUserID(id string) string {
+	if id == "" || strings.HasPrefix(id, "user") {
+		return id
+	}
+	return "user" + id
+}
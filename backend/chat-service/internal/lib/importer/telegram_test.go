@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const telegramFullExport = `{
+	"personal_information": {"user_id": 1},
+	"chats": {"list": [
+		{"id": 2, "name": "Bob", "type": "personal_chat", "messages": [
+			{"type": "message", "date_unixtime": "1700000000", "from": "Alice", "from_id": "user1", "text": "hi"},
+			{"type": "message", "date_unixtime": "1700000060", "from": "Bob", "from_id": "user2",
+				"text": ["see ", {"type": "link", "text": "example.com"}]},
+			{"type": "message", "date_unixtime": "1700000120", "from": "Bob", "from_id": "user2", "photo": "photos/1.jpg", "text": ""},
+			{"type": "message", "date_unixtime": "1700000180", "from": "Bob", "from_id": "user2", "text": ""},
+			{"type": "service", "date_unixtime": "1700000240", "actor": "Bob", "actor_id": "user2", "action": "pin_message", "text": ""},
+			{"type": "poll", "date_unixtime": "1700000300", "from_id": "user2", "text": ""}
+		]},
+		{"id": 3, "name": "", "type": "saved_messages", "messages": [
+			{"type": "message", "date_unixtime": "1700000000", "from_id": "user1", "text": "note"}
+		]}
+	]},
+	"left_chats": {"list": [
+		{"id": 4, "name": "Team", "type": "private_supergroup", "messages": [
+			{"type": "service", "date": "2023-11-14T10:00:00", "actor": "Carol", "actor_id": "user5",
+				"action": "invite_members", "members": ["Alice", "Dave"], "text": ""}
+		]}
+	]}
+}`
+
+func TestReadTelegram(t *testing.T) {
+	archive, err := ReadTelegram(strings.NewReader(telegramFullExport), "")
+	if err != nil {
+		t.Fatalf("ReadTelegram() error = %v", err)
+	}
+
+	if archive.Source != SourceTelegram || archive.SelfID != "user1" {
+		t.Errorf("source = %q, self = %q, want telegram export of user1", archive.Source, archive.SelfID)
+	}
+	if len(archive.Chats) != 2 {
+		t.Fatalf("%d chats, want the personal chat and the group", len(archive.Chats))
+	}
+
+	private := archive.Chats[0]
+	if private.Type != ChatTypePrivate || private.PeerID != "user2" || len(private.Channels) != 1 {
+		t.Errorf("private chat = %+v, want a private chat with user2 and one channel", private)
+	}
+	var texts []string
+	for _, message := range private.Channels[0].Messages {
+		texts = append(texts, message.Text)
+	}
+	wantTexts := []string{"hi", "see example.com", "[photo]", "Bob pin message"}
+	if !reflect.DeepEqual(texts, wantTexts) {
+		t.Errorf("messages = %q, want %q", texts, wantTexts)
+	}
+	if pin := private.Channels[0].Messages[3]; !pin.System || pin.SenderID != "user2" {
+		t.Errorf("pin = %+v, want a system message of user2", pin)
+	}
+	if first := private.Channels[0].Messages[0]; !first.CreatedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("created_at = %v, want the date_unixtime", first.CreatedAt)
+	}
+
+	group := archive.Chats[1]
+	if group.Type != ChatTypeGroup || group.Name != "Team" {
+		t.Errorf("left chat = %+v, want the Team group", group)
+	}
+	invite := group.Channels[0].Messages[0]
+	if invite.Text != `Carol invite members Alice, Dave` || !invite.CreatedAt.Equal(time.Date(2023, 11, 14, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("invite = %+v, want the invite of Alice and Dave at the local date", invite)
+	}
+
+	wantUsers := []User{{ExternalID: "user2", Name: "Bob"}, {ExternalID: "user1", Name: "Alice"}, {ExternalID: "user5", Name: "Carol"}}
+	if !reflect.DeepEqual(archive.Users, wantUsers) {
+		t.Errorf("users = %+v, want %+v", archive.Users, wantUsers)
+	}
+
+	wantSkipped := []Skip{
+		{Chat: "Bob", Reason: "attachments are not imported, a placeholder is kept in the text", Count: 1},
+		{Chat: "Bob", Reason: "messages without author or content", Count: 1},
+		{Chat: "Bob", Reason: "unsupported message type poll", Count: 1},
+		{Chat: "3", Reason: "unsupported chat type saved_messages", Count: 1},
+	}
+	if !reflect.DeepEqual(archive.Skipped, wantSkipped) {
+		t.Errorf("skipped = %+v, want %+v", archive.Skipped, wantSkipped)
+	}
+}
+
+func TestReadTelegramSingleChat(t *testing.T) {
+	const export = `{"id": 2, "name": "Bob", "type": "personal_chat", "messages": [
+		{"type": "message", "date_unixtime": "1700000000", "from": "Bob", "from_id": "user2", "text": "hi"}
+	]}`
+
+	tests := []struct {
+		name        string
+		selfID      string
+		wantSelfID  string
+		wantChats   int
+		wantSkipped []Skip
+	}{
+		{
+			name:        "own id unknown",
+			wantSkipped: []Skip{{Chat: "Bob", Reason: "own telegram user id is unknown", Count: 1}},
+		},
+		{name: "numeric own id", selfID: "1", wantSelfID: "user1", wantChats: 1},
+		{name: "from_id own id", selfID: "user1", wantSelfID: "user1", wantChats: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, err := ReadTelegram(strings.NewReader(export), tt.selfID)
+			if err != nil {
+				t.Fatalf("ReadTelegram() error = %v", err)
+			}
+			if archive.SelfID != tt.wantSelfID || len(archive.Chats) != tt.wantChats {
+				t.Errorf("self = %q, %d chats, want %q and %d", archive.SelfID, len(archive.Chats), tt.wantSelfID, tt.wantChats)
+			}
+			if !reflect.DeepEqual(archive.Skipped, tt.wantSkipped) {
+				t.Errorf("skipped = %+v, want %+v", archive.Skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestReadTelegramInvalid(t *testing.T) {
+	if _, err := ReadTelegram(strings.NewReader(`[1, 2]`), ""); err == nil {
+		t.Error("ReadTelegram() error = nil, want a decode error")
+	}
+}
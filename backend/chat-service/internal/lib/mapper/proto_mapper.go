@@ -1,6 +1,9 @@
 package mapper
 
 import (
+	"sort"
+	"time"
+
 	chatpb "chat-service/gen"
 	"chat-service/internal/domain"
 
@@ -9,11 +12,40 @@ import (
 
 func ConvertMessageToProto(msg *domain.Message) *chatpb.Message {
 	return &chatpb.Message{
-		MessageId: msg.ID,
-		ChannelId: msg.ChannelID,
-		Text:      msg.Text,
-		SenderId:  msg.SenderID,
-		CreatedAt: timestamppb.New(msg.CreatedAt),
+		MessageId:  msg.ID,
+		ChannelId:  msg.ChannelID,
+		Text:       msg.Text,
+		SenderId:   msg.SenderID,
+		CreatedAt:  timestamppb.New(msg.CreatedAt),
+		Type:       msg.Type,
+		PostId:     msg.PostID,
+		Call:       ConvertCallRecordToProto(msg.Call),
+		SenderName: msg.SenderName,
+		Webhook:    ConvertWebhookSenderToProto(msg.Webhook),
+	}
+}
+
+func ConvertWebhookSenderToProto(sender *domain.WebhookSender) *chatpb.MessageWebhook {
+	if sender == nil {
+		return nil
+	}
+	return &chatpb.MessageWebhook{
+		WebhookId: sender.WebhookID,
+		Name:      sender.Name,
+		AvatarUrl: sender.AvatarURL,
+	}
+}
+
+func ConvertCallRecordToProto(record *domain.CallRecord) *chatpb.CallRecord {
+	if record == nil {
+		return nil
+	}
+	return &chatpb.CallRecord{
+		CallId:          record.CallID,
+		CallerId:        record.CallerID,
+		Video:           record.Video,
+		EndReason:       record.EndReason,
+		DurationSeconds: record.DurationSeconds,
 	}
 }
 
@@ -27,8 +59,11 @@ func ConvertMessagesToProto(messages []*domain.Message) []*chatpb.Message {
 
 func ConvertChatPreviewToProto(chatPrw *domain.ChatPreview) *chatpb.ChatPreview {
 	return &chatpb.ChatPreview{
-		ChatId: chatPrw.ID,
-		Name:   chatPrw.Name,
+		ChatId:      chatPrw.ID,
+		Name:        chatPrw.Name,
+		Archived:    chatPrw.Archived,
+		CommunityId: chatPrw.CommunityID,
+		PeerId:      chatPrw.PeerID,
 	}
 }
 
@@ -41,12 +76,45 @@ func ConvertChatPreviewsToProto(chatPreviews []*domain.ChatPreview) []*chatpb.Ch
 }
 
 func ConvertChannelToProto(chn domain.Channel) *chatpb.Channel {
+	mode := chn.Mode
+	if mode == "" {
+		mode = domain.ChannelModeNormal
+	}
+
 	return &chatpb.Channel{
-		ChannelId:  chn.ID,
-		ChatId:     chn.ChatID,
-		Name:       chn.Name,
-		Type:       chn.Type,
-		MessageIds: chn.MessageIDs,
+		ChannelId:           chn.ID,
+		ChatId:              chn.ChatID,
+		Name:                chn.Name,
+		Type:                chn.Type,
+		PermissionOverrides: ConvertPermissionOverridesToProto(chn.PermissionOverrides),
+		Topic:               chn.Topic,
+		Description:         chn.Description,
+		Position:            chn.Position,
+		Category:            chn.Category,
+		Archived:            chn.Archived,
+		Private:             chn.Private,
+		MemberIds:           chn.MemberIDs,
+		AllowedRoles:        chn.AllowedRoles,
+		Mode:                mode,
+		PosterIds:           chn.PosterIDs,
+		Tags:                chn.Tags,
+		Retention:           ConvertRetentionToProto(chn.Retention),
+		MessageCount:        chn.MessageCount,
+		LastMessage:         ConvertLastMessageToProto(chn.ID, chn.LastMessage),
+	}
+}
+
+func ConvertLastMessageToProto(channelID string, last *domain.LastMessage) *chatpb.Message {
+	if last == nil {
+		return nil
+	}
+	return &chatpb.Message{
+		MessageId: last.MessageID,
+		ChannelId: channelID,
+		Text:      last.Text,
+		SenderId:  last.SenderID,
+		CreatedAt: timestamppb.New(last.CreatedAt),
+		Type:      last.Type,
 	}
 }
 
@@ -57,3 +125,310 @@ func ConvertChannelsToProto(channels []domain.Channel) []*chatpb.Channel {
 	}
 	return protoChannels
 }
+
+func ConvertUpdateToProto(update *domain.Update) *chatpb.Update {
+	protoUpdate := &chatpb.Update{
+		Seq:       update.Seq,
+		Type:      update.Type,
+		ChatId:    update.ChatID,
+		ChannelId: update.ChannelID,
+		MessageId: update.MessageID,
+		UserIds:   update.UserIDs,
+		CreatedAt: timestamppb.New(update.CreatedAt),
+	}
+	if update.Message != nil {
+		protoUpdate.Message = ConvertMessageToProto(update.Message)
+	}
+	if update.Chat != nil {
+		protoUpdate.Chat = ConvertChatToProto(update.Chat)
+	}
+	if update.Channel != nil {
+		protoUpdate.Channel = ConvertChannelToProto(*update.Channel)
+	}
+	if update.Post != nil {
+		protoUpdate.Post = ConvertPostToProto(update.Post)
+	}
+	if update.Call != nil {
+		protoUpdate.Call = ConvertCallToProto(*update.Call)
+	}
+	if update.PurgedBefore != nil {
+		protoUpdate.PurgedBefore = timestamppb.New(*update.PurgedBefore)
+	}
+	return protoUpdate
+}
+
+func ConvertUpdatesToProto(updates []*domain.Update) []*chatpb.Update {
+	protoUpdates := make([]*chatpb.Update, len(updates))
+	for i, update := range updates {
+		protoUpdates[i] = ConvertUpdateToProto(update)
+	}
+	return protoUpdates
+}
+
+func ConvertPermissionsToProto(perms domain.Permission) []string {
+	var names []string
+	for perm, name := range domain.PermissionNames {
+		if perms&perm != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ConvertPermissionsFromProto(names []string) (domain.Permission, error) {
+	var perms domain.Permission
+	for _, name := range names {
+		found := false
+		for perm, permName := range domain.PermissionNames {
+			if permName == name {
+				perms |= perm
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, domain.ErrInvalidPermission
+		}
+	}
+	return perms, nil
+}
+
+func ConvertPermissionOverridesToProto(overrides []domain.PermissionOverride) []*chatpb.PermissionOverride {
+	protoOverrides := make([]*chatpb.PermissionOverride, len(overrides))
+	for i, override := range overrides {
+		protoOverrides[i] = &chatpb.PermissionOverride{
+			Role:   override.Role,
+			UserId: override.UserID,
+			Allow:  ConvertPermissionsToProto(override.Allow),
+			Deny:   ConvertPermissionsToProto(override.Deny),
+		}
+	}
+	return protoOverrides
+}
+
+func ConvertPermissionOverridesFromProto(protoOverrides []*chatpb.PermissionOverride) ([]domain.PermissionOverride, error) {
+	overrides := make([]domain.PermissionOverride, len(protoOverrides))
+	for i, protoOverride := range protoOverrides {
+		allow, err := ConvertPermissionsFromProto(protoOverride.GetAllow())
+		if err != nil {
+			return nil, err
+		}
+		deny, err := ConvertPermissionsFromProto(protoOverride.GetDeny())
+		if err != nil {
+			return nil, err
+		}
+
+		overrides[i] = domain.PermissionOverride{
+			Role:   protoOverride.GetRole(),
+			UserID: protoOverride.GetUserId(),
+			Allow:  allow,
+			Deny:   deny,
+		}
+	}
+	return overrides, nil
+}
+
+func ConvertInviteToProto(invite *domain.Invite) *chatpb.Invite {
+	protoInvite := &chatpb.Invite{
+		InviteId:         invite.ID,
+		ChatId:           invite.ChatID,
+		Code:             invite.Code,
+		Link:             invite.Link,
+		CreatorId:        invite.CreatorID,
+		MaxUses:          invite.MaxUses,
+		Uses:             invite.Uses,
+		RequiresApproval: invite.RequiresApproval,
+		Revoked:          invite.Revoked,
+		CreatedAt:        timestamppb.New(invite.CreatedAt),
+	}
+	if invite.ExpiresAt != nil {
+		protoInvite.ExpiresAt = timestamppb.New(*invite.ExpiresAt)
+	}
+	return protoInvite
+}
+
+func ConvertInvitesToProto(invites []*domain.Invite) []*chatpb.Invite {
+	protoInvites := make([]*chatpb.Invite, len(invites))
+	for i, invite := range invites {
+		protoInvites[i] = ConvertInviteToProto(invite)
+	}
+	return protoInvites
+}
+
+func ConvertWebhookToProto(webhook *domain.Webhook) *chatpb.Webhook {
+	return &chatpb.Webhook{
+		WebhookId: webhook.ID,
+		ChatId:    webhook.ChatID,
+		ChannelId: webhook.ChannelID,
+		Name:      webhook.Name,
+		AvatarUrl: webhook.AvatarURL,
+		CreatorId: webhook.CreatorID,
+		Revoked:   webhook.Revoked,
+		CreatedAt: timestamppb.New(webhook.CreatedAt),
+		Url:       webhook.URL,
+	}
+}
+
+func ConvertWebhooksToProto(webhooks []*domain.Webhook) []*chatpb.Webhook {
+	protoWebhooks := make([]*chatpb.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		protoWebhooks[i] = ConvertWebhookToProto(webhook)
+	}
+	return protoWebhooks
+}
+
+func ConvertJoinRecordToProto(record *domain.JoinRecord) *chatpb.JoinRequest {
+	return &chatpb.JoinRequest{
+		RequestId:  record.ID,
+		ChatId:     record.ChatID,
+		UserId:     record.UserID,
+		InviteId:   record.InviteID,
+		Status:     record.Status,
+		ReviewerId: record.ReviewerID,
+		CreatedAt:  timestamppb.New(record.CreatedAt),
+	}
+}
+
+func ConvertJoinRecordsToProto(records []*domain.JoinRecord) []*chatpb.JoinRequest {
+	protoRecords := make([]*chatpb.JoinRequest, len(records))
+	for i, record := range records {
+		protoRecords[i] = ConvertJoinRecordToProto(record)
+	}
+	return protoRecords
+}
+
+func ConvertChatToProto(chat *domain.Chat) *chatpb.Chat {
+	return &chatpb.Chat{
+		ChatId:      chat.ID,
+		Type:        chat.Type,
+		Name:        chat.Name,
+		MemberIds:   chat.MemberIDs,
+		Topic:       chat.Topic,
+		Description: chat.Description,
+		Archived:    chat.Archived,
+		CommunityId: chat.CommunityID,
+		Retention:   ConvertRetentionToProto(chat.Retention),
+	}
+}
+
+func ConvertPostToProto(post *domain.Post) *chatpb.ForumPost {
+	return &chatpb.ForumPost{
+		PostId:         post.ID,
+		ChannelId:      post.ChannelID,
+		AuthorId:       post.AuthorID,
+		Title:          post.Title,
+		Text:           post.Text,
+		Tags:           post.Tags,
+		CreatedAt:      timestamppb.New(post.CreatedAt),
+		LastActivityAt: timestamppb.New(post.LastActivityAt),
+		ReplyCount:     post.ReplyCount,
+	}
+}
+
+func ConvertPostsToProto(posts []*domain.Post) []*chatpb.ForumPost {
+	protoPosts := make([]*chatpb.ForumPost, len(posts))
+	for i, post := range posts {
+		protoPosts[i] = ConvertPostToProto(post)
+	}
+	return protoPosts
+}
+
+func ConvertVoiceParticipantsToProto(participants []domain.VoiceParticipant) []*chatpb.VoiceParticipant {
+	protoParticipants := make([]*chatpb.VoiceParticipant, len(participants))
+	for i, participant := range participants {
+		protoParticipants[i] = ConvertVoiceParticipantToProto(participant)
+	}
+	return protoParticipants
+}
+
+func ConvertVoiceParticipantToProto(participant domain.VoiceParticipant) *chatpb.VoiceParticipant {
+	return &chatpb.VoiceParticipant{
+		UserId:   participant.UserID,
+		Muted:    participant.Muted,
+		Deafened: participant.Deafened,
+		JoinedAt: timestamppb.New(participant.JoinedAt),
+	}
+}
+
+func ConvertSignalToProto(signal domain.Signal) *chatpb.SignalEvent {
+	return &chatpb.SignalEvent{
+		ChannelId:  signal.ChannelID,
+		CallId:     signal.CallID,
+		FromUserId: signal.FromUserID,
+		Type:       signal.Type,
+		Payload:    signal.Payload,
+	}
+}
+
+func ConvertSignalFromProto(req *chatpb.SignalRequest) domain.Signal {
+	return domain.Signal{
+		ChannelID: req.GetChannelId(),
+		CallID:    req.GetCallId(),
+		ToUserID:  req.GetToUserId(),
+		Type:      req.GetType(),
+		Payload:   req.GetPayload(),
+	}
+}
+
+func ConvertCallToProto(call domain.Call) *chatpb.Call {
+	protoCall := &chatpb.Call{
+		CallId:          call.ID,
+		ChatId:          call.ChatID,
+		CallerId:        call.CallerID,
+		CalleeId:        call.CalleeID,
+		Video:           call.Video,
+		State:           call.State,
+		EndReason:       call.EndReason,
+		CreatedAt:       timestamppb.New(call.CreatedAt),
+		DurationSeconds: int64(call.Duration().Seconds()),
+	}
+	if !call.AcceptedAt.IsZero() {
+		protoCall.AcceptedAt = timestamppb.New(call.AcceptedAt)
+	}
+	if !call.EndedAt.IsZero() {
+		protoCall.EndedAt = timestamppb.New(call.EndedAt)
+	}
+	return protoCall
+}
+
+func ConvertCommunityToProto(community *domain.Community) *chatpb.Community {
+	return &chatpb.Community{
+		CommunityId:   community.ID,
+		Name:          community.Name,
+		Description:   community.Description,
+		MemberIds:     community.MemberIDs,
+		ChatIds:       community.ChatIDs,
+		DefaultChatId: community.DefaultChatID,
+		Roles:         community.Roles,
+	}
+}
+
+func ConvertCommunitiesToProto(communities []*domain.Community) []*chatpb.Community {
+	protoCommunities := make([]*chatpb.Community, len(communities))
+	for i, community := range communities {
+		protoCommunities[i] = ConvertCommunityToProto(community)
+	}
+	return protoCommunities
+}
+
+func ConvertRetentionToProto(policy *domain.RetentionPolicy) *chatpb.RetentionPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &chatpb.RetentionPolicy{
+		MaxAgeDays: int32(policy.MaxAge / (24 * time.Hour)),
+		LegalHold:  policy.LegalHold,
+	}
+}
+
+// ConvertRetentionFromProto returns nil for an unset policy
+func ConvertRetentionFromProto(policy *chatpb.RetentionPolicy) *domain.RetentionPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &domain.RetentionPolicy{
+		MaxAge:    time.Duration(policy.GetMaxAgeDays()) * 24 * time.Hour,
+		LegalHold: policy.GetLegalHold(),
+	}
+}
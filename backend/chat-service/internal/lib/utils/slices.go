@@ -22,3 +22,14 @@ func UniqueStrings(input []string) []string {
 
 	return result
 }
+
+// Exclude returns elements of the slice that are not in values
+func Exclude(slice []string, values []string) []string {
+	result := make([]string, 0, len(slice))
+	for _, v := range slice {
+		if !Contains(values, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
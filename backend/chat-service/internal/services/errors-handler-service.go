@@ -14,13 +14,81 @@ func handleServiceError(err error, op, defaultLogText string, log *slog.Logger)
 		log.Error("user has no access", logger.Err(domain.ErrAccessDenied))
 		return fmt.Errorf("%s: %w", op, domain.ErrAccessDenied)
 
+	case errors.Is(err, domain.ErrPermissionDenied):
+		log.Error("user has not enough permissions", logger.Err(domain.ErrPermissionDenied))
+		return fmt.Errorf("%s: %w", op, domain.ErrPermissionDenied)
+	case errors.Is(err, domain.ErrOwnerCannotLeave):
+		log.Error("owner cannot leave the chat", logger.Err(domain.ErrOwnerCannotLeave))
+		return fmt.Errorf("%s: %w", op, domain.ErrOwnerCannotLeave)
+	case errors.Is(err, domain.ErrAnnouncementOnly):
+		log.Error("user cannot post in announcement channel", logger.Err(domain.ErrAnnouncementOnly))
+		return fmt.Errorf("%s: %w", op, domain.ErrAnnouncementOnly)
+	case errors.Is(err, domain.ErrArchived):
+		log.Error("chat or channel is archived", logger.Err(domain.ErrArchived))
+		return fmt.Errorf("%s: %w", op, domain.ErrArchived)
+	case errors.Is(err, domain.ErrLastChannel):
+		log.Error("cannot delete the last channel of a chat", logger.Err(domain.ErrLastChannel))
+		return fmt.Errorf("%s: %w", op, domain.ErrLastChannel)
+
 	case errors.Is(err, domain.ErrChatNotFound):
 		log.Error("chat not found", logger.Err(domain.ErrChatNotFound))
 		return fmt.Errorf("%s: %w", op, domain.ErrChatNotFound)
+	case errors.Is(err, domain.ErrCommunityNotFound):
+		log.Error("community not found", logger.Err(domain.ErrCommunityNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrCommunityNotFound)
 	case errors.Is(err, domain.ErrChannelNotFound):
 		log.Error("channel not found", logger.Err(domain.ErrChannelNotFound))
 		return domain.ErrChannelNotFound
 
+	case errors.Is(err, domain.ErrPostNotFound):
+		log.Error("post not found", logger.Err(domain.ErrPostNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrPostNotFound)
+
+	case errors.Is(err, domain.ErrNotVoiceParticipant):
+		log.Error("user is not connected to this voice channel", logger.Err(domain.ErrNotVoiceParticipant))
+		return fmt.Errorf("%s: %w", op, domain.ErrNotVoiceParticipant)
+	case errors.Is(err, domain.ErrCallNotFound):
+		log.Error("call not found", logger.Err(domain.ErrCallNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrCallNotFound)
+	case errors.Is(err, domain.ErrCallInProgress):
+		log.Error("user is already in a call", logger.Err(domain.ErrCallInProgress))
+		return fmt.Errorf("%s: %w", op, domain.ErrCallInProgress)
+	case errors.Is(err, domain.ErrInvalidCallState):
+		log.Error("action is not allowed in the current call state", logger.Err(domain.ErrInvalidCallState))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidCallState)
+
+	case errors.Is(err, domain.ErrUserNotFound):
+		log.Error("user not found", logger.Err(domain.ErrUserNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+	case errors.Is(err, domain.ErrUserBlocked):
+		log.Error("one of the users blocked the other", logger.Err(domain.ErrUserBlocked))
+		return fmt.Errorf("%s: %w", op, domain.ErrUserBlocked)
+	case errors.Is(err, domain.ErrNotChatMember):
+		log.Error("user is not a member of this chat", logger.Err(domain.ErrNotChatMember))
+		return fmt.Errorf("%s: %w", op, domain.ErrNotChatMember)
+	case errors.Is(err, domain.ErrNotCommunityMember):
+		log.Error("user is not a member of this community", logger.Err(domain.ErrNotCommunityMember))
+		return fmt.Errorf("%s: %w", op, domain.ErrNotCommunityMember)
+	case errors.Is(err, domain.ErrCommunityChat):
+		log.Error("members of community chats are managed by the community", logger.Err(domain.ErrCommunityChat))
+		return fmt.Errorf("%s: %w", op, domain.ErrCommunityChat)
+	case errors.Is(err, domain.ErrDefaultCommunityChat):
+		log.Error("default chat of a community cannot be deleted", logger.Err(domain.ErrDefaultCommunityChat))
+		return fmt.Errorf("%s: %w", op, domain.ErrDefaultCommunityChat)
+	case errors.Is(err, domain.ErrNotChannelMember):
+		log.Error("user is not a member of this channel", logger.Err(domain.ErrNotChannelMember))
+		return fmt.Errorf("%s: %w", op, domain.ErrNotChannelMember)
+
+	case errors.Is(err, domain.ErrInviteNotFound):
+		log.Error("invite not found", logger.Err(domain.ErrInviteNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrInviteNotFound)
+	case errors.Is(err, domain.ErrJoinReqNotFound):
+		log.Error("join request not found", logger.Err(domain.ErrJoinReqNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrJoinReqNotFound)
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		log.Error("webhook not found", logger.Err(domain.ErrWebhookNotFound))
+		return fmt.Errorf("%s: %w", op, domain.ErrWebhookNotFound)
+
 	case errors.Is(err, domain.ErrChatExists):
 		log.Warn("chat already exists!", logger.Err(domain.ErrChatExists))
 		return fmt.Errorf("%s: %w", op, domain.ErrChatExists)
@@ -30,6 +98,63 @@ func handleServiceError(err error, op, defaultLogText string, log *slog.Logger)
 	case errors.Is(err, domain.ErrInvalidUserCountPrivateChat):
 		log.Error("invalid input: private chat must contain only 1 user_id", logger.Err(domain.ErrInvalidUserCountPrivateChat))
 		return fmt.Errorf("%s: %w", op, domain.ErrInvalidUserCountPrivateChat)
+	case errors.Is(err, domain.ErrPrivateChatMembers):
+		log.Error("invalid input: private chat must keep exactly two members", logger.Err(domain.ErrPrivateChatMembers))
+		return fmt.Errorf("%s: %w", op, domain.ErrPrivateChatMembers)
+	case errors.Is(err, domain.ErrInvalidRole):
+		log.Error("invalid input: invalid role", logger.Err(domain.ErrInvalidRole))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidRole)
+	case errors.Is(err, domain.ErrInvalidPermission):
+		log.Error("invalid input: invalid permission override", logger.Err(domain.ErrInvalidPermission))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidPermission)
+	case errors.Is(err, domain.ErrInvalidInvite):
+		log.Warn("invite is not usable", logger.Err(domain.ErrInvalidInvite))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidInvite)
+	case errors.Is(err, domain.ErrInvalidInviteParams):
+		log.Error("invalid input: invalid invite params", logger.Err(domain.ErrInvalidInviteParams))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidInviteParams)
+	case errors.Is(err, domain.ErrEmptyName):
+		log.Error("invalid input: name must be not empty", logger.Err(domain.ErrEmptyName))
+		return fmt.Errorf("%s: %w", op, domain.ErrEmptyName)
+	case errors.Is(err, domain.ErrPrivateChatName):
+		log.Error("invalid input: private chat cannot be renamed", logger.Err(domain.ErrPrivateChatName))
+		return fmt.Errorf("%s: %w", op, domain.ErrPrivateChatName)
+	case errors.Is(err, domain.ErrInvalidChannelMode):
+		log.Error("invalid input: channel mode must be only normal or announcement", logger.Err(domain.ErrInvalidChannelMode))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidChannelMode)
+	case errors.Is(err, domain.ErrNotForumChannel):
+		log.Error("invalid input: channel is not a forum", logger.Err(domain.ErrNotForumChannel))
+		return fmt.Errorf("%s: %w", op, domain.ErrNotForumChannel)
+	case errors.Is(err, domain.ErrForumPostRequired):
+		log.Error("invalid input: messages in forum channel must be replies to a post", logger.Err(domain.ErrForumPostRequired))
+		return fmt.Errorf("%s: %w", op, domain.ErrForumPostRequired)
+	case errors.Is(err, domain.ErrNotVoiceChannel):
+		log.Error("invalid input: channel is not a voice channel", logger.Err(domain.ErrNotVoiceChannel))
+		return fmt.Errorf("%s: %w", op, domain.ErrNotVoiceChannel)
+	case errors.Is(err, domain.ErrInvalidExportFormat):
+		log.Error("invalid input: invalid export format", logger.Err(domain.ErrInvalidExportFormat))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidExportFormat)
+	case errors.Is(err, domain.ErrInvalidRetention):
+		log.Error("invalid input: retention max age must be not negative", logger.Err(domain.ErrInvalidRetention))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidRetention)
+	case errors.Is(err, domain.ErrInvalidAvatarURL):
+		log.Error("invalid input: avatar url must be an absolute http or https url", logger.Err(domain.ErrInvalidAvatarURL))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidAvatarURL)
+	case errors.Is(err, domain.ErrInvalidSignal):
+		log.Error("invalid input: invalid signal", logger.Err(domain.ErrInvalidSignal))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidSignal)
+	case errors.Is(err, domain.ErrInvalidTag):
+		log.Error("invalid input: invalid tag", logger.Err(domain.ErrInvalidTag))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidTag)
+	case errors.Is(err, domain.ErrEmptyTitle):
+		log.Error("invalid input: post title must be not empty", logger.Err(domain.ErrEmptyTitle))
+		return fmt.Errorf("%s: %w", op, domain.ErrEmptyTitle)
+	case errors.Is(err, domain.ErrInvalidPage):
+		log.Error("invalid input: invalid pagination params", logger.Err(domain.ErrInvalidPage))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidPage)
+	case errors.Is(err, domain.ErrInvalidPosition):
+		log.Error("invalid input: channel position must be not negative", logger.Err(domain.ErrInvalidPosition))
+		return fmt.Errorf("%s: %w", op, domain.ErrInvalidPosition)
 	case errors.Is(err, domain.ErrInvalidMessage):
 		log.Error("invalid message length", logger.Err(domain.ErrInvalidMessage))
 		return fmt.Errorf("%s: %w", op, domain.ErrInvalidMessage)
@@ -38,6 +163,9 @@ func handleServiceError(err error, op, defaultLogText string, log *slog.Logger)
 		log.Error("invalid input: private chat can be created only with another person", logger.Err(domain.ErrSameUser))
 		return fmt.Errorf("%s: %w", op, domain.ErrSameUser)
 
+	case errors.Is(err, domain.ErrEmptyCommunityName):
+		log.Error("invalid input: community name must be not empty", logger.Err(domain.ErrEmptyCommunityName))
+		return fmt.Errorf("%s: %w", op, domain.ErrEmptyCommunityName)
 	case errors.Is(err, domain.ErrEmptyGroupName):
 		log.Error("invalid input: group name must be not empty", logger.Err(domain.ErrEmptyGroupName))
 		return fmt.Errorf("%s: %w", op, domain.ErrEmptyGroupName)
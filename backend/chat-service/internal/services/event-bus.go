@@ -0,0 +1,240 @@
+package services
+
+import (
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/mapper"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const subscriberBufferSize = 16
+
+type subscriber struct {
+	userID    string
+	chatID    string
+	channelID string
+	events    chan *chatpb.ChatStreamResponse
+	// closed is closed when the user loses access to the chat
+	closed chan struct{}
+}
+
+// EventBus records updates into the per-user update log and delivers them to open streams
+type EventBus struct {
+	log            *slog.Logger
+	updateProvider interfaces.UpdateProvider
+
+	// subscriptions are grouped by chat_id so chat-wide events reach every channel stream
+	subscriptions map[string][]*subscriber
+	// accessLost are told about users who lost access to channels of a chat
+	accessLost []func(chatID string, lost accessMatcher)
+	mu         sync.Mutex
+}
+
+// accessMatcher reports whether the user lost access to the channel
+type accessMatcher func(channelID string, userID string) bool
+
+func NewEventBus(log *slog.Logger, updateProvider interfaces.UpdateProvider) *EventBus {
+	return &EventBus{
+		log:            log,
+		updateProvider: updateProvider,
+
+		subscriptions: make(map[string][]*subscriber),
+	}
+}
+
+func (eventBus *EventBus) subscribe(chatID string, channelID string, userID string) *subscriber {
+	sub := &subscriber{
+		userID:    userID,
+		chatID:    chatID,
+		channelID: channelID,
+		events:    make(chan *chatpb.ChatStreamResponse, subscriberBufferSize),
+		closed:    make(chan struct{}),
+	}
+
+	eventBus.mu.Lock()
+	eventBus.subscriptions[chatID] = append(eventBus.subscriptions[chatID], sub)
+	eventBus.mu.Unlock()
+
+	return sub
+}
+
+func (eventBus *EventBus) unsubscribe(sub *subscriber) {
+	eventBus.mu.Lock()
+	defer eventBus.mu.Unlock()
+
+	subs := eventBus.subscriptions[sub.chatID]
+	for i, s := range subs {
+		if s == sub {
+			eventBus.subscriptions[sub.chatID] = append(subs[:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(eventBus.subscriptions[sub.chatID]) == 0 {
+		delete(eventBus.subscriptions, sub.chatID)
+	}
+}
+
+// onAccessLost registers fn to be called whenever streams are closed because users lost access
+func (eventBus *EventBus) onAccessLost(fn func(chatID string, lost accessMatcher)) {
+	eventBus.mu.Lock()
+	defer eventBus.mu.Unlock()
+
+	eventBus.accessLost = append(eventBus.accessLost, fn)
+}
+
+// closeUserStreams detaches every stream of the user in the chat and signals them to stop
+func (eventBus *EventBus) closeUserStreams(chatID string, userID string) {
+	eventBus.closeStreams(chatID, func(_ string, id string) bool { return id == userID })
+}
+
+// closeChannelStreams detaches every stream of a deleted channel
+func (eventBus *EventBus) closeChannelStreams(chatID string, channelID string) {
+	eventBus.closeStreams(chatID, func(id string, _ string) bool { return id == channelID })
+}
+
+// closeChannelUserStreams detaches streams of users who lost access to the channel
+func (eventBus *EventBus) closeChannelUserStreams(chatID string, channelID string, userIDs []string) {
+	eventBus.closeStreams(chatID, func(id string, userID string) bool {
+		return id == channelID && utils.Contains(userIDs, userID)
+	})
+}
+
+// closeChatStreams detaches every stream of a deleted chat
+func (eventBus *EventBus) closeChatStreams(chatID string) {
+	eventBus.closeStreams(chatID, func(string, string) bool { return true })
+}
+
+func (eventBus *EventBus) closeStreams(chatID string, lost accessMatcher) {
+	eventBus.mu.Lock()
+	subs := eventBus.subscriptions[chatID]
+	kept := subs[:0]
+	for _, sub := range subs {
+		if lost(sub.channelID, sub.userID) {
+			close(sub.closed)
+			continue
+		}
+		kept = append(kept, sub)
+	}
+
+	if len(kept) == 0 {
+		delete(eventBus.subscriptions, chatID)
+	} else {
+		eventBus.subscriptions[chatID] = kept
+	}
+	accessLost := eventBus.accessLost
+	eventBus.mu.Unlock()
+
+	// listeners may publish events, so they run without the lock
+	for _, fn := range accessLost {
+		fn(chatID, lost)
+	}
+}
+
+// publish writes the update to the log of every member and sends the event to their open streams.
+// With scopeChannelID the event reaches only streams of that channel, otherwise all streams of the chat.
+// Inside a transaction the update is published once it commits
+func (eventBus *EventBus) publish(ctx context.Context, log *slog.Logger, memberIDs []string, scopeChannelID string, update domain.Update, payload *chatpb.ChatStreamResponse) {
+	if holdUntilCommit(ctx, func(ctx context.Context) {
+		eventBus.publish(ctx, log, memberIDs, scopeChannelID, update, payload)
+	}) {
+		return
+	}
+
+	log.Debug("recording updates", slog.String("type", update.Type))
+	seqs, err := eventBus.updateProvider.SaveUpdates(ctx, update, memberIDs)
+	if err != nil {
+		log.Warn("failed to record updates", logger.Err(err))
+	}
+
+	if payload == nil {
+		return
+	}
+
+	eventBus.send(log, update.ChatID, memberIDs, scopeChannelID, payload, seqs)
+}
+
+// notify sends an ephemeral event to open streams of the members without recording it in the update log
+func (eventBus *EventBus) notify(log *slog.Logger, chatID string, memberIDs []string, scopeChannelID string, payload *chatpb.ChatStreamResponse) {
+	eventBus.send(log, chatID, memberIDs, scopeChannelID, payload, nil)
+}
+
+func (eventBus *EventBus) send(log *slog.Logger, chatID string, memberIDs []string, scopeChannelID string, payload *chatpb.ChatStreamResponse, seqs map[string]int64) {
+	log.Debug("publishing event")
+	eventBus.mu.Lock()
+	defer eventBus.mu.Unlock()
+
+	for _, sub := range eventBus.subscriptions[chatID] {
+		if scopeChannelID != "" && sub.channelID != scopeChannelID {
+			continue
+		}
+		if !utils.Contains(memberIDs, sub.userID) {
+			continue
+		}
+
+		event := &chatpb.ChatStreamResponse{
+			Payload: payload.Payload,
+			Seq:     seqs[sub.userID],
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			log.Warn("failed to send event to subscriber", slog.String("user_id", sub.userID), slog.String("channel_id", sub.channelID))
+		}
+	}
+}
+
+// publishMessage publishes a saved message to the chat members who see its channel except skipIDs
+func (eventBus *EventBus) publishMessage(ctx context.Context, log *slog.Logger, chat domain.Chat, channel domain.Channel, message *domain.Message, skipIDs ...string) {
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_NewMessage{
+			NewMessage: mapper.ConvertMessageToProto(message),
+		},
+	}
+
+	update := domain.Update{
+		Type:      domain.UpdateNewMessage,
+		ChatID:    chat.ID,
+		ChannelID: message.ChannelID,
+		MessageID: message.ID,
+		Message:   message,
+		CreatedAt: message.CreatedAt,
+	}
+
+	viewerIDs := channel.ViewerIDs(chat)
+	if len(skipIDs) > 0 {
+		viewerIDs = utils.Exclude(viewerIDs, skipIDs)
+	}
+
+	eventBus.publish(ctx, log, viewerIDs, message.ChannelID, update, event)
+}
+
+// publishPurge tells the members who see the channel that its messages created before the moment were deleted
+func (eventBus *EventBus) publishPurge(ctx context.Context, log *slog.Logger, chat domain.Chat, channel domain.Channel, before time.Time) {
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_MessagesPurged{
+			MessagesPurged: &chatpb.MessagesPurged{
+				ChannelId: channel.ID,
+				Before:    timestamppb.New(before),
+			},
+		},
+	}
+
+	update := domain.Update{
+		Type:         domain.UpdateMessagesPurged,
+		ChatID:       chat.ID,
+		ChannelID:    channel.ID,
+		PurgedBefore: &before,
+		CreatedAt:    time.Now(),
+	}
+
+	eventBus.publish(ctx, log, channel.ViewerIDs(chat), channel.ID, update, event)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+	"regexp"
+)
+
+// mentionAllPattern matches @all and @everyone as whole words, so @allen or mail@all.org do not mention anyone
+var mentionAllPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@(?:all|everyone)(?:$|[^\p{L}\p{N}_])`)
+
+// checkPermission checks that the user is a chat member and has every permission from perms.
+// When channel is not nil the user must see the channel and its overrides are applied
+func checkPermission(chat domain.Chat, channel *domain.Channel, userID string, perms domain.Permission) error {
+	if !utils.Contains(chat.MemberIDs, userID) {
+		return domain.ErrAccessDenied
+	}
+	if channel != nil && !channel.CanView(chat, userID) {
+		return domain.ErrAccessDenied
+	}
+
+	if domain.PermissionsOf(chat, channel, userID)&perms != perms {
+		return domain.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// channelValidation finds the channel and its chat and checks that the user sees the channel
+// and has every permission from perms in it
+func channelValidation(
+	ctx context.Context,
+	log *slog.Logger,
+	chatProvider interfaces.ChatProvider,
+	channelProvider interfaces.ChannelProvider,
+	channelID string,
+	userID string,
+	perms domain.Permission,
+) (domain.Chat, domain.Channel, error) {
+	const op = "services.channelValidation"
+
+	log.Debug("checking if channel exists")
+	channel, err := channelProvider.FindChannelByID(ctx, channelID)
+	if err != nil {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(err, op, "check channel existence", log)
+	}
+
+	log.Debug("checking if chat exists")
+	chat, err := chatProvider.FindChatByID(ctx, channel.ChatID, userID)
+	if err != nil {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(err, op, "check chat existence", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, &channel, userID, perms); err != nil {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(err, op, "check user permissions", log)
+	}
+
+	return chat, channel, nil
+}
+
+// checkCommunityPermission checks that the user is a community member and has every permission from perms
+func checkCommunityPermission(community domain.Community, userID string, perms domain.Permission) error {
+	if !utils.Contains(community.MemberIDs, userID) {
+		return domain.ErrAccessDenied
+	}
+
+	if domain.RolePermissions[community.RoleOf(userID)]&perms != perms {
+		return domain.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+func mentionsAll(text string) bool {
+	return mentionAllPattern.MatchString(text)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/boltdb"
+)
+
+func TestMentionsAll(t *testing.T) {
+	tests := map[string]bool{
+		"@all":                     true,
+		"hey @all, deploy is done": true,
+		"ping @everyone!":          true,
+		"(@all)":                   true,
+		"@allen said hi":           false,
+		"@allowed values":          false,
+		"write to mail@all.org":    false,
+		"@everyones":               false,
+		"nothing here":             false,
+	}
+	for text, want := range tests {
+		if got := mentionsAll(text); got != want {
+			t.Errorf("mentionsAll(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	chat := domain.Chat{Type: "group", MemberIDs: []string{"owner", "member"}, Roles: map[string]string{"owner": domain.RoleOwner}}
+	channel := &domain.Channel{
+		Private:             true,
+		MemberIDs:           []string{"member"},
+		PermissionOverrides: []domain.PermissionOverride{{UserID: "member", Allow: domain.PermManageChannels}},
+	}
+	hidden := &domain.Channel{Private: true}
+
+	tests := []struct {
+		name    string
+		channel *domain.Channel
+		userID  string
+		perms   domain.Permission
+		want    error
+	}{
+		{"not a member", nil, "stranger", 0, domain.ErrAccessDenied},
+		{"hidden channel", hidden, "member", 0, domain.ErrAccessDenied},
+		{"member lacks permission", nil, "member", domain.PermManageChannels, domain.ErrPermissionDenied},
+		{"channel override grants permission", channel, "member", domain.PermManageChannels, nil},
+		{"owner", hidden, "owner", domain.PermAll, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkPermission(chat, tt.channel, tt.userID, tt.perms); !errors.Is(err, tt.want) {
+				t.Errorf("checkPermission() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelValidation(t *testing.T) {
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+	ctx := context.Background()
+
+	chatID, err := storage.SaveChat(ctx, domain.Chat{
+		Type:      "group",
+		Name:      "team",
+		MemberIDs: []string{"owner", "member"},
+		Roles:     map[string]string{"owner": domain.RoleOwner},
+	})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+	channelID, err := storage.SaveChannel(ctx, domain.Channel{ChatID: chatID, Name: "general", Type: "text"})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+	hiddenID, err := storage.SaveChannel(ctx, domain.Channel{ChatID: chatID, Name: "staff", Type: "text", Private: true})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		channelID string
+		userID    string
+		perms     domain.Permission
+		want      error
+	}{
+		{"member sees channel", channelID, "member", 0, nil},
+		{"unknown channel", "404", "member", 0, domain.ErrChannelNotFound},
+		{"not a member", channelID, "stranger", 0, domain.ErrAccessDenied},
+		{"hidden channel", hiddenID, "member", 0, domain.ErrAccessDenied},
+		{"member lacks permission", channelID, "member", domain.PermManageChannels, domain.ErrPermissionDenied},
+		{"owner", hiddenID, "owner", domain.PermManageChannels, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat, channel, err := channelValidation(ctx, testLogger(), storage, storage, tt.channelID, tt.userID, tt.perms)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("channelValidation() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (chat.ID != chatID || channel.ID != tt.channelID) {
+				t.Errorf("channelValidation() = chat %s, channel %s, want chat %s, channel %s", chat.ID, channel.ID, chatID, tt.channelID)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ArchiveService moves messages older than maxAge to the archive and applies retention policies
+// to archived messages. Forum channels stay hot, their replies are read by post.
+// Channels are walked page by page like in RetentionService
+type ArchiveService struct {
+	log             *slog.Logger
+	chatProvider    interfaces.ChatProvider
+	channelProvider interfaces.ChannelProvider
+	archive         interfaces.MessageArchive
+	eventBus        *EventBus
+
+	global     domain.RetentionPolicy
+	maxAge     time.Duration
+	interval   time.Duration
+	batchSize  int32
+	batchPause time.Duration
+}
+
+func NewArchiveService(
+	log *slog.Logger,
+	chatProvider interfaces.ChatProvider,
+	channelProvider interfaces.ChannelProvider,
+	archive interfaces.MessageArchive,
+	eventBus *EventBus,
+	global domain.RetentionPolicy,
+	maxAge time.Duration,
+	interval time.Duration,
+	batchSize int32,
+	batchPause time.Duration,
+) *ArchiveService {
+	return &ArchiveService{
+		log:             log,
+		chatProvider:    chatProvider,
+		channelProvider: channelProvider,
+		archive:         archive,
+		eventBus:        eventBus,
+
+		global:     global,
+		maxAge:     maxAge,
+		interval:   interval,
+		batchSize:  batchSize,
+		batchPause: batchPause,
+	}
+}
+
+// Run archives messages every interval until the context is canceled
+func (archiveService *ArchiveService) Run(ctx context.Context) {
+	ticker := time.NewTicker(archiveService.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := archiveService.Archive(ctx); err != nil && ctx.Err() == nil {
+			archiveService.log.Error("failed to archive messages", logger.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Archive walks every channel once, purges archived messages past retention and moves old messages
+func (archiveService *ArchiveService) Archive(ctx context.Context) error {
+	const op = "services.archive.Archive"
+
+	log := archiveService.log.With(slog.String("op", op))
+	log.Info("archiving messages")
+
+	startedAt := time.Now()
+	archivedTotal, purgedTotal := 0, 0
+
+	afterID := ""
+	for {
+		log.Debug("finding channels page", slog.String("after_id", afterID))
+		channels, err := archiveService.channelProvider.FindChannelsPage(ctx, afterID, archiveService.batchSize)
+		if err != nil {
+			return handleServiceError(err, op, "find channels page", log)
+		}
+		if len(channels) == 0 {
+			break
+		}
+		afterID = channels[len(channels)-1].ID
+
+		chatIDs := make([]string, 0, len(channels))
+		for _, channel := range channels {
+			chatIDs = append(chatIDs, channel.ChatID)
+		}
+
+		log.Debug("finding chats of channels")
+		chats, err := archiveService.chatProvider.FindChatsByIDs(ctx, utils.UniqueStrings(chatIDs))
+		if err != nil {
+			return handleServiceError(err, op, "find chats of channels", log)
+		}
+		chatsByID := make(map[string]domain.Chat, len(chats))
+		for _, chat := range chats {
+			chatsByID[chat.ID] = chat
+		}
+
+		for _, channel := range channels {
+			if channel.Type == domain.ChannelTypeForum {
+				continue
+			}
+
+			policy := domain.EffectiveRetention(archiveService.global, chatsByID[channel.ChatID], channel)
+			if !policy.LegalHold && policy.MaxAge > 0 {
+				before := startedAt.Add(-policy.MaxAge)
+
+				log.Debug("purging archived messages", slog.String("channel_id", channel.ID))
+				purged, err := archiveService.archive.PurgeBefore(ctx, channel.ID, before)
+				if err != nil {
+					return handleServiceError(err, op, "purge archived messages", log)
+				}
+				if purged > 0 {
+					archiveService.eventBus.publishPurge(ctx, log, chatsByID[channel.ChatID], channel, before)
+				}
+				purgedTotal += purged
+			}
+
+			// moved messages stay readable through the archive, so moving them is not an update
+
+			archived, err := archiveService.archiveChannel(ctx, log, channel.ID, startedAt.Add(-archiveService.maxAge))
+			if err != nil {
+				return handleServiceError(err, op, "archive channel", log)
+			}
+			if archived == 0 {
+				continue
+			}
+
+			log.Info("channel archived",
+				slog.String("chat_id", channel.ChatID),
+				slog.String("channel_id", channel.ID),
+				slog.Int("messages", archived),
+			)
+			archivedTotal += archived
+		}
+
+		if len(channels) < int(archiveService.batchSize) {
+			break
+		}
+	}
+
+	log.Info("messages archived successfully",
+		slog.Int("archived", archivedTotal),
+		slog.Int("purged", purgedTotal),
+		slog.Duration("took", time.Since(startedAt)),
+	)
+	return nil
+}
+
+// archiveChannel moves messages of the channel batch by batch and returns how many were moved
+func (archiveService *ArchiveService) archiveChannel(ctx context.Context, log *slog.Logger, channelID string, before time.Time) (int, error) {
+	archived := 0
+	for {
+		log.Debug("archiving messages batch", slog.String("channel_id", channelID))
+		moved, err := archiveService.archive.ArchiveMessagesBefore(ctx, channelID, before, archiveService.batchSize)
+		if err != nil {
+			return archived, err
+		}
+		archived += moved
+
+		if moved < int(archiveService.batchSize) {
+			return archived, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return archived, ctx.Err()
+		case <-time.After(archiveService.batchPause):
+		}
+	}
+}
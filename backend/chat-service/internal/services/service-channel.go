@@ -34,7 +34,7 @@ func NewChannelService(log *slog.Logger, chatProvider interfaces.ChatProvider, c
 }
 
 var (
-	allowedChannelTypes = []string{"voice", "text"}
+	allowedChannelTypes = []string{"voice", "text", "forum"}
 )
 
 // FIXME: not working!
@@ -114,10 +114,9 @@ func (c *Channel) CreateChannel(ctx context.Context, chatID string, name string,
 	}
 
 	newCh := domain.Channel{
-		ChatID:     chatID,
-		Name:       name,
-		Type:       chanType,
-		MessageIDs: []string{},
+		ChatID: chatID,
+		Name:   name,
+		Type:   chanType,
 	}
 
 	log.Debug("saving channel")
@@ -69,9 +69,8 @@ func (c *Chat) CreateChat(ctx context.Context, chatType string, name string, use
 	}
 
 	newChat := domain.Chat{
-		MemberIDs:  user_ids,
-		ChannelIDs: []string{},
-		Type:       chatType,
+		MemberIDs: user_ids,
+		Type:      chatType,
 	}
 
 	if chatType == "group" {
@@ -86,10 +85,9 @@ func (c *Chat) CreateChat(ctx context.Context, chatType string, name string, use
 	}
 
 	mainCh := domain.Channel{
-		ChatID:     chatID,
-		Name:       "Main",
-		Type:       "text",
-		MessageIDs: []string{},
+		ChatID: chatID,
+		Name:   "Main",
+		Type:   "text",
 	}
 
 	log.Debug("saving main channel")
@@ -120,7 +118,7 @@ func (c *Chat) GetUserChats(ctx context.Context, chatType string) ([]*chatpb.Cha
 
 	// TODO: add main channel id here
 	log.Debug("getting users chats")
-	chatPreviews, err := c.chatProvider.FindUserChats(ctx, userID, chatType)
+	chatPreviews, err := c.chatProvider.FindUserChats(ctx, userID, chatType, "", false)
 	if err != nil {
 		return nil, handleServiceError(err, op, "get user chats", log)
 
@@ -157,7 +155,7 @@ func (c *Chat) GetChatInfo(ctx context.Context, chatID string) (chatInfo domain.
 	}
 
 	log.Debug("getting channels info")
-	channels, err := c.channelProvider.FindChannelsByIDs(ctx, chat.ChannelIDs)
+	channels, err := c.channelProvider.FindChatChannels(ctx, chat.ID)
 	if err != nil {
 		return domain.ChatInfo{}, handleServiceError(err, op, "get channels info", log)
 
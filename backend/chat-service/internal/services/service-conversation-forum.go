@@ -0,0 +1,136 @@
+package services
+
+import (
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/mapper"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// CreateForumPost starts a new titled thread in a forum channel
+func (conversationService *ConversationService) CreateForumPost(ctx context.Context, channelID string, title string, text string, tags []string) (domain.Post, error) {
+	const op = "services.conversationService.CreateForumPost"
+
+	log := conversationService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("creating forum post")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Post{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return domain.Post{}, handleServiceError(domain.ErrEmptyTitle, op, "check request body", log)
+	}
+
+	chat, channel, err := conversationService.postingValidation(ctx, log, channelID, userID, text)
+	if err != nil {
+		return domain.Post{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking channel type")
+	if channel.Type != domain.ChannelTypeForum {
+		return domain.Post{}, handleServiceError(domain.ErrNotForumChannel, op, "check channel type", log)
+	}
+
+	log.Debug("checking post tags")
+	tags = utils.UniqueStrings(tags)
+	for _, tag := range tags {
+		if !utils.Contains(channel.Tags, tag) {
+			return domain.Post{}, handleServiceError(domain.ErrInvalidTag, op, "check post tags", log)
+		}
+	}
+
+	createdAt := time.Now()
+	post := domain.Post{
+		ChannelID:      channelID,
+		AuthorID:       userID,
+		Title:          title,
+		Text:           text,
+		Tags:           tags,
+		CreatedAt:      createdAt,
+		LastActivityAt: createdAt,
+	}
+
+	log.Debug("saving post")
+	if post.ID, err = conversationService.postProvider.SavePost(ctx, post); err != nil {
+		return domain.Post{}, handleServiceError(err, op, "save post", log)
+	}
+
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_ForumPost{
+			ForumPost: mapper.ConvertPostToProto(&post),
+		},
+	}
+	update := domain.Update{
+		Type:      domain.UpdateNewPost,
+		ChatID:    chat.ID,
+		ChannelID: channelID,
+		Post:      &post,
+		CreatedAt: createdAt,
+	}
+	conversationService.eventBus.publish(ctx, log, channel.ViewerIDs(chat), channelID, update, event)
+
+	log.Info("forum post created successfully", slog.String("post_id", post.ID))
+	return post, nil
+}
+
+// ReplyToPost adds a message to the reply thread of a forum post
+func (conversationService *ConversationService) ReplyToPost(ctx context.Context, postID string, text string) (string, error) {
+	const op = "services.conversationService.ReplyToPost"
+
+	log := conversationService.log.With(slog.String("op", op), slog.String("post_id", postID))
+	log.Info("replying to post")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return "", handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding post by id")
+	post, err := conversationService.postProvider.FindPostByID(ctx, postID)
+	if err != nil {
+		return "", handleServiceError(err, op, "find post by id", log)
+	}
+
+	chat, channel, err := conversationService.postingValidation(ctx, log, post.ChannelID, userID, text)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	newMessage := domain.Message{
+		ChannelID: post.ChannelID,
+		Text:      text,
+		SenderID:  userID,
+		CreatedAt: time.Now(),
+		PostID:    postID,
+	}
+
+	err = inTransaction(ctx, conversationService.transactor, func(ctx context.Context) error {
+		if err := conversationService.deliverMessage(ctx, log, chat, channel, &newMessage); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Debug("updating post activity")
+		if err := conversationService.postProvider.TouchPost(ctx, postID, newMessage.CreatedAt); err != nil {
+			return handleServiceError(err, op, "update post activity", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("reply sent successfully", slog.String("message_id", newMessage.ID))
+	return newMessage.ID, nil
+}
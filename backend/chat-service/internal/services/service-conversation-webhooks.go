@@ -0,0 +1,66 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ExecuteWebhook posts the text into the channel of the webhook on behalf of the webhook.
+// A wrong token and a revoked webhook are reported as a missing webhook, so the endpoint
+// does not tell which webhooks exist. The webhook posts only while its creator may still
+// manage the channel, so it may post in announcement channels
+func (conversationService *ConversationService) ExecuteWebhook(ctx context.Context, webhookID string, token string, text string) (string, error) {
+	const op = "services.conversationService.ExecuteWebhook"
+
+	log := conversationService.log.With(slog.String("op", op), slog.String("webhook_id", webhookID))
+	log.Info("executing webhook")
+
+	log.Debug("finding webhook by id")
+	webhook, err := conversationService.webhookProvider.FindWebhookByID(ctx, webhookID)
+	if err != nil {
+		return "", handleServiceError(err, op, "find webhook by id", log)
+	}
+
+	log.Debug("checking webhook token")
+	tokenHash := hashWebhookToken(token)
+	if webhook.Revoked || subtle.ConstantTimeCompare([]byte(tokenHash), []byte(webhook.TokenHash)) != 1 {
+		return "", handleServiceError(domain.ErrWebhookNotFound, op, "check webhook token", log)
+	}
+
+	log.Debug("vaildating request body")
+	if strings.TrimSpace(text) == "" || len(text) > conversationService.maxMessageLength {
+		return "", handleServiceError(domain.ErrInvalidMessage, op, "validate request body", log)
+	}
+
+	// the creator may have left the chat, lost the role or the view of the channel since
+	chat, channel, err := channelValidation(ctx, log, conversationService.chatProvider, conversationService.channelProvider, webhook.ChannelID, webhook.CreatorID, domain.PermManageChannels)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if chat or channel is archived")
+	if chat.Archived || channel.Archived {
+		return "", handleServiceError(domain.ErrArchived, op, "check if chat or channel is archived", log)
+	}
+
+	newMessage := domain.Message{
+		ChannelID: channel.ID,
+		Text:      text,
+		CreatedAt: time.Now(),
+		Webhook:   webhook.Sender(),
+
+		SenderName: webhook.Name,
+	}
+
+	if err := conversationService.deliverMessage(ctx, log, chat, channel, &newMessage); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("webhook executed successfully", slog.String("message_id", newMessage.ID))
+	return newMessage.ID, nil
+}
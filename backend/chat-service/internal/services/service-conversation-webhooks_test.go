@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"chat-service/internal/domain"
+)
+
+func TestExecuteWebhookChecksCreator(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(managerService *ManagerService, ctx context.Context, chatID string, channelID string) error
+		wantErr error
+	}{
+		{
+			name: "creator manages the channel",
+		},
+		{
+			name: "creator removed from chat",
+			change: func(managerService *ManagerService, ctx context.Context, chatID string, channelID string) error {
+				return managerService.RemoveMember(ctx, chatID, "u-bob")
+			},
+			wantErr: domain.ErrAccessDenied,
+		},
+		{
+			name: "creator demoted",
+			change: func(managerService *ManagerService, ctx context.Context, chatID string, channelID string) error {
+				return managerService.SetMemberRole(ctx, chatID, "u-bob", domain.RoleMember)
+			},
+			wantErr: domain.ErrPermissionDenied,
+		},
+		{
+			name: "channel override denies creator",
+			change: func(managerService *ManagerService, ctx context.Context, chatID string, channelID string) error {
+				return managerService.SetChannelPermissions(ctx, channelID, []domain.PermissionOverride{{UserID: "u-bob", Deny: domain.PermManageChannels}})
+			},
+			wantErr: domain.ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			managerService, storage := newTestManager(t, testUsers)
+			conversationService := NewConversationService(
+				testLogger(),
+				storage,
+				storage,
+				storage,
+				storage,
+				storage,
+				testUsers,
+				storage,
+				NewEventBus(testLogger(), storage),
+				4096,
+			)
+			ctx := userContext("u-alice")
+
+			chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+			if err != nil {
+				t.Fatalf("CreateChat() error = %v", err)
+			}
+			if err := managerService.SetMemberRole(ctx, chatID, "u-bob", domain.RoleAdmin); err != nil {
+				t.Fatalf("SetMemberRole() error = %v", err)
+			}
+			mainChannel, err := findMainChannel(context.Background(), storage, chatID)
+			if err != nil {
+				t.Fatalf("findMainChannel() error = %v", err)
+			}
+			webhook, err := managerService.CreateWebhook(userContext("u-bob"), mainChannel.ID, "ci", "")
+			if err != nil {
+				t.Fatalf("CreateWebhook() error = %v", err)
+			}
+
+			if tt.change != nil {
+				if err := tt.change(managerService, ctx, chatID, mainChannel.ID); err != nil {
+					t.Fatalf("change error = %v", err)
+				}
+			}
+
+			_, err = conversationService.ExecuteWebhook(context.Background(), webhook.ID, path.Base(webhook.URL), "deployed")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExecuteWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
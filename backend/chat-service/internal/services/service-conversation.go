@@ -4,12 +4,10 @@ import (
 	chatpb "chat-service/gen"
 	"chat-service/internal/domain"
 	"chat-service/internal/domain/interfaces"
-	"chat-service/internal/lib/mapper"
 	"chat-service/internal/lib/utils"
 	"context"
 	"fmt"
 	"log/slog"
-	"sync"
 	"time"
 )
 
@@ -18,10 +16,13 @@ type ConversationService struct {
 	chatProvider     interfaces.ChatProvider
 	channelProvider  interfaces.ChannelProvider
 	messageProvider  interfaces.MessageProvider
+	postProvider     interfaces.PostProvider
+	webhookProvider  interfaces.WebhookProvider
+	userProvider     interfaces.UserProvider
+	transactor       interfaces.Transactor
 	maxMessageLength int
 
-	subscriptions map[string][]chan *chatpb.ChatStreamResponse
-	mu            sync.Mutex
+	eventBus *EventBus
 }
 
 func NewConversationService(
@@ -29,6 +30,11 @@ func NewConversationService(
 	chatProvider interfaces.ChatProvider,
 	channelProvider interfaces.ChannelProvider,
 	messageProvider interfaces.MessageProvider,
+	postProvider interfaces.PostProvider,
+	webhookProvider interfaces.WebhookProvider,
+	userProvider interfaces.UserProvider,
+	transactor interfaces.Transactor,
+	eventBus *EventBus,
 	maxMessageLength int,
 ) *ConversationService {
 	return &ConversationService{
@@ -36,9 +42,13 @@ func NewConversationService(
 		chatProvider:     chatProvider,
 		channelProvider:  channelProvider,
 		messageProvider:  messageProvider,
+		postProvider:     postProvider,
+		webhookProvider:  webhookProvider,
+		userProvider:     userProvider,
+		transactor:       transactor,
 		maxMessageLength: maxMessageLength,
 
-		subscriptions: make(map[string][]chan *chatpb.ChatStreamResponse),
+		eventBus: eventBus,
 	}
 }
 
@@ -48,31 +58,17 @@ func (conversationService *ConversationService) SubscribeToChannelEvents(ctx con
 	log := conversationService.log.With(slog.String("op", op), slog.String("channel_id", channelID), slog.String("user_id", userID))
 	log.Info("subscribing to channel events")
 
-	if err := conversationService.channelValidation(ctx, log, channelID, userID); err != nil {
+	chat, _, err := channelValidation(ctx, log, conversationService.chatProvider, conversationService.channelProvider, channelID, userID, 0)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	subscriberChan := make(chan *chatpb.ChatStreamResponse)
-
 	log.Debug("adding subscriber to subscription list")
-	conversationService.mu.Lock()
-	if _, exists := conversationService.subscriptions[channelID]; !exists {
-		conversationService.subscriptions[channelID] = []chan *chatpb.ChatStreamResponse{}
-	}
-	conversationService.subscriptions[channelID] = append(conversationService.subscriptions[channelID], subscriberChan)
-	conversationService.mu.Unlock()
+	sub := conversationService.eventBus.subscribe(chat.ID, channelID, userID)
 
 	defer func() {
 		log.Debug("removing subscriber from subscription list")
-		conversationService.mu.Lock()
-		for i, ch := range conversationService.subscriptions[channelID] {
-			if ch == subscriberChan {
-				conversationService.subscriptions[channelID] = append(conversationService.subscriptions[channelID][:i], conversationService.subscriptions[channelID][i+1:]...)
-				break
-			}
-		}
-		close(subscriberChan)
-		conversationService.mu.Unlock()
+		conversationService.eventBus.unsubscribe(sub)
 	}()
 
 	for {
@@ -81,7 +77,11 @@ func (conversationService *ConversationService) SubscribeToChannelEvents(ctx con
 			log.Info("client disconnected or context canceled")
 			return nil
 
-		case event := <-subscriberChan:
+		case <-sub.closed:
+			log.Info("user lost access to the chat, closing stream")
+			return fmt.Errorf("%s: %w", op, domain.ErrAccessDenied)
+
+		case event := <-sub.events:
 			sendEvent(event)
 		}
 	}
@@ -99,72 +99,103 @@ func (conversationService *ConversationService) SendMessage(ctx context.Context,
 		return "", handleServiceError(err, op, "get user_id from context", log)
 	}
 
-	if err := conversationService.channelValidation(ctx, log, channelID, userID); err != nil {
+	chat, channel, err := conversationService.postingValidation(ctx, log, channelID, userID, text)
+	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	log.Debug("vaildating request body")
-	if len(text) > conversationService.maxMessageLength {
-		return "", handleServiceError(err, op, "validate request body", log)
+	log.Debug("checking channel type")
+	if channel.Type == domain.ChannelTypeForum {
+		return "", handleServiceError(domain.ErrForumPostRequired, op, "check channel type", log)
 	}
 
-	createdAt := time.Now()
 	newMessage := domain.Message{
 		ChannelID: channelID,
 		Text:      text,
 		SenderID:  userID,
-		CreatedAt: createdAt,
+		CreatedAt: time.Now(),
 	}
 
-	log.Debug("saving message")
-	if newMessage.ID, err = conversationService.messageProvider.SaveMessage(ctx, newMessage); err != nil {
-		return "", handleServiceError(err, op, "save message", log)
+	if err := conversationService.deliverMessage(ctx, log, chat, channel, &newMessage); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	protoMessage := mapper.ConvertMessageToProto(&newMessage)
+	log.Info("message sent successfully", slog.String("message_id", newMessage.ID))
+	return newMessage.ID, nil
+}
 
-	log.Debug("adding new message event")
-	event := &chatpb.ChatStreamResponse{
-		Payload: &chatpb.ChatStreamResponse_NewMessage{
-			NewMessage: protoMessage,
-		},
+// postingValidation checks that the user may post the text into the channel
+func (conversationService *ConversationService) postingValidation(ctx context.Context, log *slog.Logger, channelID string, userID string, text string) (domain.Chat, domain.Channel, error) {
+	const op = "services.conversationService.postingValidation"
+
+	perms := domain.PermSendMessages
+	if mentionsAll(text) {
+		perms |= domain.PermMentionAll
 	}
 
-	log.Debug("publishing event")
-	conversationService.mu.Lock()
-	subscribers := conversationService.subscriptions[channelID]
-	for _, subscriber := range subscribers {
-		select {
-		case subscriber <- event:
-		default:
-			log.Warn("failed to send event to subscriber", slog.String("channel_id", channelID))
+	chat, channel, err := channelValidation(ctx, log, conversationService.chatProvider, conversationService.channelProvider, channelID, userID, perms)
+	if err != nil {
+		return domain.Chat{}, domain.Channel{}, err
+	}
+
+	log.Debug("checking if chat or channel is archived")
+	if chat.Archived || channel.Archived {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(domain.ErrArchived, op, "check if chat or channel is archived", log)
+	}
+
+	log.Debug("checking channel mode")
+	if !channel.CanPost(chat, userID) {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(domain.ErrAnnouncementOnly, op, "check channel mode", log)
+	}
+
+	if chat.Type == "private" {
+		if err := checkNotBlocked(ctx, log, conversationService.userProvider, userID, chat.MemberIDs); err != nil {
+			return domain.Chat{}, domain.Channel{}, err
 		}
 	}
-	conversationService.mu.Unlock()
 
-	log.Info("message sent successfully", slog.String("message_id", newMessage.ID))
-	return newMessage.ID, nil
+	log.Debug("vaildating request body")
+	if len(text) > conversationService.maxMessageLength {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(domain.ErrInvalidMessage, op, "validate request body", log)
+	}
+
+	return chat, channel, nil
 }
 
-func (m *ConversationService) channelValidation(ctx context.Context, log *slog.Logger, channelID string, userID string) error {
-	const op = "services.message.channelValidation"
+// deliverMessage saves the message, shows a hidden private chat again and publishes the message.
+// Members who blocked the sender are not notified, the message stays in their history.
+// Messages of webhooks have no sender to block
+func (conversationService *ConversationService) deliverMessage(ctx context.Context, log *slog.Logger, chat domain.Chat, channel domain.Channel, message *domain.Message) error {
+	const op = "services.conversationService.deliverMessage"
+
+	err := inTransaction(ctx, conversationService.transactor, func(ctx context.Context) error {
+		log.Debug("saving message")
+		messageID, err := conversationService.messageProvider.SaveMessage(ctx, *message)
+		if err != nil {
+			return handleServiceError(err, op, "save message", log)
+		}
+		message.ID = messageID
 
-	log.Debug("checking if channel exists")
-	existingChannel, err := m.channelProvider.FindChannelByID(ctx, channelID)
-	if err != nil {
-		return handleServiceError(err, op, "check channel existence", log)
-	}
+		if chat.Type == "private" && len(chat.HiddenFor) > 0 {
+			log.Debug("unhiding private chat")
+			if err := conversationService.chatProvider.UnhideChat(ctx, chat.ID); err != nil {
+				return handleServiceError(err, op, "unhide private chat", log)
+			}
+		}
 
-	log.Debug("checking if chat exists")
-	existingChat, err := m.chatProvider.FindChatByID(ctx, existingChannel.ChatID, userID)
+		return nil
+	})
 	if err != nil {
-		return handleServiceError(err, op, "check chat existence", log)
+		return err
 	}
 
-	log.Debug("checking if user in this chat")
-	if !utils.Contains(existingChat.MemberIDs, userID) {
-		return handleServiceError(domain.ErrAccessDenied, op, "check if user in this chat", log)
+	var blockerIDs []string
+	if chat.Type != "private" && message.SenderID != "" {
+		blockerIDs = blockersOf(ctx, log, conversationService.userProvider, message.SenderID)
 	}
 
+	log.Debug("adding new message event")
+	conversationService.eventBus.publishMessage(ctx, log, chat, channel, message, blockerIDs...)
+
 	return nil
 }
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/importer"
+	"chat-service/internal/lib/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// ImportService turns an importer.Archive into chats of the importing user. Chats and channels
+// are created through the manager service so they get the same defaults and events as created
+// by hand, messages are inserted in bulk with their original timestamps
+type ImportService struct {
+	log             *slog.Logger
+	managerService  interfaces.ManagerService
+	chatProvider    interfaces.ChatProvider
+	channelProvider interfaces.ChannelProvider
+	messageProvider interfaces.MessageProvider
+	userProvider    interfaces.UserProvider
+}
+
+func NewImportService(
+	log *slog.Logger,
+	managerService interfaces.ManagerService,
+	chatProvider interfaces.ChatProvider,
+	channelProvider interfaces.ChannelProvider,
+	messageProvider interfaces.MessageProvider,
+	userProvider interfaces.UserProvider,
+) *ImportService {
+	return &ImportService{
+		log:             log,
+		managerService:  managerService,
+		chatProvider:    chatProvider,
+		channelProvider: channelProvider,
+		messageProvider: messageProvider,
+		userProvider:    userProvider,
+	}
+}
+
+// placeholderPrefixes keep placeholder usernames of different sources apart
+var placeholderPrefixes = map[string]string{
+	importer.SourceTelegram: "tg_",
+	importer.SourceSlack:    "slack_",
+}
+
+var notUsernameChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Import imports the archive on behalf of the user from the context. mapping maps external user ids
+// to user_ids or usernames, other authors are matched by username or get placeholder accounts.
+// The report lists everything that was skipped, it is returned with the error as well
+func (importService *ImportService) Import(ctx context.Context, archive importer.Archive, mapping map[string]string) (domain.ImportReport, error) {
+	const op = "services.import.Import"
+
+	log := importService.log.With(slog.String("op", op), slog.String("source", archive.Source))
+	log.Info("importing archive")
+
+	log.Debug("getting user_id from context")
+	ownerID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.ImportReport{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	var report domain.ImportReport
+	for _, skip := range archive.Skipped {
+		report.Skipped = append(report.Skipped, domain.ImportSkip{Chat: skip.Chat, Reason: skip.Reason, Count: skip.Count})
+	}
+
+	userIDs, err := importService.mapUsers(ctx, log, archive, mapping, ownerID, &report)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, chat := range archive.Chats {
+		imported, err := importService.importChat(ctx, log, chat, userIDs, ownerID, &report)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+		if imported.ChatID != "" {
+			report.Chats = append(report.Chats, imported)
+		}
+	}
+
+	log.Info("archive imported successfully", slog.Int("chats", len(report.Chats)))
+	return report, nil
+}
+
+// mapUsers returns user_ids by external user id
+func (importService *ImportService) mapUsers(
+	ctx context.Context,
+	log *slog.Logger,
+	archive importer.Archive,
+	mapping map[string]string,
+	ownerID string,
+	report *domain.ImportReport,
+) (map[string]string, error) {
+	const op = "services.import.mapUsers"
+
+	userIDs := make(map[string]string, len(archive.Users))
+	if archive.SelfID != "" {
+		userIDs[archive.SelfID] = ownerID
+	}
+
+	log.Debug("resolving user mapping")
+	for externalID, ref := range mapping {
+		resolved, err := resolveUserIDs(ctx, log, importService.userProvider, []string{ref})
+		if err != nil {
+			return nil, handleServiceError(err, op, "resolve user mapping", log)
+		}
+		userIDs[externalID] = resolved[0]
+	}
+
+	var usernames []string
+	for _, user := range archive.Users {
+		if _, ok := userIDs[user.ExternalID]; !ok && user.Username != "" {
+			usernames = append(usernames, user.Username)
+		}
+	}
+	if len(usernames) > 0 {
+		log.Debug("finding users by username")
+		found, err := importService.userProvider.GetUserIDs(ctx, utils.UniqueStrings(usernames))
+		if err != nil {
+			return nil, handleServiceError(err, op, "find users by username", log)
+		}
+		for _, user := range archive.Users {
+			if _, ok := userIDs[user.ExternalID]; ok {
+				continue
+			}
+			if userID, ok := found[user.Username]; ok && user.Username != "" {
+				userIDs[user.ExternalID] = userID
+			}
+		}
+	}
+
+	placeholders := make(map[string]string)
+	var placeholderNames []string
+	for _, user := range archive.Users {
+		if _, ok := userIDs[user.ExternalID]; ok {
+			continue
+		}
+		name := user.Username
+		if name == "" {
+			name = user.ExternalID
+		}
+		username := placeholderPrefixes[archive.Source] + notUsernameChars.ReplaceAllString(name, "_")
+		placeholders[user.ExternalID] = username
+		placeholderNames = append(placeholderNames, username)
+	}
+	if len(placeholderNames) > 0 {
+		log.Debug("creating placeholder users", slog.Int("count", len(placeholderNames)))
+		created, err := importService.userProvider.CreatePlaceholderUsers(ctx, utils.UniqueStrings(placeholderNames))
+		if err != nil {
+			return nil, handleServiceError(err, op, "create placeholder users", log)
+		}
+		for externalID, username := range placeholders {
+			userIDs[externalID] = created[username]
+		}
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		ids = append(ids, userID)
+	}
+	names := displayNames(ctx, log, importService.userProvider, utils.UniqueStrings(ids))
+
+	for _, user := range archive.Users {
+		userID := userIDs[user.ExternalID]
+		_, placeholder := placeholders[user.ExternalID]
+		report.Users = append(report.Users, domain.ImportedUser{
+			ExternalID:  user.ExternalID,
+			Name:        user.Name,
+			UserID:      userID,
+			Username:    nameOrID(names, userID),
+			Placeholder: placeholder,
+		})
+	}
+
+	return userIDs, nil
+}
+
+// importChat creates the chat with its channels and messages. Chats that cannot be created
+// for the user are added to the report and an empty ImportedChat is returned
+func (importService *ImportService) importChat(
+	ctx context.Context,
+	log *slog.Logger,
+	chat importer.Chat,
+	userIDs map[string]string,
+	ownerID string,
+	report *domain.ImportReport,
+) (domain.ImportedChat, error) {
+	const op = "services.import.importChat"
+
+	log = log.With(slog.String("external_id", chat.ExternalID))
+	imported := domain.ImportedChat{ExternalID: chat.ExternalID, Name: chat.Name}
+
+	skip := func(reason string) (domain.ImportedChat, error) {
+		count := 0
+		for _, channel := range chat.Channels {
+			count += len(channel.Messages)
+		}
+		report.Skipped = append(report.Skipped, domain.ImportSkip{Chat: chat.Name, Reason: reason, Count: count})
+		return domain.ImportedChat{}, nil
+	}
+
+	var chatID string
+	var err error
+	if chat.Type == importer.ChatTypePrivate {
+		peerID := userIDs[chat.PeerID]
+		if len(chat.Channels) > 1 {
+			return skip("private chat has more than one channel")
+		}
+
+		log.Debug("creating private chat")
+		chatID, err = importService.managerService.CreateChat(ctx, "private", "", []string{peerID})
+		switch {
+		case errors.Is(err, domain.ErrChatExists):
+			return skip("private chat with the user already exists")
+		case errors.Is(err, domain.ErrSameUser):
+			return skip("chat with yourself")
+		case errors.Is(err, domain.ErrUserBlocked):
+			return skip("user is blocked")
+		}
+	} else {
+		name := chat.Name
+		if strings.TrimSpace(name) == "" {
+			name = chat.ExternalID
+		}
+
+		memberIDs := make([]string, 0, len(chat.MemberIDs))
+		for _, externalID := range chat.MemberIDs {
+			memberIDs = append(memberIDs, userIDs[externalID])
+		}
+
+		log.Debug("creating group chat")
+		chatID, err = importService.managerService.CreateChat(ctx, "group", name, utils.Exclude(memberIDs, []string{ownerID}))
+	}
+	if err != nil {
+		return domain.ImportedChat{}, handleServiceError(err, op, "create chat", log)
+	}
+	imported.ChatID = chatID
+
+	log.Debug("finding chat by id")
+	created, err := importService.chatProvider.FindChatByID(ctx, chatID, ownerID)
+	if err != nil {
+		return imported, handleServiceError(err, op, "find chat by id", log)
+	}
+
+	for i, channel := range chat.Channels {
+		channelID, err := importService.createChannel(ctx, log, created, i, channel, userIDs)
+		if err != nil {
+			return imported, fmt.Errorf("%s: %w", op, err)
+		}
+
+		messages := make([]domain.Message, 0, len(channel.Messages))
+		for _, message := range channel.Messages {
+			saved := domain.Message{
+				SenderID:  userIDs[message.SenderID],
+				Text:      message.Text,
+				CreatedAt: message.CreatedAt,
+			}
+			if message.System {
+				saved.Type = domain.MessageTypeSystem
+			}
+			messages = append(messages, saved)
+		}
+
+		log.Debug("saving messages", slog.String("channel_id", channelID), slog.Int("count", len(messages)))
+		if _, err := importService.messageProvider.SaveMessages(ctx, channelID, messages); err != nil {
+			return imported, handleServiceError(err, op, "save messages", log)
+		}
+
+		imported.Channels++
+		imported.Messages += len(messages)
+	}
+
+	return imported, nil
+}
+
+// createChannel returns the channel to import into. The first channel of the archive goes into
+// the main channel of the new chat, which is renamed after it in group chats
+func (importService *ImportService) createChannel(
+	ctx context.Context,
+	log *slog.Logger,
+	chat domain.Chat,
+	index int,
+	channel importer.Channel,
+	userIDs map[string]string,
+) (string, error) {
+	const op = "services.import.createChannel"
+
+	var channelID string
+	if index == 0 {
+		log.Debug("finding main channel")
+		mainChannel, err := findMainChannel(ctx, importService.channelProvider, chat.ID)
+		if err != nil {
+			return "", handleServiceError(err, op, "find main channel", log)
+		}
+		channelID = mainChannel.ID
+		if chat.Type != "group" || strings.TrimSpace(channel.Name) == "" {
+			return channelID, nil
+		}
+
+		log.Debug("renaming main channel")
+		patch := domain.ChannelPatch{Name: &channel.Name}
+		if channel.Topic != "" {
+			patch.Topic = &channel.Topic
+		}
+		if _, err := importService.managerService.UpdateChannel(ctx, channelID, patch); err != nil {
+			return "", handleServiceError(err, op, "rename main channel", log)
+		}
+	} else {
+		log.Debug("creating channel", slog.String("name", channel.Name))
+		var err error
+		channelID, err = importService.managerService.CreateChannel(ctx, chat.ID, channel.Name, "text", "", nil)
+		if err != nil {
+			return "", handleServiceError(err, op, "create channel", log)
+		}
+
+		if channel.Topic != "" {
+			if _, err := importService.managerService.UpdateChannel(ctx, channelID, domain.ChannelPatch{Topic: &channel.Topic}); err != nil {
+				return "", handleServiceError(err, op, "set channel topic", log)
+			}
+		}
+	}
+
+	if !channel.Private {
+		return channelID, nil
+	}
+
+	log.Debug("making channel private", slog.String("channel_id", channelID))
+	if _, err := importService.managerService.SetChannelAccess(ctx, channelID, true, nil); err != nil {
+		return "", handleServiceError(err, op, "make channel private", log)
+	}
+
+	memberIDs := make([]string, 0, len(channel.MemberIDs))
+	for _, externalID := range channel.MemberIDs {
+		if userID, ok := userIDs[externalID]; ok {
+			memberIDs = append(memberIDs, userID)
+		}
+	}
+	if len(memberIDs) > 0 {
+		if _, err := importService.managerService.AddChannelMembers(ctx, channelID, utils.UniqueStrings(memberIDs)); err != nil {
+			return "", handleServiceError(err, op, "add channel members", log)
+		}
+	}
+
+	return channelID, nil
+}
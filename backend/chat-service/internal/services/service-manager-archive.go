@@ -0,0 +1,318 @@
+package services
+
+import (
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ArchiveChat makes a group chat read-only and hides it from chat lists by default
+func (managerService *ManagerService) ArchiveChat(ctx context.Context, chatID string, archived bool) error {
+	const op = "services.manager.ArchiveChat"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID), slog.Bool("archived", archived))
+	log.Info("archiving chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, nil, userID, domain.PermManageChat); err != nil {
+		return handleServiceError(err, op, "check user permissions", log)
+	}
+
+	log.Debug("checking chat type")
+	if chat.Type == "private" {
+		return handleServiceError(domain.ErrInvalidChatType, op, "check chat type", log)
+	}
+
+	if chat.Archived == archived {
+		log.Info("chat archive state is unchanged")
+		return nil
+	}
+
+	log.Debug("saving chat")
+	if err := managerService.chatProvider.UpdateChat(ctx, chatID, domain.ChatPatch{Archived: &archived}); err != nil {
+		return handleServiceError(err, op, "save chat", log)
+	}
+	chat.Archived = archived
+
+	managerService.publishChatUpdated(ctx, log, chat)
+
+	log.Info("chat archive state changed successfully")
+	return nil
+}
+
+// DeleteChat deletes the chat with its channels, messages and invites.
+// Any member of a private chat may delete it for both sides
+func (managerService *ManagerService) DeleteChat(ctx context.Context, chatID string) error {
+	const op = "services.manager.DeleteChat"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("deleting chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, nil, userID, domain.PermManageChat); err != nil {
+		return handleServiceError(err, op, "check user permissions", log)
+	}
+
+	// the chat and everything in it is deleted at once, a failure leaves it intact
+	err = inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		if chat.CommunityID != "" {
+			log.Debug("finding community of chat")
+			community, err := managerService.communityProvider.FindCommunityByID(ctx, chat.CommunityID)
+			if err != nil {
+				return handleServiceError(err, op, "find community of chat", log)
+			}
+
+			log.Debug("checking if chat is the default one")
+			if community.DefaultChatID == chatID {
+				return handleServiceError(domain.ErrDefaultCommunityChat, op, "check if chat is the default one", log)
+			}
+
+			log.Debug("removing chat from community")
+			if err := managerService.communityProvider.RemoveCommunityChat(ctx, community.ID, chatID); err != nil {
+				return handleServiceError(err, op, "remove chat from community", log)
+			}
+		}
+
+		log.Debug("finding chat channels")
+		channels, err := managerService.channelProvider.FindChatChannels(ctx, chatID)
+		if err != nil {
+			return handleServiceError(err, op, "find chat channels", log)
+		}
+		channelIDs := make([]string, 0, len(channels))
+		for _, channel := range channels {
+			channelIDs = append(channelIDs, channel.ID)
+		}
+
+		if len(channelIDs) > 0 {
+			log.Debug("deleting chat messages")
+			if err := managerService.messageProvider.DeleteChannelsMessages(ctx, channelIDs); err != nil {
+				return handleServiceError(err, op, "delete chat messages", log)
+			}
+
+			log.Debug("deleting chat forum posts")
+			if err := managerService.postProvider.DeleteChannelsPosts(ctx, channelIDs); err != nil {
+				return handleServiceError(err, op, "delete chat forum posts", log)
+			}
+
+			log.Debug("deleting chat webhooks")
+			if err := managerService.webhookProvider.DeleteChannelsWebhooks(ctx, channelIDs); err != nil {
+				return handleServiceError(err, op, "delete chat webhooks", log)
+			}
+		}
+
+		log.Debug("deleting chat channels")
+		if err := managerService.channelProvider.DeleteChatChannels(ctx, chatID); err != nil {
+			return handleServiceError(err, op, "delete chat channels", log)
+		}
+
+		log.Debug("deleting chat invites")
+		if err := managerService.inviteProvider.DeleteChatInvites(ctx, chatID); err != nil {
+			return handleServiceError(err, op, "delete chat invites", log)
+		}
+
+		log.Debug("deleting chat")
+		if err := managerService.chatProvider.DeleteChat(ctx, chatID); err != nil {
+			return handleServiceError(err, op, "delete chat", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_ChatDeleted{
+			ChatDeleted: chatID,
+		},
+	}
+	update := domain.Update{
+		Type:      domain.UpdateChatDeleted,
+		ChatID:    chatID,
+		CreatedAt: time.Now(),
+	}
+	managerService.eventBus.publish(ctx, log, chat.MemberIDs, "", update, event)
+	managerService.eventBus.closeChatStreams(chatID)
+
+	log.Info("chat deleted successfully")
+	return nil
+}
+
+// HideChat hides a private chat from the user's chat list until a new message arrives
+func (managerService *ManagerService) HideChat(ctx context.Context, chatID string, hidden bool) error {
+	const op = "services.manager.HideChat"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID), slog.Bool("hidden", hidden))
+	log.Info("hiding chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking if user in this chat")
+	if !utils.Contains(chat.MemberIDs, userID) {
+		return handleServiceError(domain.ErrAccessDenied, op, "check if user in this chat", log)
+	}
+
+	log.Debug("checking chat type")
+	if chat.Type != "private" {
+		return handleServiceError(domain.ErrInvalidChatType, op, "check chat type", log)
+	}
+
+	log.Debug("saving chat")
+	if err := managerService.chatProvider.SetChatHidden(ctx, chatID, userID, hidden); err != nil {
+		return handleServiceError(err, op, "save chat", log)
+	}
+
+	log.Info("chat hidden state changed successfully")
+	return nil
+}
+
+// ArchiveChannel makes a channel read-only and hides it from chat info by default
+func (managerService *ManagerService) ArchiveChannel(ctx context.Context, channelID string, archived bool) error {
+	const op = "services.manager.ArchiveChannel"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID), slog.Bool("archived", archived))
+	log.Info("archiving channel")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return err
+	}
+
+	if channel.Archived == archived {
+		log.Info("channel archive state is unchanged")
+		return nil
+	}
+
+	log.Debug("saving channel")
+	if err := managerService.channelProvider.UpdateChannel(ctx, channelID, domain.ChannelPatch{Archived: &archived}); err != nil {
+		return handleServiceError(err, op, "save channel", log)
+	}
+	channel.Archived = archived
+
+	managerService.publishChannelUpdated(ctx, log, chat, channel, channel.ViewerIDs(chat))
+
+	log.Info("channel archive state changed successfully")
+	return nil
+}
+
+// DeleteChannel deletes the channel with its messages, the last channel of a chat cannot be deleted
+func (managerService *ManagerService) DeleteChannel(ctx context.Context, channelID string) error {
+	const op = "services.manager.DeleteChannel"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("deleting channel")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("checking if channel is the last one")
+	channels, err := managerService.channelProvider.FindChatChannels(ctx, chat.ID)
+	if err != nil {
+		return handleServiceError(err, op, "find chat channels", log)
+	}
+	if len(channels) <= 1 {
+		return handleServiceError(domain.ErrLastChannel, op, "check if channel is the last one", log)
+	}
+
+	err = inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("deleting channel messages")
+		if err := managerService.messageProvider.DeleteChannelsMessages(ctx, []string{channelID}); err != nil {
+			return handleServiceError(err, op, "delete channel messages", log)
+		}
+
+		if channel.Type == domain.ChannelTypeForum {
+			log.Debug("deleting channel forum posts")
+			if err := managerService.postProvider.DeleteChannelsPosts(ctx, []string{channelID}); err != nil {
+				return handleServiceError(err, op, "delete channel forum posts", log)
+			}
+		}
+
+		log.Debug("deleting channel webhooks")
+		if err := managerService.webhookProvider.DeleteChannelsWebhooks(ctx, []string{channelID}); err != nil {
+			return handleServiceError(err, op, "delete channel webhooks", log)
+		}
+
+		log.Debug("deleting channel")
+		if err := managerService.channelProvider.DeleteChannel(ctx, channelID); err != nil {
+			return handleServiceError(err, op, "delete channel", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_ChannelDeleted{
+			ChannelDeleted: channelID,
+		},
+	}
+	update := domain.Update{
+		Type:      domain.UpdateChannelDeleted,
+		ChatID:    chat.ID,
+		ChannelID: channel.ID,
+		CreatedAt: time.Now(),
+	}
+	managerService.eventBus.publish(ctx, log, channel.ViewerIDs(chat), "", update, event)
+	managerService.eventBus.closeChannelStreams(chat.ID, channelID)
+
+	log.Info("channel deleted successfully")
+	return nil
+}
+
+// manageChannelValidation checks that the user may manage channels of the channel's chat
+func (managerService *ManagerService) manageChannelValidation(ctx context.Context, log *slog.Logger, channelID string, userID string) (domain.Chat, domain.Channel, error) {
+	return channelValidation(ctx, log, managerService.chatProvider, managerService.channelProvider, channelID, userID, domain.PermManageChannels)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+)
+
+var allowedChannelRoles = []string{domain.RoleAdmin, domain.RoleMember}
+
+// SetChannelAccess makes the channel private or public and sets roles that see a private channel
+func (managerService *ManagerService) SetChannelAccess(ctx context.Context, channelID string, private bool, allowedRoles []string) (domain.Channel, error) {
+	const op = "services.manager.SetChannelAccess"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID), slog.Bool("private", private))
+	log.Info("setting channel access")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Channel{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	allowedRoles = utils.UniqueStrings(allowedRoles)
+	for _, role := range allowedRoles {
+		if !utils.Contains(allowedChannelRoles, role) {
+			return domain.Channel{}, handleServiceError(domain.ErrInvalidRole, op, "check request body", log)
+		}
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return domain.Channel{}, err
+	}
+
+	log.Debug("saving channel access")
+	if err := managerService.channelProvider.SetChannelAccess(ctx, channelID, private, allowedRoles); err != nil {
+		return domain.Channel{}, handleServiceError(err, op, "save channel access", log)
+	}
+
+	oldViewerIDs := channel.ViewerIDs(chat)
+	channel.Private = private
+	channel.AllowedRoles = allowedRoles
+
+	managerService.applyChannelAccessChange(ctx, log, chat, channel, oldViewerIDs)
+
+	log.Info("channel access set successfully")
+	return channel, nil
+}
+
+// AddChannelMembers gives chat members access to a private channel
+func (managerService *ManagerService) AddChannelMembers(ctx context.Context, channelID string, userIDs []string) ([]string, error) {
+	const op = "services.manager.AddChannelMembers"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("adding channel members")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("checking if users in this chat")
+	userIDs = utils.UniqueStrings(userIDs)
+	for _, id := range userIDs {
+		if !utils.Contains(chat.MemberIDs, id) {
+			return nil, handleServiceError(domain.ErrNotChatMember, op, "check if users in this chat", log)
+		}
+	}
+
+	log.Debug("saving channel members")
+	if err := managerService.channelProvider.AddChannelMembers(ctx, channelID, userIDs); err != nil {
+		return nil, handleServiceError(err, op, "save channel members", log)
+	}
+
+	oldViewerIDs := channel.ViewerIDs(chat)
+	channel.MemberIDs = utils.UniqueStrings(append(channel.MemberIDs, userIDs...))
+
+	managerService.applyChannelAccessChange(ctx, log, chat, channel, oldViewerIDs)
+
+	log.Info("channel members added successfully")
+	return channel.MemberIDs, nil
+}
+
+// RemoveChannelMember takes access to a private channel away from the user, admins keep seeing it
+func (managerService *ManagerService) RemoveChannelMember(ctx context.Context, channelID string, memberID string) error {
+	const op = "services.manager.RemoveChannelMember"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID), slog.String("member_id", memberID))
+	log.Info("removing channel member")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("checking if user in this channel")
+	if !utils.Contains(channel.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotChannelMember, op, "check if user in this channel", log)
+	}
+
+	log.Debug("removing channel member")
+	if err := managerService.channelProvider.RemoveChannelMember(ctx, channelID, memberID); err != nil {
+		return handleServiceError(err, op, "remove channel member", log)
+	}
+
+	oldViewerIDs := channel.ViewerIDs(chat)
+	memberIDs := make([]string, 0, len(channel.MemberIDs))
+	for _, id := range channel.MemberIDs {
+		if id != memberID {
+			memberIDs = append(memberIDs, id)
+		}
+	}
+	channel.MemberIDs = memberIDs
+
+	managerService.applyChannelAccessChange(ctx, log, chat, channel, oldViewerIDs)
+
+	log.Info("channel member removed successfully")
+	return nil
+}
+
+// applyChannelAccessChange publishes the channel to old and new viewers, so that clients
+// of users who lost access can drop it, and closes their streams of the channel
+func (managerService *ManagerService) applyChannelAccessChange(ctx context.Context, log *slog.Logger, chat domain.Chat, channel domain.Channel, oldViewerIDs []string) {
+	viewerIDs := channel.ViewerIDs(chat)
+
+	var lostIDs []string
+	for _, id := range oldViewerIDs {
+		if !utils.Contains(viewerIDs, id) {
+			lostIDs = append(lostIDs, id)
+		}
+	}
+
+	recipientIDs := make([]string, 0, len(viewerIDs)+len(lostIDs))
+	recipientIDs = append(recipientIDs, viewerIDs...)
+	recipientIDs = append(recipientIDs, lostIDs...)
+	managerService.publishChannelUpdated(ctx, log, chat, channel, recipientIDs)
+
+	if len(lostIDs) > 0 {
+		log.Debug("closing streams of users who lost access")
+		managerService.eventBus.closeChannelUserStreams(chat.ID, channel.ID, lostIDs)
+	}
+}
@@ -0,0 +1,465 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const defaultCommunityChatName = "General"
+
+// CreateCommunity creates a community owned by the user together with its default chat
+func (managerService *ManagerService) CreateCommunity(ctx context.Context, name string, description string, memberIDs []string) (string, string, error) {
+	const op = "services.manager.CreateCommunity"
+
+	log := managerService.log.With(slog.String("op", op))
+	log.Info("creating community")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return "", "", handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if strings.TrimSpace(name) == "" {
+		return "", "", handleServiceError(domain.ErrEmptyCommunityName, op, "check request body", log)
+	}
+
+	memberIDs, err = resolveUserIDs(ctx, log, managerService.userProvider, memberIDs)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", op, err)
+	}
+	memberIDs = utils.UniqueStrings(append(memberIDs, userID))
+
+	community := domain.Community{
+		Name:        name,
+		Description: description,
+		MemberIDs:   memberIDs,
+		ChatIDs:     []string{},
+		Roles:       map[string]string{userID: domain.RoleOwner},
+	}
+
+	var communityID, chatID string
+	err = inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("saving community")
+		var err error
+		communityID, err = managerService.communityProvider.SaveCommunity(ctx, community)
+		if err != nil {
+			return handleServiceError(err, op, "save community", log)
+		}
+		community.ID = communityID
+
+		chatID, err = managerService.saveCommunityChat(ctx, log, community, defaultCommunityChatName)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Debug("saving default chat")
+		if err := managerService.communityProvider.UpdateCommunity(ctx, communityID, domain.CommunityPatch{DefaultChatID: &chatID}); err != nil {
+			return handleServiceError(err, op, "save default chat", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	log.Info("community created successfully", slog.String("community_id", communityID))
+	return communityID, chatID, nil
+}
+
+func (managerService *ManagerService) UpdateCommunity(ctx context.Context, communityID string, patch domain.CommunityPatch) (domain.Community, error) {
+	const op = "services.manager.UpdateCommunity"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID))
+	log.Info("updating community")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Community{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, domain.PermManageChat)
+	if err != nil {
+		return domain.Community{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking request body")
+	if patch.Name != nil && strings.TrimSpace(*patch.Name) == "" {
+		return domain.Community{}, handleServiceError(domain.ErrEmptyCommunityName, op, "check request body", log)
+	}
+	if patch.DefaultChatID != nil && !utils.Contains(community.ChatIDs, *patch.DefaultChatID) {
+		return domain.Community{}, handleServiceError(domain.ErrChatNotFound, op, "check request body", log)
+	}
+
+	log.Debug("saving community")
+	if err := managerService.communityProvider.UpdateCommunity(ctx, communityID, patch); err != nil {
+		return domain.Community{}, handleServiceError(err, op, "save community", log)
+	}
+
+	if patch.Name != nil {
+		community.Name = *patch.Name
+	}
+	if patch.Description != nil {
+		community.Description = *patch.Description
+	}
+	if patch.DefaultChatID != nil {
+		community.DefaultChatID = *patch.DefaultChatID
+	}
+
+	log.Info("community updated successfully")
+	return community, nil
+}
+
+// CreateCommunityChat adds a group chat to the community, every community member joins it
+func (managerService *ManagerService) CreateCommunityChat(ctx context.Context, communityID string, name string) (string, error) {
+	const op = "services.manager.CreateCommunityChat"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID))
+	log.Info("creating community chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return "", handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if strings.TrimSpace(name) == "" {
+		return "", handleServiceError(domain.ErrEmptyGroupName, op, "check request body", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, domain.PermManageChat)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	chatID, err := managerService.saveCommunityChat(ctx, log, community, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("finding created chat")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return "", handleServiceError(err, op, "find created chat", log)
+	}
+	managerService.publishChatUpdated(ctx, log, chat)
+
+	log.Info("community chat created successfully", slog.String("chat_id", chatID))
+	return chatID, nil
+}
+
+func (managerService *ManagerService) AddCommunityMembers(ctx context.Context, communityID string, userIDs []string) ([]string, error) {
+	const op = "services.manager.AddCommunityMembers"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID))
+	log.Info("adding community members")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, domain.PermManageMembers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	userIDs, err = resolveUserIDs(ctx, log, managerService.userProvider, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	newIDs := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !utils.Contains(community.MemberIDs, id) {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(newIDs) == 0 {
+		log.Info("all users are already in community")
+		return community.MemberIDs, nil
+	}
+
+	names := displayNames(ctx, log, managerService.userProvider, append([]string{userID}, newIDs...))
+	text := fmt.Sprintf("%s added %s", nameOrID(names, userID), joinNames(names, newIDs))
+	if err := managerService.addCommunityMembers(ctx, log, community, userID, newIDs, text); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("community members added successfully")
+	return append(community.MemberIDs, newIDs...), nil
+}
+
+func (managerService *ManagerService) RemoveCommunityMember(ctx context.Context, communityID string, memberID string) error {
+	const op = "services.manager.RemoveCommunityMember"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID), slog.String("member_id", memberID))
+	log.Info("removing community member")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, domain.PermManageMembers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking member role")
+	if memberID != userID && !domain.Outranks(community.RoleOf(userID), community.RoleOf(memberID)) {
+		return handleServiceError(domain.ErrPermissionDenied, op, "check member role", log)
+	}
+
+	if err := managerService.removeCommunityMember(ctx, log, community, userID, memberID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("community member removed successfully")
+	return nil
+}
+
+func (managerService *ManagerService) LeaveCommunity(ctx context.Context, communityID string) error {
+	const op = "services.manager.LeaveCommunity"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID))
+	log.Info("leaving community")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if user is owner")
+	if community.RoleOf(userID) == domain.RoleOwner && len(community.MemberIDs) > 1 {
+		return handleServiceError(domain.ErrOwnerCannotLeave, op, "check if user is owner", log)
+	}
+
+	if err := managerService.removeCommunityMember(ctx, log, community, userID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("community left successfully")
+	return nil
+}
+
+// SetCommunityRole sets the role of a member in the community and in every community chat
+func (managerService *ManagerService) SetCommunityRole(ctx context.Context, communityID string, memberID string, role string) error {
+	const op = "services.manager.SetCommunityRole"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID), slog.String("member_id", memberID))
+	log.Info("setting community role")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if !utils.Contains(assignableRoles, role) {
+		return handleServiceError(domain.ErrInvalidRole, op, "check request body", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, domain.PermManageMembers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if member in this community")
+	if !utils.Contains(community.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotCommunityMember, op, "check if member in this community", log)
+	}
+
+	log.Debug("checking user role")
+	userRole := community.RoleOf(userID)
+	if !domain.Outranks(userRole, community.RoleOf(memberID)) || !domain.Outranks(userRole, role) {
+		return handleServiceError(domain.ErrPermissionDenied, op, "check user role", log)
+	}
+
+	log.Debug("saving community role")
+	if err := managerService.communityProvider.SetCommunityRoles(ctx, communityID, map[string]string{memberID: role}); err != nil {
+		return handleServiceError(err, op, "save community role", log)
+	}
+
+	log.Info("community role set successfully")
+	return nil
+}
+
+func (managerService *ManagerService) TransferCommunityOwnership(ctx context.Context, communityID string, memberID string) error {
+	const op = "services.manager.TransferCommunityOwnership"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("community_id", communityID), slog.String("member_id", memberID))
+	log.Info("transferring community ownership")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	community, err := managerService.communityValidation(ctx, log, communityID, userID, 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if user is owner")
+	if community.RoleOf(userID) != domain.RoleOwner {
+		return handleServiceError(domain.ErrPermissionDenied, op, "check if user is owner", log)
+	}
+
+	log.Debug("checking if member in this community")
+	if memberID == userID || !utils.Contains(community.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotCommunityMember, op, "check if member in this community", log)
+	}
+
+	log.Debug("saving community roles")
+	roles := map[string]string{
+		memberID: domain.RoleOwner,
+		userID:   domain.RoleAdmin,
+	}
+	if err := managerService.communityProvider.SetCommunityRoles(ctx, communityID, roles); err != nil {
+		return handleServiceError(err, op, "save community roles", log)
+	}
+
+	log.Info("community ownership transferred successfully")
+	return nil
+}
+
+// communityValidation checks that the user is a community member with perms
+func (managerService *ManagerService) communityValidation(ctx context.Context, log *slog.Logger, communityID string, userID string, perms domain.Permission) (domain.Community, error) {
+	const op = "services.manager.communityValidation"
+
+	log.Debug("finding community by id")
+	community, err := managerService.communityProvider.FindCommunityByID(ctx, communityID)
+	if err != nil {
+		return domain.Community{}, handleServiceError(err, op, "find community by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkCommunityPermission(community, userID, perms); err != nil {
+		return domain.Community{}, handleServiceError(err, op, "check user permissions", log)
+	}
+
+	return community, nil
+}
+
+// saveCommunityChat saves a group chat of the community with the community members and roles
+func (managerService *ManagerService) saveCommunityChat(ctx context.Context, log *slog.Logger, community domain.Community, name string) (string, error) {
+	const op = "services.manager.saveCommunityChat"
+
+	chat := domain.Chat{
+		Type:        "group",
+		Name:        name,
+		MemberIDs:   community.MemberIDs,
+		Roles:       community.Roles,
+		CommunityID: community.ID,
+	}
+
+	var chatID string
+	err := inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		var err error
+		chatID, err = managerService.saveChatWithMainChannel(ctx, log, chat)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Debug("saving community chat")
+		if err := managerService.communityProvider.AddCommunityChat(ctx, community.ID, chatID); err != nil {
+			return handleServiceError(err, op, "save community chat", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return chatID, nil
+}
+
+// addCommunityMembers adds users to the community and its chats. The system message is
+// posted only in the default chat, other chats just get the membership update
+func (managerService *ManagerService) addCommunityMembers(ctx context.Context, log *slog.Logger, community domain.Community, actorID string, userIDs []string, text string) error {
+	const op = "services.manager.addCommunityMembers"
+
+	log.Debug("saving community members")
+	if err := managerService.communityProvider.AddCommunityMembers(ctx, community.ID, userIDs); err != nil {
+		return handleServiceError(err, op, "save community members", log)
+	}
+
+	for _, chat := range managerService.communityChats(ctx, log, community, actorID) {
+		chatText := ""
+		if chat.ID == community.DefaultChatID {
+			chatText = text
+		}
+		managerService.notifyMembersChanged(ctx, log, chat, domain.UpdateMembersAdded, actorID, userIDs, chatText)
+	}
+
+	return nil
+}
+
+// removeCommunityMember removes the member from the community and its chats
+func (managerService *ManagerService) removeCommunityMember(ctx context.Context, log *slog.Logger, community domain.Community, actorID string, memberID string) error {
+	const op = "services.manager.removeCommunityMember"
+
+	if !utils.Contains(community.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotCommunityMember, op, "check if member in this community", log)
+	}
+
+	// chats are loaded before removal so the removed member still gets the update
+	chats := managerService.communityChats(ctx, log, community, actorID)
+
+	log.Debug("removing community member")
+	if err := managerService.communityProvider.RemoveCommunityMember(ctx, community.ID, memberID); err != nil {
+		return handleServiceError(err, op, "remove community member", log)
+	}
+
+	names := displayNames(ctx, log, managerService.userProvider, []string{actorID, memberID})
+	text := fmt.Sprintf("%s removed %s", nameOrID(names, actorID), nameOrID(names, memberID))
+	if actorID == memberID {
+		text = fmt.Sprintf("%s left the community", nameOrID(names, memberID))
+	}
+
+	for _, chat := range chats {
+		chatText := ""
+		if chat.ID == community.DefaultChatID {
+			chatText = text
+		}
+		managerService.notifyMembersChanged(ctx, log, chat, domain.UpdateMemberRemoved, actorID, []string{memberID}, chatText)
+		managerService.eventBus.closeUserStreams(chat.ID, memberID)
+	}
+
+	return nil
+}
+
+// communityChats loads chats of the community, chats that fail to load are skipped
+func (managerService *ManagerService) communityChats(ctx context.Context, log *slog.Logger, community domain.Community, userID string) []domain.Chat {
+	chats := make([]domain.Chat, 0, len(community.ChatIDs))
+	for _, chatID := range community.ChatIDs {
+		chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+		if err != nil {
+			log.Warn("failed to find community chat", slog.String("chat_id", chatID), logger.Err(err))
+			continue
+		}
+		chats = append(chats, chat)
+	}
+	return chats
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+)
+
+const inviteCodeSize = 12
+
+func (managerService *ManagerService) CreateInvite(ctx context.Context, chatID string, expiresAt *time.Time, maxUses int32, requiresApproval bool) (domain.Invite, error) {
+	const op = "services.manager.CreateInvite"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("creating invite")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Invite{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	now := time.Now()
+	if maxUses < 0 || (expiresAt != nil && !expiresAt.After(now)) {
+		return domain.Invite{}, handleServiceError(domain.ErrInvalidInviteParams, op, "check request body", log)
+	}
+
+	if _, err := managerService.memberChatValidation(ctx, log, chatID, userID, domain.PermManageMembers); err != nil {
+		return domain.Invite{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("generating invite code")
+	code, err := utils.RandomCode(inviteCodeSize)
+	if err != nil {
+		return domain.Invite{}, handleServiceError(err, op, "generate invite code", log)
+	}
+
+	invite := domain.Invite{
+		ChatID:           chatID,
+		Code:             code,
+		CreatorID:        userID,
+		ExpiresAt:        expiresAt,
+		MaxUses:          maxUses,
+		RequiresApproval: requiresApproval,
+		CreatedAt:        now,
+	}
+
+	log.Debug("saving invite")
+	if invite.ID, err = managerService.inviteProvider.SaveInvite(ctx, invite); err != nil {
+		return domain.Invite{}, handleServiceError(err, op, "save invite", log)
+	}
+	invite.Link = managerService.inviteLinkBase + invite.Code
+
+	log.Info("invite created successfully", slog.String("invite_id", invite.ID))
+	return invite, nil
+}
+
+func (managerService *ManagerService) ListInvites(ctx context.Context, chatID string) ([]*domain.Invite, error) {
+	const op = "services.manager.ListInvites"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("listing invites")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	if _, err := managerService.memberChatValidation(ctx, log, chatID, userID, domain.PermManageMembers); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("getting chat invites")
+	invites, err := managerService.inviteProvider.FindChatInvites(ctx, chatID)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get chat invites", log)
+	}
+	for _, invite := range invites {
+		invite.Link = managerService.inviteLinkBase + invite.Code
+	}
+
+	log.Info("invites listed successfully")
+	return invites, nil
+}
+
+func (managerService *ManagerService) RevokeInvite(ctx context.Context, inviteID string) error {
+	const op = "services.manager.RevokeInvite"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("invite_id", inviteID))
+	log.Info("revoking invite")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding invite by id")
+	invite, err := managerService.inviteProvider.FindInviteByID(ctx, inviteID)
+	if err != nil {
+		return handleServiceError(err, op, "find invite by id", log)
+	}
+
+	if _, err := managerService.memberChatValidation(ctx, log, invite.ChatID, userID, domain.PermManageMembers); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("revoking invite")
+	if err := managerService.inviteProvider.RevokeInvite(ctx, inviteID); err != nil {
+		return handleServiceError(err, op, "revoke invite", log)
+	}
+
+	log.Info("invite revoked successfully")
+	return nil
+}
+
+// JoinByInvite accepts an invite code or a full invite link
+func (managerService *ManagerService) JoinByInvite(ctx context.Context, code string) (string, bool, error) {
+	const op = "services.manager.JoinByInvite"
+
+	log := managerService.log.With(slog.String("op", op))
+	log.Info("joining chat by invite")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return "", false, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding invite by code")
+	invite, err := managerService.inviteProvider.FindInviteByCode(ctx, path.Base(code))
+	if err != nil {
+		return "", false, handleServiceError(err, op, "find invite by code", log)
+	}
+
+	now := time.Now()
+	if !invite.Usable(now) {
+		return "", false, handleServiceError(domain.ErrInvalidInvite, op, "check invite", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, invite.ChatID, userID)
+	if err != nil {
+		return "", false, handleServiceError(err, op, "find chat by id", log)
+	}
+
+	if utils.Contains(chat.MemberIDs, userID) {
+		log.Info("user is already in chat")
+		return chat.ID, false, nil
+	}
+
+	log.Debug("checking if chat is archived")
+	if chat.Archived {
+		return "", false, handleServiceError(domain.ErrArchived, op, "check if chat is archived", log)
+	}
+
+	if invite.RequiresApproval {
+		log.Debug("checking pending join requests")
+		pending, err := managerService.inviteProvider.FindPendingJoinRecord(ctx, chat.ID, userID)
+		if err != nil {
+			return "", false, handleServiceError(err, op, "check pending join requests", log)
+		}
+		if pending != nil {
+			log.Info("join request is already pending")
+			return chat.ID, true, nil
+		}
+	}
+
+	names := displayNames(ctx, log, managerService.userProvider, []string{userID})
+
+	// the use of the invite is counted only together with the join it led to
+	err = inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("using invite")
+		if err := managerService.inviteProvider.UseInvite(ctx, invite.ID, now); err != nil {
+			return handleServiceError(err, op, "use invite", log)
+		}
+
+		record := domain.JoinRecord{
+			ChatID:     chat.ID,
+			UserID:     userID,
+			InviteID:   invite.ID,
+			InviteCode: invite.Code,
+			Status:     domain.JoinStatusJoined,
+			CreatedAt:  now,
+		}
+
+		if invite.RequiresApproval {
+			record.Status = domain.JoinStatusPending
+
+			log.Debug("saving join request")
+			if _, err := managerService.inviteProvider.SaveJoinRecord(ctx, record); err != nil {
+				return handleServiceError(err, op, "save join request", log)
+			}
+			return nil
+		}
+
+		text := fmt.Sprintf("%s joined by invite", nameOrID(names, userID))
+		if err := managerService.saveChatMembers(ctx, log, chat, userID, []string{userID}, text); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Debug("saving join record")
+		if _, err := managerService.inviteProvider.SaveJoinRecord(ctx, record); err != nil {
+			return handleServiceError(err, op, "save join record", log)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	if invite.RequiresApproval {
+		log.Info("join request created, waiting for approval")
+		return chat.ID, true, nil
+	}
+
+	log.Info("chat joined successfully")
+	return chat.ID, false, nil
+}
+
+func (managerService *ManagerService) ListJoinRequests(ctx context.Context, chatID string) ([]*domain.JoinRecord, error) {
+	const op = "services.manager.ListJoinRequests"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("listing join requests")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	if _, err := managerService.memberChatValidation(ctx, log, chatID, userID, domain.PermManageMembers); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("getting pending join requests")
+	records, err := managerService.inviteProvider.FindJoinRecords(ctx, chatID, domain.JoinStatusPending)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get pending join requests", log)
+	}
+
+	log.Info("join requests listed successfully")
+	return records, nil
+}
+
+func (managerService *ManagerService) ReviewJoinRequest(ctx context.Context, requestID string, approve bool) error {
+	const op = "services.manager.ReviewJoinRequest"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("request_id", requestID), slog.Bool("approve", approve))
+	log.Info("reviewing join request")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding join request by id")
+	record, err := managerService.inviteProvider.FindJoinRecordByID(ctx, requestID)
+	if err != nil {
+		return handleServiceError(err, op, "find join request by id", log)
+	}
+	if record.Status != domain.JoinStatusPending {
+		return handleServiceError(domain.ErrJoinReqNotFound, op, "check join request status", log)
+	}
+
+	chat, err := managerService.memberChatValidation(ctx, log, record.ChatID, userID, domain.PermManageMembers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !approve {
+		log.Debug("rejecting join request")
+		if err := managerService.inviteProvider.SetJoinRecordStatus(ctx, requestID, domain.JoinStatusRejected, userID); err != nil {
+			return handleServiceError(err, op, "reject join request", log)
+		}
+
+		log.Info("join request rejected")
+		return nil
+	}
+
+	log.Debug("checking if chat is archived")
+	if chat.Archived {
+		return handleServiceError(domain.ErrArchived, op, "check if chat is archived", log)
+	}
+
+	names := displayNames(ctx, log, managerService.userProvider, []string{userID, record.UserID})
+	err = inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		text := fmt.Sprintf("%s approved %s joining by invite", nameOrID(names, userID), nameOrID(names, record.UserID))
+		if err := managerService.saveChatMembers(ctx, log, chat, userID, []string{record.UserID}, text); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		log.Debug("approving join request")
+		if err := managerService.inviteProvider.SetJoinRecordStatus(ctx, requestID, domain.JoinStatusApproved, userID); err != nil {
+			return handleServiceError(err, op, "approve join request", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("join request approved")
+	return nil
+}
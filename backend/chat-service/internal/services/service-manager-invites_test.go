@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"chat-service/internal/domain"
+)
+
+func TestJoinByInviteArchivedChat(t *testing.T) {
+	managerService, storage := newTestManager(t, testUsers)
+	ctx := userContext("u-alice")
+
+	chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+	if err != nil {
+		t.Fatalf("CreateChat() error = %v", err)
+	}
+	invite, err := managerService.CreateInvite(ctx, chatID, nil, 0, false)
+	if err != nil {
+		t.Fatalf("CreateInvite() error = %v", err)
+	}
+	if err := managerService.ArchiveChat(ctx, chatID, true); err != nil {
+		t.Fatalf("ArchiveChat() error = %v", err)
+	}
+
+	if _, _, err := managerService.JoinByInvite(userContext("u-carol"), invite.Code); !errors.Is(err, domain.ErrArchived) {
+		t.Errorf("JoinByInvite() error = %v, want %v", err, domain.ErrArchived)
+	}
+
+	chat, err := storage.FindChatByID(context.Background(), chatID, "u-alice")
+	if err != nil {
+		t.Fatalf("FindChatByID() error = %v", err)
+	}
+	if want := []string{"u-bob", "u-alice"}; !slices.Equal(chat.MemberIDs, want) {
+		t.Errorf("members = %v, want %v", chat.MemberIDs, want)
+	}
+}
+
+func TestReviewJoinRequestArchivedChat(t *testing.T) {
+	tests := []struct {
+		name    string
+		approve bool
+		wantErr error
+	}{
+		{"approve", true, domain.ErrArchived},
+		{"reject", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			managerService, storage := newTestManager(t, testUsers)
+			ctx := userContext("u-alice")
+
+			chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+			if err != nil {
+				t.Fatalf("CreateChat() error = %v", err)
+			}
+			invite, err := managerService.CreateInvite(ctx, chatID, nil, 0, true)
+			if err != nil {
+				t.Fatalf("CreateInvite() error = %v", err)
+			}
+			if _, pending, err := managerService.JoinByInvite(userContext("u-carol"), invite.Code); err != nil || !pending {
+				t.Fatalf("JoinByInvite() = %v, %v, want a pending join request", pending, err)
+			}
+			requests, err := managerService.ListJoinRequests(ctx, chatID)
+			if err != nil || len(requests) != 1 {
+				t.Fatalf("ListJoinRequests() = %v, %v, want the request of carol", requests, err)
+			}
+			if err := managerService.ArchiveChat(ctx, chatID, true); err != nil {
+				t.Fatalf("ArchiveChat() error = %v", err)
+			}
+
+			if err := managerService.ReviewJoinRequest(ctx, requests[0].ID, tt.approve); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReviewJoinRequest() error = %v, want %v", err, tt.wantErr)
+			}
+
+			chat, err := storage.FindChatByID(context.Background(), chatID, "u-alice")
+			if err != nil {
+				t.Fatalf("FindChatByID() error = %v", err)
+			}
+			if want := []string{"u-bob", "u-alice"}; !slices.Equal(chat.MemberIDs, want) {
+				t.Errorf("members = %v, want %v", chat.MemberIDs, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+func (managerService *ManagerService) AddMembers(ctx context.Context, chatID string, userIDs []string) ([]string, error) {
+	const op = "services.manager.AddMembers"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("adding members to chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, err := managerService.memberChatValidation(ctx, log, chatID, userID, domain.PermManageMembers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if chat is archived")
+	if chat.Archived {
+		return nil, handleServiceError(domain.ErrArchived, op, "check if chat is archived", log)
+	}
+
+	userIDs, err = resolveUserIDs(ctx, log, managerService.userProvider, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	newIDs := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !utils.Contains(chat.MemberIDs, id) {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(newIDs) == 0 {
+		log.Info("all users are already in chat")
+		return chat.MemberIDs, nil
+	}
+
+	names := displayNames(ctx, log, managerService.userProvider, append([]string{userID}, newIDs...))
+	text := fmt.Sprintf("%s added %s", nameOrID(names, userID), joinNames(names, newIDs))
+	if err := managerService.saveChatMembers(ctx, log, chat, userID, newIDs, text); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("members added successfully")
+	return append(chat.MemberIDs, newIDs...), nil
+}
+
+func (managerService *ManagerService) RemoveMember(ctx context.Context, chatID string, memberID string) error {
+	const op = "services.manager.RemoveMember"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID), slog.String("member_id", memberID))
+	log.Info("removing member from chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, err := managerService.memberChatValidation(ctx, log, chatID, userID, domain.PermManageMembers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if chat is archived")
+	if chat.Archived {
+		return handleServiceError(domain.ErrArchived, op, "check if chat is archived", log)
+	}
+
+	log.Debug("checking if chat belongs to a community")
+	if chat.CommunityID != "" {
+		return handleServiceError(domain.ErrCommunityChat, op, "check if chat belongs to a community", log)
+	}
+
+	log.Debug("checking member role")
+	if memberID != userID && !domain.Outranks(chat.RoleOf(userID), chat.RoleOf(memberID)) {
+		return handleServiceError(domain.ErrPermissionDenied, op, "check member role", log)
+	}
+
+	if err := managerService.removeMember(ctx, log, chat, userID, memberID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("member removed successfully")
+	return nil
+}
+
+func (managerService *ManagerService) LeaveChat(ctx context.Context, chatID string) error {
+	const op = "services.manager.LeaveChat"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("leaving chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, err := managerService.memberChatValidation(ctx, log, chatID, userID, 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if chat belongs to a community")
+	if chat.CommunityID != "" {
+		return handleServiceError(domain.ErrCommunityChat, op, "check if chat belongs to a community", log)
+	}
+
+	log.Debug("checking if user is owner")
+	if chat.RoleOf(userID) == domain.RoleOwner && len(chat.MemberIDs) > 1 {
+		return handleServiceError(domain.ErrOwnerCannotLeave, op, "check if user is owner", log)
+	}
+
+	if err := managerService.removeMember(ctx, log, chat, userID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("chat left successfully")
+	return nil
+}
+
+// memberChatValidation checks that the chat is a group chat and the user is its member with perms
+func (managerService *ManagerService) memberChatValidation(ctx context.Context, log *slog.Logger, chatID string, userID string, perms domain.Permission) (domain.Chat, error) {
+	const op = "services.manager.memberChatValidation"
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return domain.Chat{}, handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, nil, userID, perms); err != nil {
+		return domain.Chat{}, handleServiceError(err, op, "check user permissions", log)
+	}
+
+	log.Debug("checking chat type")
+	if chat.Type == "private" {
+		return domain.Chat{}, handleServiceError(domain.ErrPrivateChatMembers, op, "check chat type", log)
+	}
+
+	return chat, nil
+}
+
+func (managerService *ManagerService) removeMember(ctx context.Context, log *slog.Logger, chat domain.Chat, actorID string, memberID string) error {
+	const op = "services.manager.removeMember"
+
+	if !utils.Contains(chat.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotChatMember, op, "check if member in this chat", log)
+	}
+
+	names := displayNames(ctx, log, managerService.userProvider, []string{actorID, memberID})
+	text := fmt.Sprintf("%s removed %s", nameOrID(names, actorID), nameOrID(names, memberID))
+	if actorID == memberID {
+		text = fmt.Sprintf("%s left the chat", nameOrID(names, memberID))
+	}
+
+	// the member is removed in one transaction with the system message and the update
+	err := inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("removing chat member")
+		if err := managerService.chatProvider.RemoveChatMember(ctx, chat.ID, memberID); err != nil {
+			return handleServiceError(err, op, "remove chat member", log)
+		}
+
+		// the removed member still gets the update so their client can drop the chat
+		managerService.notifyMembersChanged(ctx, log, chat, domain.UpdateMemberRemoved, actorID, []string{memberID}, text)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("closing streams of removed member")
+	managerService.eventBus.closeUserStreams(chat.ID, memberID)
+
+	return nil
+}
+
+// saveChatMembers adds users to the chat and notifies members in one transaction with the system message.
+// Users added to a community chat join the whole community
+func (managerService *ManagerService) saveChatMembers(ctx context.Context, log *slog.Logger, chat domain.Chat, actorID string, userIDs []string, text string) error {
+	const op = "services.manager.saveChatMembers"
+
+	return inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		if chat.CommunityID != "" {
+			log.Debug("finding community of chat")
+			community, err := managerService.communityProvider.FindCommunityByID(ctx, chat.CommunityID)
+			if err != nil {
+				return handleServiceError(err, op, "find community of chat", log)
+			}
+
+			return managerService.addCommunityMembers(ctx, log, community, actorID, userIDs, text)
+		}
+
+		log.Debug("saving chat members")
+		if err := managerService.chatProvider.AddChatMembers(ctx, chat.ID, userIDs); err != nil {
+			return handleServiceError(err, op, "save chat members", log)
+		}
+
+		for _, id := range userIDs {
+			if !utils.Contains(chat.MemberIDs, id) {
+				chat.MemberIDs = append(chat.MemberIDs, id)
+			}
+		}
+		managerService.notifyMembersChanged(ctx, log, chat, domain.UpdateMembersAdded, actorID, userIDs, text)
+
+		return nil
+	})
+}
+
+// postSystemMessage saves a system message into the channel and publishes it once it is committed,
+// failures are only logged. Inside a transaction a failed save fails the whole transaction
+func (managerService *ManagerService) postSystemMessage(ctx context.Context, log *slog.Logger, chat domain.Chat, channelID string, actorID string, text string) {
+	err := inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("finding channel of system message")
+		channel, err := managerService.channelProvider.FindChannelByID(ctx, channelID)
+		if err != nil {
+			return err
+		}
+
+		systemMessage := domain.Message{
+			ChannelID: channelID,
+			Text:      text,
+			SenderID:  actorID,
+			CreatedAt: time.Now(),
+			Type:      domain.MessageTypeSystem,
+		}
+
+		log.Debug("saving system message")
+		if systemMessage.ID, err = managerService.messageProvider.SaveMessage(ctx, systemMessage); err != nil {
+			return err
+		}
+
+		managerService.eventBus.publishMessage(ctx, log, chat, channel, &systemMessage)
+		return nil
+	})
+	if err != nil {
+		log.Warn("failed to post system message", logger.Err(err))
+	}
+}
+
+// notifyMembersChanged posts a system message into the main channel unless text is empty and
+// publishes the membership change to every chat member
+func (managerService *ManagerService) notifyMembersChanged(ctx context.Context, log *slog.Logger, chat domain.Chat, updateType string, actorID string, userIDs []string, text string) {
+	if text != "" {
+		mainChannel, err := findMainChannel(ctx, managerService.channelProvider, chat.ID)
+		if err != nil {
+			log.Warn("failed to find main channel for system message", logger.Err(err))
+		} else {
+			managerService.postSystemMessage(ctx, log, chat, mainChannel.ID, actorID, text)
+		}
+	}
+
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_MembersChanged{
+			MembersChanged: &chatpb.MembersChanged{
+				ChatId:  chat.ID,
+				Type:    updateType,
+				ActorId: actorID,
+				UserIds: userIDs,
+			},
+		},
+	}
+
+	update := domain.Update{
+		Type:      updateType,
+		ChatID:    chat.ID,
+		UserIDs:   userIDs,
+		CreatedAt: time.Now(),
+	}
+
+	managerService.eventBus.publish(ctx, log, chat.MemberIDs, "", update, event)
+}
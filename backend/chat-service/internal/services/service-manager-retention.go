@@ -0,0 +1,84 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+)
+
+// SetChatRetention sets the retention policy of the chat, nil falls back to the global policy
+func (managerService *ManagerService) SetChatRetention(ctx context.Context, chatID string, policy *domain.RetentionPolicy) error {
+	const op = "services.manager.SetChatRetention"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID), slog.Any("policy", policy))
+	log.Info("setting chat retention")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if policy != nil && policy.MaxAge < 0 {
+		return handleServiceError(domain.ErrInvalidRetention, op, "check request body", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, nil, userID, domain.PermManageChat); err != nil {
+		return handleServiceError(err, op, "check user permissions", log)
+	}
+
+	log.Debug("saving chat retention")
+	if err := managerService.chatProvider.SetChatRetention(ctx, chatID, policy); err != nil {
+		return handleServiceError(err, op, "save chat retention", log)
+	}
+	chat.Retention = policy
+
+	managerService.publishChatUpdated(ctx, log, chat)
+
+	log.Info("chat retention set successfully")
+	return nil
+}
+
+// SetChannelRetention sets the retention policy of the channel, nil falls back to the chat policy
+func (managerService *ManagerService) SetChannelRetention(ctx context.Context, channelID string, policy *domain.RetentionPolicy) error {
+	const op = "services.manager.SetChannelRetention"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID), slog.Any("policy", policy))
+	log.Info("setting channel retention")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if policy != nil && policy.MaxAge < 0 {
+		return handleServiceError(domain.ErrInvalidRetention, op, "check request body", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("saving channel retention")
+	if err := managerService.channelProvider.SetChannelRetention(ctx, channelID, policy); err != nil {
+		return handleServiceError(err, op, "save channel retention", log)
+	}
+	channel.Retention = policy
+
+	managerService.publishChannelUpdated(ctx, log, chat, channel, channel.ViewerIDs(chat))
+
+	log.Info("channel retention set successfully")
+	return nil
+}
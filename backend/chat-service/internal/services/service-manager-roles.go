@@ -0,0 +1,147 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+var assignableRoles = []string{domain.RoleAdmin, domain.RoleMember}
+
+func (managerService *ManagerService) SetMemberRole(ctx context.Context, chatID string, memberID string, role string) error {
+	const op = "services.manager.SetMemberRole"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID), slog.String("member_id", memberID))
+	log.Info("setting member role")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if !utils.Contains(assignableRoles, role) {
+		return handleServiceError(domain.ErrInvalidRole, op, "check request body", log)
+	}
+
+	chat, err := managerService.memberChatValidation(ctx, log, chatID, userID, domain.PermManageMembers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if member in this chat")
+	if !utils.Contains(chat.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotChatMember, op, "check if member in this chat", log)
+	}
+
+	log.Debug("checking user role")
+	userRole := chat.RoleOf(userID)
+	if !domain.Outranks(userRole, chat.RoleOf(memberID)) || !domain.Outranks(userRole, role) {
+		return handleServiceError(domain.ErrPermissionDenied, op, "check user role", log)
+	}
+
+	log.Debug("saving member role")
+	if err := managerService.chatProvider.SetChatRoles(ctx, chatID, map[string]string{memberID: role}); err != nil {
+		return handleServiceError(err, op, "save member role", log)
+	}
+
+	log.Info("member role set successfully")
+	return nil
+}
+
+func (managerService *ManagerService) TransferOwnership(ctx context.Context, chatID string, memberID string) error {
+	const op = "services.manager.TransferOwnership"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID), slog.String("member_id", memberID))
+	log.Info("transferring chat ownership")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, err := managerService.memberChatValidation(ctx, log, chatID, userID, 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if chat belongs to a community")
+	if chat.CommunityID != "" {
+		return handleServiceError(domain.ErrCommunityChat, op, "check if chat belongs to a community", log)
+	}
+
+	log.Debug("checking if user is owner")
+	if chat.RoleOf(userID) != domain.RoleOwner {
+		return handleServiceError(domain.ErrPermissionDenied, op, "check if user is owner", log)
+	}
+
+	log.Debug("checking if member in this chat")
+	if memberID == userID || !utils.Contains(chat.MemberIDs, memberID) {
+		return handleServiceError(domain.ErrNotChatMember, op, "check if member in this chat", log)
+	}
+
+	log.Debug("saving chat roles")
+	roles := map[string]string{
+		memberID: domain.RoleOwner,
+		userID:   domain.RoleAdmin,
+	}
+	if err := managerService.chatProvider.SetChatRoles(ctx, chatID, roles); err != nil {
+		return handleServiceError(err, op, "save chat roles", log)
+	}
+
+	log.Info("chat ownership transferred successfully")
+	return nil
+}
+
+func (managerService *ManagerService) SetChannelPermissions(ctx context.Context, channelID string, overrides []domain.PermissionOverride) error {
+	const op = "services.manager.SetChannelPermissions"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("setting channel permissions")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, _, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking request body")
+	for _, override := range overrides {
+		if err := validatePermissionOverride(chat, override); err != nil {
+			return handleServiceError(err, op, "check request body", log)
+		}
+	}
+
+	log.Debug("saving channel permissions")
+	if err := managerService.channelProvider.SetChannelPermissions(ctx, channelID, overrides); err != nil {
+		return handleServiceError(err, op, "save channel permissions", log)
+	}
+
+	log.Info("channel permissions set successfully")
+	return nil
+}
+
+// validatePermissionOverride checks that the override targets exactly one role or chat member
+func validatePermissionOverride(chat domain.Chat, override domain.PermissionOverride) error {
+	switch {
+	case (override.Role == "") == (override.UserID == ""):
+		return domain.ErrInvalidPermission
+	case override.Role != "" && !utils.Contains(assignableRoles, override.Role):
+		return domain.ErrInvalidRole
+	case override.UserID != "" && !utils.Contains(chat.MemberIDs, override.UserID):
+		return domain.ErrNotChatMember
+	case override.Allow&^domain.PermAll != 0 || override.Deny&^domain.PermAll != 0:
+		return domain.ErrInvalidPermission
+	default:
+		return nil
+	}
+}
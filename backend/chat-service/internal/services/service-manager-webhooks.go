@@ -0,0 +1,149 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/utils"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const webhookTokenSize = 32
+
+func (managerService *ManagerService) CreateWebhook(ctx context.Context, channelID string, name string, avatarURL string) (domain.Webhook, error) {
+	const op = "services.manager.CreateWebhook"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("creating webhook")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Webhook{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.Webhook{}, handleServiceError(domain.ErrEmptyName, op, "check request body", log)
+	}
+	if !validAvatarURL(avatarURL) {
+		return domain.Webhook{}, handleServiceError(domain.ErrInvalidAvatarURL, op, "check request body", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return domain.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking channel type")
+	if channel.Type == domain.ChannelTypeForum {
+		return domain.Webhook{}, handleServiceError(domain.ErrForumPostRequired, op, "check channel type", log)
+	}
+
+	log.Debug("generating webhook token")
+	token, err := utils.RandomCode(webhookTokenSize)
+	if err != nil {
+		return domain.Webhook{}, handleServiceError(err, op, "generate webhook token", log)
+	}
+
+	webhook := domain.Webhook{
+		ChatID:    chat.ID,
+		ChannelID: channelID,
+		Name:      name,
+		AvatarURL: avatarURL,
+		TokenHash: hashWebhookToken(token),
+		CreatorID: userID,
+		CreatedAt: time.Now(),
+	}
+
+	log.Debug("saving webhook")
+	if webhook.ID, err = managerService.webhookProvider.SaveWebhook(ctx, webhook); err != nil {
+		return domain.Webhook{}, handleServiceError(err, op, "save webhook", log)
+	}
+	webhook.URL = managerService.webhookURLBase + webhook.ID + "/" + token
+
+	log.Info("webhook created successfully", slog.String("webhook_id", webhook.ID))
+	return webhook, nil
+}
+
+func (managerService *ManagerService) ListWebhooks(ctx context.Context, channelID string) ([]*domain.Webhook, error) {
+	const op = "services.manager.ListWebhooks"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("listing webhooks")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	if _, _, err := managerService.manageChannelValidation(ctx, log, channelID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("getting channel webhooks")
+	webhooks, err := managerService.webhookProvider.FindChannelWebhooks(ctx, channelID)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get channel webhooks", log)
+	}
+
+	log.Info("webhooks listed successfully")
+	return webhooks, nil
+}
+
+func (managerService *ManagerService) RevokeWebhook(ctx context.Context, webhookID string) error {
+	const op = "services.manager.RevokeWebhook"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("webhook_id", webhookID))
+	log.Info("revoking webhook")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding webhook by id")
+	webhook, err := managerService.webhookProvider.FindWebhookByID(ctx, webhookID)
+	if err != nil {
+		return handleServiceError(err, op, "find webhook by id", log)
+	}
+
+	if _, _, err := managerService.manageChannelValidation(ctx, log, webhook.ChannelID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("revoking webhook")
+	if err := managerService.webhookProvider.RevokeWebhook(ctx, webhookID); err != nil {
+		return handleServiceError(err, op, "revoke webhook", log)
+	}
+
+	log.Info("webhook revoked successfully")
+	return nil
+}
+
+// hashWebhookToken returns the hex sha256 of the token, tokens are random so no salt is needed
+func hashWebhookToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validAvatarURL accepts an empty url or an absolute http or https one
+func validAvatarURL(avatarURL string) bool {
+	if avatarURL == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(avatarURL)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -1,17 +1,33 @@
 package services
 
 import (
+	chatpb "chat-service/gen"
 	"chat-service/internal/domain"
 	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/mapper"
 	"chat-service/internal/lib/utils"
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 )
 
 type ManagerService struct {
-	log             *slog.Logger
-	chatProvider    interfaces.ChatProvider
-	channelProvider interfaces.ChannelProvider
+	log               *slog.Logger
+	chatProvider      interfaces.ChatProvider
+	channelProvider   interfaces.ChannelProvider
+	messageProvider   interfaces.MessageProvider
+	inviteProvider    interfaces.InviteProvider
+	postProvider      interfaces.PostProvider
+	communityProvider interfaces.CommunityProvider
+	webhookProvider   interfaces.WebhookProvider
+	userProvider      interfaces.UserProvider
+	transactor        interfaces.Transactor
+
+	eventBus       *EventBus
+	inviteLinkBase string
+	webhookURLBase string
 }
 
 func NewManagerService(
@@ -19,11 +35,31 @@ func NewManagerService(
 	chatProvider interfaces.ChatProvider,
 	channelProvider interfaces.ChannelProvider,
 	messageProvider interfaces.MessageProvider,
+	inviteProvider interfaces.InviteProvider,
+	postProvider interfaces.PostProvider,
+	communityProvider interfaces.CommunityProvider,
+	webhookProvider interfaces.WebhookProvider,
+	userProvider interfaces.UserProvider,
+	transactor interfaces.Transactor,
+	eventBus *EventBus,
+	inviteLinkBase string,
+	webhookURLBase string,
 ) *ManagerService {
 	return &ManagerService{
-		log:             log,
-		chatProvider:    chatProvider,
-		channelProvider: channelProvider,
+		log:               log,
+		chatProvider:      chatProvider,
+		channelProvider:   channelProvider,
+		messageProvider:   messageProvider,
+		inviteProvider:    inviteProvider,
+		postProvider:      postProvider,
+		communityProvider: communityProvider,
+		webhookProvider:   webhookProvider,
+		userProvider:      userProvider,
+		transactor:        transactor,
+
+		eventBus:       eventBus,
+		inviteLinkBase: inviteLinkBase,
+		webhookURLBase: webhookURLBase,
 	}
 }
 
@@ -44,6 +80,12 @@ func (managerService *ManagerService) CreateChat(ctx context.Context, chatType s
 		return "", handleServiceError(domain.ErrInvalidChatType, op, "check request body", log)
 	}
 
+	// user_ids may also hold usernames
+	user_ids, err = resolveUserIDs(ctx, log, managerService.userProvider, user_ids)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
 	// TODO: улучшить проверку
 	if chatType == "private" {
 		if len(user_ids) != 1 {
@@ -52,6 +94,9 @@ func (managerService *ManagerService) CreateChat(ctx context.Context, chatType s
 		if user_ids[0] == userID {
 			return "", handleServiceError(domain.ErrSameUser, op, "check private chat input", log)
 		}
+		if err := checkNotBlocked(ctx, log, managerService.userProvider, userID, user_ids); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
 	} else if name == "" {
 		return "", handleServiceError(domain.ErrEmptyGroupName, op, "check group chat input", log)
 
@@ -66,39 +111,72 @@ func (managerService *ManagerService) CreateChat(ctx context.Context, chatType s
 	}
 
 	newChat := domain.Chat{
-		MemberIDs:  user_ids,
-		ChannelIDs: []string{},
-		Type:       chatType,
+		MemberIDs: user_ids,
+		Type:      chatType,
 	}
 
 	if chatType == "group" {
 		newChat.Name = name
+		newChat.Roles = map[string]string{userID: domain.RoleOwner}
 	}
 
-	log.Debug("saving chat")
-	chatID, err := managerService.chatProvider.SaveChat(ctx, newChat)
+	chatID, err := managerService.saveChatWithMainChannel(ctx, log, newChat)
 	if err != nil {
-		return "", handleServiceError(err, op, "save chat", log)
-
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	mainCh := domain.Channel{
-		ChatID:     chatID,
-		Name:       "Main",
-		Type:       "text",
-		MessageIDs: []string{},
-	}
+	log.Info("chat created successfully")
+	return chatID, nil
+}
+
+// saveChatWithMainChannel saves a new chat together with its first text channel, a chat is never left without one
+func (managerService *ManagerService) saveChatWithMainChannel(ctx context.Context, log *slog.Logger, chat domain.Chat) (string, error) {
+	const op = "services.manager.saveChatWithMainChannel"
+
+	var chatID string
+	err := inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("saving chat")
+		var err error
+		chatID, err = managerService.chatProvider.SaveChat(ctx, chat)
+		if err != nil {
+			return handleServiceError(err, op, "save chat", log)
+		}
+
+		mainCh := domain.Channel{
+			ChatID:   chatID,
+			Name:     "Main",
+			Type:     "text",
+			Position: 0,
+		}
 
-	log.Debug("saving main channel")
-	if _, err = managerService.channelProvider.SaveChannel(ctx, mainCh); err != nil {
-		return "", handleServiceError(err, op, "save main channel", log)
+		log.Debug("saving main channel")
+		if _, err = managerService.channelProvider.SaveChannel(ctx, mainCh); err != nil {
+			return handleServiceError(err, op, "save main channel", log)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	log.Info("chat created successfully")
 	return chatID, nil
 }
 
-func (managerService *ManagerService) CreateChannel(ctx context.Context, chatID string, name string, chanType string) (string, error) {
+// findMainChannel returns the main channel of the chat, which is the first channel created
+func findMainChannel(ctx context.Context, channelProvider interfaces.ChannelProvider, chatID string) (domain.Channel, error) {
+	channels, err := channelProvider.FindChatChannels(ctx, chatID)
+	if err != nil {
+		return domain.Channel{}, err
+	}
+	if len(channels) == 0 {
+		return domain.Channel{}, domain.ErrChannelNotFound
+	}
+
+	return channels[0], nil
+}
+
+func (managerService *ManagerService) CreateChannel(ctx context.Context, chatID string, name string, chanType string, mode string, posterIDs []string) (string, error) {
 	const op = "services.channel.CreateChannel"
 
 	log := managerService.log.With(slog.String("op", op))
@@ -117,26 +195,57 @@ func (managerService *ManagerService) CreateChannel(ctx context.Context, chatID
 		return "", handleServiceError(err, op, "find chat by id", log)
 	}
 
-	// TODO: логика для voice и для text
 	log.Debug("checking request body")
 	if !utils.Contains(allowedChannelTypes, chanType) {
 		return "", handleServiceError(domain.ErrInvalidChannelType, op, "check request body", log)
 	}
 
-	log.Debug("checking if user in this chat")
-	if !utils.Contains(chat.MemberIDs, userID) {
-		return "", handleServiceError(domain.ErrAccessDenied, op, "check if user in this chat", log)
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, nil, userID, domain.PermManageChannels); err != nil {
+		return "", handleServiceError(err, op, "check user permissions", log)
+	}
+
+	log.Debug("checking if chat is archived")
+	if chat.Archived {
+		return "", handleServiceError(domain.ErrArchived, op, "check if chat is archived", log)
+	}
+
+	log.Debug("checking channel mode")
+	if mode == "" {
+		mode = domain.ChannelModeNormal
+	}
+	posterIDs = utils.UniqueStrings(posterIDs)
+	if err := validateChannelMode(chat, mode, posterIDs); err != nil {
+		return "", handleServiceError(err, op, "check channel mode", log)
+	}
+
+	log.Debug("finding chat channels")
+	channels, err := managerService.channelProvider.FindChatChannels(ctx, chatID)
+	if err != nil {
+		return "", handleServiceError(err, op, "find chat channels", log)
+	}
+
+	// the new channel goes after every channel, deleted channels leave gaps in positions
+	var position int32
+	for _, channel := range channels {
+		position = max(position, channel.Position+1)
 	}
 
 	newCh := domain.Channel{
-		ChatID:     chatID,
-		Name:       name,
-		Type:       chanType,
-		MessageIDs: []string{},
+		ChatID:    chatID,
+		Name:      name,
+		Type:      chanType,
+		Position:  position,
+		Mode:      mode,
+		PosterIDs: posterIDs,
 	}
 
-	log.Debug("saving channel")
-	channelID, err := managerService.channelProvider.SaveChannel(ctx, newCh)
+	var channelID string
+	err = inTransaction(ctx, managerService.transactor, func(ctx context.Context) error {
+		log.Debug("saving channel")
+		channelID, err = managerService.channelProvider.SaveChannel(ctx, newCh)
+		return err
+	})
 	if err != nil {
 		return "", handleServiceError(err, op, "save channel", log)
 	}
@@ -144,3 +253,222 @@ func (managerService *ManagerService) CreateChannel(ctx context.Context, chatID
 	log.Info("channel created successfully")
 	return channelID, nil
 }
+
+func (managerService *ManagerService) UpdateChat(ctx context.Context, chatID string, patch domain.ChatPatch) (domain.Chat, error) {
+	const op = "services.manager.UpdateChat"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("updating chat")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Chat{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := managerService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return domain.Chat{}, handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkPermission(chat, nil, userID, domain.PermManageChat); err != nil {
+		return domain.Chat{}, handleServiceError(err, op, "check user permissions", log)
+	}
+
+	log.Debug("checking request body")
+	if patch.Name != nil {
+		if chat.Type == "private" {
+			return domain.Chat{}, handleServiceError(domain.ErrPrivateChatName, op, "check request body", log)
+		}
+		if strings.TrimSpace(*patch.Name) == "" {
+			return domain.Chat{}, handleServiceError(domain.ErrEmptyGroupName, op, "check request body", log)
+		}
+	}
+
+	log.Debug("saving chat")
+	if err := managerService.chatProvider.UpdateChat(ctx, chatID, patch); err != nil {
+		return domain.Chat{}, handleServiceError(err, op, "save chat", log)
+	}
+
+	if patch.Name != nil {
+		chat.Name = *patch.Name
+	}
+	if patch.Topic != nil {
+		chat.Topic = *patch.Topic
+	}
+	if patch.Description != nil {
+		chat.Description = *patch.Description
+	}
+
+	managerService.publishChatUpdated(ctx, log, chat)
+
+	log.Info("chat updated successfully")
+	return chat, nil
+}
+
+func (managerService *ManagerService) UpdateChannel(ctx context.Context, channelID string, patch domain.ChannelPatch) (domain.Channel, error) {
+	const op = "services.manager.UpdateChannel"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("updating channel")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Channel{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if patch.Name != nil && strings.TrimSpace(*patch.Name) == "" {
+		return domain.Channel{}, handleServiceError(domain.ErrEmptyName, op, "check request body", log)
+	}
+	if patch.Position != nil && *patch.Position < 0 {
+		return domain.Channel{}, handleServiceError(domain.ErrInvalidPosition, op, "check request body", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return domain.Channel{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if patch.Mode != nil || patch.PosterIDs != nil {
+		log.Debug("checking channel mode")
+		mode, posterIDs := channel.Mode, channel.PosterIDs
+		if patch.Mode != nil {
+			mode = *patch.Mode
+		}
+		if patch.PosterIDs != nil {
+			posterIDs = utils.UniqueStrings(*patch.PosterIDs)
+			patch.PosterIDs = &posterIDs
+		}
+		if err := validateChannelMode(chat, mode, posterIDs); err != nil {
+			return domain.Channel{}, handleServiceError(err, op, "check channel mode", log)
+		}
+	}
+
+	log.Debug("saving channel")
+	if err := managerService.channelProvider.UpdateChannel(ctx, channelID, patch); err != nil {
+		return domain.Channel{}, handleServiceError(err, op, "save channel", log)
+	}
+
+	if patch.Name != nil {
+		channel.Name = *patch.Name
+	}
+	if patch.Topic != nil {
+		channel.Topic = *patch.Topic
+	}
+	if patch.Description != nil {
+		channel.Description = *patch.Description
+	}
+	if patch.Position != nil {
+		channel.Position = *patch.Position
+	}
+	if patch.Category != nil {
+		channel.Category = *patch.Category
+	}
+	if patch.Mode != nil {
+		channel.Mode = *patch.Mode
+	}
+	if patch.PosterIDs != nil {
+		channel.PosterIDs = *patch.PosterIDs
+	}
+
+	managerService.publishChannelUpdated(ctx, log, chat, channel, channel.ViewerIDs(chat))
+
+	log.Info("channel updated successfully")
+	return channel, nil
+}
+
+// SetForumTags replaces the set of tags available for posts of a forum channel
+func (managerService *ManagerService) SetForumTags(ctx context.Context, channelID string, tags []string) (domain.Channel, error) {
+	const op = "services.manager.SetForumTags"
+
+	log := managerService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("setting forum tags")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Channel{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := managerService.manageChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return domain.Channel{}, err
+	}
+
+	log.Debug("checking channel type")
+	if channel.Type != domain.ChannelTypeForum {
+		return domain.Channel{}, handleServiceError(domain.ErrNotForumChannel, op, "check channel type", log)
+	}
+
+	log.Debug("checking request body")
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return domain.Channel{}, handleServiceError(domain.ErrInvalidTag, op, "check request body", log)
+		}
+		normalized = append(normalized, tag)
+	}
+	tags = utils.UniqueStrings(normalized)
+
+	log.Debug("saving channel tags")
+	if err := managerService.channelProvider.SetChannelTags(ctx, channelID, tags); err != nil {
+		return domain.Channel{}, handleServiceError(err, op, "save channel tags", log)
+	}
+	channel.Tags = tags
+
+	managerService.publishChannelUpdated(ctx, log, chat, channel, channel.ViewerIDs(chat))
+
+	log.Info("forum tags set successfully")
+	return channel, nil
+}
+
+// publishChatUpdated publishes the changed chat to every chat member
+func (managerService *ManagerService) publishChatUpdated(ctx context.Context, log *slog.Logger, chat domain.Chat) {
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_ChatUpdated{
+			ChatUpdated: mapper.ConvertChatToProto(&chat),
+		},
+	}
+	update := domain.Update{
+		Type:      domain.UpdateChatUpdated,
+		ChatID:    chat.ID,
+		Chat:      &chat,
+		CreatedAt: time.Now(),
+	}
+	managerService.eventBus.publish(ctx, log, chat.MemberIDs, "", update, event)
+}
+
+// publishChannelUpdated publishes the changed channel to the given chat members
+func (managerService *ManagerService) publishChannelUpdated(ctx context.Context, log *slog.Logger, chat domain.Chat, channel domain.Channel, memberIDs []string) {
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_ChannelUpdated{
+			ChannelUpdated: mapper.ConvertChannelToProto(channel),
+		},
+	}
+	update := domain.Update{
+		Type:      domain.UpdateChannelUpdated,
+		ChatID:    chat.ID,
+		ChannelID: channel.ID,
+		Channel:   &channel,
+		CreatedAt: time.Now(),
+	}
+	managerService.eventBus.publish(ctx, log, memberIDs, "", update, event)
+}
+
+// validateChannelMode checks the mode and that allowed posters are chat members
+func validateChannelMode(chat domain.Chat, mode string, posterIDs []string) error {
+	if mode != domain.ChannelModeNormal && mode != domain.ChannelModeAnnouncement {
+		return domain.ErrInvalidChannelMode
+	}
+	for _, id := range posterIDs {
+		if !utils.Contains(chat.MemberIDs, id) {
+			return domain.ErrNotChatMember
+		}
+	}
+	return nil
+}
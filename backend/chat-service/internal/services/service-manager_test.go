@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/boltdb"
+)
+
+// testUsers have user_ids told apart from their usernames
+var testUsers = &fakeUsers{usernames: map[string]string{"u-alice": "alice", "u-bob": "bob", "u-carol": "carol"}}
+
+func newTestManager(t *testing.T, users *fakeUsers) (*ManagerService, *boltdb.BoltDB) {
+	t.Helper()
+
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+
+	managerService := NewManagerService(
+		testLogger(),
+		storage,
+		storage,
+		storage,
+		storage,
+		storage,
+		storage,
+		storage,
+		users,
+		storage,
+		NewEventBus(testLogger(), storage),
+		"https://chat.example.com/invite/",
+		"https://chat.example.com/webhooks/",
+	)
+	return managerService, storage
+}
+
+func TestCreateChannelGoesAfterEveryChannel(t *testing.T) {
+	managerService, storage := newTestManager(t, &fakeUsers{usernames: map[string]string{"alice": "alice", "bob": "bob"}})
+	ctx := userContext("alice")
+
+	chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+	if err != nil {
+		t.Fatalf("CreateChat() error = %v", err)
+	}
+
+	var channelIDs []string
+	for _, name := range []string{"random", "news"} {
+		channelID, err := managerService.CreateChannel(ctx, chatID, name, "text", "", nil)
+		if err != nil {
+			t.Fatalf("CreateChannel(%s) error = %v", name, err)
+		}
+		channelIDs = append(channelIDs, channelID)
+	}
+	if err := managerService.DeleteChannel(ctx, channelIDs[0]); err != nil {
+		t.Fatalf("DeleteChannel() error = %v", err)
+	}
+	if _, err := managerService.CreateChannel(ctx, chatID, "events", "text", "", nil); err != nil {
+		t.Fatalf("CreateChannel(events) error = %v", err)
+	}
+
+	channels, err := storage.FindChatChannels(context.Background(), chatID)
+	if err != nil {
+		t.Fatalf("FindChatChannels() error = %v", err)
+	}
+	positions := make(map[string]int32, len(channels))
+	for _, channel := range channels {
+		positions[channel.Name] = channel.Position
+	}
+	want := map[string]int32{"Main": 0, "news": 2, "events": 3}
+	if len(positions) != len(want) {
+		t.Fatalf("channel positions = %v, want %v", positions, want)
+	}
+	for name, position := range want {
+		if positions[name] != position {
+			t.Errorf("position of %s = %d, want %d", name, positions[name], position)
+		}
+	}
+}
+
+func TestMemberChangesPostSystemMessages(t *testing.T) {
+	managerService, storage := newTestManager(t, testUsers)
+	ctx := userContext("u-alice")
+
+	chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+	if err != nil {
+		t.Fatalf("CreateChat() error = %v", err)
+	}
+	if _, err := managerService.AddMembers(ctx, chatID, []string{"carol"}); err != nil {
+		t.Fatalf("AddMembers() error = %v", err)
+	}
+	if err := managerService.RemoveMember(ctx, chatID, "u-carol"); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+	if err := managerService.LeaveChat(userContext("u-bob"), chatID); err != nil {
+		t.Fatalf("LeaveChat() error = %v", err)
+	}
+
+	mainChannel, err := findMainChannel(context.Background(), storage, chatID)
+	if err != nil {
+		t.Fatalf("findMainChannel() error = %v", err)
+	}
+	messages, err := storage.GetMessages(context.Background(), mainChannel.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages() error = %v", err)
+	}
+	var texts []string
+	for _, message := range messages {
+		if message.Type == domain.MessageTypeSystem {
+			texts = append(texts, message.Text)
+		}
+	}
+	slices.Sort(texts)
+	if want := []string{"alice added carol", "alice removed carol", "bob left the chat"}; !slices.Equal(texts, want) {
+		t.Errorf("system messages = %q, want %q", texts, want)
+	}
+}
+
+func TestMemberChangesInArchivedChat(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(managerService *ManagerService, ctx context.Context, chatID string) error
+	}{
+		{
+			name: "add",
+			change: func(managerService *ManagerService, ctx context.Context, chatID string) error {
+				_, err := managerService.AddMembers(ctx, chatID, []string{"carol"})
+				return err
+			},
+		},
+		{
+			name: "remove",
+			change: func(managerService *ManagerService, ctx context.Context, chatID string) error {
+				return managerService.RemoveMember(ctx, chatID, "u-bob")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			managerService, storage := newTestManager(t, testUsers)
+			ctx := userContext("u-alice")
+
+			chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+			if err != nil {
+				t.Fatalf("CreateChat() error = %v", err)
+			}
+			if err := managerService.ArchiveChat(ctx, chatID, true); err != nil {
+				t.Fatalf("ArchiveChat() error = %v", err)
+			}
+
+			if err := tt.change(managerService, ctx, chatID); !errors.Is(err, domain.ErrArchived) {
+				t.Errorf("error = %v, want %v", err, domain.ErrArchived)
+			}
+
+			chat, err := storage.FindChatByID(context.Background(), chatID, "u-alice")
+			if err != nil {
+				t.Fatalf("FindChatByID() error = %v", err)
+			}
+			if want := []string{"u-bob", "u-alice"}; !slices.Equal(chat.MemberIDs, want) {
+				t.Errorf("members = %v, want %v", chat.MemberIDs, want)
+			}
+		})
+	}
+}
+
+// failingCommit runs fn in a transaction of the storage and rolls it back as if the commit failed
+type failingCommit struct {
+	storage *boltdb.BoltDB
+}
+
+var errCommit = errors.New("commit failed")
+
+func (f failingCommit) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f.storage.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return errCommit
+	})
+}
+
+func TestRemoveMemberRollsBackWithSystemMessage(t *testing.T) {
+	managerService, storage := newTestManager(t, testUsers)
+	ctx := userContext("u-alice")
+
+	chatID, err := managerService.CreateChat(ctx, "group", "team", []string{"bob"})
+	if err != nil {
+		t.Fatalf("CreateChat() error = %v", err)
+	}
+
+	managerService.transactor = failingCommit{storage: storage}
+	if err := managerService.RemoveMember(ctx, chatID, "u-bob"); !errors.Is(err, errCommit) {
+		t.Fatalf("RemoveMember() error = %v, want %v", err, errCommit)
+	}
+
+	chat, err := storage.FindChatByID(context.Background(), chatID, "u-alice")
+	if err != nil {
+		t.Fatalf("FindChatByID() error = %v", err)
+	}
+	if want := []string{"u-bob", "u-alice"}; !slices.Equal(chat.MemberIDs, want) {
+		t.Errorf("members = %v, want %v", chat.MemberIDs, want)
+	}
+
+	mainChannel, err := findMainChannel(context.Background(), storage, chatID)
+	if err != nil {
+		t.Fatalf("findMainChannel() error = %v", err)
+	}
+	messages, err := storage.GetMessages(context.Background(), mainChannel.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetMessages() error = %v", err)
+	}
+	for _, message := range messages {
+		if message.Text == "alice removed bob" {
+			t.Errorf("system message %q saved by a rolled back removal", message.Text)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RetentionService deletes messages older than the retention policy of their channel.
+// Channels are walked page by page and messages are deleted in small batches with a pause
+// between them, so the purge does not hold the storage busy for live traffic.
+// Messages do not carry files yet, so there are no attachments to delete with them
+type RetentionService struct {
+	log             *slog.Logger
+	chatProvider    interfaces.ChatProvider
+	channelProvider interfaces.ChannelProvider
+	messageProvider interfaces.MessageProvider
+	eventBus        *EventBus
+
+	global     domain.RetentionPolicy
+	interval   time.Duration
+	batchSize  int32
+	batchPause time.Duration
+}
+
+func NewRetentionService(
+	log *slog.Logger,
+	chatProvider interfaces.ChatProvider,
+	channelProvider interfaces.ChannelProvider,
+	messageProvider interfaces.MessageProvider,
+	eventBus *EventBus,
+	global domain.RetentionPolicy,
+	interval time.Duration,
+	batchSize int32,
+	batchPause time.Duration,
+) *RetentionService {
+	return &RetentionService{
+		log:             log,
+		chatProvider:    chatProvider,
+		channelProvider: channelProvider,
+		messageProvider: messageProvider,
+		eventBus:        eventBus,
+
+		global:     global,
+		interval:   interval,
+		batchSize:  batchSize,
+		batchPause: batchPause,
+	}
+}
+
+// Run purges messages every interval until the context is canceled
+func (retentionService *RetentionService) Run(ctx context.Context) {
+	ticker := time.NewTicker(retentionService.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := retentionService.Purge(ctx); err != nil && ctx.Err() == nil {
+			retentionService.log.Error("failed to purge messages", logger.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge applies retention policies to every channel once and reports what was deleted
+func (retentionService *RetentionService) Purge(ctx context.Context) (domain.PurgeReport, error) {
+	const op = "services.retention.Purge"
+
+	log := retentionService.log.With(slog.String("op", op))
+	log.Info("purging messages")
+
+	report := domain.PurgeReport{StartedAt: time.Now()}
+
+	afterID := ""
+	for {
+		log.Debug("finding channels page", slog.String("after_id", afterID))
+		channels, err := retentionService.channelProvider.FindChannelsPage(ctx, afterID, retentionService.batchSize)
+		if err != nil {
+			return report, handleServiceError(err, op, "find channels page", log)
+		}
+		if len(channels) == 0 {
+			break
+		}
+		afterID = channels[len(channels)-1].ID
+
+		chatIDs := make([]string, 0, len(channels))
+		for _, channel := range channels {
+			chatIDs = append(chatIDs, channel.ChatID)
+		}
+
+		log.Debug("finding chats of channels")
+		chats, err := retentionService.chatProvider.FindChatsByIDs(ctx, utils.UniqueStrings(chatIDs))
+		if err != nil {
+			return report, handleServiceError(err, op, "find chats of channels", log)
+		}
+		chatsByID := make(map[string]domain.Chat, len(chats))
+		for _, chat := range chats {
+			chatsByID[chat.ID] = chat
+		}
+
+		for _, channel := range channels {
+			policy := domain.EffectiveRetention(retentionService.global, chatsByID[channel.ChatID], channel)
+			if policy.LegalHold || policy.MaxAge == 0 {
+				continue
+			}
+
+			before := report.StartedAt.Add(-policy.MaxAge)
+			purged, err := retentionService.purgeChannel(ctx, log, channel.ID, before)
+			if err != nil {
+				return report, handleServiceError(err, op, "purge channel", log)
+			}
+			if purged == 0 {
+				continue
+			}
+			retentionService.eventBus.publishPurge(ctx, log, chatsByID[channel.ChatID], channel, before)
+
+			log.Info("channel purged",
+				slog.String("chat_id", channel.ChatID),
+				slog.String("channel_id", channel.ID),
+				slog.Time("before", before),
+				slog.Int("messages", purged),
+			)
+			report.Channels = append(report.Channels, domain.PurgedChannel{
+				ChatID:    channel.ChatID,
+				ChannelID: channel.ID,
+				Before:    before,
+				Messages:  purged,
+			})
+			report.Messages += purged
+		}
+
+		if len(channels) < int(retentionService.batchSize) {
+			break
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	log.Info("messages purged successfully",
+		slog.Int("channels", len(report.Channels)),
+		slog.Int("messages", report.Messages),
+		slog.Duration("took", report.FinishedAt.Sub(report.StartedAt)),
+	)
+	return report, nil
+}
+
+// purgeChannel deletes messages of the channel batch by batch and returns how many were deleted
+func (retentionService *RetentionService) purgeChannel(ctx context.Context, log *slog.Logger, channelID string, before time.Time) (int, error) {
+	purged := 0
+	for {
+		log.Debug("deleting messages batch", slog.String("channel_id", channelID))
+		messageIDs, err := retentionService.messageProvider.DeleteMessagesBefore(ctx, channelID, before, retentionService.batchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += len(messageIDs)
+
+		if len(messageIDs) < int(retentionService.batchSize) {
+			return purged, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return purged, ctx.Err()
+		case <-time.After(retentionService.batchPause):
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/boltdb"
+)
+
+func TestRetentionPurgeRecordsUpdate(t *testing.T) {
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+	ctx := context.Background()
+
+	chatID, err := storage.SaveChat(ctx, domain.Chat{Type: "group", Name: "team", MemberIDs: []string{"alice", "bob"}})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+	channelID, err := storage.SaveChannel(ctx, domain.Channel{ChatID: chatID, Name: "general", Type: "text"})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+	now := time.Now()
+	if _, err := storage.SaveMessages(ctx, channelID, []domain.Message{
+		{ChannelID: channelID, SenderID: "alice", Text: "old", CreatedAt: now.Add(-48 * time.Hour)},
+		{ChannelID: channelID, SenderID: "bob", Text: "new", CreatedAt: now},
+	}); err != nil {
+		t.Fatalf("SaveMessages() error = %v", err)
+	}
+
+	retentionService := NewRetentionService(
+		testLogger(),
+		storage,
+		storage,
+		storage,
+		NewEventBus(testLogger(), storage),
+		domain.RetentionPolicy{MaxAge: 24 * time.Hour},
+		time.Hour,
+		100,
+		0,
+	)
+
+	report, err := retentionService.Purge(ctx)
+	if err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if report.Messages != 1 {
+		t.Fatalf("Purge() deleted %d messages, want 1", report.Messages)
+	}
+
+	for _, userID := range []string{"alice", "bob"} {
+		updates, err := storage.GetUpdates(ctx, userID, 0, 10)
+		if err != nil {
+			t.Fatalf("GetUpdates() error = %v", err)
+		}
+		if len(updates) != 1 || updates[0].Type != domain.UpdateMessagesPurged || updates[0].ChannelID != channelID || updates[0].PurgedBefore == nil {
+			t.Errorf("%s updates = %+v, want one messages_purged update", userID, updates)
+		}
+	}
+}
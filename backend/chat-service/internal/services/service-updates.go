@@ -0,0 +1,70 @@
+package services
+
+import (
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// UpdatesService deletes updates older than the retention of the update log. Clients
+// that fall behind the oldest kept update are asked for a full resync by GetUpdates
+type UpdatesService struct {
+	log            *slog.Logger
+	updateProvider interfaces.UpdateProvider
+
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewUpdatesService(
+	log *slog.Logger,
+	updateProvider interfaces.UpdateProvider,
+	retention time.Duration,
+	interval time.Duration,
+) *UpdatesService {
+	return &UpdatesService{
+		log:            log,
+		updateProvider: updateProvider,
+
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Run trims the update log every interval until the context is canceled
+func (updatesService *UpdatesService) Run(ctx context.Context) {
+	ticker := time.NewTicker(updatesService.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := updatesService.Trim(ctx); err != nil && ctx.Err() == nil {
+			updatesService.log.Error("failed to trim updates", logger.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Trim deletes updates of all users older than the retention and returns how many were deleted
+func (updatesService *UpdatesService) Trim(ctx context.Context) (int64, error) {
+	const op = "services.updates.Trim"
+
+	before := time.Now().Add(-updatesService.retention)
+
+	log := updatesService.log.With(slog.String("op", op), slog.Time("before", before))
+	log.Info("trimming updates")
+
+	deleted, err := updatesService.updateProvider.DeleteUpdatesBefore(ctx, before)
+	if err != nil {
+		return 0, handleServiceError(err, op, "delete expired updates", log)
+	}
+
+	log.Info("updates trimmed successfully", slog.Int64("deleted", deleted))
+	return deleted, nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/utils"
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// resolveUserIDs turns user_ids and usernames into user_ids, every reference must match an existing user
+func resolveUserIDs(ctx context.Context, log *slog.Logger, userProvider interfaces.UserProvider, refs []string) ([]string, error) {
+	const op = "services.resolveUserIDs"
+
+	refs = utils.UniqueStrings(refs)
+	if len(refs) == 0 {
+		return refs, nil
+	}
+
+	log.Debug("checking if users exist")
+	usernames, err := userProvider.GetUsernames(ctx, refs)
+	if err != nil {
+		return nil, handleServiceError(err, op, "check users existence", log)
+	}
+
+	var names []string
+	for _, ref := range refs {
+		if _, ok := usernames[ref]; !ok {
+			names = append(names, ref)
+		}
+	}
+
+	var userIDs map[string]string
+	if len(names) > 0 {
+		log.Debug("finding users by username")
+		if userIDs, err = userProvider.GetUserIDs(ctx, names); err != nil {
+			return nil, handleServiceError(err, op, "find users by username", log)
+		}
+	}
+
+	resolved := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if _, ok := usernames[ref]; ok {
+			resolved = append(resolved, ref)
+			continue
+		}
+
+		userID, ok := userIDs[ref]
+		if !ok {
+			return nil, handleServiceError(domain.ErrUserNotFound, op, "check users existence", log)
+		}
+		resolved = append(resolved, userID)
+	}
+
+	return utils.UniqueStrings(resolved), nil
+}
+
+// displayNames returns usernames of the users. Views must work while user-service is down,
+// so failures are only logged and the callers fall back to user_ids
+func displayNames(ctx context.Context, log *slog.Logger, userProvider interfaces.UserProvider, userIDs []string) map[string]string {
+	userIDs = utils.UniqueStrings(userIDs)
+	if len(userIDs) == 0 {
+		return map[string]string{}
+	}
+
+	log.Debug("getting display names")
+	usernames, err := userProvider.GetUsernames(ctx, userIDs)
+	if err != nil {
+		log.Warn("failed to get display names", logger.Err(err))
+		return map[string]string{}
+	}
+
+	return usernames
+}
+
+// nameOrID returns the display name of the user or the user_id when it is unknown
+func nameOrID(names map[string]string, userID string) string {
+	if name, ok := names[userID]; ok {
+		return name
+	}
+	return userID
+}
+
+// joinNames joins display names of the users for system messages, see nameOrID
+func joinNames(names map[string]string, userIDs []string) string {
+	joined := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		joined = append(joined, nameOrID(names, userID))
+	}
+	return strings.Join(joined, ", ")
+}
+
+// fillSenderNames sets SenderName of the messages, senders unknown to user-service keep an empty name.
+// Messages of webhooks are named after the webhook
+func fillSenderNames(ctx context.Context, log *slog.Logger, userProvider interfaces.UserProvider, messages []*domain.Message) {
+	senderIDs := make([]string, 0, len(messages))
+	for _, message := range messages {
+		if message.Webhook == nil {
+			senderIDs = append(senderIDs, message.SenderID)
+		}
+	}
+
+	names := displayNames(ctx, log, userProvider, senderIDs)
+	for _, message := range messages {
+		if message.Webhook != nil {
+			message.SenderName = message.Webhook.Name
+			continue
+		}
+		message.SenderName = names[message.SenderID]
+	}
+}
+
+// checkNotBlocked returns ErrUserBlocked when the user and any of the peers blocked one another.
+// Blockers are cached by the user-service client, so this is cheap enough for every message
+func checkNotBlocked(ctx context.Context, log *slog.Logger, userProvider interfaces.UserProvider, userID string, peerIDs []string) error {
+	const op = "services.checkNotBlocked"
+
+	log.Debug("checking blocks between users")
+	blockers, err := userProvider.GetBlockedBy(ctx, utils.UniqueStrings(append([]string{userID}, peerIDs...)))
+	if err != nil {
+		return handleServiceError(err, op, "check blocks between users", log)
+	}
+
+	for _, peerID := range peerIDs {
+		if peerID == userID {
+			continue
+		}
+		if utils.Contains(blockers[userID], peerID) || utils.Contains(blockers[peerID], userID) {
+			return handleServiceError(domain.ErrUserBlocked, op, "check blocks between users", log)
+		}
+	}
+
+	return nil
+}
+
+// blockersOf returns users who blocked the user. Delivery into group chats must work while
+// user-service is down, so failures are only logged and nobody is skipped
+func blockersOf(ctx context.Context, log *slog.Logger, userProvider interfaces.UserProvider, userID string) []string {
+	log.Debug("getting blockers of user")
+	blockers, err := userProvider.GetBlockedBy(ctx, []string{userID})
+	if err != nil {
+		log.Warn("failed to get blockers of user", logger.Err(err))
+		return nil
+	}
+
+	return blockers[userID]
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/export"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	exportBatchSize = 500
+	// exportBufferSize is the size of data chunks passed to the export writer
+	exportBufferSize = 64 << 10
+)
+
+// ExportChannel writes the whole history of the channel in the format into the writer returned by open.
+// With bundle the export is packed into a zip archive together with message attachments.
+// Messages are read in batches, so the export does not hold the history in memory
+func (viewService *ViewService) ExportChannel(ctx context.Context, channelID string, format string, bundle bool, open func(file domain.ExportFile) (io.Writer, error)) error {
+	const op = "services.viewService.ExportChannel"
+
+	log := viewService.log.With(slog.String("op", op), slog.String("channel_id", channelID), slog.String("format", format))
+	log.Info("exporting channel")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	if !export.Valid(format) {
+		return handleServiceError(domain.ErrInvalidExportFormat, op, "check request body", log)
+	}
+
+	chat, channel, err := channelValidation(ctx, log, viewService.chatProvider, viewService.channelProvider, channelID, userID, 0)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// private chats have no name, the export is titled with their members
+	if chat.Type == "private" {
+		names := displayNames(ctx, log, viewService.userProvider, chat.MemberIDs)
+		memberNames := make([]string, 0, len(chat.MemberIDs))
+		for _, memberID := range chat.MemberIDs {
+			memberNames = append(memberNames, nameOrID(names, memberID))
+		}
+		chat.Name = strings.Join(memberNames, ", ")
+	}
+
+	header := export.Header{
+		Chat:       chat,
+		Channel:    channel,
+		ExportedAt: time.Now(),
+	}
+	if channel.Type == domain.ChannelTypeForum {
+		if header.Posts, err = viewService.exportPosts(ctx, log, channelID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		authorIDs := make([]string, 0, len(header.Posts))
+		for _, post := range header.Posts {
+			authorIDs = append(authorIDs, post.AuthorID)
+		}
+		header.Names = displayNames(ctx, log, viewService.userProvider, authorIDs)
+	}
+
+	file := export.File(channel, format)
+	entryName := file.Name
+	if bundle {
+		file = export.Bundle(file)
+	}
+
+	log.Debug("opening export file")
+	w, err := open(file)
+	if err != nil {
+		return handleServiceError(err, op, "open export file", log)
+	}
+	buffered := bufio.NewWriterSize(w, exportBufferSize)
+
+	var archive *zip.Writer
+	var out io.Writer = buffered
+	if bundle {
+		archive = zip.NewWriter(buffered)
+		if out, err = archive.Create(entryName); err != nil {
+			return handleServiceError(err, op, "create zip entry", log)
+		}
+	}
+
+	count, err := viewService.renderChannel(ctx, log, export.NewRenderer(format, out), header)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// messages do not carry files yet, so the archive holds only the export itself
+	if archive != nil {
+		if err := archive.Close(); err != nil {
+			return handleServiceError(err, op, "close zip archive", log)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return handleServiceError(err, op, "flush export file", log)
+	}
+
+	log.Info("channel exported successfully", slog.Int("messages", count))
+	return nil
+}
+
+// renderChannel renders the header and every message of the channel, it returns the number of messages
+func (viewService *ViewService) renderChannel(ctx context.Context, log *slog.Logger, renderer export.Renderer, header export.Header) (int, error) {
+	const op = "services.viewService.renderChannel"
+
+	if err := renderer.Begin(header); err != nil {
+		return 0, handleServiceError(err, op, "write export header", log)
+	}
+
+	var (
+		afterTime time.Time
+		afterID   string
+		count     int
+	)
+	for {
+		log.Debug("getting messages batch", slog.Int("exported", count))
+		messages, err := viewService.messageProvider.GetMessagesAfter(ctx, header.Channel.ID, afterTime, afterID, exportBatchSize)
+		if err != nil {
+			return 0, handleServiceError(err, op, "get messages batch", log)
+		}
+		fillSenderNames(ctx, log, viewService.userProvider, messages)
+
+		for _, message := range messages {
+			if err := renderer.Message(message); err != nil {
+				return 0, handleServiceError(err, op, "write message", log)
+			}
+		}
+		count += len(messages)
+
+		if len(messages) < exportBatchSize {
+			break
+		}
+		last := messages[len(messages)-1]
+		afterTime, afterID = last.CreatedAt, last.ID
+	}
+
+	if err := renderer.End(); err != nil {
+		return 0, handleServiceError(err, op, "write export footer", log)
+	}
+
+	return count, nil
+}
+
+// exportPosts returns every post of the forum channel in creation order
+func (viewService *ViewService) exportPosts(ctx context.Context, log *slog.Logger, channelID string) ([]*domain.Post, error) {
+	const op = "services.viewService.exportPosts"
+
+	var posts []*domain.Post
+	for offset := int32(0); ; offset += maxPostsLimit {
+		log.Debug("getting forum posts", slog.Int("offset", int(offset)))
+		batch, err := viewService.postProvider.FindChannelPosts(ctx, channelID, "", maxPostsLimit, offset)
+		if err != nil {
+			return nil, handleServiceError(err, op, "get forum posts", log)
+		}
+		posts = append(posts, batch...)
+
+		if len(batch) < maxPostsLimit {
+			break
+		}
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+
+	return posts, nil
+}
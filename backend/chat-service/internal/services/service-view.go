@@ -9,13 +9,26 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
+)
+
+const (
+	defaultUpdatesLimit = 100
+	maxUpdatesLimit     = 1000
+
+	defaultPostsLimit = 50
+	maxPostsLimit     = 200
 )
 
 type ViewService struct {
-	log             *slog.Logger
-	chatProvider    interfaces.ChatProvider
-	channelProvider interfaces.ChannelProvider
-	messageProvider interfaces.MessageProvider
+	log               *slog.Logger
+	chatProvider      interfaces.ChatProvider
+	channelProvider   interfaces.ChannelProvider
+	messageProvider   interfaces.MessageProvider
+	updateProvider    interfaces.UpdateProvider
+	postProvider      interfaces.PostProvider
+	communityProvider interfaces.CommunityProvider
+	userProvider      interfaces.UserProvider
 }
 
 func NewViewService(
@@ -23,16 +36,25 @@ func NewViewService(
 	chatProvider interfaces.ChatProvider,
 	channelProvider interfaces.ChannelProvider,
 	messageProvider interfaces.MessageProvider,
+	updateProvider interfaces.UpdateProvider,
+	postProvider interfaces.PostProvider,
+	communityProvider interfaces.CommunityProvider,
+	userProvider interfaces.UserProvider,
 ) *ViewService {
 	return &ViewService{
-		log:             log,
-		chatProvider:    chatProvider,
-		channelProvider: channelProvider,
-		messageProvider: messageProvider,
+		log:               log,
+		chatProvider:      chatProvider,
+		channelProvider:   channelProvider,
+		messageProvider:   messageProvider,
+		updateProvider:    updateProvider,
+		postProvider:      postProvider,
+		communityProvider: communityProvider,
+		userProvider:      userProvider,
 	}
 }
 
-func (viewService *ViewService) GetUserChats(ctx context.Context, chatType string) ([]*chatpb.ChatPreview, error) {
+// GetUserChats lists chats of the given type outside communities or, when communityID is set, chats of the community
+func (viewService *ViewService) GetUserChats(ctx context.Context, chatType string, communityID string, includeArchived bool) ([]*chatpb.ChatPreview, error) {
 	const op = "services.viewService.GetUserChats"
 
 	log := viewService.log.With(slog.String("op", op))
@@ -44,6 +66,24 @@ func (viewService *ViewService) GetUserChats(ctx context.Context, chatType strin
 		return nil, handleServiceError(err, op, "get user_id from context", log)
 	}
 
+	if communityID != "" {
+		log.Debug("finding community by id")
+		community, err := viewService.communityProvider.FindCommunityByID(ctx, communityID)
+		if err != nil {
+			return nil, handleServiceError(err, op, "find community by id", log)
+		}
+
+		log.Debug("checking user permissions")
+		if err := checkCommunityPermission(community, userID, 0); err != nil {
+			return nil, handleServiceError(err, op, "check user permissions", log)
+		}
+
+		// communities own only group chats
+		if chatType == "" {
+			chatType = "group"
+		}
+	}
+
 	log.Debug("checking request body")
 	if !utils.Contains(allowedChatTypes, chatType) {
 		return nil, handleServiceError(domain.ErrInvalidChatType, op, "check request body", log)
@@ -51,19 +91,32 @@ func (viewService *ViewService) GetUserChats(ctx context.Context, chatType strin
 
 	// TODO: add main channel id here
 	log.Debug("getting users chats")
-	chatPreviews, err := viewService.chatProvider.FindUserChats(ctx, userID, chatType)
+	chatPreviews, err := viewService.chatProvider.FindUserChats(ctx, userID, chatType, communityID, includeArchived)
 	if err != nil {
 		return nil, handleServiceError(err, op, "get user chats", log)
 
 	}
 
+	peerIDs := make([]string, 0, len(chatPreviews))
+	for _, chatPreview := range chatPreviews {
+		if chatPreview.PeerID != "" {
+			peerIDs = append(peerIDs, chatPreview.PeerID)
+		}
+	}
+	names := displayNames(ctx, log, viewService.userProvider, peerIDs)
+	for _, chatPreview := range chatPreviews {
+		if name, ok := names[chatPreview.PeerID]; ok {
+			chatPreview.Name = name
+		}
+	}
+
 	protoChatPreviews := mapper.ConvertChatPreviewsToProto(chatPreviews)
 
 	log.Info("chat previews got successfully")
 	return protoChatPreviews, nil
 }
 
-func (viewService *ViewService) GetChatInfo(ctx context.Context, chatID string) (chatInfo domain.ChatInfo, err error) {
+func (viewService *ViewService) GetChatInfo(ctx context.Context, chatID string, includeArchived bool) (chatInfo domain.ChatInfo, err error) {
 	const op = "services.viewService.GetChatInfo"
 
 	log := viewService.log.With(slog.String("op", op))
@@ -88,18 +141,31 @@ func (viewService *ViewService) GetChatInfo(ctx context.Context, chatID string)
 	}
 
 	log.Debug("getting channels info")
-	channels, err := viewService.channelProvider.FindChannelsByIDs(ctx, chat.ChannelIDs)
+	channels, err := viewService.channelProvider.FindChatChannels(ctx, chat.ID)
 	if err != nil {
 		return domain.ChatInfo{}, handleServiceError(err, op, "get channels info", log)
 
 	}
+	channels = visibleChannels(channels, chat, userID, includeArchived)
+	sortChannels(channels)
 	protoChannels := mapper.ConvertChannelsToProto(channels)
 
+	memberNames := displayNames(ctx, log, viewService.userProvider, chat.MemberIDs)
+	if name, ok := memberNames[chat.Name]; ok && chat.Type == "private" {
+		chat.Name = name
+	}
+
 	chatInfo = domain.ChatInfo{
 		ID:            chat.ID,
 		Type:          chat.Type,
 		Name:          chat.Name,
+		Topic:         chat.Topic,
+		Description:   chat.Description,
+		Archived:      chat.Archived,
+		CommunityID:   chat.CommunityID,
 		MemberIDs:     chat.MemberIDs,
+		MemberNames:   memberNames,
+		Roles:         chat.Roles,
 		ProtoChannels: protoChannels,
 	}
 
@@ -120,7 +186,7 @@ func (viewService *ViewService) GetMessages(ctx context.Context, channelID strin
 
 	}
 
-	if err := viewService.channelValidation(ctx, log, channelID, userID); err != nil {
+	if _, _, err := channelValidation(ctx, log, viewService.chatProvider, viewService.channelProvider, channelID, userID, 0); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -129,31 +195,240 @@ func (viewService *ViewService) GetMessages(ctx context.Context, channelID strin
 	if err != nil {
 		return nil, handleServiceError(err, op, "get messages from channel", log)
 	}
+	fillSenderNames(ctx, log, viewService.userProvider, messages)
 	protoMessages := mapper.ConvertMessagesToProto(messages)
 
 	log.Info("messages got successfully")
 	return protoMessages, nil
 }
 
-func (viewService *ViewService) channelValidation(ctx context.Context, log *slog.Logger, channelID string, userID string) error {
-	const op = "services.viewService.channelValidation"
+// GetUpdates returns updates with seq greater than sinceSeq or asks the client
+// for a full resync when part of the difference is no longer in the log. The page ends
+// before the first gap in seqs, with HasMore set Seq is the seq of the last returned update
+// so the client continues from it
+func (viewService *ViewService) GetUpdates(ctx context.Context, sinceSeq int64, limit int32) (domain.UpdatesDifference, error) {
+	const op = "services.viewService.GetUpdates"
+
+	log := viewService.log.With(slog.String("op", op), slog.Int64("since_seq", sinceSeq))
+	log.Info("getting updates")
 
-	log.Debug("checking if channel exists")
-	existingChannel, err := viewService.channelProvider.FindChannelByID(ctx, channelID)
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
 	if err != nil {
-		return handleServiceError(err, op, "check channel existence", log)
+		return domain.UpdatesDifference{}, handleServiceError(err, op, "get user_id from context", log)
 	}
 
-	log.Debug("checking if chat exists")
-	existingChat, err := viewService.chatProvider.FindChatByID(ctx, existingChannel.ChatID, userID)
+	log.Debug("checking request body")
+	if sinceSeq < 0 || limit < 0 {
+		return domain.UpdatesDifference{}, handleServiceError(domain.ErrInvalidPage, op, "check request body", log)
+	}
+	if limit == 0 {
+		limit = defaultUpdatesLimit
+	}
+	limit = min(limit, maxUpdatesLimit)
+
+	log.Debug("getting updates state")
+	seq, err := viewService.updateProvider.GetUpdatesState(ctx, userID)
 	if err != nil {
-		return handleServiceError(err, op, "check chat existence", log)
+		return domain.UpdatesDifference{}, handleServiceError(err, op, "get updates state", log)
 	}
 
-	log.Debug("checking if user in this chat")
-	if !utils.Contains(existingChat.MemberIDs, userID) {
-		return handleServiceError(domain.ErrAccessDenied, op, "check if user in this chat", log)
+	switch {
+	case sinceSeq == seq:
+		log.Info("no new updates")
+		return domain.UpdatesDifference{Seq: seq}, nil
+	case sinceSeq > seq:
+		log.Warn("client seq is ahead of the server, resync required")
+		return domain.UpdatesDifference{Seq: seq, ResyncRequired: true}, nil
+	}
+
+	log.Debug("getting updates difference")
+	updates, err := viewService.updateProvider.GetUpdates(ctx, userID, sinceSeq, limit+1)
+	if err != nil {
+		return domain.UpdatesDifference{}, handleServiceError(err, op, "get updates difference", log)
+	}
+
+	if len(updates) == 0 || updates[0].Seq != sinceSeq+1 {
+		log.Info("updates are too old, resync required")
+		return domain.UpdatesDifference{Seq: seq, ResyncRequired: true}, nil
+	}
+
+	hasMore := len(updates) > int(limit)
+	if hasMore {
+		updates = updates[:limit]
+	}
+	for i, update := range updates {
+		if update.Seq != sinceSeq+int64(i)+1 {
+			log.Warn("gap in updates, returning updates before it", slog.Int64("expected_seq", sinceSeq+int64(i)+1), slog.Int64("seq", update.Seq))
+			updates = updates[:i]
+			hasMore = true
+			break
+		}
+	}
+	if hasMore {
+		seq = updates[len(updates)-1].Seq
+	}
+
+	log.Info("updates got successfully")
+	return domain.UpdatesDifference{
+		Updates: updates,
+		Seq:     seq,
+		HasMore: hasMore,
+	}, nil
+}
+
+// ListForumPosts returns posts of a forum channel sorted by latest activity, filtered by tag when it is set
+func (viewService *ViewService) ListForumPosts(ctx context.Context, channelID string, tag string, limit int32, offset int32) ([]*chatpb.ForumPost, error) {
+	const op = "services.viewService.ListForumPosts"
+
+	log := viewService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("listing forum posts")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	limit, err = pageLimit(limit, offset, defaultPostsLimit, maxPostsLimit)
+	if err != nil {
+		return nil, handleServiceError(err, op, "check request body", log)
+	}
+
+	if _, _, err := channelValidation(ctx, log, viewService.chatProvider, viewService.channelProvider, channelID, userID, 0); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("getting forum posts")
+	posts, err := viewService.postProvider.FindChannelPosts(ctx, channelID, tag, limit, offset)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get forum posts", log)
+	}
+
+	log.Info("forum posts got successfully")
+	return mapper.ConvertPostsToProto(posts), nil
+}
+
+// GetPostReplies returns the reply thread of a forum post in chronological order
+func (viewService *ViewService) GetPostReplies(ctx context.Context, postID string, limit int32, offset int32) ([]*chatpb.Message, error) {
+	const op = "services.viewService.GetPostReplies"
+
+	log := viewService.log.With(slog.String("op", op), slog.String("post_id", postID))
+	log.Info("getting post replies")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("checking request body")
+	limit, err = pageLimit(limit, offset, defaultPostsLimit, maxPostsLimit)
+	if err != nil {
+		return nil, handleServiceError(err, op, "check request body", log)
+	}
+
+	log.Debug("finding post by id")
+	post, err := viewService.postProvider.FindPostByID(ctx, postID)
+	if err != nil {
+		return nil, handleServiceError(err, op, "find post by id", log)
+	}
+
+	if _, _, err := channelValidation(ctx, log, viewService.chatProvider, viewService.channelProvider, post.ChannelID, userID, 0); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("getting post replies")
+	messages, err := viewService.messageProvider.GetPostMessages(ctx, postID, limit, offset)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get post replies", log)
+	}
+	fillSenderNames(ctx, log, viewService.userProvider, messages)
+
+	log.Info("post replies got successfully")
+	return mapper.ConvertMessagesToProto(messages), nil
+}
+
+// sortChannels orders channels listed in creation order by position, channels with equal
+// position keep creation order
+func sortChannels(channels []domain.Channel) {
+	sort.SliceStable(channels, func(i, j int) bool {
+		return channels[i].Position < channels[j].Position
+	})
+}
+
+// visibleChannels filters out private channels the user doesn't see and, unless asked, archived channels
+func visibleChannels(channels []domain.Channel, chat domain.Chat, userID string, includeArchived bool) []domain.Channel {
+	visible := channels[:0]
+	for _, channel := range channels {
+		if channel.Archived && !includeArchived {
+			continue
+		}
+		if !channel.CanView(chat, userID) {
+			continue
+		}
+		visible = append(visible, channel)
+	}
+	return visible
+}
+
+// pageLimit applies the default and the upper bound to limit
+func pageLimit(limit int32, offset int32, defaultLimit int32, maxLimit int32) (int32, error) {
+	if limit < 0 || offset < 0 {
+		return 0, domain.ErrInvalidPage
+	}
+	if limit == 0 {
+		return defaultLimit, nil
+	}
+	return min(limit, maxLimit), nil
+}
+
+func (viewService *ViewService) GetCommunity(ctx context.Context, communityID string) (domain.Community, error) {
+	const op = "services.viewService.GetCommunity"
+
+	log := viewService.log.With(slog.String("op", op), slog.String("community_id", communityID))
+	log.Info("getting community")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Community{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding community by id")
+	community, err := viewService.communityProvider.FindCommunityByID(ctx, communityID)
+	if err != nil {
+		return domain.Community{}, handleServiceError(err, op, "find community by id", log)
+	}
+
+	log.Debug("checking user permissions")
+	if err := checkCommunityPermission(community, userID, 0); err != nil {
+		return domain.Community{}, handleServiceError(err, op, "check user permissions", log)
+	}
+
+	log.Info("community got successfully")
+	return community, nil
+}
+
+func (viewService *ViewService) GetUserCommunities(ctx context.Context) ([]*domain.Community, error) {
+	const op = "services.viewService.GetUserCommunities"
+
+	log := viewService.log.With(slog.String("op", op))
+	log.Info("getting user communities")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding user communities")
+	communities, err := viewService.communityProvider.FindUserCommunities(ctx, userID)
+	if err != nil {
+		return nil, handleServiceError(err, op, "find user communities", log)
 	}
 
-	return nil
+	log.Info("user communities got successfully")
+	return communities, nil
 }
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+)
+
+// fakeUpdates keeps the update log of a single user, seqs may have gaps
+type fakeUpdates struct {
+	seq     int64
+	updates []*domain.Update
+}
+
+func (f *fakeUpdates) SaveUpdates(ctx context.Context, update domain.Update, userIDs []string) (map[string]int64, error) {
+	seqs := make(map[string]int64, len(userIDs))
+	for _, userID := range userIDs {
+		f.seq++
+		memberUpdate := update
+		memberUpdate.UserID = userID
+		memberUpdate.Seq = f.seq
+		f.updates = append(f.updates, &memberUpdate)
+		seqs[userID] = f.seq
+	}
+	return seqs, nil
+}
+
+func (f *fakeUpdates) GetUpdates(ctx context.Context, userID string, sinceSeq int64, limit int32) ([]*domain.Update, error) {
+	var updates []*domain.Update
+	for _, update := range f.updates {
+		if update.Seq > sinceSeq && len(updates) < int(limit) {
+			updates = append(updates, update)
+		}
+	}
+	return updates, nil
+}
+
+func (f *fakeUpdates) GetUpdatesState(ctx context.Context, userID string) (int64, error) {
+	return f.seq, nil
+}
+
+func (f *fakeUpdates) DeleteUpdatesBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newFakeUpdates(seqs ...int64) *fakeUpdates {
+	f := &fakeUpdates{}
+	for _, seq := range seqs {
+		f.updates = append(f.updates, &domain.Update{Seq: seq, Type: domain.UpdateNewMessage})
+		f.seq = seq
+	}
+	return f
+}
+
+func TestViewServiceGetUpdates(t *testing.T) {
+	tests := []struct {
+		name     string
+		seqs     []int64
+		sinceSeq int64
+		limit    int32
+
+		wantSeqs    []int64
+		wantSeq     int64
+		wantHasMore bool
+		wantResync  bool
+	}{
+		{name: "up to date", seqs: []int64{1, 2}, sinceSeq: 2, wantSeq: 2},
+		{name: "whole difference", seqs: []int64{1, 2, 3}, sinceSeq: 1, wantSeqs: []int64{2, 3}, wantSeq: 3},
+		{name: "page ends at limit", seqs: []int64{1, 2, 3, 4}, sinceSeq: 0, limit: 2, wantSeqs: []int64{1, 2}, wantSeq: 2, wantHasMore: true},
+		{name: "page ends before gap", seqs: []int64{1, 2, 4, 5}, sinceSeq: 0, wantSeqs: []int64{1, 2}, wantSeq: 2, wantHasMore: true},
+		{name: "trimmed log", seqs: []int64{3, 4}, sinceSeq: 1, wantSeq: 4, wantResync: true},
+		{name: "client ahead", seqs: []int64{1}, sinceSeq: 5, wantSeq: 1, wantResync: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewService := NewViewService(testLogger(), nil, nil, nil, newFakeUpdates(tt.seqs...), nil, nil, nil)
+
+			diff, err := viewService.GetUpdates(userContext("user"), tt.sinceSeq, tt.limit)
+			if err != nil {
+				t.Fatalf("GetUpdates() error = %v", err)
+			}
+
+			var seqs []int64
+			for _, update := range diff.Updates {
+				seqs = append(seqs, update.Seq)
+			}
+			if len(seqs) != len(tt.wantSeqs) {
+				t.Fatalf("seqs = %v, want %v", seqs, tt.wantSeqs)
+			}
+			for i := range seqs {
+				if seqs[i] != tt.wantSeqs[i] {
+					t.Fatalf("seqs = %v, want %v", seqs, tt.wantSeqs)
+				}
+			}
+			if diff.Seq != tt.wantSeq || diff.HasMore != tt.wantHasMore || diff.ResyncRequired != tt.wantResync {
+				t.Errorf("seq = %d, has_more = %v, resync = %v, want %d, %v, %v",
+					diff.Seq, diff.HasMore, diff.ResyncRequired, tt.wantSeq, tt.wantHasMore, tt.wantResync)
+			}
+		})
+	}
+}
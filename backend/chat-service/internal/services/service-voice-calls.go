@@ -0,0 +1,459 @@
+package services
+
+import (
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/mapper"
+	"chat-service/internal/lib/utils"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const callIDSize = 12
+
+// activeCall is a call that has not ended yet
+type activeCall struct {
+	call domain.Call
+	// timer ends a ringing call nobody answered or an active call both parties stay disconnected from
+	timer *time.Timer
+	peers map[string]*voicePeer
+}
+
+// StartCall rings the other member of a private chat, the call is missed if it is not answered in time
+func (voiceService *VoiceService) StartCall(ctx context.Context, chatID string, video bool) (domain.Call, error) {
+	const op = "services.voice.StartCall"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("chat_id", chatID))
+	log.Info("starting call")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Call{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding chat by id")
+	chat, err := voiceService.chatProvider.FindChatByID(ctx, chatID, userID)
+	if err != nil {
+		return domain.Call{}, handleServiceError(err, op, "find chat by id", log)
+	}
+
+	log.Debug("checking if user in this chat")
+	if !utils.Contains(chat.MemberIDs, userID) {
+		return domain.Call{}, handleServiceError(domain.ErrAccessDenied, op, "check if user in this chat", log)
+	}
+
+	log.Debug("checking chat type")
+	if chat.Type != "private" {
+		return domain.Call{}, handleServiceError(domain.ErrInvalidChatType, op, "check chat type", log)
+	}
+
+	log.Debug("checking if chat is archived")
+	if chat.Archived {
+		return domain.Call{}, handleServiceError(domain.ErrArchived, op, "check if chat is archived", log)
+	}
+
+	var calleeID string
+	for _, memberID := range chat.MemberIDs {
+		if memberID != userID {
+			calleeID = memberID
+		}
+	}
+	if calleeID == "" {
+		return domain.Call{}, handleServiceError(domain.ErrInvalidChatType, op, "find callee", log)
+	}
+
+	if err := checkNotBlocked(ctx, log, voiceService.userProvider, userID, []string{calleeID}); err != nil {
+		return domain.Call{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("generating call id")
+	callID, err := utils.RandomCode(callIDSize)
+	if err != nil {
+		return domain.Call{}, handleServiceError(err, op, "generate call id", log)
+	}
+
+	call := domain.Call{
+		ID:        callID,
+		ChatID:    chat.ID,
+		CallerID:  userID,
+		CalleeID:  calleeID,
+		Video:     video,
+		State:     domain.CallStateRinging,
+		CreatedAt: time.Now(),
+	}
+
+	voiceService.mu.Lock()
+	_, callerBusy := voiceService.userCalls[userID]
+	_, calleeBusy := voiceService.userCalls[calleeID]
+	if callerBusy || calleeBusy {
+		voiceService.mu.Unlock()
+		return domain.Call{}, handleServiceError(domain.ErrCallInProgress, op, "check if users are busy", log)
+	}
+
+	active := &activeCall{call: call, peers: make(map[string]*voicePeer)}
+	voiceService.calls[callID] = active
+	voiceService.userCalls[userID] = callID
+	voiceService.userCalls[calleeID] = callID
+	voiceService.armCallTimer(active, voiceService.ringTimeout, domain.CallEndMissed)
+	voiceService.mu.Unlock()
+
+	voiceService.publishCall(ctx, log, call)
+
+	log.Info("call started successfully", slog.String("call_id", callID))
+	return call, nil
+}
+
+// AcceptCall answers a ringing call, only the callee may accept it
+func (voiceService *VoiceService) AcceptCall(ctx context.Context, callID string) (domain.Call, error) {
+	const op = "services.voice.AcceptCall"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("call_id", callID))
+	log.Info("accepting call")
+
+	call, err := voiceService.changeCall(ctx, log, callID, domain.CallStateRinging, false, true, "")
+	if err != nil {
+		return domain.Call{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("call accepted successfully")
+	return call, nil
+}
+
+// DeclineCall rejects a ringing call, only the callee may decline it
+func (voiceService *VoiceService) DeclineCall(ctx context.Context, callID string) (domain.Call, error) {
+	const op = "services.voice.DeclineCall"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("call_id", callID))
+	log.Info("declining call")
+
+	call, err := voiceService.changeCall(ctx, log, callID, domain.CallStateRinging, false, true, domain.CallEndDeclined)
+	if err != nil {
+		return domain.Call{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("call declined successfully")
+	return call, nil
+}
+
+// CancelCall stops ringing before the callee answers, only the caller may cancel the call
+func (voiceService *VoiceService) CancelCall(ctx context.Context, callID string) (domain.Call, error) {
+	const op = "services.voice.CancelCall"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("call_id", callID))
+	log.Info("canceling call")
+
+	call, err := voiceService.changeCall(ctx, log, callID, domain.CallStateRinging, true, false, domain.CallEndCanceled)
+	if err != nil {
+		return domain.Call{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("call canceled successfully")
+	return call, nil
+}
+
+// HangUpCall ends an active call, either party may hang up
+func (voiceService *VoiceService) HangUpCall(ctx context.Context, callID string) (domain.Call, error) {
+	const op = "services.voice.HangUpCall"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("call_id", callID))
+	log.Info("hanging up call")
+
+	call, err := voiceService.changeCall(ctx, log, callID, domain.CallStateActive, true, true, domain.CallEndHangup)
+	if err != nil {
+		return domain.Call{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("call hung up successfully")
+	return call, nil
+}
+
+// RelayCallSignals opens the signaling stream of a party of an active call. It works like RelaySignals,
+// but closing the stream does not end the call: the call times out only when both parties stay
+// disconnected for longer than the reconnect timeout
+func (voiceService *VoiceService) RelayCallSignals(ctx context.Context, callID string, recv func() (domain.Signal, error), send func(domain.Signal) error) error {
+	const op = "services.voice.RelayCallSignals"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("call_id", callID))
+	log.Info("opening call signaling stream")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+	log = log.With(slog.String("user_id", userID))
+
+	peer := &voicePeer{
+		signals: make(chan domain.Signal, signalBufferSize),
+		done:    make(chan struct{}),
+	}
+
+	voiceService.mu.Lock()
+	active, ok := voiceService.calls[callID]
+	if !ok || !active.call.HasParty(userID) {
+		voiceService.mu.Unlock()
+		return handleServiceError(domain.ErrCallNotFound, op, "find call", log)
+	}
+	if active.call.State != domain.CallStateActive {
+		voiceService.mu.Unlock()
+		return handleServiceError(domain.ErrInvalidCallState, op, "check call state", log)
+	}
+	if previous, ok := active.peers[userID]; ok {
+		close(previous.done)
+	}
+	active.peers[userID] = peer
+	if active.timer != nil {
+		active.timer.Stop()
+		active.timer = nil
+	}
+	signalPeers(log, active.peers, domain.Signal{CallID: callID, FromUserID: userID, Type: domain.SignalPeerJoined})
+	otherID := active.call.OtherParty(userID)
+	voiceService.mu.Unlock()
+
+	defer func() {
+		voiceService.mu.Lock()
+		defer voiceService.mu.Unlock()
+
+		// a replaced stream must not disconnect the party
+		if active.peers[userID] != peer {
+			return
+		}
+		delete(active.peers, userID)
+
+		if voiceService.calls[callID] != active {
+			return
+		}
+		signalPeers(log, active.peers, domain.Signal{CallID: callID, FromUserID: userID, Type: domain.SignalPeerLeft})
+		if len(active.peers) == 0 {
+			log.Debug("both parties disconnected, waiting for reconnect")
+			voiceService.armCallTimer(active, voiceService.reconnectTimeout, domain.CallEndTimeout)
+		}
+	}()
+
+	return voiceService.pumpSignals(ctx, log, op, peer, recv, send, func(signal domain.Signal) {
+		signal.CallID = callID
+		signal.FromUserID = userID
+		signal.ToUserID = otherID
+
+		voiceService.relayCall(log, signal)
+	})
+}
+
+// changeCall moves the call out of the from state on behalf of the user, byCaller and byCallee tell
+// which party may do it. An empty endReason accepts the call, any other ends it with that reason
+func (voiceService *VoiceService) changeCall(ctx context.Context, log *slog.Logger, callID string, from string, byCaller bool, byCallee bool, endReason string) (domain.Call, error) {
+	const op = "services.voice.changeCall"
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.Call{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	voiceService.mu.Lock()
+	active, ok := voiceService.calls[callID]
+	if !ok || !active.call.HasParty(userID) {
+		voiceService.mu.Unlock()
+		return domain.Call{}, handleServiceError(domain.ErrCallNotFound, op, "find call", log)
+	}
+
+	if (userID == active.call.CallerID && !byCaller) || (userID == active.call.CalleeID && !byCallee) {
+		voiceService.mu.Unlock()
+		return domain.Call{}, handleServiceError(domain.ErrPermissionDenied, op, "check call party", log)
+	}
+
+	if active.call.State != from {
+		voiceService.mu.Unlock()
+		return domain.Call{}, handleServiceError(domain.ErrInvalidCallState, op, "check call state", log)
+	}
+
+	if endReason == "" {
+		active.call.State = domain.CallStateActive
+		active.call.AcceptedAt = time.Now()
+		// the parties have the reconnect timeout to open their signaling streams
+		voiceService.armCallTimer(active, voiceService.reconnectTimeout, domain.CallEndTimeout)
+		call := active.call
+		voiceService.mu.Unlock()
+
+		voiceService.publishCall(ctx, log, call)
+		return call, nil
+	}
+
+	call := voiceService.endCall(active, endReason)
+	voiceService.mu.Unlock()
+
+	voiceService.afterCallEnded(ctx, log, call)
+	return call, nil
+}
+
+// armCallTimer replaces the timer of the call, the caller holds the service lock
+func (voiceService *VoiceService) armCallTimer(active *activeCall, timeout time.Duration, endReason string) {
+	if active.timer != nil {
+		active.timer.Stop()
+	}
+
+	callID := active.call.ID
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		// timer is assigned under the service lock, so it is read under the lock too
+		voiceService.mu.Lock()
+		fired := timer
+		voiceService.mu.Unlock()
+
+		voiceService.expireCall(callID, fired, endReason)
+	})
+	active.timer = timer
+}
+
+// expireCall ends the call when its timer fires, timers replaced in the meantime are ignored
+func (voiceService *VoiceService) expireCall(callID string, timer *time.Timer, endReason string) {
+	log := voiceService.log.With(slog.String("op", "services.voice.expireCall"), slog.String("call_id", callID))
+
+	voiceService.mu.Lock()
+	active, ok := voiceService.calls[callID]
+	if !ok || active.timer != timer {
+		voiceService.mu.Unlock()
+		return
+	}
+	call := voiceService.endCall(active, endReason)
+	voiceService.mu.Unlock()
+
+	log.Info("call expired", slog.String("end_reason", endReason))
+	voiceService.afterCallEnded(context.Background(), log, call)
+}
+
+// endCall marks the call ended and closes its signaling streams, the caller holds the service lock
+func (voiceService *VoiceService) endCall(active *activeCall, endReason string) domain.Call {
+	if active.timer != nil {
+		active.timer.Stop()
+		active.timer = nil
+	}
+
+	active.call.State = domain.CallStateEnded
+	active.call.EndReason = endReason
+	active.call.EndedAt = time.Now()
+
+	delete(voiceService.calls, active.call.ID)
+	delete(voiceService.userCalls, active.call.CallerID)
+	delete(voiceService.userCalls, active.call.CalleeID)
+
+	for _, peer := range active.peers {
+		close(peer.done)
+	}
+
+	return active.call
+}
+
+// afterCallEnded tells both parties and records the call in the chat history
+func (voiceService *VoiceService) afterCallEnded(ctx context.Context, log *slog.Logger, call domain.Call) {
+	voiceService.publishCall(ctx, log, call)
+
+	log.Debug("finding chat of call")
+	chat, err := voiceService.chatProvider.FindChatByID(ctx, call.ChatID, call.CallerID)
+	if err != nil {
+		log.Warn("failed to find chat of call", logger.Err(err))
+		return
+	}
+
+	log.Debug("finding channel of call history message")
+	channel, err := findMainChannel(ctx, voiceService.channelProvider, chat.ID)
+	if err != nil {
+		log.Warn("failed to find channel of call history message", logger.Err(err))
+		return
+	}
+
+	duration := call.Duration()
+	message := domain.Message{
+		ChannelID: channel.ID,
+		Text:      callHistoryText(call),
+		SenderID:  call.CallerID,
+		CreatedAt: call.EndedAt,
+		Type:      domain.MessageTypeCall,
+		Call: &domain.CallRecord{
+			CallID:          call.ID,
+			CallerID:        call.CallerID,
+			Video:           call.Video,
+			EndReason:       call.EndReason,
+			DurationSeconds: int64(duration.Seconds()),
+		},
+	}
+
+	log.Debug("saving call history message")
+	if message.ID, err = voiceService.messageProvider.SaveMessage(ctx, message); err != nil {
+		log.Warn("failed to save call history message", logger.Err(err))
+		return
+	}
+
+	if len(chat.HiddenFor) > 0 {
+		log.Debug("unhiding private chat")
+		if err := voiceService.chatProvider.UnhideChat(ctx, chat.ID); err != nil {
+			log.Warn("failed to unhide private chat", logger.Err(err))
+		}
+	}
+
+	voiceService.eventBus.publishMessage(ctx, log, chat, channel, &message)
+}
+
+// publishCall sends the call state to both parties and records it in their update logs,
+// so a callee without an open stream still learns about the ring
+func (voiceService *VoiceService) publishCall(ctx context.Context, log *slog.Logger, call domain.Call) {
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_CallUpdated{
+			CallUpdated: mapper.ConvertCallToProto(call),
+		},
+	}
+	update := domain.Update{
+		Type:      domain.UpdateCallUpdated,
+		ChatID:    call.ChatID,
+		Call:      &call,
+		CreatedAt: time.Now(),
+	}
+
+	voiceService.eventBus.publish(ctx, log, []string{call.CallerID, call.CalleeID}, "", update, event)
+}
+
+// relayCall delivers the signal to the open signaling stream of the other party
+func (voiceService *VoiceService) relayCall(log *slog.Logger, signal domain.Signal) {
+	voiceService.mu.Lock()
+	defer voiceService.mu.Unlock()
+
+	active, ok := voiceService.calls[signal.CallID]
+	if !ok {
+		return
+	}
+
+	peer, ok := active.peers[signal.ToUserID]
+	if !ok {
+		log.Warn("signal recipient has no signaling stream", slog.String("to_user_id", signal.ToUserID))
+		return
+	}
+
+	select {
+	case peer.signals <- signal:
+	default:
+		log.Warn("failed to relay signal", slog.String("to_user_id", signal.ToUserID))
+	}
+}
+
+func callHistoryText(call domain.Call) string {
+	kind := "Call"
+	if call.Video {
+		kind = "Video call"
+	}
+
+	switch call.EndReason {
+	case domain.CallEndDeclined:
+		return kind + " declined"
+	case domain.CallEndCanceled:
+		return kind + " canceled"
+	case domain.CallEndMissed:
+		return "Missed " + strings.ToLower(kind)
+	default:
+		return fmt.Sprintf("%s ended, %s", kind, call.Duration().Round(time.Second))
+	}
+}
@@ -0,0 +1,554 @@
+package services
+
+import (
+	chatpb "chat-service/gen"
+	"chat-service/internal/domain"
+	"chat-service/internal/domain/interfaces"
+	"chat-service/internal/lib/logger"
+	"chat-service/internal/lib/mapper"
+	"chat-service/internal/lib/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const signalBufferSize = 32
+
+var relayedSignalTypes = []string{domain.SignalOffer, domain.SignalAnswer, domain.SignalCandidate}
+
+// voicePeer is an open signaling stream of a participant
+type voicePeer struct {
+	signals chan domain.Signal
+	// done is closed when the participant leaves or opens another signaling stream
+	done chan struct{}
+}
+
+type voiceRoom struct {
+	chatID       string
+	participants map[string]*domain.VoiceParticipant
+	peers        map[string]*voicePeer
+	// timers disconnect participants who have no signaling stream for the reconnect timeout
+	timers map[string]*time.Timer
+}
+
+// VoiceService tracks who is connected to voice channels and relays WebRTC signaling between them.
+// Voice state lives in memory of the instance and is lost on restart
+type VoiceService struct {
+	log             *slog.Logger
+	chatProvider    interfaces.ChatProvider
+	channelProvider interfaces.ChannelProvider
+	messageProvider interfaces.MessageProvider
+	userProvider    interfaces.UserProvider
+
+	eventBus *EventBus
+
+	ringTimeout      time.Duration
+	reconnectTimeout time.Duration
+
+	// rooms are keyed by channel_id
+	rooms map[string]*voiceRoom
+	// calls are keyed by call_id, userCalls maps user_id to the call the user is in
+	calls     map[string]*activeCall
+	userCalls map[string]string
+	mu        sync.Mutex
+}
+
+func NewVoiceService(
+	log *slog.Logger,
+	chatProvider interfaces.ChatProvider,
+	channelProvider interfaces.ChannelProvider,
+	messageProvider interfaces.MessageProvider,
+	userProvider interfaces.UserProvider,
+	eventBus *EventBus,
+	ringTimeout time.Duration,
+	reconnectTimeout time.Duration,
+) *VoiceService {
+	voiceService := &VoiceService{
+		log:             log,
+		chatProvider:    chatProvider,
+		channelProvider: channelProvider,
+		messageProvider: messageProvider,
+		userProvider:    userProvider,
+
+		eventBus: eventBus,
+
+		ringTimeout:      ringTimeout,
+		reconnectTimeout: reconnectTimeout,
+
+		rooms:     make(map[string]*voiceRoom),
+		calls:     make(map[string]*activeCall),
+		userCalls: make(map[string]string),
+	}
+
+	eventBus.onAccessLost(voiceService.evict)
+
+	return voiceService
+}
+
+func (voiceService *VoiceService) JoinVoiceChannel(ctx context.Context, channelID string, muted bool, deafened bool) ([]domain.VoiceParticipant, error) {
+	const op = "services.voice.JoinVoiceChannel"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("joining voice channel")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := voiceService.voiceChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Debug("checking if chat or channel is archived")
+	if chat.Archived || channel.Archived {
+		return nil, handleServiceError(domain.ErrArchived, op, "check if chat or channel is archived", log)
+	}
+
+	voiceService.mu.Lock()
+	room, ok := voiceService.rooms[channelID]
+	if !ok {
+		room = &voiceRoom{
+			chatID:       chat.ID,
+			participants: make(map[string]*domain.VoiceParticipant),
+			peers:        make(map[string]*voicePeer),
+			timers:       make(map[string]*time.Timer),
+		}
+		voiceService.rooms[channelID] = room
+	}
+
+	participant, rejoined := room.participants[userID]
+	if !rejoined {
+		participant = &domain.VoiceParticipant{UserID: userID, JoinedAt: time.Now()}
+		room.participants[userID] = participant
+		// the participant has the reconnect timeout to open the signaling stream
+		voiceService.armParticipantTimer(channelID, room, userID)
+	}
+	participant.Muted = muted || deafened
+	participant.Deafened = deafened
+
+	if !rejoined {
+		room.signal(log, domain.Signal{ChannelID: channelID, FromUserID: userID, Type: domain.SignalPeerJoined})
+	}
+	participants := room.list()
+	voiceService.mu.Unlock()
+
+	voiceService.broadcastParticipants(log, chat, channel, participants)
+
+	log.Info("voice channel joined successfully")
+	return participants, nil
+}
+
+func (voiceService *VoiceService) LeaveVoiceChannel(ctx context.Context, channelID string) error {
+	const op = "services.voice.LeaveVoiceChannel"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("leaving voice channel")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	if !voiceService.leave(ctx, log, channelID, userID) {
+		return handleServiceError(domain.ErrNotVoiceParticipant, op, "leave voice channel", log)
+	}
+
+	log.Info("voice channel left successfully")
+	return nil
+}
+
+// UpdateVoiceState changes mute and deafen state of the participant, deafened participants are always muted
+func (voiceService *VoiceService) UpdateVoiceState(ctx context.Context, channelID string, muted *bool, deafened *bool) (domain.VoiceParticipant, error) {
+	const op = "services.voice.UpdateVoiceState"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("updating voice state")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return domain.VoiceParticipant{}, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	chat, channel, err := voiceService.voiceChannelValidation(ctx, log, channelID, userID)
+	if err != nil {
+		return domain.VoiceParticipant{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	voiceService.mu.Lock()
+	room, ok := voiceService.rooms[channelID]
+	if !ok || room.participants[userID] == nil {
+		voiceService.mu.Unlock()
+		return domain.VoiceParticipant{}, handleServiceError(domain.ErrNotVoiceParticipant, op, "find voice participant", log)
+	}
+
+	participant := room.participants[userID]
+	if muted != nil {
+		participant.Muted = *muted
+	}
+	if deafened != nil {
+		participant.Deafened = *deafened
+	}
+	if participant.Deafened {
+		participant.Muted = true
+	}
+	updated := *participant
+	participants := room.list()
+	voiceService.mu.Unlock()
+
+	voiceService.broadcastParticipants(log, chat, channel, participants)
+
+	log.Info("voice state updated successfully")
+	return updated, nil
+}
+
+func (voiceService *VoiceService) GetVoiceParticipants(ctx context.Context, channelID string) ([]domain.VoiceParticipant, error) {
+	const op = "services.voice.GetVoiceParticipants"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("getting voice participants")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	if _, _, err := voiceService.voiceChannelValidation(ctx, log, channelID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	voiceService.mu.Lock()
+	defer voiceService.mu.Unlock()
+
+	room, ok := voiceService.rooms[channelID]
+	if !ok {
+		return nil, nil
+	}
+
+	log.Info("voice participants got successfully")
+	return room.list(), nil
+}
+
+// RelaySignals opens the signaling stream of a participant. Signals received from the client are
+// relayed to their recipients and signals of other participants are sent back until the client
+// disconnects or leaves. A participant who has no open stream for the reconnect timeout is
+// disconnected from the voice channel
+func (voiceService *VoiceService) RelaySignals(ctx context.Context, channelID string, recv func() (domain.Signal, error), send func(domain.Signal) error) error {
+	const op = "services.voice.RelaySignals"
+
+	log := voiceService.log.With(slog.String("op", op), slog.String("channel_id", channelID))
+	log.Info("opening signaling stream")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+	log = log.With(slog.String("user_id", userID))
+
+	if _, _, err := voiceService.voiceChannelValidation(ctx, log, channelID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	peer := &voicePeer{
+		signals: make(chan domain.Signal, signalBufferSize),
+		done:    make(chan struct{}),
+	}
+
+	voiceService.mu.Lock()
+	room, ok := voiceService.rooms[channelID]
+	if !ok || room.participants[userID] == nil {
+		voiceService.mu.Unlock()
+		return handleServiceError(domain.ErrNotVoiceParticipant, op, "find voice participant", log)
+	}
+	if previous, ok := room.peers[userID]; ok {
+		close(previous.done)
+	}
+	room.peers[userID] = peer
+	if timer, ok := room.timers[userID]; ok {
+		timer.Stop()
+		delete(room.timers, userID)
+	}
+	voiceService.mu.Unlock()
+
+	defer func() {
+		voiceService.mu.Lock()
+		defer voiceService.mu.Unlock()
+
+		// a replaced stream must not disconnect the participant
+		if room.peers[userID] != peer {
+			return
+		}
+		delete(room.peers, userID)
+
+		log.Debug("signaling stream closed, waiting for reconnect")
+		voiceService.armParticipantTimer(channelID, room, userID)
+	}()
+
+	return voiceService.pumpSignals(ctx, log, op, peer, recv, send, func(signal domain.Signal) {
+		signal.ChannelID = channelID
+		signal.FromUserID = userID
+
+		voiceService.relay(log, signal)
+	})
+}
+
+// pumpSignals passes valid signals received from the client to route and sends signals of the peer
+// back to the client until the client disconnects or the peer is closed
+func (voiceService *VoiceService) pumpSignals(ctx context.Context, log *slog.Logger, op string, peer *voicePeer, recv func() (domain.Signal, error), send func(domain.Signal) error, route func(domain.Signal)) error {
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			signal, err := recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			if !utils.Contains(relayedSignalTypes, signal.Type) || signal.ToUserID == "" {
+				recvErr <- domain.ErrInvalidSignal
+				return
+			}
+
+			route(signal)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("client disconnected or context canceled")
+			return nil
+
+		case <-peer.done:
+			log.Info("peer left or reconnected, closing signaling stream")
+			return nil
+
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				log.Info("client closed signaling stream")
+				return nil
+			}
+			return handleServiceError(err, op, "receive signal", log)
+
+		case signal := <-peer.signals:
+			if err := send(signal); err != nil {
+				return handleServiceError(err, op, "send signal", log)
+			}
+		}
+	}
+}
+
+// relay delivers the signal to the open signaling stream of its recipient
+func (voiceService *VoiceService) relay(log *slog.Logger, signal domain.Signal) {
+	voiceService.mu.Lock()
+	defer voiceService.mu.Unlock()
+
+	room, ok := voiceService.rooms[signal.ChannelID]
+	if !ok {
+		return
+	}
+
+	peer, ok := room.peers[signal.ToUserID]
+	if !ok {
+		log.Warn("signal recipient has no signaling stream", slog.String("to_user_id", signal.ToUserID))
+		return
+	}
+
+	select {
+	case peer.signals <- signal:
+	default:
+		log.Warn("failed to relay signal", slog.String("to_user_id", signal.ToUserID))
+	}
+}
+
+// leave disconnects the participant and tells everyone else, it reports whether the user was connected
+func (voiceService *VoiceService) leave(ctx context.Context, log *slog.Logger, channelID string, userID string) bool {
+	voiceService.mu.Lock()
+	room, ok := voiceService.rooms[channelID]
+	if !ok || room.participants[userID] == nil {
+		voiceService.mu.Unlock()
+		return false
+	}
+	participants := voiceService.removeParticipant(log, channelID, room, userID)
+	voiceService.mu.Unlock()
+
+	voiceService.afterParticipantsChanged(ctx, log, channelID, room.chatID, participants)
+	return true
+}
+
+// armParticipantTimer replaces the timer of the participant, the caller holds the service lock
+func (voiceService *VoiceService) armParticipantTimer(channelID string, room *voiceRoom, userID string) {
+	if timer, ok := room.timers[userID]; ok {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(voiceService.reconnectTimeout, func() {
+		// timer is assigned under the service lock, so it is read under the lock too
+		voiceService.mu.Lock()
+		fired := timer
+		voiceService.mu.Unlock()
+
+		voiceService.expireParticipant(channelID, userID, fired)
+	})
+	room.timers[userID] = timer
+}
+
+// expireParticipant disconnects the participant when their timer fires, timers replaced in the meantime are ignored
+func (voiceService *VoiceService) expireParticipant(channelID string, userID string, timer *time.Timer) {
+	log := voiceService.log.With(slog.String("op", "services.voice.expireParticipant"), slog.String("channel_id", channelID), slog.String("user_id", userID))
+
+	voiceService.mu.Lock()
+	room, ok := voiceService.rooms[channelID]
+	if !ok || room.timers[userID] != timer {
+		voiceService.mu.Unlock()
+		return
+	}
+	participants := voiceService.removeParticipant(log, channelID, room, userID)
+	voiceService.mu.Unlock()
+
+	log.Info("voice participant expired without signaling stream")
+	voiceService.afterParticipantsChanged(context.Background(), log, channelID, room.chatID, participants)
+}
+
+// evict disconnects participants who lost access to voice channels of the chat
+func (voiceService *VoiceService) evict(chatID string, lost accessMatcher) {
+	log := voiceService.log.With(slog.String("op", "services.voice.evict"), slog.String("chat_id", chatID))
+
+	changed := make(map[string][]domain.VoiceParticipant)
+	voiceService.mu.Lock()
+	for channelID, room := range voiceService.rooms {
+		if room.chatID != chatID {
+			continue
+		}
+		for userID := range room.participants {
+			if lost(channelID, userID) {
+				log.Info("evicting voice participant", slog.String("channel_id", channelID), slog.String("user_id", userID))
+				changed[channelID] = voiceService.removeParticipant(log, channelID, room, userID)
+			}
+		}
+	}
+	voiceService.mu.Unlock()
+
+	for channelID, participants := range changed {
+		voiceService.afterParticipantsChanged(context.Background(), log, channelID, chatID, participants)
+	}
+}
+
+// removeParticipant closes the stream of the participant and tells the rest of the room, it returns
+// the participants left. The room is dropped once empty, the caller holds the service lock
+func (voiceService *VoiceService) removeParticipant(log *slog.Logger, channelID string, room *voiceRoom, userID string) []domain.VoiceParticipant {
+	delete(room.participants, userID)
+	if peer, ok := room.peers[userID]; ok {
+		close(peer.done)
+		delete(room.peers, userID)
+	}
+	if timer, ok := room.timers[userID]; ok {
+		timer.Stop()
+		delete(room.timers, userID)
+	}
+	room.signal(log, domain.Signal{ChannelID: channelID, FromUserID: userID, Type: domain.SignalPeerLeft})
+
+	if len(room.participants) == 0 && voiceService.rooms[channelID] == room {
+		delete(voiceService.rooms, channelID)
+	}
+	return room.list()
+}
+
+// afterParticipantsChanged broadcasts the participants left in the channel, nothing is sent
+// when the channel or its chat was deleted
+func (voiceService *VoiceService) afterParticipantsChanged(ctx context.Context, log *slog.Logger, channelID string, chatID string, participants []domain.VoiceParticipant) {
+	log.Debug("finding channel to broadcast participants")
+	channel, err := voiceService.channelProvider.FindChannelByID(ctx, channelID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrChannelNotFound) {
+			log.Warn("failed to find channel to broadcast participants", logger.Err(err))
+		}
+		return
+	}
+	chat, err := voiceService.chatProvider.FindChatByID(ctx, chatID, "")
+	if err != nil {
+		if !errors.Is(err, domain.ErrChatNotFound) {
+			log.Warn("failed to find chat to broadcast participants", logger.Err(err))
+		}
+		return
+	}
+
+	voiceService.broadcastParticipants(log, chat, channel, participants)
+}
+
+// broadcastParticipants sends the participant list to every chat member who sees the channel
+func (voiceService *VoiceService) broadcastParticipants(log *slog.Logger, chat domain.Chat, channel domain.Channel, participants []domain.VoiceParticipant) {
+	event := &chatpb.ChatStreamResponse{
+		Payload: &chatpb.ChatStreamResponse_VoiceParticipants{
+			VoiceParticipants: &chatpb.VoiceParticipants{
+				ChannelId:    channel.ID,
+				Participants: mapper.ConvertVoiceParticipantsToProto(participants),
+			},
+		},
+	}
+
+	voiceService.eventBus.notify(log, chat.ID, channel.ViewerIDs(chat), "", event)
+}
+
+// voiceChannelValidation checks that the channel is a voice channel the user has access to
+func (voiceService *VoiceService) voiceChannelValidation(ctx context.Context, log *slog.Logger, channelID string, userID string) (domain.Chat, domain.Channel, error) {
+	const op = "services.voice.voiceChannelValidation"
+
+	chat, channel, err := channelValidation(ctx, log, voiceService.chatProvider, voiceService.channelProvider, channelID, userID, 0)
+	if err != nil {
+		return domain.Chat{}, domain.Channel{}, err
+	}
+
+	log.Debug("checking channel type")
+	if channel.Type != domain.ChannelTypeVoice {
+		return domain.Chat{}, domain.Channel{}, handleServiceError(domain.ErrNotVoiceChannel, op, "check channel type", log)
+	}
+
+	return chat, channel, nil
+}
+
+// signal sends a server signal to every other participant, the caller holds the service lock
+func (room *voiceRoom) signal(log *slog.Logger, signal domain.Signal) {
+	signalPeers(log, room.peers, signal)
+}
+
+// signalPeers sends a server signal to every peer except its sender, the caller holds the service lock
+func signalPeers(log *slog.Logger, peers map[string]*voicePeer, signal domain.Signal) {
+	for userID, peer := range peers {
+		if userID == signal.FromUserID {
+			continue
+		}
+
+		select {
+		case peer.signals <- signal:
+		default:
+			log.Warn("failed to send signal", slog.String("to_user_id", userID))
+		}
+	}
+}
+
+// list returns participants ordered by join time, the caller holds the service lock
+func (room *voiceRoom) list() []domain.VoiceParticipant {
+	participants := make([]domain.VoiceParticipant, 0, len(room.participants))
+	for _, participant := range room.participants {
+		participants = append(participants, *participant)
+	}
+
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].JoinedAt.Before(participants[j].JoinedAt)
+	})
+
+	return participants
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"chat-service/internal/domain"
+	"chat-service/internal/infrastructure/boltdb"
+)
+
+const testReconnectTimeout = 50 * time.Millisecond
+
+// loopbackClient is the client end of a signaling stream, signals written to in are received
+// by the service and signals sent by the service arrive at out
+type loopbackClient struct {
+	in   chan domain.Signal
+	out  chan domain.Signal
+	done chan error
+}
+
+// connect opens the signaling stream of userID, the stream ends when in is closed
+func connect(voiceService *VoiceService, channelID string, userID string) *loopbackClient {
+	client := &loopbackClient{
+		in:   make(chan domain.Signal),
+		out:  make(chan domain.Signal, signalBufferSize),
+		done: make(chan error, 1),
+	}
+
+	recv := func() (domain.Signal, error) {
+		signal, ok := <-client.in
+		if !ok {
+			return domain.Signal{}, io.EOF
+		}
+		return signal, nil
+	}
+	send := func(signal domain.Signal) error {
+		client.out <- signal
+		return nil
+	}
+
+	go func() {
+		client.done <- voiceService.RelaySignals(userContext(userID), channelID, recv, send)
+	}()
+	return client
+}
+
+// waitSignal returns the first signal of type signalType the client receives
+func (client *loopbackClient) waitSignal(t *testing.T, signalType string) domain.Signal {
+	t.Helper()
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case signal := <-client.out:
+			if signal.Type == signalType {
+				return signal
+			}
+		case <-timeout:
+			t.Fatalf("no %s signal received", signalType)
+		}
+	}
+}
+
+// waitClosed waits for the service to end the signaling stream
+func (client *loopbackClient) waitClosed(t *testing.T) {
+	t.Helper()
+
+	select {
+	case err := <-client.done:
+		if err != nil {
+			t.Fatalf("RelaySignals() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("signaling stream was not closed")
+	}
+}
+
+type voiceFixture struct {
+	storage          *boltdb.BoltDB
+	voiceService     *VoiceService
+	eventBus         *EventBus
+	chatID           string
+	channelID        string
+	privateChannelID string
+}
+
+func newVoiceFixture(t *testing.T) voiceFixture {
+	t.Helper()
+
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+	ctx := context.Background()
+
+	chatID, err := storage.SaveChat(ctx, domain.Chat{Type: "group", Name: "team", MemberIDs: []string{"alice", "bob"}})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+	channelID, err := storage.SaveChannel(ctx, domain.Channel{ChatID: chatID, Name: "lounge", Type: domain.ChannelTypeVoice})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+	privateChannelID, err := storage.SaveChannel(ctx, domain.Channel{
+		ChatID:    chatID,
+		Name:      "backstage",
+		Type:      domain.ChannelTypeVoice,
+		Private:   true,
+		MemberIDs: []string{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("SaveChannel() error = %v", err)
+	}
+
+	eventBus := NewEventBus(testLogger(), storage)
+	return voiceFixture{
+		storage:          storage,
+		voiceService:     NewVoiceService(testLogger(), storage, storage, storage, &fakeUsers{}, eventBus, time.Minute, testReconnectTimeout),
+		eventBus:         eventBus,
+		chatID:           chatID,
+		channelID:        channelID,
+		privateChannelID: privateChannelID,
+	}
+}
+
+func (f voiceFixture) join(t *testing.T, channelID string, userID string) {
+	t.Helper()
+
+	if _, err := f.voiceService.JoinVoiceChannel(userContext(userID), channelID, false, false); err != nil {
+		t.Fatalf("JoinVoiceChannel(%s) error = %v", userID, err)
+	}
+}
+
+// participantIDs returns users connected to the channel as alice sees them
+func (f voiceFixture) participantIDs(t *testing.T, channelID string) []string {
+	t.Helper()
+
+	participants, err := f.voiceService.GetVoiceParticipants(userContext("alice"), channelID)
+	if err != nil {
+		t.Fatalf("GetVoiceParticipants() error = %v", err)
+	}
+
+	var ids []string
+	for _, participant := range participants {
+		ids = append(ids, participant.UserID)
+	}
+	return ids
+}
+
+// waitParticipants polls until the channel has exactly want participants
+func (f voiceFixture) waitParticipants(t *testing.T, channelID string, want ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		ids := f.participantIDs(t, channelID)
+		if equalIDs(ids, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("participants = %v, want %v", ids, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitStreams polls until count signaling streams of the channel are registered,
+// signals to participants without a stream are dropped
+func (f voiceFixture) waitStreams(t *testing.T, channelID string, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		f.voiceService.mu.Lock()
+		registered := 0
+		if room, ok := f.voiceService.rooms[channelID]; ok {
+			registered = len(room.peers)
+		}
+		f.voiceService.mu.Unlock()
+		if registered == count {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d signaling streams registered, want %d", registered, count)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func equalIDs(got []string, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestVoiceParticipantExpiresWithoutStream(t *testing.T) {
+	f := newVoiceFixture(t)
+
+	f.join(t, f.channelID, "alice")
+	f.waitParticipants(t, f.channelID)
+}
+
+func TestVoiceParticipantReconnects(t *testing.T) {
+	f := newVoiceFixture(t)
+	f.join(t, f.channelID, "alice")
+
+	client := connect(f.voiceService, f.channelID, "alice")
+	time.Sleep(2 * testReconnectTimeout)
+	if ids := f.participantIDs(t, f.channelID); !equalIDs(ids, []string{"alice"}) {
+		t.Fatalf("participants with open stream = %v, want [alice]", ids)
+	}
+
+	close(client.in)
+	client.waitClosed(t)
+	client = connect(f.voiceService, f.channelID, "alice")
+	time.Sleep(2 * testReconnectTimeout)
+	if ids := f.participantIDs(t, f.channelID); !equalIDs(ids, []string{"alice"}) {
+		t.Fatalf("participants after reconnect = %v, want [alice]", ids)
+	}
+
+	close(client.in)
+	client.waitClosed(t)
+	f.waitParticipants(t, f.channelID)
+}
+
+func TestVoiceRelaysSignals(t *testing.T) {
+	f := newVoiceFixture(t)
+	f.join(t, f.channelID, "alice")
+	f.join(t, f.channelID, "bob")
+
+	alice := connect(f.voiceService, f.channelID, "alice")
+	bob := connect(f.voiceService, f.channelID, "bob")
+	t.Cleanup(func() {
+		close(alice.in)
+		close(bob.in)
+	})
+
+	f.waitStreams(t, f.channelID, 2)
+
+	alice.in <- domain.Signal{Type: domain.SignalOffer, ToUserID: "bob", Payload: "sdp"}
+
+	offer := bob.waitSignal(t, domain.SignalOffer)
+	if offer.FromUserID != "alice" || offer.ChannelID != f.channelID || offer.Payload != "sdp" {
+		t.Errorf("offer = %+v, want sdp from alice in channel %s", offer, f.channelID)
+	}
+}
+
+func TestVoiceEvictsParticipantsWhoLostAccess(t *testing.T) {
+	tests := []struct {
+		name      string
+		private   bool
+		closeFunc func(f voiceFixture)
+		wantLeft  []string
+	}{
+		{
+			name:      "removed from chat",
+			closeFunc: func(f voiceFixture) { f.eventBus.closeUserStreams(f.chatID, "bob") },
+			wantLeft:  []string{"alice"},
+		},
+		{
+			name:    "removed from private channel",
+			private: true,
+			closeFunc: func(f voiceFixture) {
+				f.eventBus.closeChannelUserStreams(f.chatID, f.privateChannelID, []string{"bob"})
+			},
+			wantLeft: []string{"alice"},
+		},
+		{
+			name:      "chat deleted",
+			closeFunc: func(f voiceFixture) { f.eventBus.closeChatStreams(f.chatID) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newVoiceFixture(t)
+			channelID := f.channelID
+			if tt.private {
+				channelID = f.privateChannelID
+			}
+			f.join(t, channelID, "alice")
+			f.join(t, channelID, "bob")
+			alice := connect(f.voiceService, channelID, "alice")
+			bob := connect(f.voiceService, channelID, "bob")
+			t.Cleanup(func() { close(alice.in) })
+			f.waitStreams(t, channelID, 2)
+
+			tt.closeFunc(f)
+
+			bob.waitClosed(t)
+			if tt.wantLeft == nil {
+				alice.waitClosed(t)
+			} else if peerLeft := alice.waitSignal(t, domain.SignalPeerLeft); peerLeft.FromUserID != "bob" {
+				t.Errorf("peer_left from %s, want bob", peerLeft.FromUserID)
+			}
+
+			f.voiceService.mu.Lock()
+			var left []string
+			if room, ok := f.voiceService.rooms[channelID]; ok {
+				left = make([]string, 0, len(room.participants))
+				for _, participant := range room.list() {
+					left = append(left, participant.UserID)
+				}
+			}
+			f.voiceService.mu.Unlock()
+			if !equalIDs(left, tt.wantLeft) {
+				t.Errorf("participants left = %v, want %v", left, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestCallRingIsRecordedForCallee(t *testing.T) {
+	f := newVoiceFixture(t)
+	ctx := context.Background()
+
+	chatID, err := f.storage.SaveChat(ctx, domain.Chat{Type: "private", MemberIDs: []string{"alice", "bob"}})
+	if err != nil {
+		t.Fatalf("SaveChat() error = %v", err)
+	}
+
+	call, err := f.voiceService.StartCall(userContext("alice"), chatID, false)
+	if err != nil {
+		t.Fatalf("StartCall() error = %v", err)
+	}
+	if _, err := f.voiceService.CancelCall(userContext("alice"), call.ID); err != nil {
+		t.Fatalf("CancelCall() error = %v", err)
+	}
+
+	// bob had no open stream, the ring and its end are waiting in his update log
+	updates, err := f.storage.GetUpdates(ctx, "bob", 0, 0)
+	if err != nil {
+		t.Fatalf("GetUpdates() error = %v", err)
+	}
+	var states []string
+	for _, update := range updates {
+		if update.Type == domain.UpdateCallUpdated && update.Call != nil && update.Call.ID == call.ID {
+			states = append(states, update.Call.State)
+		}
+	}
+	if want := []string{domain.CallStateRinging, domain.CallStateEnded}; !equalIDs(states, want) {
+		t.Errorf("recorded call states = %v, want %v", states, want)
+	}
+}
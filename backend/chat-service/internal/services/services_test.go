@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// fakeUsers knows usernames of users by user_id, nobody blocks anyone
+type fakeUsers struct {
+	usernames map[string]string
+}
+
+func (f *fakeUsers) GetUsernames(ctx context.Context, userIDs []string) (map[string]string, error) {
+	usernames := make(map[string]string, len(userIDs))
+	for _, userID := range userIDs {
+		if username, ok := f.usernames[userID]; ok {
+			usernames[userID] = username
+		}
+	}
+	return usernames, nil
+}
+
+func (f *fakeUsers) GetUserIDs(ctx context.Context, usernames []string) (map[string]string, error) {
+	userIDs := make(map[string]string, len(usernames))
+	for userID, username := range f.usernames {
+		for _, wanted := range usernames {
+			if username == wanted {
+				userIDs[username] = userID
+			}
+		}
+	}
+	return userIDs, nil
+}
+
+func (f *fakeUsers) GetBlockedBy(ctx context.Context, userIDs []string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (f *fakeUsers) CreatePlaceholderUsers(ctx context.Context, usernames []string) (map[string]string, error) {
+	userIDs := make(map[string]string, len(usernames))
+	for _, username := range usernames {
+		userIDs[username] = "placeholder-" + username
+	}
+	return userIDs, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// userContext is the context an authenticated call of userID is served with
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), "user_id", userID)
+}
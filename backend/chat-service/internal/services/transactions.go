@@ -0,0 +1,48 @@
+package services
+
+import (
+	"chat-service/internal/domain/interfaces"
+	"context"
+)
+
+type pendingEventsKey struct{}
+
+// pendingEvents collects events published inside a transaction
+type pendingEvents struct {
+	publish []func(ctx context.Context)
+}
+
+// inTransaction runs fn in a storage transaction. Events published inside fn are held back and
+// delivered after the commit, so a rolled back or retried attempt publishes nothing. Nested calls
+// join the outer transaction
+func inTransaction(ctx context.Context, transactor interfaces.Transactor, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		return fn(ctx)
+	}
+
+	pending := &pendingEvents{}
+	err := transactor.WithinTransaction(ctx, func(txCtx context.Context) error {
+		pending.publish = nil
+		return fn(context.WithValue(txCtx, pendingEventsKey{}, pending))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, publish := range pending.publish {
+		publish(ctx)
+	}
+	return nil
+}
+
+// holdUntilCommit queues publish when ctx belongs to a transaction and reports whether it did.
+// publish is later called with a context outside of the transaction
+func holdUntilCommit(ctx context.Context, publish func(ctx context.Context)) bool {
+	pending, ok := ctx.Value(pendingEventsKey{}).(*pendingEvents)
+	if !ok {
+		return false
+	}
+
+	pending.publish = append(pending.publish, publish)
+	return true
+}
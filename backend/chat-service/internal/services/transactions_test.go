@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeTransactor runs fn once, like a storage transaction that commits or rolls back
+type fakeTransactor struct {
+	calls int
+}
+
+func (f *fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+func TestInTransactionPublishesAfterCommit(t *testing.T) {
+	transactor := &fakeTransactor{}
+	published := 0
+
+	err := inTransaction(context.Background(), transactor, func(ctx context.Context) error {
+		if !holdUntilCommit(ctx, func(ctx context.Context) { published++ }) {
+			t.Fatal("holdUntilCommit() = false inside a transaction")
+		}
+
+		// nested calls join the outer transaction
+		if err := inTransaction(ctx, transactor, func(ctx context.Context) error {
+			holdUntilCommit(ctx, func(ctx context.Context) { published++ })
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if published != 0 {
+			t.Errorf("published %d events before the commit", published)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("inTransaction() error = %v", err)
+	}
+	if transactor.calls != 1 {
+		t.Errorf("WithinTransaction() called %d times, want 1", transactor.calls)
+	}
+	if published != 2 {
+		t.Errorf("published %d events after the commit, want 2", published)
+	}
+}
+
+func TestInTransactionDropsEventsOnRollback(t *testing.T) {
+	errRollback := errors.New("rollback")
+	published := 0
+
+	err := inTransaction(context.Background(), &fakeTransactor{}, func(ctx context.Context) error {
+		holdUntilCommit(ctx, func(ctx context.Context) { published++ })
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("inTransaction() error = %v, want %v", err, errRollback)
+	}
+	if published != 0 {
+		t.Errorf("published %d events of a rolled back transaction", published)
+	}
+}
+
+func TestHoldUntilCommitOutsideTransaction(t *testing.T) {
+	if holdUntilCommit(context.Background(), func(ctx context.Context) {}) {
+		t.Error("holdUntilCommit() = true outside a transaction")
+	}
+}
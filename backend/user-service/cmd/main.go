@@ -34,6 +34,9 @@ func main() {
 	log.Info("stopping application", slog.String("signal", sign.String()))
 
 	application.GRPCSrv.Stop()
+	if err := application.Storage.Close(); err != nil {
+		log.Error("failed to close storage", logger.Err(err))
+	}
 
 	log.Info("application stopped")
 }
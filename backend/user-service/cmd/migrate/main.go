@@ -0,0 +1,90 @@
+// Command migrate applies and reverts versioned migrations of the user-service database:
+//
+//	go run ./cmd/migrate -config ./config/local.yaml up
+//	go run ./cmd/migrate -config ./config/local.yaml -steps 1 down
+//	go run ./cmd/migrate -config ./config/local.yaml status
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"user-service/internal/config"
+	"user-service/internal/infrastructure/mongodb"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "path to config file")
+	steps := flag.Int("steps", 1, "number of migrations to revert with down")
+	flag.Parse()
+
+	if *configPath == "" || flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: migrate -config <path> [-steps n] up|down|status")
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoadByPath(*configPath)
+	if cfg.Yaml.Storage.Driver == config.StorageDriverEmbedded {
+		fmt.Println("the embedded storage has no migrations")
+		return
+	}
+
+	storage := mongodb.New(
+		cfg.DotEnv.Storage.StoragePath,
+		cfg.Yaml.Storage.StorageName,
+		cfg.Yaml.Storage.UsersColName,
+		cfg.Yaml.Storage.BlocksColName,
+		cfg.Yaml.Storage.MigrationsColName,
+	)
+	defer storage.Close()
+
+	if err := run(context.Background(), storage, flag.Arg(0), *steps); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate failed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, storage *mongodb.MongoDB, command string, steps int) error {
+	switch command {
+	case "up":
+		applied, err := storage.MigrateUp(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %d %s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		reverted, err := storage.MigrateDown(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d %s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := storage.MigrationStatuses(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED_AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown command %q, use up, down or status", command)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"log/slog"
 	"net"
 
+	userpb "user-service/gen"
 	"user-service/internal/domain/interfaces"
 	authgrpc "user-service/internal/grpc"
+	"user-service/internal/grpc/middleware"
 
 	"google.golang.org/grpc"
 )
@@ -21,11 +23,24 @@ func New(
 	log *slog.Logger,
 	authService interfaces.AuthService,
 	usersService interfaces.UsersService,
+	blocksService interfaces.BlocksService,
 	port int,
+	appSecret string,
+	serviceSecret string,
 ) *App {
-	gRPCServer := grpc.NewServer()
+	gRPCServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			middleware.ServiceAuthInterceptor(serviceSecret, userpb.Internal_ServiceDesc.ServiceName),
+			middleware.AuthInterceptor(
+				appSecret,
+				userpb.Auth_BlockUser_FullMethodName,
+				userpb.Auth_UnblockUser_FullMethodName,
+				userpb.Auth_ListBlockedUsers_FullMethodName,
+			),
+		),
+	)
 
-	authgrpc.Register(gRPCServer, authService, usersService)
+	authgrpc.Register(gRPCServer, authService, usersService, blocksService)
 
 	return &App{
 		log:        log,
@@ -1,46 +1,54 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 
 	appgrpc "user-service/internal/app/app-grpc"
 	"user-service/internal/config"
 
-	"user-service/internal/infrastructure/mongodb"
 	"user-service/internal/services"
 )
 
 type App struct {
 	GRPCSrv *appgrpc.App
+	Storage *Storage
 }
 
 func New(
 	log *slog.Logger,
 	cfg *config.Config,
 ) *App {
-	storage := mongodb.New(
-		cfg.DotEnv.Storage.StoragePath,
-		cfg.Yaml.Storage.StorageName,
-		cfg.Yaml.Storage.UsersColName,
-	)
+	storage := NewStorage(cfg)
+	if cfg.Yaml.Storage.MigrateOnStart {
+		if err := storage.MigrateUp(context.Background(), log); err != nil {
+			panic(err)
+		}
+	}
+	log.Info("storage initialized", slog.String("driver", cfg.Yaml.Storage.Driver))
 
 	authService := services.NewAuthService(
 		log,
-		storage,
-		storage,
+		storage.UserSaver,
+		storage.UserProvider,
 		cfg.Yaml.TokenTTL,
 		cfg.DotEnv.Secrets.AppSecret,
 	)
-	usersService := services.NewUsersService(log, storage)
+	usersService := services.NewUsersService(log, storage.UserSaver, storage.UserProvider)
+	blocksService := services.NewBlocksService(log, storage.UserProvider, storage.BlockProvider)
 
 	grpcApp := appgrpc.New(
 		log,
 		authService,
 		usersService,
+		blocksService,
 		cfg.Yaml.GRPC.Port,
+		cfg.DotEnv.Secrets.AppSecret,
+		cfg.DotEnv.Secrets.ServiceSecret,
 	)
 
 	return &App{
 		GRPCSrv: grpcApp,
+		Storage: storage,
 	}
 }
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"user-service/internal/config"
+	"user-service/internal/domain/interfaces"
+	"user-service/internal/infrastructure/boltdb"
+	"user-service/internal/infrastructure/mongodb"
+)
+
+// Storage holds the providers chosen by storage.driver
+type Storage struct {
+	// Mongo is nil with the embedded driver
+	Mongo *mongodb.MongoDB
+	// Embedded is nil unless the driver is embedded
+	Embedded *boltdb.BoltDB
+
+	UserSaver     interfaces.UserSaver
+	UserProvider  interfaces.UserProvider
+	BlockProvider interfaces.BlockProvider
+}
+
+func NewStorage(cfg *config.Config) *Storage {
+	switch cfg.Yaml.Storage.Driver {
+	case config.StorageDriverMongoDB:
+		mongo := mongodb.New(
+			cfg.DotEnv.Storage.StoragePath,
+			cfg.Yaml.Storage.StorageName,
+			cfg.Yaml.Storage.UsersColName,
+			cfg.Yaml.Storage.BlocksColName,
+			cfg.Yaml.Storage.MigrationsColName,
+		)
+		return &Storage{
+			Mongo:         mongo,
+			UserSaver:     mongo,
+			UserProvider:  mongo,
+			BlockProvider: mongo,
+		}
+
+	case config.StorageDriverEmbedded:
+		embedded := boltdb.New(cfg.Yaml.Storage.EmbeddedPath)
+		return &Storage{
+			Embedded:      embedded,
+			UserSaver:     embedded,
+			UserProvider:  embedded,
+			BlockProvider: embedded,
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown storage driver %q", cfg.Yaml.Storage.Driver))
+	}
+}
+
+// MigrateUp applies pending migrations, the embedded file needs none
+func (storage *Storage) MigrateUp(ctx context.Context, log *slog.Logger) error {
+	if storage.Mongo == nil {
+		return nil
+	}
+
+	applied, err := storage.Mongo.MigrateUp(ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range applied {
+		log.Info("migration applied", slog.Int("version", migration.Version), slog.String("name", migration.Name))
+	}
+
+	return nil
+}
+
+func (storage *Storage) Close() error {
+	if storage.Embedded != nil {
+		return storage.Embedded.Close()
+	}
+	return storage.Mongo.Close()
+}
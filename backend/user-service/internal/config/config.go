@@ -21,11 +21,14 @@ type DotEnvConfig struct {
 }
 
 type DotEnvStorage struct {
+	// StoragePath is the MongoDB connection string, it is not used with the embedded storage driver
 	StoragePath string
 }
 
 type SecretsConfig struct {
 	AppSecret string
+	// ServiceSecret authenticates calls from other backend services to the Internal gRPC service
+	ServiceSecret string
 }
 
 // Config opts from yaml file
@@ -42,9 +45,25 @@ type GRPCConfig struct {
 	Timeout time.Duration `yaml:"timeout"`
 }
 
+const (
+	StorageDriverMongoDB = "mongodb"
+	// StorageDriverEmbedded keeps all data in a single file at EmbeddedPath and needs no database server
+	StorageDriverEmbedded = "embedded"
+)
+
 type YamlStorage struct {
-	StorageName  string `yaml:"storage_name"`
-	UsersColName string `yaml:"users_collection"`
+	// Driver selects where users and blocks are kept
+	Driver string `yaml:"driver" env-default:"mongodb"`
+	// EmbeddedPath is the file of the embedded driver, ":memory:" keeps data only while the service runs
+	EmbeddedPath string `yaml:"embedded_path" env-default:":memory:"`
+
+	StorageName   string `yaml:"storage_name"`
+	UsersColName  string `yaml:"users_collection"`
+	BlocksColName string `yaml:"blocks_collection"`
+
+	MigrationsColName string `yaml:"migrations_collection" env-default:"migrations"`
+	// MigrateOnStart applies pending migrations when the service starts
+	MigrateOnStart bool `yaml:"migrate_on_start" env-default:"true"`
 }
 
 func MustLoad() *Config {
@@ -69,12 +88,18 @@ func MustLoadByPath(configPath string) *Config {
 
 	godotenv.Load()
 
+	storagePath := os.Getenv("STORAGE_PATH")
+	if cfg.Yaml.Storage.Driver != StorageDriverEmbedded {
+		storagePath = getEnvParam("STORAGE_PATH", "")
+	}
+
 	cfg.DotEnv = DotEnvConfig{
 		Storage: DotEnvStorage{
-			StoragePath: getEnvParam("STORAGE_PATH", ""),
+			StoragePath: storagePath,
 		},
 		Secrets: SecretsConfig{
-			AppSecret: getEnvParam("APP_SECRET", "app-secret"),
+			AppSecret:     getEnvParam("APP_SECRET", "app-secret"),
+			ServiceSecret: getEnvParam("SERVICE_SECRET", "service-secret"),
 		},
 	}
 
@@ -15,6 +15,9 @@ var (
 	ErrUserExists       = errors.New("user already exists")
 	ErrUsernameNotFound = errors.New("username not found")
 	ErrMissingUsernames = errors.New("missing usernames")
+
+	ErrBlockSelf      = errors.New("users cannot block themselves")
+	ErrUserNotBlocked = errors.New("user is not blocked")
 )
 
 var (
@@ -24,4 +27,5 @@ var (
 
 	ErrRequireUsernames = errors.New("usernames are required")
 	ErrRequireUserIDs   = errors.New("user_ids are required")
+	ErrRequireUserID    = errors.New("user_id is required")
 )
@@ -1,6 +1,10 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+
+	"user-service/internal/domain"
+)
 
 type AuthService interface {
 	Login(ctx context.Context, email string, password string) (token string, err error)
@@ -9,4 +13,12 @@ type AuthService interface {
 
 type UsersService interface {
 	GetStrings(ctx context.Context, userIDs []string, key string) (strings map[string]string, err error)
+	CreatePlaceholders(ctx context.Context, usernames []string) (userIDs map[string]string, err error)
+}
+
+type BlocksService interface {
+	BlockUser(ctx context.Context, blockedUserID string) (err error)
+	UnblockUser(ctx context.Context, blockedUserID string) (err error)
+	ListBlockedUsers(ctx context.Context) (blocks []domain.Block, usernames map[string]string, err error)
+	GetBlockedBy(ctx context.Context, userIDs []string) (blockers map[string][]string, err error)
 }
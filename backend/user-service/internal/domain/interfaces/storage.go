@@ -8,6 +8,7 @@ import (
 
 type UserSaver interface {
 	SaveUser(ctx context.Context, email string, passHash []byte, username string) (uid string, err error)
+	SavePlaceholderUser(ctx context.Context, username string) (uid string, err error)
 }
 
 type UserProvider interface {
@@ -15,3 +16,10 @@ type UserProvider interface {
 
 	GetStringsByField(ctx context.Context, fieldStrings []string, field string) (strings map[string]string, err error)
 }
+
+type BlockProvider interface {
+	SaveBlock(ctx context.Context, block domain.Block) (err error)
+	DeleteBlock(ctx context.Context, userID string, blockedUserID string) (err error)
+	FindUserBlocks(ctx context.Context, userID string) (blocks []domain.Block, err error)
+	FindBlockers(ctx context.Context, blockedUserIDs []string) (blocks []domain.Block, err error)
+}
@@ -1,9 +1,20 @@
 package domain
 
+import "time"
+
 type User struct {
 	ID       string `bson:"_id,omitempty"`
 	Email    string `bson:"email,omitempty"`
 	PassHash []byte `bson:"passHash"`
 	IsAdmin  bool   `bson:"is_admin,omitempty"`
 	Username string `bson:"username"`
+	// Placeholder users are created by history imports, they have no credentials and cannot log in
+	Placeholder bool `bson:"placeholder,omitempty"`
+}
+
+// Block means that UserID blocked BlockedUserID
+type Block struct {
+	UserID        string    `bson:"user_id"`
+	BlockedUserID string    `bson:"blocked_user_id"`
+	CreatedAt     time.Time `bson:"created_at"`
 }
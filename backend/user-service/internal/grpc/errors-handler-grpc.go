@@ -21,6 +21,11 @@ func getStatusError(err error) error {
 	case errors.Is(err, domain.ErrUsernameNotFound):
 		return status.Error(codes.NotFound, "username not found")
 
+	case errors.Is(err, domain.ErrBlockSelf):
+		return status.Error(codes.InvalidArgument, "users cannot block themselves")
+	case errors.Is(err, domain.ErrUserNotBlocked):
+		return status.Error(codes.NotFound, "user is not blocked")
+
 	case errors.Is(err, domain.ErrInvalidEmailFormat):
 		return status.Error(codes.InvalidArgument, "email format must be example@mail.com")
 	case errors.Is(err, domain.ErrInvalidPassFormat):
@@ -38,6 +43,8 @@ func getStatusError(err error) error {
 		return status.Error(codes.InvalidArgument, "usernames are required")
 	case errors.Is(err, domain.ErrRequireUserIDs):
 		return status.Error(codes.InvalidArgument, "user_ids are required")
+	case errors.Is(err, domain.ErrRequireUserID):
+		return status.Error(codes.InvalidArgument, "user_id is required")
 
 	default:
 		return status.Error(codes.Internal, "internal error")
@@ -0,0 +1,74 @@
+package grpccontroller
+
+import (
+	"context"
+
+	userpb "user-service/gen"
+	"user-service/internal/domain"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *serverAPI) BlockUser(ctx context.Context, req *userpb.BlockUserRequest) (*userpb.BlockUserResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, getStatusError(domain.ErrRequireUserID)
+	}
+
+	if err := s.blocks.BlockUser(ctx, req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &userpb.BlockUserResponse{}, nil
+}
+
+func (s *serverAPI) UnblockUser(ctx context.Context, req *userpb.UnblockUserRequest) (*userpb.UnblockUserResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, getStatusError(domain.ErrRequireUserID)
+	}
+
+	if err := s.blocks.UnblockUser(ctx, req.GetUserId()); err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &userpb.UnblockUserResponse{}, nil
+}
+
+func (s *serverAPI) ListBlockedUsers(ctx context.Context, req *userpb.ListBlockedUsersRequest) (*userpb.ListBlockedUsersResponse, error) {
+	blocks, usernames, err := s.blocks.ListBlockedUsers(ctx)
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	users := make([]*userpb.BlockedUser, 0, len(blocks))
+	for _, block := range blocks {
+		users = append(users, &userpb.BlockedUser{
+			UserId:    block.BlockedUserID,
+			Username:  usernames[block.BlockedUserID],
+			BlockedAt: timestamppb.New(block.CreatedAt),
+		})
+	}
+
+	return &userpb.ListBlockedUsersResponse{
+		Users: users,
+	}, nil
+}
+
+func (s *internalAPI) GetBlockedBy(ctx context.Context, req *userpb.GetBlockedByRequest) (*userpb.GetBlockedByResponse, error) {
+	if len(req.GetUserIds()) == 0 {
+		return nil, getStatusError(domain.ErrRequireUserIDs)
+	}
+
+	blockers, err := s.blocks.GetBlockedBy(ctx, req.GetUserIds())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	resp := make(map[string]*userpb.Blockers, len(blockers))
+	for userID, blockerIDs := range blockers {
+		resp[userID] = &userpb.Blockers{UserIds: blockerIDs}
+	}
+
+	return &userpb.GetBlockedByResponse{
+		Blockers: resp,
+	}, nil
+}
@@ -54,3 +54,18 @@ func validateGetUserIDs(req *userpb.GetUserIDsRequest) error {
 		return nil
 	}
 }
+
+func (s *internalAPI) CreatePlaceholderUsers(ctx context.Context, req *userpb.CreatePlaceholderUsersRequest) (*userpb.CreatePlaceholderUsersResponse, error) {
+	if len(req.GetUsernames()) == 0 {
+		return nil, getStatusError(domain.ErrRequireUsernames)
+	}
+
+	userIDs, err := s.users.CreatePlaceholders(ctx, req.GetUsernames())
+	if err != nil {
+		return nil, getStatusError(err)
+	}
+
+	return &userpb.CreatePlaceholderUsersResponse{
+		UserIds: userIDs,
+	}, nil
+}
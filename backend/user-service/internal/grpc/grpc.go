@@ -9,14 +9,24 @@ import (
 
 type serverAPI struct {
 	userpb.UnimplementedAuthServer
-	auth  interfaces.AuthService
-	users interfaces.UsersService
+	auth   interfaces.AuthService
+	users  interfaces.UsersService
+	blocks interfaces.BlocksService
+}
+
+// internalAPI serves the methods other backend services call on behalf of their users
+type internalAPI struct {
+	userpb.UnimplementedInternalServer
+	users  interfaces.UsersService
+	blocks interfaces.BlocksService
 }
 
 func Register(
 	gRPC *grpc.Server,
 	auth interfaces.AuthService,
 	users interfaces.UsersService,
+	blocks interfaces.BlocksService,
 ) {
-	userpb.RegisterAuthServer(gRPC, &serverAPI{auth: auth, users: users})
+	userpb.RegisterAuthServer(gRPC, &serverAPI{auth: auth, users: users, blocks: blocks})
+	userpb.RegisterInternalServer(gRPC, &internalAPI{users: users, blocks: blocks})
 }
@@ -0,0 +1,103 @@
+package grpccontroller
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	userpb "user-service/gen"
+	"user-service/internal/grpc/middleware"
+	"user-service/internal/infrastructure/boltdb"
+	"user-service/internal/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testServiceSecret = "service-secret"
+
+func newTestClient(t *testing.T) userpb.InternalClient {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage := boltdb.New(boltdb.MemoryPath)
+	t.Cleanup(func() { storage.Close() })
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		middleware.ServiceAuthInterceptor(testServiceSecret, userpb.Internal_ServiceDesc.ServiceName),
+	))
+	Register(
+		server,
+		services.NewAuthService(log, storage, storage, 0, "app-secret"),
+		services.NewUsersService(log, storage, storage),
+		services.NewBlocksService(log, storage, storage),
+	)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return userpb.NewInternalClient(conn)
+}
+
+func TestCreatePlaceholderUsersRequiresServiceSecret(t *testing.T) {
+	client := newTestClient(t)
+	req := &userpb.CreatePlaceholderUsersRequest{Usernames: []string{"imported_user"}}
+
+	_, err := client.CreatePlaceholderUsers(context.Background(), req)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("without secret: code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), middleware.ServiceSecretHeader, testServiceSecret)
+	first, err := client.CreatePlaceholderUsers(ctx, req)
+	if err != nil {
+		t.Fatalf("with secret: %v", err)
+	}
+	userID := first.GetUserIds()["imported_user"]
+	if userID == "" {
+		t.Fatalf("user_ids = %v, want imported_user", first.GetUserIds())
+	}
+
+	second, err := client.CreatePlaceholderUsers(ctx, req)
+	if err != nil {
+		t.Fatalf("repeated call: %v", err)
+	}
+	if got := second.GetUserIds()["imported_user"]; got != userID {
+		t.Errorf("repeated call returned user_id %q, want existing %q", got, userID)
+	}
+}
+
+func TestGetBlockedByRequiresServiceSecret(t *testing.T) {
+	client := newTestClient(t)
+	req := &userpb.GetBlockedByRequest{UserIds: []string{"1"}}
+
+	if _, err := client.GetBlockedBy(context.Background(), req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("without secret: code = %v, want %v", status.Code(err), codes.PermissionDenied)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), middleware.ServiceSecretHeader, testServiceSecret)
+	resp, err := client.GetBlockedBy(ctx, req)
+	if err != nil {
+		t.Fatalf("with secret: %v", err)
+	}
+	if len(resp.GetBlockers()) != 0 {
+		t.Errorf("blockers = %v, want none", resp.GetBlockers())
+	}
+}
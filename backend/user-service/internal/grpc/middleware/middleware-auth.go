@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"user-service/internal/lib/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func extractUserIDFromContext(ctx context.Context, appSecret string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	authHeader, exists := md["authorization"]
+	if !exists || len(authHeader) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization token is required")
+	}
+
+	tokenParts := strings.Split(authHeader[0], " ")
+	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+		return nil, status.Error(codes.Unauthenticated, "invalid token format")
+	}
+
+	claims, err := utils.ValidateToken(tokenParts[1], appSecret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return context.WithValue(ctx, "user_id", claims.UserID), nil
+}
+
+// AuthInterceptor requires a valid token for the given methods, the other methods
+// (registration, login and lookups made by chat-service) stay public
+func AuthInterceptor(appSecret string, methods ...string) grpc.UnaryServerInterceptor {
+	protected := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		protected[method] = struct{}{}
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if _, ok := protected[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		newCtx, err := extractUserIDFromContext(ctx, appSecret)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(newCtx, req)
+	}
+}
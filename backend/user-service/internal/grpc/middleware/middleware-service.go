@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceSecretHeader carries the secret shared by backend services
+const ServiceSecretHeader = "x-service-secret"
+
+// ServiceAuthInterceptor requires the service secret for every method of the given gRPC services,
+// so they cannot be called by users even if the port is reachable
+func ServiceAuthInterceptor(serviceSecret string, services ...string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !isServiceMethod(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		secret := md.Get(ServiceSecretHeader)
+		if len(secret) == 0 || subtle.ConstantTimeCompare([]byte(secret[0]), []byte(serviceSecret)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "service credentials are required")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func isServiceMethod(fullMethod string, services []string) bool {
+	for _, service := range services {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
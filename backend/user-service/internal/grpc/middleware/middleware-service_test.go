@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServiceAuthInterceptor(t *testing.T) {
+	interceptor := ServiceAuthInterceptor("secret", "user.Internal")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	tests := []struct {
+		name   string
+		method string
+		md     metadata.MD
+		want   codes.Code
+	}{
+		{"public method without secret", "/user.Auth/Login", nil, codes.OK},
+		{"internal method without secret", "/user.Internal/GetBlockedBy", nil, codes.PermissionDenied},
+		{"internal method with wrong secret", "/user.Internal/GetBlockedBy", metadata.Pairs(ServiceSecretHeader, "guess"), codes.PermissionDenied},
+		{"internal method with secret", "/user.Internal/GetBlockedBy", metadata.Pairs(ServiceSecretHeader, "secret"), codes.OK},
+		{"service name prefix only", "/user.InternalX/GetBlockedBy", nil, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
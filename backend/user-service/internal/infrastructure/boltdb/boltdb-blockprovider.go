@@ -0,0 +1,115 @@
+package boltdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"user-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+// Blocks are keyed by the user id and the blocked user id separated by a zero byte,
+// so blocks of a user are found by prefix
+
+func blockKey(userID string, blockedUserID string) []byte {
+	return append(append([]byte(userID), 0), blockedUserID...)
+}
+
+// SaveBlock stores the block, blocking an already blocked user keeps the original block
+func (b *BoltDB) SaveBlock(ctx context.Context, block domain.Block) error {
+	const op = "infrastructure.boltdb.blockprovider.SaveBlock"
+
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		blocks := tx.Bucket(blocksBucket)
+
+		key := blockKey(block.UserID, block.BlockedUserID)
+		if blocks.Get(key) != nil {
+			return nil
+		}
+		return put(blocks, key, block)
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (b *BoltDB) DeleteBlock(ctx context.Context, userID string, blockedUserID string) error {
+	const op = "infrastructure.boltdb.blockprovider.DeleteBlock"
+
+	var found bool
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		blocks := tx.Bucket(blocksBucket)
+
+		key := blockKey(userID, blockedUserID)
+		if found = blocks.Get(key) != nil; !found {
+			return nil
+		}
+		return blocks.Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return fmt.Errorf("%s : %w", op, domain.ErrUserNotBlocked)
+	}
+
+	return nil
+}
+
+// FindUserBlocks returns users blocked by the user, most recent first
+func (b *BoltDB) FindUserBlocks(ctx context.Context, userID string) ([]domain.Block, error) {
+	const op = "infrastructure.boltdb.blockprovider.FindUserBlocks"
+
+	blocks := []domain.Block{}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		prefix := append([]byte(userID), 0)
+
+		cursor := tx.Bucket(blocksBucket).Cursor()
+		for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+			block, err := decode[domain.Block](data)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, block)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	slices.SortStableFunc(blocks, func(a, b domain.Block) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return blocks, nil
+}
+
+// FindBlockers returns blocks where any of the users is the blocked one
+func (b *BoltDB) FindBlockers(ctx context.Context, blockedUserIDs []string) ([]domain.Block, error) {
+	const op = "infrastructure.boltdb.blockprovider.FindBlockers"
+
+	blocks := []domain.Block{}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(blocksBucket).ForEach(func(_, data []byte) error {
+			block, err := decode[domain.Block](data)
+			if err != nil {
+				return err
+			}
+			if slices.Contains(blockedUserIDs, block.BlockedUserID) {
+				blocks = append(blocks, block)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,98 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+
+	"user-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+// GetUserByField finds the user by email, username or _id
+func (b *BoltDB) GetUserByField(ctx context.Context, email, field string) (domain.User, error) {
+	const op = "infrastructure.boltdb.userprovider.GetUserByField"
+
+	var user domain.User
+	var found bool
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		key, ok := userKey(tx, email, field)
+		if !ok {
+			return nil
+		}
+
+		var err error
+		user, found, err = get[domain.User](tx.Bucket(usersBucket), key)
+		return err
+	})
+	if err != nil {
+		return domain.User{}, fmt.Errorf("%s : %w", op, err)
+	}
+	if !found {
+		return domain.User{}, fmt.Errorf("%s : %w", op, domain.ErrUserNotFound)
+	}
+
+	return user, nil
+}
+
+// userKey resolves the key of the user with the field value, ok is false if there is none
+func userKey(tx *bbolt.Tx, value string, field string) (key []byte, ok bool) {
+	switch field {
+	case "_id":
+		return parseID(value)
+	case "email":
+		key = tx.Bucket(emailsBucket).Get([]byte(value))
+	case "username":
+		key = tx.Bucket(usernamesBucket).Get([]byte(value))
+	}
+	return key, key != nil
+}
+
+func (b *BoltDB) GetStringsByField(ctx context.Context, fieldStrings []string, field string) (map[string]string, error) {
+	const op = "infrastructure.boltdb.userprovider.GetStringsByField"
+
+	result := make(map[string]string)
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		users := tx.Bucket(usersBucket)
+
+		for _, value := range fieldStrings {
+			var key []byte
+			var ok bool
+			switch field {
+			case "user_ids":
+				// strings that are not ids cannot match any user, so they are reported as missing
+				key, ok = userKey(tx, value, "_id")
+			case "usernames":
+				key, ok = userKey(tx, value, "username")
+			}
+			if !ok {
+				continue
+			}
+
+			user, found, err := get[domain.User](users, key)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+
+			switch field {
+			case "user_ids":
+				result[user.ID] = user.Username
+			case "usernames":
+				result[user.Username] = user.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, domain.ErrUserNotFound)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package boltdb
+
+import (
+	"context"
+	"fmt"
+
+	"user-service/internal/domain"
+
+	"go.etcd.io/bbolt"
+)
+
+func (b *BoltDB) SaveUser(ctx context.Context, email string, passHash []byte, username string) (string, error) {
+	const op = "infrastructure.boltdb.usersaver.SaveUser"
+
+	uid, err := b.saveUser(domain.User{Email: email, PassHash: passHash, Username: username})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return uid, nil
+}
+
+func (b *BoltDB) SavePlaceholderUser(ctx context.Context, username string) (string, error) {
+	const op = "infrastructure.boltdb.usersaver.SavePlaceholderUser"
+
+	uid, err := b.saveUser(domain.User{Username: username, Placeholder: true})
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	return uid, nil
+}
+
+// saveUser checks unique email and username and stores the user in one transaction,
+// so concurrent registrations cannot both succeed
+func (b *BoltDB) saveUser(user domain.User) (string, error) {
+	var uid string
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		emails := tx.Bucket(emailsBucket)
+		usernames := tx.Bucket(usernamesBucket)
+
+		if user.Email != "" && emails.Get([]byte(user.Email)) != nil {
+			return domain.ErrUserExists
+		}
+		if usernames.Get([]byte(user.Username)) != nil {
+			return domain.ErrUserExists
+		}
+
+		users := tx.Bucket(usersBucket)
+		seq, err := users.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := itob(seq)
+		uid = formatID(key)
+		user.ID = uid
+
+		if err := put(users, key, user); err != nil {
+			return err
+		}
+		if user.Email != "" {
+			if err := emails.Put([]byte(user.Email), key); err != nil {
+				return err
+			}
+		}
+		return usernames.Put([]byte(user.Username), key)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return uid, nil
+}
@@ -0,0 +1,121 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MemoryPath opens a database that lives only as long as the process
+const MemoryPath = ":memory:"
+
+var (
+	usersBucket = []byte("users")
+	// emailsBucket and usernamesBucket map unique fields to user ids
+	emailsBucket    = []byte("emails")
+	usernamesBucket = []byte("usernames")
+	blocksBucket    = []byte("blocks")
+)
+
+// BoltDB keeps users and blocks in a single bbolt file, documents are encoded the same way
+// as in MongoDB. IDs are sequence numbers passed around as decimal strings
+type BoltDB struct {
+	db *bbolt.DB
+	// temporary is set for MemoryPath, the file is removed on close
+	temporary bool
+}
+
+// New opens the database file at storagePath, MemoryPath opens a database in a temporary file
+// without fsync that is removed on close, so nothing outlives the process
+func New(storagePath string) *BoltDB {
+	options := &bbolt.Options{Timeout: time.Second}
+
+	temporary := storagePath == MemoryPath
+	if temporary {
+		file, err := os.CreateTemp("", "user-service-*.db")
+		if err != nil {
+			panic(err)
+		}
+		file.Close()
+
+		storagePath = file.Name()
+		options.NoSync = true
+		options.NoFreelistSync = true
+	}
+
+	db, err := bbolt.Open(storagePath, 0o600, options)
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{usersBucket, emailsBucket, usernamesBucket, blocksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return &BoltDB{db: db, temporary: temporary}
+}
+
+func (b *BoltDB) Close() error {
+	path := b.db.Path()
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+	if b.temporary {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+func itob(n uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, n)
+	return key
+}
+
+func formatID(key []byte) string {
+	return strconv.FormatUint(binary.BigEndian.Uint64(key), 10)
+}
+
+// parseID converts an id to its key, ok is false for malformed ids
+func parseID(id string) (key []byte, ok bool) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return itob(n), true
+}
+
+func put(bucket *bbolt.Bucket, key []byte, doc any) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
+
+// get decodes the document stored under key, found is false if there is none
+func get[T any](bucket *bbolt.Bucket, key []byte) (doc T, found bool, err error) {
+	data := bucket.Get(key)
+	if data == nil {
+		return doc, false, nil
+	}
+	doc, err = decode[T](data)
+	return doc, err == nil, err
+}
+
+func decode[T any](data []byte) (doc T, err error) {
+	err = bson.Unmarshal(data, &doc)
+	return doc, err
+}
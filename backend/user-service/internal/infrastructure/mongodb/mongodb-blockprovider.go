@@ -0,0 +1,69 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"user-service/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveBlock stores the block, blocking an already blocked user keeps the original block
+func (m *MongoDB) SaveBlock(ctx context.Context, block domain.Block) error {
+	const op = "infrastructure.mongodb.blockprovider.SaveBlock"
+
+	filter := bson.M{"user_id": block.UserID, "blocked_user_id": block.BlockedUserID}
+	update := bson.M{"$setOnInsert": block}
+
+	if _, err := m.blocksCol.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *MongoDB) DeleteBlock(ctx context.Context, userID string, blockedUserID string) error {
+	const op = "infrastructure.mongodb.blockprovider.DeleteBlock"
+
+	res, err := m.blocksCol.DeleteOne(ctx, bson.M{"user_id": userID, "blocked_user_id": blockedUserID})
+	if err != nil {
+		return fmt.Errorf("%s : %w", op, err)
+	}
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%s : %w", op, domain.ErrUserNotBlocked)
+	}
+
+	return nil
+}
+
+// FindUserBlocks returns users blocked by the user, most recent first
+func (m *MongoDB) FindUserBlocks(ctx context.Context, userID string) ([]domain.Block, error) {
+	const op = "infrastructure.mongodb.blockprovider.FindUserBlocks"
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	return m.findBlocks(ctx, op, bson.M{"user_id": userID}, opts)
+}
+
+// FindBlockers returns blocks where any of the users is the blocked one
+func (m *MongoDB) FindBlockers(ctx context.Context, blockedUserIDs []string) ([]domain.Block, error) {
+	const op = "infrastructure.mongodb.blockprovider.FindBlockers"
+
+	return m.findBlocks(ctx, op, bson.M{"blocked_user_id": bson.M{"$in": blockedUserIDs}}, options.Find())
+}
+
+func (m *MongoDB) findBlocks(ctx context.Context, op string, filter bson.M, opts *options.FindOptions) ([]domain.Block, error) {
+	cursor, err := m.blocksCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+	defer cursor.Close(ctx)
+
+	blocks := []domain.Block{}
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,299 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is a versioned change of the database. Applied versions are recorded in the
+// migrations collection, so every migration runs once. Up must be safe to repeat because
+// two instances starting at the same time may both run it
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, m *MongoDB) error
+	Down    func(ctx context.Context, m *MongoDB) error
+}
+
+// MigrationStatus tells whether the migration was applied and when
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type migrationRecord struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_indexes",
+		Up: func(ctx context.Context, m *MongoDB) error {
+			if err := checkUniqueUsers(ctx, m); err != nil {
+				return err
+			}
+			if err := removeDuplicateBlocks(ctx, m); err != nil {
+				return err
+			}
+			return m.createIndexes(ctx, initialIndexes(m))
+		},
+		Down: func(ctx context.Context, m *MongoDB) error {
+			return m.dropIndexes(ctx, initialIndexes(m))
+		},
+	},
+}
+
+// collectionIndexes are indexes of one collection, every index has a name so Down can drop it
+type collectionIndexes struct {
+	col     *mongo.Collection
+	indexes []mongo.IndexModel
+}
+
+// initialIndexes make email and username unique. Placeholder users have no email,
+// so the email index covers only users that have one
+func initialIndexes(m *MongoDB) []collectionIndexes {
+	return []collectionIndexes{
+		{m.usersCol, []mongo.IndexModel{
+			{
+				Keys: bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().
+					SetName("email").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"email": bson.M{"$exists": true}}),
+			},
+			{
+				Keys:    bson.D{{Key: "username", Value: 1}},
+				Options: options.Index().SetName("username").SetUnique(true),
+			},
+		}},
+		{m.blocksCol, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "blocked_user_id", Value: 1}},
+				Options: options.Index().SetName("user_id_blocked_user_id").SetUnique(true),
+			},
+			{
+				Keys:    bson.D{{Key: "blocked_user_id", Value: 1}},
+				Options: options.Index().SetName("blocked_user_id"),
+			},
+		}},
+	}
+}
+
+// maxReportedDuplicates caps how many duplicate values an error names
+const maxReportedDuplicates = 20
+
+// checkUniqueUsers fails naming emails and usernames held by more than one user. The unique
+// indexes can't be built over them, and which account keeps the value is up to an operator
+func checkUniqueUsers(ctx context.Context, m *MongoDB) error {
+	for _, field := range []string{"email", "username"} {
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{field: bson.M{"$exists": true}}}},
+			{{Key: "$group", Value: bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}}},
+			{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+			{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		}
+		cursor, err := m.usersCol.Aggregate(ctx, pipeline)
+		if err != nil {
+			return err
+		}
+
+		var groups []struct {
+			Value any `bson:"_id"`
+			Count int `bson:"count"`
+		}
+		err = cursor.All(ctx, &groups)
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			continue
+		}
+
+		var duplicates []string
+		for _, group := range groups[:min(len(groups), maxReportedDuplicates)] {
+			duplicates = append(duplicates, fmt.Sprintf("%v (%d users)", group.Value, group.Count))
+		}
+		if len(groups) > maxReportedDuplicates {
+			duplicates = append(duplicates, fmt.Sprintf("and %d more", len(groups)-maxReportedDuplicates))
+		}
+		return fmt.Errorf("%d %s values are held by more than one user, resolve them before migrating: %s",
+			len(groups), field, strings.Join(duplicates, ", "))
+	}
+
+	return nil
+}
+
+// removeDuplicateBlocks keeps one block of every user and blocked user pair, the copies carry
+// nothing the kept block doesn't
+func removeDuplicateBlocks(ctx context.Context, m *MongoDB) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"user_id": "$user_id", "blocked_user_id": "$blocked_user_id"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+	cursor, err := m.blocksCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group struct {
+			IDs []any `bson:"ids"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return err
+		}
+		if _, err := m.blocksCol.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}}); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// MigrateUp applies pending migrations in version order and returns the applied ones
+func (m *MongoDB) MigrateUp(ctx context.Context) ([]Migration, error) {
+	const op = "infrastructure.mongodb.migrations.MigrateUp"
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var done []Migration
+	for _, migration := range sortedMigrations() {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx, m); err != nil {
+			return done, fmt.Errorf("%s : migration %d %s : %w", op, migration.Version, migration.Name, err)
+		}
+
+		record := migrationRecord{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+		if _, err := m.migrationsCol.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return done, fmt.Errorf("%s : %w", op, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// MigrateDown reverts up to steps latest applied migrations and returns the reverted ones
+func (m *MongoDB) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	const op = "infrastructure.mongodb.migrations.MigrateDown"
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	sorted := sortedMigrations()
+	var done []Migration
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := sorted[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := migration.Down(ctx, m); err != nil {
+			return done, fmt.Errorf("%s : migration %d %s : %w", op, migration.Version, migration.Name, err)
+		}
+
+		if _, err := m.migrationsCol.DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return done, fmt.Errorf("%s : %w", op, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// MigrationStatuses lists all known migrations in version order
+func (m *MongoDB) MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "infrastructure.mongodb.migrations.MigrationStatuses"
+
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", op, err)
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range sortedMigrations() {
+		record, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: record.AppliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *MongoDB) appliedMigrations(ctx context.Context) (map[int]migrationRecord, error) {
+	cursor, err := m.migrationsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []migrationRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]migrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+func sortedMigrations() []Migration {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return sorted
+}
+
+func (m *MongoDB) createIndexes(ctx context.Context, indexes []collectionIndexes) error {
+	for _, col := range indexes {
+		if _, err := col.col.Indexes().CreateMany(ctx, col.indexes); err != nil {
+			return fmt.Errorf("%s : %w", col.col.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (m *MongoDB) dropIndexes(ctx context.Context, indexes []collectionIndexes) error {
+	for _, col := range indexes {
+		for _, index := range col.indexes {
+			_, err := col.col.Indexes().DropOne(ctx, *index.Options.Name)
+			var cmdErr mongo.CommandError
+			if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound") {
+				return fmt.Errorf("%s : %w", col.col.Name(), err)
+			}
+		}
+	}
+	return nil
+}
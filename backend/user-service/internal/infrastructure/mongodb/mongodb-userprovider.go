@@ -38,11 +38,12 @@ func (m *MongoDB) GetStringsByField(ctx context.Context, fieldStrings []string,
 
 	switch field {
 	case "user_ids":
+		// strings that are not ObjectIDs cannot match any user, so they are reported as missing
 		objectIDs := make([]primitive.ObjectID, 0, len(fieldStrings))
 		for _, id := range fieldStrings {
 			oid, err := primitive.ObjectIDFromHex(id)
 			if err != nil {
-				return nil, fmt.Errorf("%s: invalid ObjectID %s: %w", op, id, err)
+				continue
 			}
 			objectIDs = append(objectIDs, oid)
 		}
@@ -7,6 +7,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	_ "go.mongodb.org/mongo-driver/x/mongo/driver"
 )
 
@@ -15,6 +16,28 @@ func (m *MongoDB) SaveUser(ctx context.Context, email string, passHash []byte, u
 
 	res, err := m.usersCol.InsertOne(ctx, bson.M{"email": email, "passHash": passHash, "username": username})
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", fmt.Errorf("%s : %w", op, domain.ErrUserExists)
+		}
+		return "", fmt.Errorf("%s : %w", op, err)
+	}
+
+	objectID, ok := res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", fmt.Errorf("%s : %w", op, domain.ErrInternal)
+	}
+
+	return objectID.Hex(), nil
+}
+
+func (m *MongoDB) SavePlaceholderUser(ctx context.Context, username string) (string, error) {
+	const op = "infrastructure.mongodb.usersaver.SavePlaceholderUser"
+
+	res, err := m.usersCol.InsertOne(ctx, bson.M{"username": username, "placeholder": true})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", fmt.Errorf("%s : %w", op, domain.ErrUserExists)
+		}
 		return "", fmt.Errorf("%s : %w", op, err)
 	}
 
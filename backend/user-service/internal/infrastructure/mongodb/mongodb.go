@@ -9,12 +9,15 @@ import (
 )
 
 type MongoDB struct {
-	client   *mongo.Client
-	database *mongo.Database
-	usersCol *mongo.Collection
+	client    *mongo.Client
+	database  *mongo.Database
+	usersCol  *mongo.Collection
+	blocksCol *mongo.Collection
+
+	migrationsCol *mongo.Collection
 }
 
-func New(storagePath string, dbName string, usersColName string) *MongoDB {
+func New(storagePath string, dbName string, usersColName string, blocksColName string, migrationsColName string) *MongoDB {
 	clientOpts := options.Client().ApplyURI(storagePath)
 	client, err := mongo.Connect(context.Background(), clientOpts)
 	if err != nil {
@@ -24,9 +27,12 @@ func New(storagePath string, dbName string, usersColName string) *MongoDB {
 	db := client.Database(dbName)
 
 	return &MongoDB{
-		client:   client,
-		database: db,
-		usersCol: db.Collection(usersColName),
+		client:    client,
+		database:  db,
+		usersCol:  db.Collection(usersColName),
+		blocksCol: db.Collection(blocksColName),
+
+		migrationsCol: db.Collection(migrationsColName),
 	}
 }
 
@@ -0,0 +1,113 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// newTestMongoDB connects to MONGO_TEST_URI and uses a fresh database dropped after the test,
+// tests are skipped when the variable is not set. Migrations are left to the test
+func newTestMongoDB(t *testing.T) *MongoDB {
+	t.Helper()
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	m := New(uri, fmt.Sprintf("user_service_test_%d", time.Now().UnixNano()), "users", "blocks", "migrations")
+	t.Cleanup(func() {
+		m.database.Drop(context.Background())
+		m.Close()
+	})
+
+	return m
+}
+
+func TestMigrateUpWithDuplicates(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   []bson.M
+		blocks  []bson.M
+		wantErr []string
+	}{
+		{
+			name: "duplicate email",
+			users: []bson.M{
+				{"email": "alice@example.com", "username": "alice"},
+				{"email": "alice@example.com", "username": "alice2"},
+			},
+			wantErr: []string{"email", "alice@example.com (2 users)"},
+		},
+		{
+			name: "duplicate username",
+			users: []bson.M{
+				{"email": "bob@example.com", "username": "bob"},
+				{"username": "bob", "placeholder": true},
+			},
+			wantErr: []string{"username", "bob (2 users)"},
+		},
+		{
+			name: "placeholders without email",
+			users: []bson.M{
+				{"username": "tg_carol", "placeholder": true},
+				{"username": "tg_dave", "placeholder": true},
+			},
+		},
+		{
+			name: "duplicate blocks",
+			blocks: []bson.M{
+				{"user_id": "alice", "blocked_user_id": "bob"},
+				{"user_id": "alice", "blocked_user_id": "bob"},
+				{"user_id": "bob", "blocked_user_id": "alice"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMongoDB(t)
+			ctx := context.Background()
+
+			for _, user := range tt.users {
+				if _, err := m.usersCol.InsertOne(ctx, user); err != nil {
+					t.Fatalf("InsertOne() error = %v", err)
+				}
+			}
+			for _, block := range tt.blocks {
+				if _, err := m.blocksCol.InsertOne(ctx, block); err != nil {
+					t.Fatalf("InsertOne() error = %v", err)
+				}
+			}
+
+			_, err := m.MigrateUp(ctx)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatal("MigrateUp() error = nil, want duplicates named")
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("MigrateUp() error = %v, want it to name %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MigrateUp() error = %v", err)
+			}
+
+			count, err := m.blocksCol.CountDocuments(ctx, bson.M{})
+			if err != nil {
+				t.Fatalf("CountDocuments() error = %v", err)
+			}
+			if want := int64(min(len(tt.blocks), 2)); count != want {
+				t.Errorf("%d blocks left, want %d", count, want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"user-service/internal/domain"
@@ -8,6 +10,12 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+type Claims struct {
+	UserID string `json:"uid"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
 func NewToken(user domain.User, appSecret string, duration time.Duration) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 
@@ -23,3 +31,27 @@ func NewToken(user domain.User, appSecret string, duration time.Duration) (strin
 
 	return tokenString, nil
 }
+
+func ValidateToken(tokenString string, appSecret string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return []byte(appSecret), nil
+	})
+	if err != nil {
+		return nil, errors.New("invalid token signature")
+	}
+
+	claimsData, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+
+	if claimsData.ExpiresAt == nil || claimsData.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("token expired")
+	}
+
+	return claimsData, nil
+}
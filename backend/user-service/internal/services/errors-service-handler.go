@@ -21,6 +21,13 @@ func handleServiceError(err error, op, defaultLogText string, log *slog.Logger)
 		log.Error("user already exists", logger.Err(domain.ErrUserExists))
 		return fmt.Errorf("%s: %w", op, domain.ErrUserExists)
 
+	case errors.Is(err, domain.ErrBlockSelf):
+		log.Warn("user tried to block themselves", logger.Err(err))
+		return fmt.Errorf("%s: %w", op, domain.ErrBlockSelf)
+	case errors.Is(err, domain.ErrUserNotBlocked):
+		log.Warn("user is not blocked", logger.Err(err))
+		return fmt.Errorf("%s: %w", op, domain.ErrUserNotBlocked)
+
 	case errors.Is(err, domain.ErrInvalidCredentials):
 		log.Warn("invalid credentials", logger.Err(err))
 		return fmt.Errorf("%s: %w", op, domain.ErrInvalidCredentials)
@@ -2,7 +2,6 @@ package services
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"regexp"
 	"time"
@@ -81,15 +80,7 @@ func (authService *Auth) RegisterNewUser(ctx context.Context, email string, pass
 		return "", handleServiceError(err, op, "hash password", log)
 	}
 
-	log.Debug("checking if user exists")
-	user, err := authService.usrProvider.GetUserByField(ctx, email, "email")
-	if user.ID != "" {
-		return "", handleServiceError(domain.ErrUserExists, op, "get user by email", log)
-	}
-	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
-		return "", handleServiceError(err, op, "get user by email", log)
-	}
-
+	// unique email and username indexes reject duplicates, so concurrent registrations cannot both succeed
 	log.Debug("saving user")
 	id, err := authService.usrSaver.SaveUser(ctx, email, passHash, username)
 	if err != nil {
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"user-service/internal/domain"
+	"user-service/internal/domain/interfaces"
+	"user-service/internal/lib/utils"
+)
+
+type Blocks struct {
+	log           *slog.Logger
+	usrProvider   interfaces.UserProvider
+	blockProvider interfaces.BlockProvider
+}
+
+func NewBlocksService(log *slog.Logger, userProvider interfaces.UserProvider, blockProvider interfaces.BlockProvider) *Blocks {
+	return &Blocks{
+		log:           log,
+		usrProvider:   userProvider,
+		blockProvider: blockProvider,
+	}
+}
+
+func (blocksService *Blocks) BlockUser(ctx context.Context, blockedUserID string) error {
+	const op = "services.blocks.BlockUser"
+
+	log := blocksService.log.With(slog.String("op", op), slog.String("blocked_user_id", blockedUserID))
+	log.Info("blocking user")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	if userID == blockedUserID {
+		return handleServiceError(domain.ErrBlockSelf, op, "check blocked user", log)
+	}
+
+	log.Debug("checking if blocked user exists")
+	if _, err := blocksService.usrProvider.GetStringsByField(ctx, []string{blockedUserID}, "user_ids"); err != nil {
+		return handleServiceError(err, op, "check blocked user existence", log)
+	}
+
+	log.Debug("saving block")
+	block := domain.Block{
+		UserID:        userID,
+		BlockedUserID: blockedUserID,
+		CreatedAt:     time.Now(),
+	}
+	if err := blocksService.blockProvider.SaveBlock(ctx, block); err != nil {
+		return handleServiceError(err, op, "save block", log)
+	}
+
+	log.Info("user blocked successfully")
+	return nil
+}
+
+func (blocksService *Blocks) UnblockUser(ctx context.Context, blockedUserID string) error {
+	const op = "services.blocks.UnblockUser"
+
+	log := blocksService.log.With(slog.String("op", op), slog.String("blocked_user_id", blockedUserID))
+	log.Info("unblocking user")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("deleting block")
+	if err := blocksService.blockProvider.DeleteBlock(ctx, userID, blockedUserID); err != nil {
+		return handleServiceError(err, op, "delete block", log)
+	}
+
+	log.Info("user unblocked successfully")
+	return nil
+}
+
+// ListBlockedUsers returns blocks made by the caller with usernames of the blocked users
+func (blocksService *Blocks) ListBlockedUsers(ctx context.Context) ([]domain.Block, map[string]string, error) {
+	const op = "services.blocks.ListBlockedUsers"
+
+	log := blocksService.log.With(slog.String("op", op))
+	log.Info("listing blocked users")
+
+	log.Debug("getting user_id from context")
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, nil, handleServiceError(err, op, "get user_id from context", log)
+	}
+
+	log.Debug("finding blocks")
+	blocks, err := blocksService.blockProvider.FindUserBlocks(ctx, userID)
+	if err != nil {
+		return nil, nil, handleServiceError(err, op, "find blocks", log)
+	}
+
+	usernames := map[string]string{}
+	if len(blocks) > 0 {
+		blockedIDs := make([]string, 0, len(blocks))
+		for _, block := range blocks {
+			blockedIDs = append(blockedIDs, block.BlockedUserID)
+		}
+
+		// blocked users may have been deleted since, they are listed without a username
+		log.Debug("getting usernames of blocked users")
+		usernames, err = blocksService.usrProvider.GetStringsByField(ctx, blockedIDs, "user_ids")
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, handleServiceError(err, op, "get usernames of blocked users", log)
+		}
+	}
+
+	log.Info("blocked users listed successfully")
+	return blocks, usernames, nil
+}
+
+// GetBlockedBy returns users who blocked each of the given users, users nobody blocked are absent
+func (blocksService *Blocks) GetBlockedBy(ctx context.Context, userIDs []string) (map[string][]string, error) {
+	const op = "services.blocks.GetBlockedBy"
+
+	log := blocksService.log.With(slog.String("op", op), slog.Any("user_ids", userIDs))
+	log.Info("getting blockers")
+
+	blocks, err := blocksService.blockProvider.FindBlockers(ctx, userIDs)
+	if err != nil {
+		return nil, handleServiceError(err, op, "find blockers", log)
+	}
+
+	blockers := make(map[string][]string)
+	for _, block := range blocks {
+		blockers[block.BlockedUserID] = append(blockers[block.BlockedUserID], block.UserID)
+	}
+
+	log.Info("blockers got successfully")
+	return blockers, nil
+}
@@ -2,20 +2,24 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
+	"user-service/internal/domain"
 	"user-service/internal/domain/interfaces"
 )
 
 type Users struct {
 	log         *slog.Logger
+	usrSaver    interfaces.UserSaver
 	usrProvider interfaces.UserProvider
 }
 
-func NewUsersService(log *slog.Logger, userProvider interfaces.UserProvider) *Users {
+func NewUsersService(log *slog.Logger, userSaver interfaces.UserSaver, userProvider interfaces.UserProvider) *Users {
 	return &Users{
 		log:         log,
+		usrSaver:    userSaver,
 		usrProvider: userProvider,
 	}
 }
@@ -44,3 +48,56 @@ func (u *Users) GetStrings(ctx context.Context, fieldStrings []string, field str
 	log.Info(fmt.Sprintf("%s got successfully", field))
 	return result, nil
 }
+
+// CreatePlaceholders creates placeholder users for usernames that do not exist yet
+// and returns user_ids of all the usernames
+func (u *Users) CreatePlaceholders(ctx context.Context, usernames []string) (map[string]string, error) {
+	const op = "services.users.CreatePlaceholders"
+
+	log := u.log.With(slog.String("op", op), slog.Any("usernames", usernames))
+	log.Info("creating placeholder users")
+
+	log.Debug("validating usernames")
+	for _, username := range usernames {
+		if !usernameRegex.MatchString(username) {
+			return nil, handleServiceError(domain.ErrInvalidUsernameFormat, op, "validate usernames", log)
+		}
+	}
+
+	log.Debug("finding existing users")
+	userIDs, err := u.usrProvider.GetStringsByField(ctx, usernames, "usernames")
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, handleServiceError(err, op, "find existing users", log)
+	}
+	if userIDs == nil {
+		userIDs = make(map[string]string, len(usernames))
+	}
+
+	created := 0
+	for _, username := range usernames {
+		if _, exists := userIDs[username]; exists {
+			continue
+		}
+
+		log.Debug("saving placeholder user", slog.String("username", username))
+		userID, err := u.usrSaver.SavePlaceholderUser(ctx, username)
+		if errors.Is(err, domain.ErrUserExists) {
+			// the user was registered or created by another import after the lookup above
+			log.Debug("finding concurrently created user", slog.String("username", username))
+			found, err := u.usrProvider.GetStringsByField(ctx, []string{username}, "usernames")
+			if err != nil {
+				return nil, handleServiceError(err, op, "find concurrently created user", log)
+			}
+			userIDs[username] = found[username]
+			continue
+		}
+		if err != nil {
+			return nil, handleServiceError(err, op, "save placeholder user", log)
+		}
+		userIDs[username] = userID
+		created++
+	}
+
+	log.Info("placeholder users created successfully", slog.Int("created", created))
+	return userIDs, nil
+}